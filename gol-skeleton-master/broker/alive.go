@@ -0,0 +1,28 @@
+package main
+
+import "time"
+
+// 存活细胞数的推送：distributor 不用每隔一段时间自己问 GetAliveCellsCount，而是一直挂着一个
+// WatchAlive，broker 每隔它订阅的间隔报一次（net/rpc 没有服务端推送，和 Poll 一样是长轮询）
+
+// 以下类型必须和 distributor 那边保持一致
+type AliveArgs struct {
+	Interval time.Duration // 过多久报一次
+	JobID    string
+}
+
+type AliveReport struct {
+	Turn  int // 数的是这一回合结束时的世界
+	Count int
+}
+
+// minAliveInterval：间隔太短就退化成忙轮询了
+const minAliveInterval = 10 * time.Millisecond
+
+// WatchAlive：等 args.Interval 之后报告当前的回合数和存活细胞数。
+// 两者在 mu 下一起读（见 aliveAt），正在算的回合不会算进来，数量总是对得上某一个完整的回合
+func (s *simulation) WatchAlive(args AliveArgs, reply *AliveReport) error {
+	time.Sleep(max(args.Interval, minAliveInterval))
+	reply.Turn, reply.Count = s.aliveAt()
+	return nil
+}
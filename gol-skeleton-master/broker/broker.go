@@ -1,139 +1,245 @@
 package main
 
 import (
+	"bytes"
+	"errors"
+	"flag"
 	"fmt"
+	"log/slog"
 	"net"
+	"net/http"
 	"net/rpc"
+	"os"
+	"strconv"
 	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"uk.ac.bris.cs/gameoflife/discovery"
+	"uk.ac.bris.cs/gameoflife/hashlife"
+	"uk.ac.bris.cs/gameoflife/transport"
+	"uk.ac.bris.cs/gameoflife/util"
 )
 
-// Broker 负责调度 worker，并维护当前世界（用于 AliveCellsCount）
+// Broker 负责调度 worker，同时托管几个互不相干的模拟（job），各有各的世界、回合数和观察者。
+// 每个 RPC 的参数里都带着 JobID 指明是哪一个（见 dispatch.go）；JobID 为空的是默认 job，
+// 不带 JobID 的旧客户端用的都是它
 type Broker struct {
+	mu   sync.Mutex
+	jobs map[string]*simulation // 按 JobID，默认 job 一直都在
+
+	submitted int           // SubmitJob 起过的 JobID 个数
+	queueTail chan struct{} // 最后提交的任务结束时关闭，下一个任务等它
+
+	xfers transfers // net/rpc 大世界的分块上传 / 下载，传输编号在所有 job 之间唯一
+}
+
+// newBroker：只有默认 job 的 broker
+func newBroker() *Broker {
+	return &Broker{jobs: map[string]*simulation{"": newSimulation("")}}
+}
+
+// simulation：一个 job 的模拟，维护当前世界（用于 AliveCellsCount）
+type simulation struct {
+	id  string       // JobID，默认 job 为空
+	log *slog.Logger // 带上 job 的 logger
+
 	currentWorld [][]uint8
-	mu           sync.Mutex // 保护 currentWorld
+	turn         int                // StartSimulation 之后已经完成的回合数
+	boundary     util.Boundary      // 世界边界之外看到什么，StartSimulation 时设置
+	rule         util.Rule          // 演化规则，StartSimulation 时设置
+	halo         *haloTopology      // halo 模式下的行段分配，此时 currentWorld 只是初始世界
+	life         *hashlife.Universe // engine = hashlife 时的世界，此时 currentWorld 也只是初始世界
+	bg           *background        // Detach 之后在后台推进的循环，没有时为 nil
+	batch        *batch             // SubmitJob 提交的最近一个任务，没有时为 nil
+	paused       chan struct{}      // 暂停时非 nil，Resume 时关闭
+	mu           sync.Mutex         // 保护 currentWorld / turn / boundary / rule / halo / life / bg / batch / paused
+
+	turnMu sync.Mutex // 保证 NextTurn 一次只算一回合
+
+	subs subscriptions // -observe 的只读观察者
+}
+
+func newSimulation(id string) *simulation {
+	log := logger
+	if id != "" {
+		log = logger.With("job", id)
+	}
+	return &simulation{id: id, log: log}
 }
 
 // WorldParams 必须和 distributor / worker 那边保持一致
 type WorldParams struct {
 	ImageWidth  int
 	ImageHeight int
-	World       [][]uint8
+	World       util.World
+	Turn        int           // StartSimulation：从第几回合接着算（从快照恢复时不为 0）
+	Boundary    util.Boundary // 世界边界之外看到什么，空字符串表示环绕
+	Rule        string        // B/S 记法的规则，空字符串表示 B3/S23
+	JobID       string        // 哪个 job，为空表示默认 job
 }
 
 // 每个 worker 客户端连接
 type WorkerClient struct {
-	addr   string
-	client *rpc.Client
+	addr   string // host:port，gRPC worker 带 grpc:// 前缀，Unix 域套接字是 unix:///路径，反向连接是 reverse://id
+	client transport.Client
+	score  float64    // 注册时上报的 benchmark 分数（细胞/秒），0 表示未知
+	caps   workerCaps // 注册时上报的能力（见 caps.go）
 }
 
 // 发送给 worker 的任务：，对应的 worldPart 带上下边界
 type Task struct {
 	StartY, EndY int
-	WorldPart    [][]uint8
+	WorldPart    util.World
+	Boundary     util.Boundary // 左右边界由 worker 按它处理，上下的 halo 行已经按它填好
+	Rule         string
 }
 
 var (
 	workerList  []WorkerClient
 	workerMutex sync.Mutex
+
+	logger = util.Logger("broker")
 )
 
 // ProcessTurn：接收 Distributor 的请求，分发任务给 Worker，合并结果
-func (b *Broker) ProcessTurn(params WorldParams, reply *[][]uint8) error {
-	// 1. 先更新当前世界（如果 AliveCellsCount 在下一时刻被问到）
-	b.mu.Lock()
-	b.currentWorld = params.World
-	b.mu.Unlock()
-
-	// 2. 初始化新世界
-	newWorld := make([][]uint8, params.ImageHeight)
-	for i := range newWorld {
-		newWorld[i] = make([]uint8, params.ImageWidth)
+func (s *simulation) ProcessTurn(params WorldParams, reply *util.World) error {
+	boundary, err := util.ParseBoundary(string(params.Boundary))
+	if err != nil {
+		return err
 	}
-
-	// 3. 拷贝一份当前的 worker 列表，避免并发问题
-	workerMutex.Lock()
-	numWorkers := len(workerList) //获取当前已注册的工作节点数量 。初始化
-	workers := make([]WorkerClient, numWorkers)
-	copy(workers, workerList) //获取当前时刻 避免变化影响逻辑
-	workerMutex.Unlock()
-
-	if numWorkers == 0 {
-		return fmt.Errorf("no workers available")
+	params.Boundary = boundary
+	rule, err := util.ParseRule(params.Rule)
+	if err != nil {
+		return err
 	}
+	params.Rule = rule.String()
 
-	rowsPerWorker := params.ImageHeight / numWorkers
-
-	var wg sync.WaitGroup
-	var resultMu sync.Mutex
-
-	// 4. 分给每个 worker 一段 y 区间
-	for i, worker := range workers { //// i 是当前工作节点的索引，worker 是对应的工作节点客户端（用于后续分配任务）
-		startY := i * rowsPerWorker
-		endY := startY + rowsPerWorker
-		if i == numWorkers-1 {
-			endY = params.ImageHeight // 最后一个 worker 把剩下的都算完 将结束行设为世界总高度
-		}
-
-		// 构造 worldPart：核心行 + 上下边界（循环边界）
-		worldPartLen := endY - startY
-		worldPart := make([][]uint8, worldPartLen+2)
+	// 1. 先更新当前世界（如果 AliveCellsCount 在下一时刻被问到）
+	s.stopBackground()
+	s.mu.Lock()
+	s.currentWorld = params.World
+	s.boundary = boundary
+	s.rule = rule
+	s.halo.release()
+	s.halo = nil // 无状态调用总是走 scatter 模式
+	s.life = nil
+	s.mu.Unlock()
+
+	newWorld, _, _, err := evolve(params, s.log)
+	if err != nil {
+		s.log.Error("process turn failed", "err", err)
+		return err
+	}
 
-		// 核心行复制
-		copy(worldPart[1:worldPartLen+1], params.World[startY:endY])
+	// 更新 Broker 保存的世界为新状态
+	s.mu.Lock()
+	s.currentWorld = newWorld
+	s.mu.Unlock()
+	s.subs.resetAll()
 
-		// 上边界：startY 的上一行（循环）
-		worldPart[0] = params.World[(startY-1+params.ImageHeight)%params.ImageHeight]
+	*reply = newWorld
+	return nil
+}
 
-		// 下边界：endY 的下一行（循环）
-		worldPart[worldPartLen+1] = params.World[endY%params.ImageHeight]
+// evolve：把 params.World 切成几段分发给 worker，合并出下一代世界，
+// 顺便拼起各个 worker 报上来的翻转细胞，不用再对比新旧世界
+// log 带上调用方的上下文（比如 turn），worker 失败时能看出是哪一回合
+func evolve(params WorldParams, log *slog.Logger) ([][]uint8, []util.Cell, StepTiming, error) {
+	// 2. 初始化新世界
+	newWorld := util.NewWorld(params.ImageWidth, params.ImageHeight)
 
-		task := Task{
-			StartY:    startY,
-			EndY:      endY,
-			WorldPart: worldPart,
-		}
+	// 3. 拷贝一份当前的 worker 列表，避免并发问题；不够 min_workers 个时先等一会儿（见 warmup.go）
+	workers, err := waitForWorkers(params.JobID, log)
+	if err != nil {
+		return nil, nil, StepTiming{}, err
+	}
+	workers = trusted(workers) // 有任务超时还没返回的 worker 先不用
 
+	var wg sync.WaitGroup
+	var resultMu sync.Mutex
+	var firstErr error
+	var flipped []util.Cell
+	var timing StepTiming        // 只记最慢的 worker，Elapsed 由 step 填
+	rows := make(map[string]int) // 这一回合每个 worker 算了多少行
+	failed := newFailedSet()
+
+	// 4. 按配置切分世界（行 / 列 / 块），jobs[k] 先交给 workers[firsts[k]]（失败时换别的 worker）
+	jobs, firsts := splitWorld(params, workers, currentConfig())
+	spec := newSpeculation(len(jobs))
+	for k, j := range jobs {
 		wg.Add(1)
-		go func(w WorkerClient, t Task) {
+		go func(first int, j job) {
 			defer wg.Done()
 
-			var workerResult [][]uint8
-			// 调用 Worker.ProcessPart —— 下面 worker.go 会实现这个
-			err := w.client.Call("Worker.ProcessPart", t, &workerResult)
+			// 调用 Worker.ProcessPart / ProcessTile，失败会自动换 worker / 本地计算
+			start := time.Now()
+			workerResult, addr, err := runTask(j, first, workers, failed, spec, log)
+			if err == nil && !j.fits(workerResult.Rows) {
+				err = util.Errorf(util.CodeWorkerFailed, "worker returned %d rows for %s", len(workerResult.Rows), j)
+			}
 			if err != nil {
-				fmt.Printf("Worker %s process task failed: %v\n", w.addr, err)
+				resultMu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				resultMu.Unlock()
 				return
 			}
 
 			// 合并结果到 newWorld
 			resultMu.Lock()
-			for y := 0; y < len(workerResult); y++ {
-				newWorld[t.StartY+y] = workerResult[y]
+			for y := 0; y < len(workerResult.Rows); y++ {
+				copy(newWorld[j.y0+y][j.x0:j.x1], workerResult.Rows[y])
+			}
+			flipped = append(flipped, workerResult.Flipped...)
+			if addr != "" {
+				rows[addr] += j.y1 - j.y0
 			}
+			timing.observe(addr, time.Since(start))
 			resultMu.Unlock()
-		}(worker, task)
+			// 结果已经拷进 newWorld，解码用的缓冲区留给下一回合
+			util.FreeWorld(workerResult.Rows)
+		}(firsts[k], j)
 	}
 
 	// 5. 等所有 worker 完成
 	wg.Wait()
 
-	// 6. 更新 Broker 保存的世界为新状态
-	b.mu.Lock()
-	b.currentWorld = newWorld
-	b.mu.Unlock()
-
-	*reply = newWorld
-	return nil
+	// 有一段算不出来就整轮失败，不能把带空洞的世界交给 distributor
+	if firstErr != nil {
+		return nil, nil, StepTiming{}, fmt.Errorf("turn failed: %w", firstErr)
+	}
+	sched.assign(params.JobID, rows)
+	return newWorld, flipped, timing, nil
 }
 
 // GetAliveCellsCount： Distributor 通过 RPC 查询当前世界的存活细胞数量
 // 参数类型用 struct{}，和 distributor 中的 struct{}{} 一致。
-func (b *Broker) GetAliveCellsCount(_ struct{}, reply *int) error {
-	b.mu.Lock()
-	defer b.mu.Unlock()
+func (s *simulation) GetAliveCellsCount(_ struct{}, reply *int) error {
+	_, *reply = s.aliveAt()
+	return nil
+}
+
+// aliveAt：当前的回合数和这一回合结束时的存活细胞数。两者都在 mu 下一起更新，
+// 这里也在 mu 下一起读，数的一定是 turn 那一回合的世界
+func (s *simulation) aliveAt() (turn, count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
+	// halo 模式下用 worker 每回合上报的数量
+	if s.halo != nil {
+		return s.turn, s.halo.aliveCount()
+	}
+	if s.life != nil {
+		return s.turn, s.life.Population()
+	}
+
+	if cells, ok := util.Flat(s.currentWorld); ok {
+		return s.turn, bytes.Count(cells, []byte{255})
+	}
 	aliveCount := 0
-	for _, row := range b.currentWorld {
+	for _, row := range s.currentWorld {
 		for _, cell := range row {
 			//
 			if cell == 255 {
@@ -141,78 +247,361 @@ func (b *Broker) GetAliveCellsCount(_ struct{}, reply *int) error {
 			}
 		}
 	}
+	return s.turn, aliveCount
+}
+
+// RegisterArgs：worker 启动时上报自己的地址，必须和 worker 那边保持一致
+type RegisterArgs struct {
+	Address   string  // worker 对 broker 可见的 IP / 主机名，unix 时是 socket 文件的路径，reverse 时是反向连接的 id
+	Port      int     // worker RPC 监听端口，unix 和 reverse 时不用
+	Transport string  // "grpc" 表示用 gRPC 回拨，"unix" 表示通过 Unix 域套接字回拨，"reverse" 表示用 worker 连过来的反向连接，默认 net/rpc
+	Score     float64 // benchmark 分数（细胞/秒），用来按比例分行，0 表示未知
+
+	// 能力协商（见 caps.go），旧 worker 不填
+	Version int             // worker 的 util.ProtocolVersion
+	Codecs  []util.Codec    // 能解码的世界编码
+	Cores   int             // CPU 核数
+	Rules   []util.RuleKind // 能跑的规则
+}
 
-	*reply = aliveCount
+// RegisterWorker：worker 启动后主动调用，broker 回拨它的地址并加入 workerList
+// 这样扩容时只需要启动新的 worker，不用改 broker 源码重新编译
+func (b *Broker) RegisterWorker(args RegisterArgs, reply *bool) error {
+	address, err := args.address()
+	if err != nil {
+		return err
+	}
+	caps, err := capsOf(args)
+	if err != nil {
+		return err
+	}
+	if err := registerWorker(address, args.Score, caps); err != nil {
+		return err
+	}
+	*reply = true
 	return nil
 }
 
-// 注册一个 worker 建立RPC连接
-func registerWorker(address string) error {
-	client, err := rpc.Dial("tcp", address) //TCP连接并初始化RPC客户端
+// DeregisterWorker：worker 排空（Drain / SIGTERM）时调用，之后的回合不再给它分配任务。
+// 已经发出去的任务还在它那里算，连接先不关，等它算完自己退出；halo 模式下它的行段先交给别的 worker（见 retire.go）
+func (b *Broker) DeregisterWorker(args RegisterArgs, reply *bool) error {
+	address, err := args.address()
 	if err != nil {
-		fmt.Printf("Connect worker %s failed: %v\n", address, err)
+		return err
+	}
+	if w, ok := takeWorker(address); ok {
+		logger.Info("worker deregistered, draining", "worker", address)
+		if err := b.moveOff(w); err != nil {
+			// 它反正要走了，行段留在它那里的 job 下一回合失败，交给 distributor 决定
+			logger.Warn("move bands off deregistered worker failed", "worker", address, "err", err)
+		}
+		time.AfterFunc(deregisterGrace, func() { _ = w.client.Close() })
+	}
+	*reply = true
+	return nil
+}
+
+// address：worker 的回拨地址，gRPC worker 带上 grpc:// 前缀，Unix 域套接字是 unix:// 加路径，
+// 反向连接是 reverse:// 加 id
+func (args RegisterArgs) address() (string, error) {
+	if args.Transport == "unix" {
+		if args.Address == "" {
+			return "", fmt.Errorf("invalid worker socket: empty path")
+		}
+		return transport.UnixScheme + args.Address, nil
+	}
+	if args.Transport == "reverse" {
+		if args.Address == "" {
+			return "", fmt.Errorf("invalid reverse worker: empty id")
+		}
+		return transport.ReverseScheme + args.Address, nil
+	}
+	if args.Address == "" || args.Port <= 0 {
+		return "", fmt.Errorf("invalid worker address %q:%d", args.Address, args.Port)
+	}
+	address := net.JoinHostPort(args.Address, strconv.Itoa(args.Port))
+	if args.Transport == "grpc" {
+		address = transport.GRPCScheme + address
+	}
+	return address, nil
+}
+
+// 注册一个 worker 建立RPC连接，score 为 0 时沿用之前上报过的分数
+func registerWorker(address string, score float64, caps workerCaps) error {
+	// TCP连接并初始化RPC客户端（grpc:// 地址走 gRPC，unix:// 走 Unix 域套接字），配置了多条连接时建一个连接池
+	cfg := currentConfig()
+	client, err := transport.DialPool(address, transport.Options{TCP: cfg.TCP.options()}, cfg.WorkerConns)
+	if err != nil {
+		logger.Warn("connect worker failed", "worker", address, "err", err)
 		return err
 	}
 
 	workerMutex.Lock()
-	workerList = append(workerList, WorkerClient{
-		addr:   address,
-		client: client,
-	})
+	// 同一个地址重复注册（比如 worker 重启）时替换旧连接，避免同一个 worker 被分到两份任务
+	replaced := false
+	for i := range workerList {
+		if workerList[i].addr == address {
+			_ = workerList[i].client.Close()
+			workerList[i].client = client
+			if score > 0 {
+				workerList[i].score = score
+			}
+			workerList[i].caps = caps
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		workerList = append(workerList, WorkerClient{
+			addr:   address,
+			client: client,
+			score:  score,
+			caps:   caps,
+		})
+	}
 	workerMutex.Unlock()
+	workerJoined()
 
-	fmt.Printf("Worker %s registered successfully\n", address)
+	logger.Info("worker registered", "worker", address, "score", score, "caps", caps)
 	return nil
 }
 
 func main() {
-	workerAddresses := []string{
-		// EC2-A
-		"172.31.90.169:8031",
-		"172.31.90.169:8032",
-		"172.31.90.169:8033",
-		// EC2-B
-		"172.31.17.148:8031",
-		"172.31.17.148:8032",
-		"172.31.17.148:8033",
-
-		// EC2-C
-		"172.31.16.85:8031",
-		"172.31.16.85:8032",
-		"172.31.16.85:8033",
-		"172.31.16.85:8034",
-	}
-
-	// 注册所有 worker
-	for _, addr := range workerAddresses { // 注册每个 worker
-		if err := registerWorker(addr); err != nil {
-			fmt.Printf("Register worker %s failed\n", addr)
+	configPath := flag.String("config", "", "path to broker config file (JSON), see broker.example.json; SIGHUP reloads it")
+	logLevel := flag.String("log-level", "info", "log level: debug, info, warn or error")
+	logFormat := flag.String("log-format", "text", "log format: text or json")
+	pprofAddr := flag.String("pprof", "", "serve net/http/pprof profiles on this address, e.g. :6060 (empty = off)")
+	flag.DurationVar(&stopTimeout, "stop-timeout", stopTimeout, "on SIGTERM or SIGINT, how long to wait for turns in flight before exiting anyway")
+	flag.Parse()
+
+	if err := util.ConfigureLogging(os.Stderr, *logFormat, *logLevel); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if *pprofAddr != "" {
+		addr, err := util.StartPprof(*pprofAddr)
+		if err != nil {
+			logger.Error("start pprof failed", "addr", *pprofAddr, "err", err)
+			os.Exit(1)
 		}
+		logger.Info("serving pprof", "addr", addr)
 	}
 
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		logger.Error("load config failed", "path", *configPath, "err", err)
+		os.Exit(1)
+	}
+	cfg = overrideFromFlags(cfg)
+	if cfg.MinWorkers < 0 {
+		logger.Error("-min-workers must not be negative", "min_workers", cfg.MinWorkers)
+		os.Exit(2)
+	}
+	if cfg.WorkerConns < 1 {
+		logger.Error("-worker-conns must be at least 1", "worker_conns", cfg.WorkerConns)
+		os.Exit(2)
+	}
+	if cfg.WaitWorkers < 0 {
+		logger.Error("-wait-workers must not be negative", "wait_workers", time.Duration(cfg.WaitWorkers))
+		os.Exit(2)
+	}
+	if cfg.Mode != modeScatter && cfg.Mode != modeHalo {
+		logger.Error("unknown -mode", "mode", cfg.Mode, "expected", []string{modeScatter, modeHalo})
+		os.Exit(2)
+	}
+	if !validEngine(cfg.Engine) {
+		logger.Error("unknown -engine", "engine", cfg.Engine, "expected", []string{engineWorkers, engineHashLife})
+		os.Exit(2)
+	}
+	if !validPartition(cfg.Partition) {
+		logger.Error("unknown -partition", "partition", cfg.Partition, "expected", []string{partitionRows, partitionColumns, partitionTiles})
+		os.Exit(2)
+	}
+	if _, _, err := parseTiles(cfg.Tiles); err != nil {
+		logger.Error("invalid -tiles", "err", err)
+		os.Exit(2)
+	}
+	if cfg.RebalanceEvery < 0 {
+		logger.Error("-rebalance-every must not be negative", "rebalance_every", cfg.RebalanceEvery)
+		os.Exit(2)
+	}
+	if cfg.SpeculateAfter != 0 && cfg.SpeculateAfter < 1 {
+		logger.Error("-speculate-after must be 0 or at least 1", "speculate_after", cfg.SpeculateAfter)
+		os.Exit(2)
+	}
+	if cfg.Retry.MaxAttempts < 0 || cfg.Retry.MaxTurnFailures < 0 || cfg.Retry.TaskTimeout < 0 {
+		logger.Error("-max-attempts, -max-turn-failures and -task-timeout must not be negative", "max_attempts", cfg.Retry.MaxAttempts,
+			"max_turn_failures", cfg.Retry.MaxTurnFailures, "task_timeout", time.Duration(cfg.Retry.TaskTimeout))
+		os.Exit(2)
+	}
+	if cfg.TCP.ReadBuffer < 0 || cfg.TCP.WriteBuffer < 0 {
+		logger.Error("-tcp-read-buffer and -tcp-write-buffer must not be negative", "read_buffer", cfg.TCP.ReadBuffer, "write_buffer", cfg.TCP.WriteBuffer)
+		os.Exit(2)
+	}
+	if cfg.Checkpoint.Interval <= 0 {
+		logger.Error("-checkpoint-interval must be positive", "interval", time.Duration(cfg.Checkpoint.Interval))
+		os.Exit(2)
+	}
+	var consul *discovery.Consul
+	if cfg.Discovery != "" {
+		if consul, err = discovery.Parse(cfg.Discovery); err != nil {
+			logger.Error("invalid -discovery", "err", err)
+			os.Exit(2)
+		}
+	}
+	if cfg.WorkersDNS != "" {
+		if _, _, err := net.SplitHostPort(cfg.WorkersDNS); err != nil {
+			logger.Error("invalid -workers-dns, want host:port", "workers_dns", cfg.WorkersDNS, "err", err)
+			os.Exit(2)
+		}
+	}
+
+	// 注册配置文件里的所有 worker（也可以由 worker 启动时自己注册）
+	applyConfig(cfg)
+	if *configPath != "" {
+		watchConfig(*configPath)
+	}
+	// 服务发现：Consul 里登记的 worker 自动加进来，key 消失就移除
+	if consul != nil {
+		watchDiscovery(consul)
+		logger.Info("watching discovery", "discovery", consul)
+	}
+	if cfg.WorkersDNS != "" {
+		watchWorkersDNS(cfg.WorkersDNS)
+		logger.Info("resolving workers from DNS", "workers_dns", cfg.WorkersDNS, "interval", dnsInterval)
+	}
+
+	// 心跳检测：掉线的 worker 会被自动移出 workerList
+	startHeartbeat()
+
 	// regist  Broker RPC service
-	broker := new(Broker)
+	broker := newBroker()
+
+	// 上次退出前写过检查点的话接着跑
+	if path := cfg.Checkpoint.Path; path != "" {
+		cp, err := loadCheckpoint(path)
+		switch {
+		case err == nil:
+			broker.defaultJob().restore(cp)
+			logger.Info("resumed from checkpoint", "path", path, "turn", cp.Turn, "saved", cp.Saved)
+		case errors.Is(err, os.ErrNotExist):
+			logger.Info("no checkpoint yet, starting fresh", "path", path)
+		default:
+			logger.Error("load checkpoint failed", "path", path, "err", err)
+			os.Exit(1)
+		}
+	}
+	broker.defaultJob().startCheckpointing()
+
 	if err := rpc.Register(broker); err != nil {
-		fmt.Printf("Register broker RPC service failed: %v\n", err)
-		return
+		logger.Error("register broker RPC service failed", "err", err)
+		os.Exit(1)
 	}
 
-	// listen 8080
-	listener, err := net.Listen("tcp", ":8080")
+	// listen
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.Port))
 	if err != nil {
-		fmt.Printf("Broker listen on port 8080 failed: %v\n", err)
-		return
+		logger.Error("listen failed", "port", cfg.Port, "err", err)
+		os.Exit(1)
 	}
+	listener = transport.TuneListener(listener, cfg.TCP.options())
 	defer listener.Close()
 
-	fmt.Println("Broker started successfully, listening on :8080...")
+	logger.Info("broker started", "port", cfg.Port, "mode", cfg.Mode, "engine", cfg.Engine, "partition", cfg.Partition, "auth", cfg.Token != "")
+
+	// 可选的 gRPC 入口，和 net/rpc 共用同一个 broker
+	var grpcServer *grpc.Server
+	var grpcListener net.Listener
+	if cfg.GRPCPort > 0 {
+		grpcListener, err = net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPCPort))
+		if err != nil {
+			logger.Error("listen failed", "grpc_port", cfg.GRPCPort, "err", err)
+			os.Exit(1)
+		}
+		grpcListener = transport.TuneListener(grpcListener, cfg.TCP.options())
+		grpcServer = transport.NewGRPCServer(broker, nil, cfg.Token)
+		go func() {
+			if err := grpcServer.Serve(grpcListener); err != nil {
+				select {
+				case <-stopping:
+				default:
+					logger.Error("gRPC server stopped", "err", err)
+				}
+			}
+		}()
+		logger.Info("broker gRPC listening", "grpc_port", cfg.GRPCPort)
+	}
+
+	// 可选的 HTTP+JSON 接口，给脚本和仪表盘用
+	var httpServer *http.Server
+	if cfg.HTTPPort > 0 {
+		if httpServer, err = serveHTTP(broker, cfg.HTTPPort, cfg.Token); err != nil {
+			logger.Error("listen failed", "http_port", cfg.HTTPPort, "err", err)
+			os.Exit(1)
+		}
+		logger.Info("broker HTTP listening", "http_port", cfg.HTTPPort)
+	}
+
+	// Shutdown 之后关掉监听，accept 循环随之退出
+	go func() {
+		<-shutdown
+		time.Sleep(shutdownGrace)
+		if grpcServer != nil {
+			grpcServer.Stop()
+		}
+		if httpServer != nil {
+			_ = httpServer.Close()
+		}
+		_ = listener.Close()
+	}()
+
+	// SIGTERM / SIGINT：先关掉 net/rpc 和 gRPC 的监听，已经连上的客户端等正在算的回合算完再断开。
+	// HTTP 接口留到最后，停止的过程照样能看
+	broker.stopOnSignal(func() {
+		close(stopping)
+		_ = listener.Close()
+		if grpcListener != nil {
+			_ = grpcListener.Close()
+		}
+	})
 
 	for {
 		conn, err := listener.Accept()
 		if err != nil {
-			fmt.Printf("Accept connection failed: %v\n", err)
+			select {
+			case <-shutdown:
+				logger.Info("broker shut down")
+				return
+			case <-stopping:
+				<-shutdown
+				time.Sleep(shutdownGrace) // 让最后几个回合的回复先发出去
+				logger.Info("broker shut down")
+				return
+			default:
+			}
+			logger.Warn("accept connection failed", "err", err)
 			continue
 		}
-		go rpc.ServeConn(conn)
+		go func(conn net.Conn) {
+			// 配置了 token 时先校验，拿不出 token 的客户端不能驱动 / 关闭模拟
+			if err := transport.CheckToken(conn, cfg.Token); err != nil {
+				logger.Warn("reject connection", "remote", conn.RemoteAddr().String(), "err", err)
+				_ = conn.Close()
+				return
+			}
+			// 客户端可以要求压缩这条连接（distributor 的 -compress），没要求就原样服务；
+			// worker 的反向连接先放着，等它注册时 registerWorker 拿去用
+			rwc, err := transport.Negotiate(conn)
+			if errors.Is(err, transport.ErrParked) {
+				logger.Debug("reverse connection parked", "remote", conn.RemoteAddr().String())
+				return
+			}
+			if err != nil {
+				logger.Warn("reject connection", "remote", conn.RemoteAddr().String(), "err", err)
+				_ = conn.Close()
+				return
+			}
+			rpc.ServeConn(rwc)
+		}(conn)
 	}
 }
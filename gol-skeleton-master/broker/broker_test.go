@@ -0,0 +1,208 @@
+package main
+
+import (
+	"math/rand"
+	"net"
+	"net/rpc"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/engine"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// fakeWorker computes parts the way the broker does when it falls back to computing them
+// itself, after delay, or once hang is closed when it isn't nil.
+type fakeWorker struct {
+	delay time.Duration
+	hang  chan struct{}
+	calls chan struct{} // gets a value for every part the worker is sent
+
+	unhang sync.Once
+}
+
+// release lets the parts a hanging worker is stuck on return.
+func (f *fakeWorker) release() {
+	if f.hang != nil {
+		f.unhang.Do(func() { close(f.hang) })
+	}
+}
+
+func (f *fakeWorker) ProcessPart(t Task, reply *PartReply) error {
+	f.calls <- struct{}{}
+	if f.hang != nil {
+		<-f.hang
+	}
+	time.Sleep(f.delay)
+	var err error
+	*reply, err = computePart(t)
+	return err
+}
+
+// parts reports how many parts the worker has been sent.
+func (f *fakeWorker) parts() int {
+	return len(f.calls)
+}
+
+// startWorkers registers a worker for each of fakes, served over an in-memory connection,
+// as the only workers of the broker. A nil fake is a worker whose connection is gone, so
+// that every part sent to it fails. They are removed when the test ends.
+func startWorkers(t *testing.T, fakes ...*fakeWorker) []string {
+	t.Helper()
+	workerMutex.Lock()
+	defer workerMutex.Unlock()
+	var addrs []string
+	for i, f := range fakes {
+		client, server := net.Pipe()
+		if f == nil {
+			server.Close()
+		} else {
+			f.calls = make(chan struct{}, 1000)
+			s := rpc.NewServer()
+			if err := s.RegisterName("Worker", f); err != nil {
+				t.Fatal(err)
+			}
+			go s.ServeConn(server)
+		}
+		addr := net.JoinHostPort("fake", string(rune('a'+i)))
+		workerList = append(workerList, WorkerClient{addr: addr, client: rpc.NewClient(client), caps: legacyCaps()})
+		addrs = append(addrs, addr)
+	}
+	t.Cleanup(func() {
+		for _, f := range fakes {
+			if f != nil {
+				f.release()
+			}
+		}
+		for _, addr := range addrs {
+			removeWorker(addr)
+		}
+	})
+	return addrs
+}
+
+// useConfig makes cfg the broker's configuration until the test ends.
+func useConfig(t *testing.T, cfg Config) {
+	configMu.Lock()
+	old := config
+	config = cfg
+	configMu.Unlock()
+	t.Cleanup(func() {
+		configMu.Lock()
+		config = old
+		configMu.Unlock()
+	})
+}
+
+// turn evolves a random soup for a turn on the registered workers and checks it against the
+// engine package.
+func turn(t *testing.T) {
+	t.Helper()
+	r := rand.New(rand.NewSource(1))
+	world := util.NewWorld(64, 64)
+	for y := range world {
+		for x := range world[y] {
+			if r.Intn(2) == 0 {
+				world[y][x] = 255
+			}
+		}
+	}
+	params := WorldParams{ImageWidth: 64, ImageHeight: 64, World: world, Boundary: util.BoundaryTorus, Rule: util.Conway.String()}
+	got, _, _, err := evolve(params, logger)
+	if err != nil {
+		t.Fatalf("turn failed: %v", err)
+	}
+	want := engine.Evolve(world, util.BoundaryTorus, util.Conway, 1)
+	if !slices.EqualFunc(got, want, slices.Equal) {
+		t.Fatalf("turn evolved to the wrong world")
+	}
+}
+
+func registered(addr string) bool {
+	workerMutex.Lock()
+	defer workerMutex.Unlock()
+	return slices.ContainsFunc(workerList, func(w WorkerClient) bool { return w.addr == addr })
+}
+
+// TestFailover tests that the parts of a worker whose connection is gone are computed by the
+// other workers, and that worker evicted.
+func TestFailover(t *testing.T) {
+	useConfig(t, defaultConfig())
+	ok1, ok2 := &fakeWorker{}, &fakeWorker{}
+	addrs := startWorkers(t, ok1, nil, ok2)
+	turn(t)
+	if registered(addrs[1]) {
+		t.Errorf("the failed worker is still registered")
+	}
+	if ok1.parts()+ok2.parts() != 3 {
+		t.Errorf("the working workers computed %d parts, want 3", ok1.parts()+ok2.parts())
+	}
+}
+
+// TestLocalFallback tests that with every worker failing the broker computes the turn itself
+// when LocalFallback is set, and the turn fails when it isn't.
+func TestLocalFallback(t *testing.T) {
+	cfg := defaultConfig()
+	useConfig(t, cfg)
+	startWorkers(t, nil, nil)
+	turn(t)
+
+	cfg.Retry.LocalFallback = false
+	useConfig(t, cfg)
+	startWorkers(t, nil, nil)
+	params := WorldParams{ImageWidth: 4, ImageHeight: 4, World: util.NewWorld(4, 4)}
+	if _, _, _, err := evolve(params, logger); err == nil {
+		t.Errorf("expected the turn to fail without a local fallback")
+	}
+}
+
+// TestSpeculate tests that a part a straggling worker is taking too long on is also computed
+// on an idle worker, and the turn finishes without waiting for the straggler.
+func TestSpeculate(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.SpeculateAfter = 2
+	useConfig(t, cfg)
+	slow := &fakeWorker{delay: 2 * time.Second}
+	fast := []*fakeWorker{{}, {}, {}}
+	startWorkers(t, fast[0], fast[1], slow, fast[2])
+
+	start := time.Now()
+	turn(t)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("turn took %v, waiting for the straggler", elapsed)
+	}
+	if n := fast[0].parts() + fast[1].parts() + fast[2].parts(); n != 4 {
+		t.Errorf("the fast workers computed %d parts, want 3 and a backup", n)
+	}
+}
+
+// TestTaskTimeout tests that a part a worker hangs on past the task timeout is computed by
+// another worker, and the hung worker is suspect, and given no parts, until it returns.
+func TestTaskTimeout(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Retry.TaskTimeout = Duration(50 * time.Millisecond)
+	useConfig(t, cfg)
+	hung := &fakeWorker{hang: make(chan struct{})}
+	addrs := startWorkers(t, &fakeWorker{}, hung, &fakeWorker{})
+
+	turn(t)
+	if !isSuspect(addrs[1]) {
+		t.Fatalf("the hung worker is not suspect")
+	}
+	if !registered(addrs[1]) {
+		t.Errorf("the hung worker was evicted; it may only be slow")
+	}
+	turn(t)
+	if hung.parts() != 1 {
+		t.Errorf("the suspect worker was sent %d parts, want only the one it hung on", hung.parts())
+	}
+
+	hung.release()
+	for deadline := time.Now().Add(time.Second); isSuspect(addrs[1]); time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("the worker is still suspect after its part returned")
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"uk.ac.bris.cs/gameoflife/transport"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// 能力协商：worker 注册时带上协议版本、能解码的世界编码、核数和能跑的规则，broker 记在 WorkerClient 上，
+// 给它发任务时只用它认识的编码，它跑不了的规则交给别的 worker。这样新旧 worker 可以混在一个集群里逐台升级。
+// 没有上报能力的 worker（旧版本，或者从配置文件 / Consul / DNS 加进来的）按 legacyCaps 处理
+
+// workerCaps：worker 注册时上报的能力
+type workerCaps struct {
+	version int
+	codecs  []util.Codec
+	cores   int // 0 表示未知
+	rules   []util.RuleKind
+}
+
+// legacyCaps：协商之前的 worker 能做的事
+func legacyCaps() workerCaps {
+	return workerCaps{codecs: util.LegacyCodecs, rules: util.RuleKinds}
+}
+
+// capsOf：RegisterArgs 里上报的能力，没有上报的部分按旧 worker 处理
+func capsOf(args RegisterArgs) (workerCaps, error) {
+	caps := legacyCaps()
+	caps.version, caps.cores = args.Version, args.Cores
+	if len(args.Codecs) > 0 {
+		if !slices.Contains(args.Codecs, util.CodecRaw) {
+			return workerCaps{}, fmt.Errorf("worker must support the %q codec, got %v", util.CodecRaw, args.Codecs)
+		}
+		// 只留下 broker 自己也认识的编码，比 broker 新的 worker 多出来的编码用不上
+		caps.codecs = nil
+		for _, c := range args.Codecs {
+			if slices.Contains(util.Codecs, c) {
+				caps.codecs = append(caps.codecs, c)
+			}
+		}
+	}
+	if len(args.Rules) > 0 {
+		caps.rules = args.Rules
+	}
+	return caps, nil
+}
+
+// runs：worker 能不能跑 rule
+func (c workerCaps) runs(rule util.Rule) bool {
+	return util.Supports(c.rules, rule)
+}
+
+// String：日志和 /workers 里显示的能力
+func (c workerCaps) String() string {
+	codecs := make([]string, len(c.codecs))
+	for i, codec := range c.codecs {
+		codecs[i] = string(codec)
+	}
+	rules := make([]string, len(c.rules))
+	for i, rule := range c.rules {
+		rules[i] = string(rule)
+	}
+	return fmt.Sprintf("v%d codecs=%s rules=%s cores=%d", c.version, strings.Join(codecs, ","), strings.Join(rules, ","), c.cores)
+}
+
+// codedTask / codedTileTask：和 Task / TileTask 字段名一样，世界按 worker 认识的编码发送，worker 照样收进 Task / TileTask
+type codedTask struct {
+	StartY, EndY int
+	WorldPart    util.CodedWorld
+	Boundary     util.Boundary
+	Rule         string
+}
+
+type codedTileTask struct {
+	StartX, EndX int
+	StartY, EndY int
+	Cells        util.CodedWorld
+	Rule         string
+}
+
+// encode：按 w 的能力包装发给它的任务参数。gRPC worker 走 proto 自己的编码，不用包装
+func (w WorkerClient) encode(args interface{}) interface{} {
+	if transport.IsGRPC(w.addr) {
+		return args
+	}
+	switch t := args.(type) {
+	case Task:
+		return codedTask{
+			StartY: t.StartY, EndY: t.EndY,
+			WorldPart: util.CodedWorld{World: t.WorldPart, Codecs: w.caps.codecs},
+			Boundary:  t.Boundary,
+			Rule:      t.Rule,
+		}
+	case TileTask:
+		return codedTileTask{
+			StartX: t.StartX, EndX: t.EndX,
+			StartY: t.StartY, EndY: t.EndY,
+			Cells: util.CodedWorld{World: t.Cells, Codecs: w.caps.codecs},
+			Rule:  t.Rule,
+		}
+	}
+	return args
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// 检查点：定期把 currentWorld 和回合数写到磁盘，broker 崩溃重启后从文件恢复，
+// 跑了几个小时的模拟不会因为 broker 挂掉而丢失
+
+// 默认值，可以在配置文件 checkpoint 里修改
+const checkpointInterval = 30 * time.Second
+
+// CheckpointConfig：检查点相关的设置，Path 为空表示不写检查点
+type CheckpointConfig struct {
+	Path     string   `json:"path"`     // 检查点文件路径
+	Interval Duration `json:"interval"` // 多久写一次
+}
+
+// checkpoint：写到磁盘上的内容
+type checkpoint struct {
+	Turn     int
+	World    [][]uint8
+	Boundary util.Boundary // 旧的检查点里没有，为空，即环绕
+	Rule     string        // 同上，为空即 B3/S23
+	Saved    time.Time
+}
+
+// saveCheckpoint：先写临时文件再 rename，写到一半崩溃也不会破坏上一次的检查点
+func saveCheckpoint(path string, cp checkpoint) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(tmp).Encode(cp); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// loadCheckpoint：读取检查点，文件不存在时返回 os.ErrNotExist
+func loadCheckpoint(path string) (checkpoint, error) {
+	var cp checkpoint
+	f, err := os.Open(path)
+	if err != nil {
+		return cp, err
+	}
+	defer f.Close()
+
+	if err := gob.NewDecoder(f).Decode(&cp); err != nil {
+		return cp, fmt.Errorf("decode %s: %v", path, err)
+	}
+	if len(cp.World) == 0 || len(cp.World[0]) == 0 {
+		return cp, fmt.Errorf("decode %s: empty world", path)
+	}
+	if _, err := util.ParseRule(cp.Rule); err != nil {
+		return cp, fmt.Errorf("decode %s: %v", path, err)
+	}
+	return cp, nil
+}
+
+// restore：用检查点里的世界和回合数作为当前模拟，之后 NextTurn / FetchWorld 直接接着用
+// halo 模式的行段分配不写进检查点，恢复后先按 scatter 方式推进，直到下一次 StartSimulation
+func (s *simulation) restore(cp checkpoint) {
+	rule, _ := util.ParseRule(cp.Rule) // loadCheckpoint 已经检查过
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.currentWorld = cp.World
+	s.turn = cp.Turn
+	s.boundary = cp.Boundary
+	s.rule = rule
+	s.halo = nil
+	s.life = newLife(cp.World, cp.Boundary, rule)
+}
+
+// startCheckpointing：后台定期写检查点，回合数没变化时跳过。只用在默认 job 上
+// 路径 / 间隔每轮从当前配置读取，SIGHUP 重新加载后立即生效
+func (s *simulation) startCheckpointing() {
+	go func() {
+		saved := -1
+		for {
+			cfg := currentConfig().Checkpoint
+			time.Sleep(time.Duration(cfg.Interval))
+			if cfg.Path == "" {
+				continue
+			}
+
+			s.mu.Lock()
+			turn, started := s.turn, s.currentWorld != nil
+			s.mu.Unlock()
+			if !started || turn == saved {
+				continue
+			}
+
+			s.turnMu.Lock()
+			turn, err := s.writeCheckpoint(cfg.Path)
+			s.turnMu.Unlock()
+			if err != nil {
+				s.log.Error("write checkpoint failed", "path", cfg.Path, "turn", turn, "err", err)
+				continue
+			}
+			saved = turn
+			s.log.Debug("checkpoint written", "path", cfg.Path, "turn", turn)
+		}
+	}()
+}
+
+// writeCheckpoint：把当前世界写到 path，返回写的是第几回合。调用方需要持有 turnMu，
+// 保证世界和回合数对得上（halo 模式下要从 worker 收集）
+func (s *simulation) writeCheckpoint(path string) (int, error) {
+	world, err := s.world()
+	s.mu.Lock()
+	turn, boundary, rule := s.turn, s.boundary, s.rule
+	s.mu.Unlock()
+	if err != nil {
+		return turn, fmt.Errorf("collect world: %v", err)
+	}
+	return turn, saveCheckpoint(path, checkpoint{Turn: turn, World: world, Boundary: boundary, Rule: rule.String(), Saved: time.Now()})
+}
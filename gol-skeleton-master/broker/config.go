@@ -0,0 +1,361 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/discovery"
+	"uk.ac.bris.cs/gameoflife/transport"
+)
+
+// Duration：JSON 里写 "2s" / "500ms" 这种字符串
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// RetryConfig：worker 失败 / 心跳相关的设置。
+// 要严格（一段失败整回合就失败）：max_attempts = 1、local_fallback = false；
+// 要尽量推进：local_fallback = true，再用 max_turn_failures 限制一回合里换 worker 重试花的时间
+type RetryConfig struct {
+	MaxAttempts       int      `json:"max_attempts"`       // 一段任务最多尝试几个 worker，0 表示所有 worker 都试一遍
+	MaxTurnFailures   int      `json:"max_turn_failures"`  // 一回合里（所有段加起来）最多容忍几次 worker 失败，之后失败的段不再换 worker，0 表示不限
+	LocalFallback     bool     `json:"local_fallback"`     // 不再换 worker 之后 broker 是否本地计算这一段，否则这一回合失败
+	TaskTimeout       Duration `json:"task_timeout"`       // 一段任务最多等多久，超时就换 worker 并把它记为可疑（见 timeout.go），0 表示一直等
+	HeartbeatInterval Duration `json:"heartbeat_interval"` // 心跳间隔
+	HeartbeatTimeout  Duration `json:"heartbeat_timeout"`  // 单次 ping 超时
+	MaxMissedPings    int      `json:"max_missed_pings"`   // 连续失败多少次踢掉 worker
+}
+
+// TCPConfig：broker 的 TCP 连接参数（见 transport/tcp.go），回拨 worker 的连接和接受的连接都用。
+// 高延迟的链路上默认的缓冲区装不下多少世界数据，可以调大 read_buffer / write_buffer
+type TCPConfig struct {
+	KeepAlive   Duration `json:"keepalive"`    // keep-alive 探测间隔，0 用 Go 的默认值（15s），负数表示关掉
+	NoDelay     bool     `json:"nodelay"`      // TCP_NODELAY，默认开
+	ReadBuffer  int      `json:"read_buffer"`  // SO_RCVBUF 字节数，0 用系统默认值
+	WriteBuffer int      `json:"write_buffer"` // SO_SNDBUF 字节数，0 用系统默认值
+}
+
+// options：转成 transport 的参数
+func (t TCPConfig) options() transport.TCPOptions {
+	return transport.TCPOptions{
+		KeepAlive:   time.Duration(t.KeepAlive),
+		Delay:       !t.NoDelay,
+		ReadBuffer:  t.ReadBuffer,
+		WriteBuffer: t.WriteBuffer,
+	}
+}
+
+// Config：broker 配置文件（JSON），见 broker.example.json
+type Config struct {
+	Port        int         `json:"port"`         // broker 监听端口
+	GRPCPort    int         `json:"grpc_port"`    // gRPC 监听端口，0 表示不开
+	HTTPPort    int         `json:"http_port"`    // HTTP+JSON 接口的端口（见 http.go），0 表示不开
+	Workers     []string    `json:"workers"`      // 启动时主动连接的 worker 地址
+	MinWorkers  int         `json:"min_workers"`  // 已注册 worker 少于这个数时 ProcessTurn 报错
+	WaitWorkers Duration    `json:"wait_workers"` // worker 不够时回合先等这么久再报错（见 warmup.go），0 表示直接报错
+	WorkerConns int         `json:"worker_conns"` // 到每个 worker 开几条 net/rpc 连接，多个任务 / job 不用在一条连接上排队（见 transport/pool.go）
+	Token       string      `json:"token"`        // 共享密钥，非空时所有客户端（distributor / worker）都要带上
+	Mode        string      `json:"mode"`         // 有状态模拟的调度方式：scatter / halo
+	Engine      string      `json:"engine"`       // 有状态模拟的计算引擎：workers / hashlife（见 engine.go）
+	Retry       RetryConfig `json:"retry"`
+	TCP         TCPConfig   `json:"tcp"` // 监听端的设置改了要重启 broker 才生效
+
+	Partition      string           `json:"partition"`       // scatter 模式按 rows / columns / tiles 切分世界
+	Tiles          string           `json:"tiles"`           // tiles 模式的网格大小，比如 "4x4"
+	RebalanceEvery int              `json:"rebalance_every"` // 每隔多少回合按实测速度重新分行（只对 scatter 模式有效），0 表示只按注册分数分
+	SpeculateAfter float64          `json:"speculate_after"` // 一段算得比已算完的段的中位数慢这么多倍时在空闲 worker 上再算一份（见 speculate.go），0 表示不推测
+	Checkpoint     CheckpointConfig `json:"checkpoint"`
+
+	Discovery  string `json:"discovery"`   // 服务发现地址，比如 consul://127.0.0.1:8500/gol/workers，空表示不用
+	WorkersDNS string `json:"workers_dns"` // 定期解析的 worker 域名和端口，比如 worker.gol.svc.cluster.local:8031，空表示不用
+}
+
+// 有状态模拟（StartSimulation / NextTurn）的两种调度方式
+const (
+	modeScatter = "scatter" // 每回合 broker 切分世界发给 worker 再合并
+	modeHalo    = "halo"    // worker 长期持有行段，邻居之间直接交换 halo 行
+)
+
+// defaultConfig：没有配置文件时的默认值，和之前写死的行为一致
+func defaultConfig() Config {
+	return Config{
+		Port:        8080,
+		MinWorkers:  1,
+		WorkerConns: 1,
+		Mode:        modeScatter,
+		Engine:      engineWorkers,
+		Partition:   partitionRows,
+		Tiles:       "4x4",
+		Retry: RetryConfig{
+			MaxAttempts:       0,
+			LocalFallback:     true,
+			HeartbeatInterval: Duration(heartbeatInterval),
+			HeartbeatTimeout:  Duration(heartbeatTimeout),
+			MaxMissedPings:    maxMissedPings,
+		},
+		Checkpoint: CheckpointConfig{
+			Interval: Duration(checkpointInterval),
+		},
+		TCP: TCPConfig{NoDelay: true},
+	}
+}
+
+// loadConfig：读取配置文件，文件里没写的字段保持默认值
+func loadConfig(path string) (Config, error) {
+	cfg := defaultConfig()
+	if path == "" {
+		cfg.Token = os.Getenv("GOL_TOKEN")
+		return cfg, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("parse %s: %v", path, err)
+	}
+	if cfg.Token == "" {
+		cfg.Token = os.Getenv("GOL_TOKEN")
+	}
+	if cfg.MinWorkers < 0 {
+		return cfg, fmt.Errorf("parse %s: min_workers must not be negative", path)
+	}
+	if cfg.WorkerConns < 1 {
+		return cfg, fmt.Errorf("parse %s: worker_conns must be at least 1", path)
+	}
+	if cfg.WaitWorkers < 0 {
+		return cfg, fmt.Errorf("parse %s: wait_workers must not be negative", path)
+	}
+	if cfg.Mode != modeScatter && cfg.Mode != modeHalo {
+		return cfg, fmt.Errorf("parse %s: unknown mode %q", path, cfg.Mode)
+	}
+	if !validEngine(cfg.Engine) {
+		return cfg, fmt.Errorf("parse %s: unknown engine %q", path, cfg.Engine)
+	}
+	if cfg.Retry.MaxAttempts < 0 || cfg.Retry.MaxTurnFailures < 0 || cfg.Retry.TaskTimeout < 0 {
+		return cfg, fmt.Errorf("parse %s: max_attempts, max_turn_failures and task_timeout must not be negative", path)
+	}
+	if cfg.Retry.HeartbeatInterval <= 0 || cfg.Retry.HeartbeatTimeout <= 0 || cfg.Retry.MaxMissedPings <= 0 {
+		return cfg, fmt.Errorf("parse %s: heartbeat settings must be positive", path)
+	}
+	if cfg.Checkpoint.Interval <= 0 {
+		return cfg, fmt.Errorf("parse %s: checkpoint interval must be positive", path)
+	}
+	if cfg.TCP.ReadBuffer < 0 || cfg.TCP.WriteBuffer < 0 {
+		return cfg, fmt.Errorf("parse %s: tcp buffer sizes must not be negative", path)
+	}
+	if !validPartition(cfg.Partition) {
+		return cfg, fmt.Errorf("parse %s: unknown partition %q", path, cfg.Partition)
+	}
+	if _, _, err := parseTiles(cfg.Tiles); err != nil {
+		return cfg, fmt.Errorf("parse %s: %v", path, err)
+	}
+	if cfg.RebalanceEvery < 0 {
+		return cfg, fmt.Errorf("parse %s: rebalance_every must not be negative", path)
+	}
+	if cfg.SpeculateAfter != 0 && cfg.SpeculateAfter < 1 {
+		return cfg, fmt.Errorf("parse %s: speculate_after must be 0 or at least 1", path)
+	}
+	if cfg.Discovery != "" {
+		if _, err := discovery.Parse(cfg.Discovery); err != nil {
+			return cfg, fmt.Errorf("parse %s: %v", path, err)
+		}
+	}
+	if cfg.WorkersDNS != "" {
+		if _, _, err := net.SplitHostPort(cfg.WorkersDNS); err != nil {
+			return cfg, fmt.Errorf("parse %s: workers_dns: %v", path, err)
+		}
+	}
+	return cfg, nil
+}
+
+var (
+	config   = defaultConfig()
+	configMu sync.Mutex
+
+	// configWorkers：通过配置文件加进来的 worker，重新加载时只增删这一部分，自注册的 worker 不受影响
+	configWorkers = make(map[string]bool)
+)
+
+// currentConfig：返回当前生效配置的拷贝
+func currentConfig() Config {
+	configMu.Lock()
+	defer configMu.Unlock()
+	return config
+}
+
+// applyConfig：生效新配置，注册新增的 worker，移除配置里删掉的 worker
+func applyConfig(cfg Config) {
+	configMu.Lock()
+	config = cfg
+	old := configWorkers
+	configWorkers = make(map[string]bool, len(cfg.Workers))
+	for _, addr := range cfg.Workers {
+		configWorkers[addr] = true
+	}
+	configMu.Unlock()
+
+	for addr := range old {
+		if !configWorkers[addr] && removeWorker(addr) {
+			logger.Info("worker removed from config", "worker", addr)
+		}
+	}
+	// 列表里的 worker 全部重新注册一遍（已连接的会替换成新连接）
+	for _, addr := range cfg.Workers {
+		if err := registerWorker(addr, 0, legacyCaps()); err != nil {
+			logger.Warn("register worker from config failed", "worker", addr, "err", err)
+		}
+	}
+}
+
+// watchConfig：收到 SIGHUP 时重新读取配置文件
+func watchConfig(path string) {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	go func() {
+		for range sighup {
+			cfg, err := loadConfig(path)
+			if err != nil {
+				logger.Error("reload config failed, keeping old config", "path", path, "err", err)
+				continue
+			}
+			cfg = overrideFromFlags(cfg)
+			if old := currentConfig(); cfg.Port != old.Port || cfg.GRPCPort != old.GRPCPort || cfg.HTTPPort != old.HTTPPort || cfg.Token != old.Token || cfg.Discovery != old.Discovery || cfg.WorkersDNS != old.WorkersDNS {
+				logger.Warn("port, token and discovery changes need a broker restart, ignoring them")
+				cfg.Port, cfg.GRPCPort, cfg.HTTPPort, cfg.Token = old.Port, old.GRPCPort, old.HTTPPort, old.Token
+				cfg.Discovery, cfg.WorkersDNS = old.Discovery, old.WorkersDNS
+			}
+			logger.Info("reloading config", "path", path)
+			applyConfig(cfg)
+		}
+	}()
+}
+
+func validPartition(p string) bool {
+	return p == partitionRows || p == partitionColumns || p == partitionTiles
+}
+
+// 命令行参数，设置了就覆盖配置文件里的值（本地测试和 AWS 用同一个二进制）
+var (
+	portFlag        = flag.Int("port", 8080, "port to listen on (overrides config)")
+	workersFlag     = flag.String("workers", "", "comma-separated worker addresses, e.g. 127.0.0.1:8031,127.0.0.1:8032 (overrides config)")
+	minWorkersFlag  = flag.Int("min-workers", 1, "minimum number of registered workers needed to process a turn (overrides config)")
+	waitWorkersFlag = flag.Duration("wait-workers", 0, "when fewer than -min-workers are registered, hold a turn this long waiting for more before failing it, 0 = fail at once; keep it below the distributor's -call-timeout (overrides config)")
+	workerConnsFlag = flag.Int("worker-conns", 1, "connections to open to each worker, so several parts or jobs can be in flight to it at once (overrides config)")
+	grpcPortFlag    = flag.Int("grpc-port", 0, "also serve the broker over gRPC on this port, 0 = off (overrides config)")
+	httpPortFlag    = flag.Int("http-port", 0, "also serve a status dashboard, a JSON API (/status, /workers, /workers/retire, /world, /pause, /resume, /shutdown), the /events WebSocket stream, the /view page and /healthz and /readyz probes over HTTP on this port, 0 = off (overrides config)")
+	tokenFlag       = flag.String("token", "", "shared secret clients must present, empty = no auth (overrides config and $GOL_TOKEN)")
+	modeFlag        = flag.String("mode", modeScatter, "how stateful simulations are scheduled: scatter or halo (overrides config)")
+	engineFlag      = flag.String("engine", engineWorkers, "what evolves stateful simulations: workers, or hashlife on the broker for square power-of-two worlds (overrides config)")
+
+	partitionFlag = flag.String("partition", partitionRows, "how scatter mode slices the world: rows, columns or tiles (overrides config)")
+	tilesFlag     = flag.String("tiles", "4x4", "tile grid (ROWSxCOLS) for -partition tiles, tiles go to workers round-robin (overrides config)")
+	rebalanceFlag = flag.Int("rebalance-every", 0, "re-split rows by measured worker throughput every N turns, 0 = use registration scores only (overrides config)")
+
+	speculateFlag = flag.Float64("speculate-after", 0, "run a backup of a part on an idle worker once it takes this many times the median of the parts already done, 0 = off (overrides config)")
+
+	maxAttemptsFlag     = flag.Int("max-attempts", 0, "workers to try for each part of a turn before giving up on it, 0 = all of them (overrides config)")
+	maxTurnFailuresFlag = flag.Int("max-turn-failures", 0, "worker failures tolerated in one turn before failed parts are no longer retried on other workers, 0 = no limit (overrides config)")
+	taskTimeoutFlag     = flag.Duration("task-timeout", 0, "give up on a worker's part after this long, hand it to another worker and mark the worker suspect, 0 = wait as long as it takes (overrides config)")
+	localFallbackFlag   = flag.Bool("local-fallback", true, "compute a part on the broker when no worker could; false fails the turn instead (overrides config)")
+
+	tcpKeepAliveFlag   = flag.Duration("tcp-keepalive", 0, "TCP keep-alive probe period, 0 = Go's default (15s), negative = off (overrides config)")
+	tcpNoDelayFlag     = flag.Bool("tcp-nodelay", true, "set TCP_NODELAY; false lets Nagle's algorithm batch small writes (overrides config)")
+	tcpReadBufferFlag  = flag.Int("tcp-read-buffer", 0, "TCP receive buffer (SO_RCVBUF) in bytes, raise it for large worlds over high-latency links, 0 = OS default (overrides config)")
+	tcpWriteBufferFlag = flag.Int("tcp-write-buffer", 0, "TCP send buffer (SO_SNDBUF) in bytes, 0 = OS default (overrides config)")
+
+	checkpointFlag         = flag.String("checkpoint", "", "file to checkpoint the simulation to (also on SIGTERM or SIGINT) and resume from on startup, empty = off (overrides config)")
+	checkpointIntervalFlag = flag.Duration("checkpoint-interval", checkpointInterval, "how often to write the checkpoint (overrides config)")
+
+	discoveryFlag  = flag.String("discovery", "", "watch workers registered in Consul, e.g. consul://127.0.0.1:8500/gol/workers, empty = off (overrides config)")
+	workersDNSFlag = flag.String("workers-dns", "", "resolve this host:port every 10s and use each address as a worker, e.g. a Kubernetes headless service worker.gol.svc.cluster.local:8031 (overrides config)")
+)
+
+// overrideFromFlags：只覆盖命令行里显式给出的参数
+func overrideFromFlags(cfg Config) Config {
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "port":
+			cfg.Port = *portFlag
+		case "grpc-port":
+			cfg.GRPCPort = *grpcPortFlag
+		case "http-port":
+			cfg.HTTPPort = *httpPortFlag
+		case "workers":
+			cfg.Workers = nil
+			for _, addr := range strings.Split(*workersFlag, ",") {
+				if addr = strings.TrimSpace(addr); addr != "" {
+					cfg.Workers = append(cfg.Workers, addr)
+				}
+			}
+		case "token":
+			cfg.Token = *tokenFlag
+		case "min-workers":
+			cfg.MinWorkers = *minWorkersFlag
+		case "wait-workers":
+			cfg.WaitWorkers = Duration(*waitWorkersFlag)
+		case "worker-conns":
+			cfg.WorkerConns = *workerConnsFlag
+		case "mode":
+			cfg.Mode = *modeFlag
+		case "engine":
+			cfg.Engine = *engineFlag
+		case "partition":
+			cfg.Partition = *partitionFlag
+		case "tiles":
+			cfg.Tiles = *tilesFlag
+		case "rebalance-every":
+			cfg.RebalanceEvery = *rebalanceFlag
+		case "speculate-after":
+			cfg.SpeculateAfter = *speculateFlag
+		case "max-attempts":
+			cfg.Retry.MaxAttempts = *maxAttemptsFlag
+		case "max-turn-failures":
+			cfg.Retry.MaxTurnFailures = *maxTurnFailuresFlag
+		case "task-timeout":
+			cfg.Retry.TaskTimeout = Duration(*taskTimeoutFlag)
+		case "local-fallback":
+			cfg.Retry.LocalFallback = *localFallbackFlag
+		case "tcp-keepalive":
+			cfg.TCP.KeepAlive = Duration(*tcpKeepAliveFlag)
+		case "tcp-nodelay":
+			cfg.TCP.NoDelay = *tcpNoDelayFlag
+		case "tcp-read-buffer":
+			cfg.TCP.ReadBuffer = *tcpReadBufferFlag
+		case "tcp-write-buffer":
+			cfg.TCP.WriteBuffer = *tcpWriteBufferFlag
+		case "checkpoint":
+			cfg.Checkpoint.Path = *checkpointFlag
+		case "checkpoint-interval":
+			cfg.Checkpoint.Interval = Duration(*checkpointIntervalFlag)
+		case "discovery":
+			cfg.Discovery = *discoveryFlag
+		case "workers-dns":
+			cfg.WorkersDNS = *workersDNSFlag
+		}
+	})
+	return cfg
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// 控制器断开 / 重连：distributor 按 'q' 退出时调用 Detach，broker 在后台继续推进世界；
+// 之后新启动的 distributor（-resume）调用 Attach 拿到当前回合和世界，接着发事件
+
+// DetachArgs / AttachReply 必须和 distributor 那边保持一致
+type DetachArgs struct {
+	Turns int // 后台推进到第几回合为止
+	JobID string
+}
+
+type AttachReply struct {
+	Turn  int
+	World util.World
+	Rule  string // 模拟的规则，distributor 据此应用翻转（见 util.Rule.Flip）
+}
+
+// background：Detach（或 SubmitJob）之后在 broker 上自己推进回合的循环
+type background struct {
+	stop chan struct{}
+	done chan struct{}
+	err  error // 推进失败的原因，done 关闭之后才能读
+}
+
+// Detach：控制器退出，broker 在后台继续推进到 args.Turns 回合
+func (s *simulation) Detach(args DetachArgs, reply *bool) error {
+	s.mu.Lock()
+	started, turn := s.currentWorld != nil, s.turn
+	s.mu.Unlock()
+	if !started {
+		return fmt.Errorf("no simulation started")
+	}
+
+	s.log.Info("controller detached, evolving in background", "turn", turn, "target", args.Turns)
+	s.startBackground(args.Turns)
+
+	*reply = true
+	return nil
+}
+
+// startBackground：停掉之前的后台推进，在后台推进到 target 回合
+func (s *simulation) startBackground(target int) *background {
+	s.stopBackground()
+
+	run := &background{stop: make(chan struct{}), done: make(chan struct{})}
+	s.mu.Lock()
+	s.bg = run
+	s.mu.Unlock()
+
+	go s.runBackground(run, target)
+	return run
+}
+
+// runBackground：一回合一回合地推进，每回合之间检查是否有人 Attach / StartSimulation
+func (s *simulation) runBackground(run *background, target int) {
+	defer close(run.done)
+	for {
+		select {
+		case <-run.stop:
+			return
+		default:
+		}
+
+		// 暂停时等 Resume
+		if wait := s.pausedCh(); wait != nil {
+			select {
+			case <-wait:
+			case <-run.stop:
+				return
+			}
+			continue
+		}
+
+		s.turnMu.Lock()
+		s.mu.Lock()
+		turn := s.turn
+		s.mu.Unlock()
+		if turn >= target {
+			s.turnMu.Unlock()
+			s.log.Info("background simulation finished", "turn", turn)
+			return
+		}
+		_, _, _, err := s.step()
+		s.turnMu.Unlock()
+		if err != nil {
+			s.log.Error("background simulation stopped", "turn", turn+1, "err", err)
+			run.err = err
+			return
+		}
+	}
+}
+
+// stopBackground：停掉后台推进（如果有），返回时保证它已经不会再推进回合
+func (s *simulation) stopBackground() {
+	s.mu.Lock()
+	run := s.bg
+	s.bg = nil
+	s.mu.Unlock()
+
+	if run != nil {
+		close(run.stop)
+		<-run.done
+	}
+}
+
+// Attach：新的控制器接管模拟，停掉后台推进并返回当前回合和世界
+// 新控制器总是从执行状态开始，之前的暂停一并取消
+func (s *simulation) Attach(_ struct{}, reply *AttachReply) error {
+	s.stopBackground()
+	s.resume()
+
+	s.turnMu.Lock()
+	defer s.turnMu.Unlock()
+
+	world, err := s.world()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	reply.Turn = s.turn
+	reply.Rule = s.rule.String()
+	s.mu.Unlock()
+	reply.World = world
+
+	s.log.Info("controller attached", "turn", reply.Turn)
+	return nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/discovery"
+)
+
+// 服务发现：worker 把自己登记到 Consul 的某个前缀下（带 TTL 的 session，worker 挂了 key 会自动消失），
+// broker 用阻塞查询盯着这个前缀，增删 workerList。这样配置文件里不用写死任何地址，也能配合自动伸缩组
+
+// discoveryWait：一次阻塞查询最多等多久。没有变化时也会按这个间隔重新对一遍，
+// 把被心跳踢掉、但 key 还在（也就是又活过来了）的 worker 加回来
+const discoveryWait = 30 * time.Second
+
+// watchDiscovery：后台跟踪 Consul 里登记的 worker，直到 broker 关闭
+func watchDiscovery(c *discovery.Consul) {
+	discovered := make(map[string]bool) // 通过服务发现加进来的 worker，只增删这一部分
+	var index uint64
+	backoff := time.Second
+
+	go func() {
+		for {
+			select {
+			case <-shutdown:
+				return
+			default:
+			}
+
+			entries, next, err := c.Workers(index, discoveryWait)
+			if err != nil {
+				logger.Warn("watch discovery failed, retrying", "discovery", c, "err", err, "retry_in", backoff)
+				time.Sleep(backoff)
+				backoff = min(2*backoff, discoveryWait)
+				index = 0
+				continue
+			}
+			backoff = time.Second
+			// Consul 的 index 变小说明它重置过，从头再查
+			if next < index {
+				next = 0
+			}
+			index = next
+
+			reconcile(discovered, entries, "discovery")
+		}
+	}()
+}
+
+// reconcile：让 discovered 里的 worker 和 entries 一致：新出现的注册，消失的移除。
+// 已经在 discovered 里、却不在 workerList 里（被心跳踢掉了，但还登记着）的重新注册一次
+func reconcile(discovered map[string]bool, entries []discovery.Entry, source string) {
+	current := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		current[e.Address] = true
+		if discovered[e.Address] && hasWorker(e.Address) {
+			continue
+		}
+		if err := registerWorker(e.Address, e.Score, legacyCaps()); err != nil {
+			logger.Warn("register discovered worker failed", "source", source, "worker", e.Address, "err", err)
+			continue
+		}
+		discovered[e.Address] = true
+	}
+	for addr := range discovered {
+		if current[addr] {
+			continue
+		}
+		delete(discovered, addr)
+		if removeWorker(addr) {
+			logger.Info("worker left discovery", "source", source, "worker", addr)
+		}
+	}
+}
+
+// hasWorker：address 是否在 workerList 里
+func hasWorker(address string) bool {
+	workerMutex.Lock()
+	defer workerMutex.Unlock()
+	for _, w := range workerList {
+		if w.addr == address {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// 多个 job：Broker 的 RPC 方法只是按参数里的 JobID 找到对应的模拟，再交给它（simulation 上的同名方法）。
+// 开始模拟（StartSimulation / ProcessTurn / 分块上传 / SubmitJob）和订阅时没有这个 job 就新建一个，
+// 其它调用遇到没见过的 JobID 报错。job 一直保留到 broker 退出，-resume 和观察者随时可以再连上来。
+// 检查点只覆盖默认 job
+
+// JobArgs：只需要指明是哪个 job 的 RPC 的参数，必须和 distributor 那边保持一致
+type JobArgs struct {
+	JobID string // 为空表示默认 job
+}
+
+// job：JobID 对应的模拟，create 时没有就新建一个
+func (b *Broker) job(id string, create bool) (*simulation, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s, ok := b.jobs[id]
+	if ok {
+		return s, nil
+	}
+	if !create {
+		return nil, fmt.Errorf("unknown job %q", id)
+	}
+	s = newSimulation(id)
+	b.jobs[id] = s
+	logger.Info("job created", "job", id, "jobs", len(b.jobs))
+	return s, nil
+}
+
+// defaultJob：不带 JobID 的调用、检查点和旧客户端用的那个 job，一直都在
+func (b *Broker) defaultJob() *simulation {
+	s, _ := b.job("", false)
+	return s
+}
+
+// simulations：所有 job，按 JobID 排序
+func (b *Broker) simulations() []*simulation {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	sims := make([]*simulation, 0, len(b.jobs))
+	for _, s := range b.jobs {
+		sims = append(sims, s)
+	}
+	sort.Slice(sims, func(i, j int) bool { return sims[i].id < sims[j].id })
+	return sims
+}
+
+func (b *Broker) ProcessTurn(params WorldParams, reply *util.World) error {
+	s, err := b.job(params.JobID, true)
+	if err != nil {
+		return err
+	}
+	return s.ProcessTurn(params, reply)
+}
+
+func (b *Broker) StartSimulation(params WorldParams, reply *bool) error {
+	s, err := b.job(params.JobID, true)
+	if err != nil {
+		return err
+	}
+	return s.StartSimulation(params, reply)
+}
+
+func (b *Broker) GetAliveCellsCount(args JobArgs, reply *int) error {
+	s, err := b.job(args.JobID, false)
+	if err != nil {
+		return err
+	}
+	return s.GetAliveCellsCount(struct{}{}, reply)
+}
+
+func (b *Broker) WatchAlive(args AliveArgs, reply *AliveReport) error {
+	s, err := b.job(args.JobID, false)
+	if err != nil {
+		return err
+	}
+	return s.WatchAlive(args, reply)
+}
+
+func (b *Broker) NextTurn(args JobArgs, reply *NextTurnReply) error {
+	s, err := b.job(args.JobID, false)
+	if err != nil {
+		return err
+	}
+	return s.NextTurn(struct{}{}, reply)
+}
+
+func (b *Broker) ProcessTurns(args ProcessTurnsArgs, reply *ProcessTurnsReply) error {
+	s, err := b.job(args.JobID, false)
+	if err != nil {
+		return err
+	}
+	return s.ProcessTurns(args, reply)
+}
+
+func (b *Broker) FetchWorld(args JobArgs, reply *util.World) error {
+	s, err := b.job(args.JobID, false)
+	if err != nil {
+		return err
+	}
+	return s.FetchWorld(struct{}{}, reply)
+}
+
+func (b *Broker) GetWorld(args JobArgs, reply *WorldReply) error {
+	s, err := b.job(args.JobID, false)
+	if err != nil {
+		return err
+	}
+	return s.GetWorld(struct{}{}, reply)
+}
+
+func (b *Broker) Detach(args DetachArgs, reply *bool) error {
+	s, err := b.job(args.JobID, false)
+	if err != nil {
+		return err
+	}
+	return s.Detach(args, reply)
+}
+
+func (b *Broker) Attach(args JobArgs, reply *AttachReply) error {
+	s, err := b.job(args.JobID, false)
+	if err != nil {
+		return err
+	}
+	return s.Attach(struct{}{}, reply)
+}
+
+func (b *Broker) Subscribe(args JobArgs, reply *SubscribeReply) error {
+	s, err := b.job(args.JobID, true)
+	if err != nil {
+		return err
+	}
+	return s.Subscribe(struct{}{}, reply)
+}
+
+func (b *Broker) Poll(args SubscriptionArgs, reply *PollReply) error {
+	s, err := b.job(args.JobID, false)
+	if err != nil {
+		return err
+	}
+	return s.Poll(args, reply)
+}
+
+func (b *Broker) Unsubscribe(args SubscriptionArgs, reply *bool) error {
+	s, err := b.job(args.JobID, false)
+	if err != nil {
+		return err
+	}
+	return s.Unsubscribe(args, reply)
+}
+
+func (b *Broker) Pause(args JobArgs, reply *bool) error {
+	s, err := b.job(args.JobID, false)
+	if err != nil {
+		return err
+	}
+	return s.Pause(struct{}{}, reply)
+}
+
+func (b *Broker) Resume(args JobArgs, reply *bool) error {
+	s, err := b.job(args.JobID, false)
+	if err != nil {
+		return err
+	}
+	return s.Resume(struct{}{}, reply)
+}
+
+func (b *Broker) GetStatus(args JobArgs, reply *StatusReply) error {
+	s, err := b.job(args.JobID, false)
+	if err != nil {
+		return err
+	}
+	return s.GetStatus(struct{}{}, reply)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"net"
+	"sort"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/discovery"
+)
+
+// DNS 发现：Kubernetes 里 worker 跑在一个 headless service 后面，service 名字解析出来就是所有 pod 的 IP。
+// broker 定期解析这个名字，每条 A 记录加上端口就是一个 worker，pod 扩缩容时跟着增删
+
+// dnsInterval：多久重新解析一次
+const dnsInterval = 10 * time.Second
+
+// dnsTimeout：单次解析的超时
+const dnsTimeout = 5 * time.Second
+
+// watchWorkersDNS：后台定期解析 hostPort（比如 worker.gol.svc.cluster.local:8031），直到 broker 关闭
+func watchWorkersDNS(hostPort string) {
+	host, port, _ := net.SplitHostPort(hostPort) // loadConfig / main 里已经检查过格式
+	discovered := make(map[string]bool)
+
+	go func() {
+		ticker := time.NewTicker(dnsInterval)
+		defer ticker.Stop()
+		for {
+			if entries, err := resolveWorkers(host, port); err != nil {
+				// 解析失败（DNS 暂时不可用）时保持现状，不把所有 worker 都移除
+				logger.Warn("resolve workers failed", "workers_dns", hostPort, "err", err)
+			} else {
+				reconcile(discovered, entries, "dns")
+			}
+
+			select {
+			case <-shutdown:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// resolveWorkers：解析 host 的 IPv4 地址，每个地址加上 port 作为一个 worker
+func resolveWorkers(host, port string) ([]discovery.Entry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dnsTimeout)
+	defer cancel()
+	ips, err := net.DefaultResolver.LookupIP(ctx, "ip4", host)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]discovery.Entry, 0, len(ips))
+	for _, ip := range ips {
+		entries = append(entries, discovery.Entry{Address: net.JoinHostPort(ip.String(), port)})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Address < entries[j].Address })
+	return entries, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"uk.ac.bris.cs/gameoflife/hashlife"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// 计算引擎：默认每回合把世界切块分给 worker（engine = workers）。
+// engine = hashlife 时 broker 自己用 HashLife 推进有状态模拟，重复或稀疏的图案跑几百万回合也很快；
+// 只支持边长是 2 的幂、上下左右环绕的正方形世界和 B3/S23，其他情况照常交给 worker。
+// worker 每次只算一段行的一回合，HashLife 的缓存在那里用不上，所以它只在 broker 本地使用
+const (
+	engineWorkers  = "workers"
+	engineHashLife = "hashlife"
+)
+
+func validEngine(e string) bool {
+	return e == engineWorkers || e == engineHashLife
+}
+
+// newLife：配置为 hashlife 且世界尺寸、边界和规则支持时建一个 HashLife 宇宙，否则返回 nil（交给 worker）
+func newLife(world [][]uint8, boundary util.Boundary, rule util.Rule) *hashlife.Universe {
+	if currentConfig().Engine != engineHashLife || len(world) == 0 {
+		return nil
+	}
+	if !boundary.Torus() {
+		logger.Warn("hashlife only supports the torus boundary, using workers", "boundary", boundary)
+		return nil
+	}
+	if rule != util.Conway {
+		logger.Warn("hashlife only supports B3/S23, using workers", "rule", rule)
+		return nil
+	}
+	life, err := hashlife.New(world)
+	if err != nil {
+		logger.Warn("hashlife needs a square world with a power-of-two side, using workers", "width", len(world[0]), "height", len(world))
+		return nil
+	}
+	logger.Info("simulating with hashlife", "size", life.Size())
+	return life
+}
+
+// evolveSparse：世界足够稀疏（最多 1/util.SparseThreshold 的细胞活着）时，broker 只看活细胞的邻居自己算，
+// 一个滑翔机在 5120×5120 的世界里就不用每回合切块发给所有 worker。
+// 不够稀疏、边界不环绕、规则让没有活邻居的细胞出生（B0）或者有将死状态（Generations）时 ok 为 false
+func evolveSparse(world [][]uint8, boundary util.Boundary, rule util.Rule) (newWorld [][]uint8, flipped []util.Cell, ok bool) {
+	if !boundary.Torus() || rule.Birth&1 != 0 || rule.Generations() {
+		return nil, nil, false
+	}
+	live, sparse := util.SparseCells(world)
+	if !sparse {
+		return nil, nil, false
+	}
+	flipped = util.SparseStep(world, live, rule)
+
+	// 只复制有细胞翻转的行，其余行和旧世界共用（存下来的世界不会被原地修改）
+	newWorld = make([][]uint8, len(world))
+	copy(newWorld, world)
+	copied := make(map[int]bool)
+	for _, c := range flipped {
+		if !copied[c.Y] {
+			newWorld[c.Y] = append([]uint8(nil), world[c.Y]...)
+			copied[c.Y] = true
+		}
+		newWorld[c.Y][c.X] ^= 255
+	}
+	return newWorld, flipped, true
+}
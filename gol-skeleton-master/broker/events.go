@@ -0,0 +1,158 @@
+package main
+
+import (
+	"net/http"
+
+	"golang.org/x/net/websocket"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// WebSocket 事件流：GET /events 升级成 WebSocket 之后，每回合推一条 JSON，
+// 浏览器里的 canvas 页面（/view，见 web/view.html）不用 SDL 也能远程看模拟。
+// 内部就是一个观察者（Subscribe / Poll），所以 /status 的 observers 里也算它一个
+//
+//	{"type":"world","turn":0,"width":512,"height":512,"alive":[[x,y],...],"count":5565}
+//	{"type":"turn","turn":1,"flipped":[[x,y],...],"count":5567}
+//
+// world 是完整的世界：连上时发一次，观察者落后太多要重新同步时再发；turn 是之后每一回合
+// 活没活的状态变了的细胞。Generations 规则下将死细胞的衰减不算在 flipped 里
+
+// 以下是事件流里的 JSON 格式
+type worldEvent struct {
+	Type   string   `json:"type"`
+	Turn   int      `json:"turn"`
+	Width  int      `json:"width"`
+	Height int      `json:"height"`
+	Alive  [][2]int `json:"alive"`
+	Count  int      `json:"count"`
+}
+
+type turnEvent struct {
+	Type    string   `json:"type"`
+	Turn    int      `json:"turn"`
+	Flipped [][2]int `json:"flipped"`
+	Count   int      `json:"count"`
+}
+
+// eventsHandler：不检查 Origin，别的页面也能连（配置了 token 时照样要带）。?job= 选 job，默认是默认 job
+func (b *Broker) eventsHandler() http.Handler {
+	return websocket.Server{
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(ws *websocket.Conn) {
+			s, err := b.job(ws.Request().URL.Query().Get("job"), false)
+			if err != nil {
+				logger.Warn("event stream rejected", "remote", ws.Request().RemoteAddr, "err", err)
+				_ = ws.Close()
+				return
+			}
+			s.streamEvents(ws)
+		},
+	}
+}
+
+// streamEvents：登记成观察者，把每次 Poll 到的回合转成 JSON 推给浏览器，直到连接断开
+func (s *simulation) streamEvents(ws *websocket.Conn) {
+	defer ws.Close()
+	remote := ws.Request().RemoteAddr
+
+	var sub SubscribeReply
+	if err := s.Subscribe(struct{}{}, &sub); err != nil {
+		s.log.Warn("event stream subscribe failed", "remote", remote, "err", err)
+		return
+	}
+	defer func() {
+		var ok bool
+		_ = s.Unsubscribe(SubscriptionArgs{ID: sub.ID}, &ok)
+	}()
+	s.log.Info("event stream connected", "remote", remote, "observer", sub.ID)
+
+	// 浏览器不会发东西过来：读到错误就说明连接断了
+	closed := make(chan struct{})
+	go func() {
+		var discard []byte
+		for websocket.Message.Receive(ws, &discard) == nil {
+		}
+		close(closed)
+	}()
+
+	var v eventView
+	if sub.World != nil {
+		if err := v.reset(ws, sub.Turn, sub.World, sub.Rule); err != nil {
+			return
+		}
+	}
+	for {
+		select {
+		case <-closed:
+			s.log.Info("event stream closed", "remote", remote, "observer", sub.ID)
+			return
+		default:
+		}
+
+		var poll PollReply
+		if err := s.Poll(SubscriptionArgs{ID: sub.ID}, &poll); err != nil {
+			s.log.Warn("event stream poll failed", "remote", remote, "err", err)
+			return
+		}
+		var err error
+		if poll.World != nil {
+			err = v.reset(ws, poll.Turn, poll.World, poll.Rule)
+		}
+		for _, delta := range poll.Deltas {
+			if err == nil && v.world != nil {
+				err = v.turn(ws, delta)
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// eventView：事件流这一端的世界副本，用来算每回合活细胞的变化和数量
+type eventView struct {
+	world [][]uint8
+	rule  util.Rule
+	count int
+}
+
+// reset：换成新的世界并发一条 world 事件
+func (v *eventView) reset(ws *websocket.Conn, turn int, world [][]uint8, rule string) error {
+	var err error
+	if v.rule, err = util.ParseRule(rule); err != nil {
+		return err
+	}
+	v.world = util.CopyWorld(world)
+	event := worldEvent{Type: "world", Turn: turn, Height: len(world), Alive: [][2]int{}}
+	for y, row := range world {
+		event.Width = len(row)
+		for x, cell := range row {
+			if cell == 255 {
+				event.Alive = append(event.Alive, [2]int{x, y})
+			}
+		}
+	}
+	v.count = len(event.Alive)
+	event.Count = v.count
+	return websocket.JSON.Send(ws, event)
+}
+
+// turn：应用一回合的翻转并发一条 turn 事件
+func (v *eventView) turn(ws *websocket.Conn, delta TurnDelta) error {
+	event := turnEvent{Type: "turn", Turn: delta.Turn, Flipped: [][2]int{}}
+	for _, cell := range delta.Flipped {
+		old := v.world[cell.Y][cell.X]
+		v.world[cell.Y][cell.X] = v.rule.Flip(old)
+		switch {
+		case old == 255:
+			v.count--
+		case v.world[cell.Y][cell.X] == 255:
+			v.count++
+		default:
+			continue // 将死细胞继续衰减，活没活没变
+		}
+		event.Flipped = append(event.Flipped, [2]int{cell.X, cell.Y})
+	}
+	event.Count = v.count
+	return websocket.JSON.Send(ws, event)
+}
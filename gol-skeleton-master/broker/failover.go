@@ -0,0 +1,151 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/rpc"
+	"sync"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/transport"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// failedSet：本回合已经失败过的 worker，重试时跳过它们；failures 数本回合一共失败了几次
+type failedSet struct {
+	mu       sync.Mutex
+	addrs    map[string]bool
+	failures int
+}
+
+func newFailedSet() *failedSet {
+	return &failedSet{addrs: make(map[string]bool)}
+}
+
+func (f *failedSet) add(addr string) {
+	f.mu.Lock()
+	f.addrs[addr] = true
+	f.failures++
+	f.mu.Unlock()
+}
+
+// exhausted：本回合的失败次数到了 limit（见 RetryConfig.MaxTurnFailures），0 表示不限
+func (f *failedSet) exhausted(limit int) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return limit > 0 && f.failures >= limit
+}
+
+func (f *failedSet) has(addr string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.addrs[addr]
+}
+
+// isConnectionError：连接断开 / 网络错误才说明 worker 挂了；
+// rpc.ServerError 是 worker 正常返回的业务错误（比如 task 不合法），换 worker 也没用
+func isConnectionError(err error) bool {
+	var serverErr rpc.ServerError
+	return !errors.As(err, &serverErr)
+}
+
+// runTask：先交给第 first 个 worker，失败后按顺序换其它健康 worker（最多 MaxAttempts 个，
+// 本回合失败次数到了 MaxTurnFailures 就不再换），都不行时按配置由 broker 自己在本地算这一块，
+// 或者让这一回合失败。同时返回算出这一块的 worker，本地算的是空字符串
+func runTask(j job, first int, workers []WorkerClient, failed *failedSet, spec *speculation, log *slog.Logger) (PartReply, string, error) {
+	log = log.With("part", j.String())
+	retry := currentConfig().Retry
+	attempts := len(workers)
+	if retry.MaxAttempts > 0 && retry.MaxAttempts < attempts {
+		attempts = retry.MaxAttempts
+	}
+
+	// 解析不了的规则照样发出去，由 worker 报错
+	rule, ruleErr := util.ParseRule(j.rule)
+	for n := 0; n < len(workers) && attempts > 0; n++ {
+		w := workers[(first+n)%len(workers)]
+		if failed.has(w.addr) || (ruleErr == nil && !w.caps.runs(rule)) {
+			continue
+		}
+
+		attempts--
+		start := time.Now()
+		workerResult, addr, err := spec.call(j, w, workers, failed, log)
+		if err == nil {
+			sched.observe(addr, (j.x1-j.x0)*(j.y1-j.y0), time.Since(start))
+			return workerResult, addr, nil
+		}
+		log.Warn("worker task failed", "worker", w.addr, "err", err)
+
+		if !isConnectionError(err) {
+			return PartReply{}, "", util.Errorf(util.CodeWorkerFailed, "worker %s rejected task %s: %v", w.addr, j, err)
+		}
+		failed.add(w.addr)
+		// 超时的 worker 可能只是慢，已经记为可疑（见 timeout.go）；连接出问题的 worker 直接踢掉，心跳不用再等三次
+		if !errors.Is(err, transport.ErrTimeout) && removeWorker(w.addr) {
+			log.Warn("worker evicted after failed task", "worker", w.addr)
+		}
+		if failed.exhausted(retry.MaxTurnFailures) {
+			log.Warn("too many worker failures this turn, not retrying on other workers", "max_turn_failures", retry.MaxTurnFailures)
+			break
+		}
+	}
+
+	if !retry.LocalFallback {
+		if failed.exhausted(retry.MaxTurnFailures) {
+			return PartReply{}, "", util.Errorf(util.CodeWorkerFailed, "%d worker failures this turn, giving up on %s", retry.MaxTurnFailures, j)
+		}
+		return PartReply{}, "", util.Errorf(util.CodeWorkerFailed, "no healthy worker could process %s", j)
+	}
+
+	// 最后兜底：broker 本地计算
+	log.Warn("no healthy worker left, computing locally")
+	reply, err := j.local()
+	return reply, "", err
+}
+
+// computePart：和 Worker.ProcessPart 完全一样的规则，用于本地兜底
+func computePart(t Task) (PartReply, error) {
+	height := t.EndY - t.StartY
+	if height <= 0 {
+		return PartReply{}, fmt.Errorf("invalid task: height <= 0")
+	}
+	if len(t.WorldPart) < height+2 {
+		return PartReply{}, fmt.Errorf("invalid task: worldPart too small")
+	}
+	rule, err := util.ParseRule(t.Rule)
+	if err != nil {
+		return PartReply{}, fmt.Errorf("invalid task: %v", err)
+	}
+
+	width := len(t.WorldPart[0])
+	res := util.NewWorld(width, height)
+	var flipped []util.Cell
+
+	for y := 0; y < height; y++ {
+		row := res[y]
+		srcY := y + 1 // 对应 worldPart 中的行号
+
+		for x := 0; x < width; x++ {
+			neighbors := 0
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					if dx == 0 && dy == 0 {
+						continue
+					}
+					nx, ok := t.Boundary.Neighbour(x+dx, width) // 左右按边界处理
+					if ok && t.WorldPart[srcY+dy][nx] == 255 {
+						neighbors++
+					}
+				}
+			}
+
+			row[x] = rule.Step(t.WorldPart[srcY][x], neighbors)
+			if row[x] != t.WorldPart[srcY][x] {
+				flipped = append(flipped, util.Cell{X: x, Y: t.StartY + y})
+			}
+		}
+	}
+	return PartReply{Rows: res, Flipped: flipped}, nil
+}
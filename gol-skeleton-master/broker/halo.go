@@ -0,0 +1,239 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// halo 模式（-mode halo）：worker 长期持有自己的行段，每回合直接和邻居 worker 交换 halo 行，
+// broker 只在 StartSimulation 时建立拓扑（有新 worker 注册时在回合之间重建，见 scale.go），
+// 之后每回合只发 Step，需要完整世界时再收集
+
+// 以下类型必须和 worker 那边保持一致
+type BandSetup struct {
+	StartY, EndY int
+	Rows         util.World
+	Above, Below string        // 空字符串表示邻居就是自己
+	Height       int           // 整个世界的行数
+	Boundary     util.Boundary // 不环绕时第一段 / 最后一段自己填世界之外的 halo 行
+	Rule         string
+}
+
+type StepArgs struct {
+	Turn int
+}
+
+type StepReply struct {
+	Flipped    []util.Cell
+	AliveCount int
+}
+
+// haloTopology：一个 job 的行段分配，workers[i] 负责 bands[i]
+type haloTopology struct {
+	job           string // 哪个 job 的模拟
+	width, height int
+	workers       []WorkerClient
+	bands         [][2]int
+	alive         []int // 每段最近一次上报的存活细胞数，step 之后由 simulation.step 在 mu 下和回合数一起更新
+	base          int   // 分配行段时的回合数，worker 的回合从 0 数起
+	joined        int64 // 分配行段时的 workersJoined，之后有新 worker 注册就重新分段（见 scale.go）
+}
+
+// worker 一次只能持有一个行段：分给一个 job 的 halo 模拟之后，别的 job 就不能再用它做 halo，
+// 直到那个 job 换了拓扑（重新 StartSimulation / 无状态 ProcessTurn）。scatter 模式不受影响，
+// 一个 worker 都分不到的 job 就退回 scatter 模式
+var (
+	errHaloBusy = errors.New("all workers hold halo bands of other jobs")
+
+	haloOwners   = make(map[string]*haloTopology) // worker 地址 -> 占着它的拓扑
+	haloOwnersMu sync.Mutex
+)
+
+// claim：把 workers 里没被别的 job 的 halo 模拟占着的记到 topo 名下，返回这些 worker
+func (topo *haloTopology) claim(workers []WorkerClient) []WorkerClient {
+	haloOwnersMu.Lock()
+	defer haloOwnersMu.Unlock()
+	free := workers[:0]
+	for _, w := range workers {
+		if owner, ok := haloOwners[w.addr]; !ok || owner.job == topo.job {
+			haloOwners[w.addr] = topo
+			free = append(free, w)
+		}
+	}
+	return free
+}
+
+// release：放开 topo 还占着、且不在 keep 里的 worker，nil 时什么都不做
+func (topo *haloTopology) release(keep ...WorkerClient) {
+	if topo == nil {
+		return
+	}
+	haloOwnersMu.Lock()
+	defer haloOwnersMu.Unlock()
+	for addr, owner := range haloOwners {
+		if owner == topo && !slices.ContainsFunc(keep, func(w WorkerClient) bool { return w.addr == addr }) {
+			delete(haloOwners, addr)
+		}
+	}
+}
+
+// setupHalo：把世界按行切给 job 能用的所有 worker，并告诉每个 worker 它的上下邻居。
+// 出错时不占着任何 worker
+func setupHalo(params WorldParams, log *slog.Logger) (topo *haloTopology, err error) {
+	joined := workersJoined.Load() // 在拷贝 workerList 之前读，之后注册的 worker 下一回合还会被发现
+	workers, err := waitForWorkers(params.JobID, log)
+	if err != nil {
+		return nil, err
+	}
+
+	topo = &haloTopology{
+		job:    params.JobID,
+		width:  params.ImageWidth,
+		height: params.ImageHeight,
+		joined: joined,
+	}
+	if registered := len(workers); registered > 0 {
+		workers = topo.claim(workers)
+		defer func() {
+			if err != nil {
+				topo.release()
+			}
+		}()
+		if len(workers) == 0 {
+			return nil, fmt.Errorf("%d workers: %w", registered, errHaloBusy)
+		}
+	}
+
+	if len(workers) == 0 {
+		return nil, util.Errorf(util.CodeNoWorkers, "no workers available")
+	}
+	if minWorkers := currentConfig().MinWorkers; len(workers) < minWorkers {
+		return nil, util.Errorf(util.CodeNoWorkers, "only %d workers registered, need at least %d", len(workers), minWorkers)
+	}
+	// 按吞吐量分行，分不到行的 worker 不参加（每个 worker 至少一行），也不再占着
+	for i, band := range partition(params.ImageHeight, workerWeights(workers)) {
+		if band[0] < band[1] {
+			topo.workers = append(topo.workers, workers[i])
+			topo.bands = append(topo.bands, band)
+		}
+	}
+	topo.release(topo.workers...)
+	if err = topo.setup(params); err != nil {
+		return nil, err
+	}
+	return topo, nil
+}
+
+// setup：把 params.World 按 topo 分好的行段发给各个 worker，worker 从第 0 回合重新数起
+func (topo *haloTopology) setup(params WorldParams) error {
+	workers := topo.workers
+	n := len(workers)
+	topo.alive = make([]int, n)
+
+	neighbour := func(i, j int) string {
+		if i == j {
+			return ""
+		}
+		return workers[j].addr
+	}
+
+	return topo.forEach(func(i int, w WorkerClient) error {
+		setup := BandSetup{
+			StartY:   topo.bands[i][0],
+			EndY:     topo.bands[i][1],
+			Rows:     params.World[topo.bands[i][0]:topo.bands[i][1]],
+			Above:    neighbour(i, (i-1+n)%n),
+			Below:    neighbour(i, (i+1)%n),
+			Height:   params.ImageHeight,
+			Boundary: params.Boundary,
+			Rule:     params.Rule,
+		}
+		return w.client.Call("Worker.SetupBand", setup, &topo.alive[i])
+	})
+}
+
+// step：所有 worker 同时推进一回合（它们之间自己交换 halo），合并翻转的细胞，
+// 并返回每段上报的存活细胞数（不直接写进 alive，免得和回合数对不上）和最慢的 worker
+func (topo *haloTopology) step(turn int) ([]util.Cell, []int, StepTiming, error) {
+	replies := make([]StepReply, len(topo.workers))
+	took := make([]time.Duration, len(topo.workers))
+	err := topo.forEach(func(i int, w WorkerClient) error {
+		start := time.Now()
+		defer func() { took[i] = time.Since(start) }()
+		return w.client.Call("Worker.Step", StepArgs{Turn: turn - topo.base}, &replies[i])
+	})
+	if err != nil {
+		// 行段只保存在 worker 上，丢了一段就没法继续，交给 distributor 决定
+		return nil, nil, StepTiming{}, err
+	}
+	var timing StepTiming
+	for i, w := range topo.workers {
+		timing.observe(w.addr, took[i])
+	}
+
+	var flipped []util.Cell
+	alive := make([]int, len(replies))
+	for i, r := range replies {
+		flipped = append(flipped, r.Flipped...)
+		alive[i] = r.AliveCount
+	}
+	return flipped, alive, timing, nil
+}
+
+// gather：向每个 worker 取回行段，拼出完整世界
+func (topo *haloTopology) gather() ([][]uint8, error) {
+	world := util.NewWorld(topo.width, topo.height)
+	err := topo.forEach(func(i int, w WorkerClient) error {
+		var rows util.World
+		if err := w.client.Call("Worker.FetchBand", struct{}{}, &rows); err != nil {
+			return err
+		}
+		if len(rows) != topo.bands[i][1]-topo.bands[i][0] {
+			return fmt.Errorf("band has %d rows, expected %d", len(rows), topo.bands[i][1]-topo.bands[i][0])
+		}
+		// 各段的行互不重叠，拷进去不用加锁
+		for y, row := range rows {
+			copy(world[topo.bands[i][0]+y], row)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return world, nil
+}
+
+func (topo *haloTopology) aliveCount() int {
+	count := 0
+	for _, n := range topo.alive {
+		count += n
+	}
+	return count
+}
+
+// forEach：并发地对每个 worker 执行 fn，返回第一个错误
+func (topo *haloTopology) forEach(fn func(i int, w WorkerClient) error) error {
+	errs := make([]error, len(topo.workers))
+	var wg sync.WaitGroup
+	for i, w := range topo.workers {
+		wg.Add(1)
+		go func(i int, w WorkerClient) {
+			defer wg.Done()
+			errs[i] = fn(i, w)
+		}(i, w)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return util.Errorf(util.CodeWorkerFailed, "worker %s (rows [%d, %d)): %v", topo.workers[i].addr, topo.bands[i][0], topo.bands[i][1], err)
+		}
+	}
+	return nil
+}
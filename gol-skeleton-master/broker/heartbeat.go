@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"net/rpc"
+	"sync"
+	"time"
+)
+
+// 默认值，可以在配置文件 retry 里修改
+const (
+	heartbeatInterval = 2 * time.Second // 每隔多久 ping 一次所有 worker
+	heartbeatTimeout  = 1 * time.Second // 单次 ping 的超时时间
+	maxMissedPings    = 3               // 连续失败多少次后踢掉 worker
+)
+
+// deregisterGrace：worker 注销之后过多久关掉到它的连接，足够它把手上的任务算完
+const deregisterGrace = 30 * time.Second
+
+// pingWorker：调用 Worker.Ping，超时也算失败，成功时返回往返时间
+func pingWorker(w WorkerClient, timeout time.Duration) (time.Duration, error) {
+	var reply bool
+	start := time.Now()
+	call := w.client.Go("Worker.Ping", struct{}{}, &reply, make(chan *rpc.Call, 1))
+	select {
+	case <-call.Done:
+		return time.Since(start), call.Error
+	case <-time.After(timeout):
+		return 0, fmt.Errorf("ping timed out after %v", timeout)
+	}
+}
+
+// workerHealth：最近一次心跳的往返时间和连续失败次数，给 HTTP 的 /workers 看
+type workerHealth struct {
+	Latency time.Duration
+	Missed  int
+}
+
+var (
+	health      = make(map[string]workerHealth)
+	healthMutex sync.Mutex
+)
+
+// workerHealthOf：worker 的心跳情况，还没 ping 过时是零值
+func workerHealthOf(addr string) workerHealth {
+	healthMutex.Lock()
+	defer healthMutex.Unlock()
+	return health[addr]
+}
+
+// removeWorker：把 worker 从 workerList 中移除并关闭连接，之后 ProcessTurn 不会再给它分配行
+func removeWorker(address string) bool {
+	w, ok := takeWorker(address)
+	if ok {
+		_ = w.client.Close()
+	}
+	return ok
+}
+
+// takeWorker：把 worker 从 workerList 中移除，连接留给调用者处理
+func takeWorker(address string) (WorkerClient, bool) {
+	workerMutex.Lock()
+	defer workerMutex.Unlock()
+
+	for i, w := range workerList {
+		if w.addr == address {
+			workerList = append(workerList[:i], workerList[i+1:]...)
+			sched.forget(address)
+			forgetSuspect(address)
+			healthMutex.Lock()
+			delete(health, address)
+			healthMutex.Unlock()
+			return w, true
+		}
+	}
+	return WorkerClient{}, false
+}
+
+// startHeartbeat：后台定期 ping 所有已注册 worker，连续 MaxMissedPings 次没响应就踢掉
+// 间隔 / 超时 / 次数每轮从当前配置读取，SIGHUP 重新加载后立即生效
+func startHeartbeat() {
+	missed := make(map[string]int)
+
+	go func() {
+		interval := time.Duration(currentConfig().Retry.HeartbeatInterval)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			retry := currentConfig().Retry
+			if d := time.Duration(retry.HeartbeatInterval); d != interval {
+				interval = d
+				ticker.Reset(interval)
+			}
+
+			workerMutex.Lock()
+			workers := make([]WorkerClient, len(workerList))
+			copy(workers, workerList)
+			workerMutex.Unlock()
+
+			// 并发 ping，避免一个卡住的 worker 拖慢整轮检查
+			errs := make([]error, len(workers))
+			latencies := make([]time.Duration, len(workers))
+			var wg sync.WaitGroup
+			for i, w := range workers {
+				wg.Add(1)
+				go func(i int, w WorkerClient) {
+					defer wg.Done()
+					latencies[i], errs[i] = pingWorker(w, time.Duration(retry.HeartbeatTimeout))
+				}(i, w)
+			}
+			wg.Wait()
+
+			healthMutex.Lock()
+			for i, w := range workers {
+				h := health[w.addr]
+				if errs[i] == nil {
+					h = workerHealth{Latency: latencies[i]}
+				} else {
+					h.Missed++
+				}
+				health[w.addr] = h
+			}
+			healthMutex.Unlock()
+
+			alive := make(map[string]bool, len(workers))
+			for i, w := range workers {
+				alive[w.addr] = true
+				if errs[i] == nil {
+					delete(missed, w.addr)
+					continue
+				}
+				missed[w.addr]++
+				logger.Warn("worker heartbeat failed", "worker", w.addr, "missed", missed[w.addr], "max_missed", retry.MaxMissedPings, "err", errs[i])
+				if missed[w.addr] >= retry.MaxMissedPings {
+					if removeWorker(w.addr) {
+						logger.Warn("worker evicted after missed heartbeats", "worker", w.addr, "missed", missed[w.addr])
+					}
+					delete(missed, w.addr)
+				}
+			}
+			// 已经不在列表里的 worker 不再记录
+			for addr := range missed {
+				if !alive[addr] {
+					delete(missed, addr)
+				}
+			}
+		}
+	}()
+}
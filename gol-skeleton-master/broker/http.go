@@ -0,0 +1,256 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"uk.ac.bris.cs/gameoflife/transport"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// HTTP+JSON 接口：脚本和仪表盘不用 Go 的 RPC 客户端也能查看和控制模拟（-http-port 打开）
+//
+//	GET  /          集群状态页面（见 web/index.html）：worker、当前回合、暂停状态和活细胞数曲线
+//	GET  /jobs      每个 job 的 /status
+//	GET  /status    当前回合、活细胞数、是否暂停 / 后台推进、worker 和观察者数量
+//	GET  /workers   每个 worker 的地址、心跳延迟和测得的速度
+//	POST /workers/retire?addr=<地址>  把这个 worker 移出集群（见 retire.go），它不再参加任何回合时回复
+//	GET  /world     当前回合和所有活细胞的坐标
+//	POST /pause     暂停，回复暂停后的 /status
+//	POST /resume    继续，回复继续后的 /status
+//	POST /shutdown  和按 'k' 一样关闭 worker 和 broker
+//	GET  /events    WebSocket 事件流，每回合翻转的细胞和活细胞数（见 events.go）
+//	GET  /view      在浏览器里用 canvas 画 /events 的页面
+//	GET  /healthz   broker 还活着就回复 200
+//	GET  /readyz    注册的 worker 够 min_workers 个（至少一个）、没在停止时回复 200，否则 503（见 ready）
+//
+// 配置了 token 时请求要带 "Authorization: Bearer <token>"，浏览器打开的页面用 ?access_token=<token>，
+// 探活的 /healthz 和 /readyz 不用。
+// 除了 /jobs、/workers、/workers/retire 和 /shutdown，都作用在 ?job=<JobID> 指明的 job 上，不带时是默认 job。
+// 出错时回复 {"error": "..."}
+
+//go:embed web
+var webFiles embed.FS
+
+// 以下是 HTTP 回复的 JSON 格式
+type httpStatus struct {
+	Turn      int  `json:"turn"`
+	Alive     int  `json:"alive"`
+	Paused    bool `json:"paused"`
+	Detached  bool `json:"detached"`
+	Workers   int  `json:"workers"`
+	Observers int  `json:"observers"`
+}
+
+type httpJob struct {
+	Job string `json:"job"`
+	httpStatus
+}
+
+type httpWorker struct {
+	Addr       string   `json:"addr"`
+	Score      float64  `json:"score"`      // 注册时上报的分数（细胞/秒），0 表示未知
+	Throughput float64  `json:"throughput"` // 实际测得的速度（细胞/秒），scatter 模式算过回合之后才有
+	LatencyMs  float64  `json:"latency_ms"` // 最近一次心跳的往返时间
+	Missed     int      `json:"missed"`     // 连续没响应的心跳次数
+	Suspect    bool     `json:"suspect"`    // 有超时的任务还没返回
+	Rows       int      `json:"rows"`       // 分给它的行（见 WorkerInfo.Rows）
+	Version    int      `json:"version"`    // 协议版本，0 表示没有协商过
+	Codecs     []string `json:"codecs"`     // 给它发任务时可用的世界编码
+	Cores      int      `json:"cores"`      // CPU 核数，0 表示未知
+	Rules      []string `json:"rules"`      // 能跑的规则
+}
+
+type httpWorld struct {
+	Turn   int      `json:"turn"`
+	Width  int      `json:"width"`
+	Height int      `json:"height"`
+	Alive  [][2]int `json:"alive"` // 每个活细胞的 [x, y]
+}
+
+type httpError struct {
+	Error string `json:"error"`
+}
+
+// serveHTTP：在 port 上开 HTTP 接口，返回的 server 由调用方在关闭时 Close
+func serveHTTP(b *Broker, port int, token string) (*http.Server, error) {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return nil, err
+	}
+	server := &http.Server{Handler: b.httpHandler(token)}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("HTTP server stopped", "err", err)
+		}
+	}()
+	return server, nil
+}
+
+// httpHandler：各个接口都只是调用对应的 RPC 方法，行为和 net/rpc 客户端看到的一致
+func (b *Broker) httpHandler(token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFileFS(w, r, webFiles, "web/index.html")
+	})
+	mux.HandleFunc("GET /jobs", func(w http.ResponseWriter, r *http.Request) {
+		sims := b.simulations()
+		reply := make([]httpJob, len(sims))
+		for i, s := range sims {
+			reply[i] = httpJob{Job: s.id, httpStatus: s.status()}
+		}
+		writeJSON(w, http.StatusOK, reply)
+	})
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, r *http.Request) {
+		if s := b.jobOf(w, r); s != nil {
+			writeJSON(w, http.StatusOK, s.status())
+		}
+	})
+	mux.HandleFunc("GET /workers", func(w http.ResponseWriter, r *http.Request) {
+		workers := b.workerInfos()
+		reply := make([]httpWorker, len(workers))
+		for i, worker := range workers {
+			reply[i] = httpWorker{
+				Addr:       worker.Address,
+				Score:      worker.Score,
+				Throughput: worker.Throughput,
+				LatencyMs:  float64(worker.Latency.Microseconds()) / 1000,
+				Missed:     worker.Missed,
+				Suspect:    worker.Suspect,
+				Rows:       worker.Rows,
+				Version:    worker.Version,
+				Codecs:     worker.Codecs,
+				Cores:      worker.Cores,
+				Rules:      worker.Rules,
+			}
+		}
+		writeJSON(w, http.StatusOK, reply)
+	})
+	mux.HandleFunc("POST /workers/retire", func(w http.ResponseWriter, r *http.Request) {
+		addr := r.URL.Query().Get("addr")
+		if addr == "" {
+			writeJSON(w, http.StatusBadRequest, httpError{"missing addr"})
+			return
+		}
+		if err := b.retire(addr); err != nil {
+			writeJSON(w, http.StatusConflict, httpError{err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"retired": addr})
+	})
+	mux.HandleFunc("GET /world", func(w http.ResponseWriter, r *http.Request) {
+		s := b.jobOf(w, r)
+		if s == nil {
+			return
+		}
+		var reply WorldReply
+		if err := s.GetWorld(struct{}{}, &reply); err != nil {
+			writeJSON(w, http.StatusConflict, httpError{err.Error()})
+			return
+		}
+		world := httpWorld{Turn: reply.Turn, Height: len(reply.World), Alive: [][2]int{}}
+		for y, row := range reply.World {
+			world.Width = len(row)
+			for x, cell := range row {
+				if cell == 255 {
+					world.Alive = append(world.Alive, [2]int{x, y})
+				}
+			}
+		}
+		writeJSON(w, http.StatusOK, world)
+	})
+	mux.HandleFunc("POST /pause", func(w http.ResponseWriter, r *http.Request) {
+		if s := b.jobOf(w, r); s != nil {
+			var ok bool
+			_ = s.Pause(struct{}{}, &ok)
+			writeJSON(w, http.StatusOK, s.status())
+		}
+	})
+	mux.HandleFunc("POST /resume", func(w http.ResponseWriter, r *http.Request) {
+		if s := b.jobOf(w, r); s != nil {
+			var ok bool
+			_ = s.Resume(struct{}{}, &ok)
+			writeJSON(w, http.StatusOK, s.status())
+		}
+	})
+	mux.Handle("GET /events", b.eventsHandler())
+	mux.HandleFunc("GET /view", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFileFS(w, r, webFiles, "web/view.html")
+	})
+	mux.HandleFunc("POST /shutdown", func(w http.ResponseWriter, r *http.Request) {
+		logger.Info("shutdown requested over HTTP", "remote", r.RemoteAddr)
+		var ok bool
+		_ = b.Shutdown(struct{}{}, &ok)
+		writeJSON(w, http.StatusOK, map[string]bool{"shutdown": true})
+	})
+	util.HandleHealth(mux, ready)
+
+	// 先校验 token 再交给 mux
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if util.IsHealthPath(r.URL.Path) {
+			mux.ServeHTTP(w, r)
+			return
+		}
+		if err := transport.CheckHTTPToken(r, token); err != nil {
+			writeJSON(w, http.StatusUnauthorized, httpError{err.Error()})
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// ready：/readyz 的判断，和 ProcessTurn 检查 worker 数的标准一样
+func ready() error {
+	select {
+	case <-stopping:
+		return fmt.Errorf("broker stopping")
+	case <-shutdown:
+		return fmt.Errorf("broker shutting down")
+	default:
+	}
+	workerMutex.Lock()
+	n := len(workerList)
+	workerMutex.Unlock()
+	if need := max(currentConfig().MinWorkers, 1); n < need {
+		return fmt.Errorf("%d workers registered, need at least %d", n, need)
+	}
+	return nil
+}
+
+// jobOf：?job= 指明的 job，没有这个 job 时回复 404 并返回 nil
+func (b *Broker) jobOf(w http.ResponseWriter, r *http.Request) *simulation {
+	s, err := b.job(r.URL.Query().Get("job"), false)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, httpError{err.Error()})
+		return nil
+	}
+	return s
+}
+
+// status：GetStatus 的结果加上活细胞数
+func (s *simulation) status() httpStatus {
+	var reply StatusReply
+	_ = s.GetStatus(struct{}{}, &reply)
+	_, alive := s.aliveAt()
+	return httpStatus{
+		Turn:      reply.Turn,
+		Alive:     alive,
+		Paused:    reply.Paused,
+		Detached:  reply.Detached,
+		Workers:   reply.Workers,
+		Observers: reply.Observers,
+	}
+}
+
+// writeJSON：以 JSON 回复 v
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Warn("write HTTP reply failed", "err", err)
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// job：分给一个 worker 的一块区域 [x0, x1) × [y0, y1)
+// rows 模式走 Worker.ProcessPart（Task，只带上下 halo，左右由 worker 按边界处理），
+// 其它模式走 Worker.ProcessTile（TileTask，四周都带 halo）
+type job struct {
+	x0, x1, y0, y1 int
+	method         string
+	args           interface{}
+	rule           string                    // 这一块按什么规则算，跑不了它的 worker 不分给它
+	local          func() (PartReply, error) // 所有 worker 都失败时 broker 本地算
+}
+
+// PartReply：Worker.ProcessPart / ProcessTile 的结果，必须和 worker 那边保持一致
+type PartReply struct {
+	Rows    util.World  // 这一块的下一代
+	Flipped []util.Cell // 这一块里变了的细胞，按整个世界的坐标
+}
+
+func (j job) String() string {
+	return fmt.Sprintf("[%d, %d)x[%d, %d)", j.x0, j.x1, j.y0, j.y1)
+}
+
+// fits：worker 返回的结果大小是否和这块区域一致
+func (j job) fits(rows [][]uint8) bool {
+	if len(rows) != j.y1-j.y0 {
+		return false
+	}
+	for _, row := range rows {
+		if len(row) != j.x1-j.x0 {
+			return false
+		}
+	}
+	return true
+}
+
+// 有状态模拟的 scatter 调度可以按行、按列或者按二维块切分世界
+const (
+	partitionRows    = "rows"
+	partitionColumns = "columns" // 又宽又矮的图按行切时有的 worker 几乎没活干
+	partitionTiles   = "tiles"   // 大的方形世界切成网格，halo 占的比例比细长条小
+)
+
+// splitWorld：按 cfg.Partition 切分世界，jobs[k] 先交给 workers[firsts[k]]
+// rows / columns 按 worker 吞吐量分段，每个 worker 一段；tiles 切成 cfg.Tiles 的网格，轮流分给 worker
+func splitWorld(params WorldParams, workers []WorkerClient, cfg Config) (jobs []job, firsts []int) {
+	if cfg.Partition == partitionTiles {
+		gridRows, gridCols, _ := parseTiles(cfg.Tiles)
+		ys := partition(params.ImageHeight, equalWeights(gridRows))
+		xs := partition(params.ImageWidth, equalWeights(gridCols))
+		for _, y := range ys {
+			for _, x := range xs {
+				if y[0] == y[1] || x[0] == x[1] {
+					continue // 网格比世界还细时有的块是空的
+				}
+				firsts = append(firsts, len(jobs)%len(workers))
+				jobs = append(jobs, tileJob(params, x[0], x[1], y[0], y[1]))
+			}
+		}
+		return jobs, firsts
+	}
+
+	// 按 worker 的吞吐量分行（或列），快的机器多分（开了 rebalance_every 时用实测速度）
+	columns := cfg.Partition == partitionColumns
+	span := params.ImageHeight
+	if columns {
+		span = params.ImageWidth
+	}
+	for i, band := range partition(span, sched.weights(workers, cfg.RebalanceEvery)) {
+		if band[0] == band[1] {
+			continue // 行比 worker 少时有的 worker 分不到
+		}
+		if columns {
+			jobs = append(jobs, columnJob(params, band[0], band[1]))
+		} else {
+			jobs = append(jobs, rowJob(params, band[0], band[1]))
+		}
+		firsts = append(firsts, i)
+	}
+	return jobs, firsts
+}
+
+// equalWeights：n 份一样大的权重，tiles 模式按网格均分
+func equalWeights(n int) []float64 {
+	weights := make([]float64, n)
+	for i := range weights {
+		weights[i] = 1
+	}
+	return weights
+}
+
+// parseTiles：解析 "4x4" 这样的网格大小（行 x 列）
+func parseTiles(s string) (rows, cols int, err error) {
+	if _, err := fmt.Sscanf(s, "%dx%d", &rows, &cols); err != nil {
+		return 0, 0, fmt.Errorf("tiles %q: want ROWSxCOLS, e.g. 4x4", s)
+	}
+	if rows <= 0 || cols <= 0 {
+		return 0, 0, fmt.Errorf("tiles %q: grid must be at least 1x1", s)
+	}
+	return rows, cols, nil
+}
+
+// TileTask：一个矩形块加上四周各一圈 halo（broker 已经按边界填好），必须和 worker 那边保持一致
+type TileTask struct {
+	StartX, EndX int
+	StartY, EndY int
+	Cells        util.World // (EndY-StartY+2) 行 × (EndX-StartX+2) 列
+	Rule         string
+}
+
+// rowJob：[startY, endY) 这几行，带上下边界
+func rowJob(params WorldParams, startY, endY int) job {
+	// 构造 worldPart：核心行 + 上下边界（按 params.Boundary 取）
+	worldPartLen := endY - startY
+	worldPart := make([][]uint8, worldPartLen+2)
+
+	// 核心行复制
+	copy(worldPart[1:worldPartLen+1], params.World[startY:endY])
+
+	// 上边界：startY 的上一行
+	worldPart[0] = haloRow(params, startY-1)
+
+	// 下边界：endY 的下一行
+	worldPart[worldPartLen+1] = haloRow(params, endY)
+
+	t := Task{
+		StartY:    startY,
+		EndY:      endY,
+		WorldPart: worldPart,
+		Boundary:  params.Boundary,
+		Rule:      params.Rule,
+	}
+	return job{
+		x0: 0, x1: params.ImageWidth, y0: startY, y1: endY,
+		method: "Worker.ProcessPart",
+		args:   t,
+		rule:   params.Rule,
+		local:  func() (PartReply, error) { return computePart(t) },
+	}
+}
+
+// haloRow：第 y 行，y 可以是世界之外的一行（-1 或 ImageHeight），这时按边界取，dead 是全死的一行
+func haloRow(params WorldParams, y int) []uint8 {
+	if y, ok := params.Boundary.Neighbour(y, params.ImageHeight); ok {
+		return params.World[y]
+	}
+	return make([]uint8, params.ImageWidth)
+}
+
+// columnJob：[startX, endX) 这几列
+func columnJob(params WorldParams, startX, endX int) job {
+	return tileJob(params, startX, endX, 0, params.ImageHeight)
+}
+
+// tileJob：[startX, endX) × [startY, endY) 这一块，四周的 halo 按 params.Boundary 取
+func tileJob(params WorldParams, startX, endX, startY, endY int) job {
+	cells := util.NewWorld(endX-startX+2, endY-startY+2)
+	for ty, row := range cells {
+		for tx := range row {
+			row[tx] = params.Boundary.Cell(params.World, startX+tx-1, startY+ty-1)
+		}
+	}
+
+	t := TileTask{StartX: startX, EndX: endX, StartY: startY, EndY: endY, Cells: cells, Rule: params.Rule}
+	return job{
+		x0: startX, x1: endX, y0: startY, y1: endY,
+		method: "Worker.ProcessTile",
+		args:   t,
+		rule:   params.Rule,
+		local:  func() (PartReply, error) { return computeTile(t) },
+	}
+}
+
+// computeTile：和 Worker.ProcessTile 完全一样的规则，用于本地兜底
+func computeTile(t TileTask) (PartReply, error) {
+	width, height := t.EndX-t.StartX, t.EndY-t.StartY
+	if width <= 0 || height <= 0 {
+		return PartReply{}, fmt.Errorf("invalid tile: empty")
+	}
+	if len(t.Cells) != height+2 || len(t.Cells[0]) != width+2 {
+		return PartReply{}, fmt.Errorf("invalid tile: cells are not %dx%d", width+2, height+2)
+	}
+	rule, err := util.ParseRule(t.Rule)
+	if err != nil {
+		return PartReply{}, fmt.Errorf("invalid tile: %v", err)
+	}
+
+	res := util.NewWorld(width, height)
+	var flipped []util.Cell
+	for y := 1; y <= height; y++ {
+		row := res[y-1]
+		for x := 1; x <= width; x++ {
+			neighbors := 0
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					if (dx != 0 || dy != 0) && t.Cells[y+dy][x+dx] == 255 {
+						neighbors++
+					}
+				}
+			}
+			row[x-1] = rule.Step(t.Cells[y][x], neighbors)
+			if row[x-1] != t.Cells[y][x] {
+				flipped = append(flipped, util.Cell{X: t.StartX + x - 1, Y: t.StartY + y - 1})
+			}
+		}
+	}
+	return PartReply{Rows: res, Flipped: flipped}, nil
+}
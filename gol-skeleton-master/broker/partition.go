@@ -0,0 +1,193 @@
+package main
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// 按 worker 的吞吐量切分行：worker 注册时上报 benchmark 分数（每秒算多少个细胞），
+// 分数高的机器（比如 c5.large）分到的行多，t2.micro 分到的少
+
+// workerWeights：每个 worker 的权重，没有上报分数的（配置文件里的 worker）按已知分数的平均值算
+func workerWeights(workers []WorkerClient) []float64 {
+	known, sum := 0, 0.0
+	for _, w := range workers {
+		if w.score > 0 {
+			known++
+			sum += w.score
+		}
+	}
+	fallback := 1.0
+	if known > 0 {
+		fallback = sum / float64(known)
+	}
+
+	weights := make([]float64, len(workers))
+	for i, w := range workers {
+		weights[i] = w.score
+		if weights[i] <= 0 {
+			weights[i] = fallback
+		}
+	}
+	return weights
+}
+
+// partition：把 height 行按权重切成连续的 [startY, endY) 段，bands[i] 对应 weights[i]
+// 行数够的话每段至少一行，其余按最大余数法分配；行比 worker 少时多出来的 worker 分到空段
+func partition(height int, weights []float64) [][2]int {
+	n := len(weights)
+	rows := make([]int, n)
+	if n == 0 {
+		return nil
+	}
+
+	total := 0.0
+	for _, w := range weights {
+		total += w
+	}
+
+	base := 0
+	if height >= n {
+		base = 1
+	}
+	spare := height - base*n
+
+	// 先按比例向下取整，剩下的行给小数部分最大的几个
+	assigned := 0
+	remainders := make([]float64, n)
+	for i, w := range weights {
+		share := float64(spare) * w / total
+		rows[i] = base + int(math.Floor(share))
+		remainders[i] = share - math.Floor(share)
+		assigned += rows[i]
+	}
+	for ; assigned < height; assigned++ {
+		best := 0
+		for i := range remainders {
+			if remainders[i] > remainders[best] {
+				best = i
+			}
+		}
+		rows[best]++
+		remainders[best] = -1
+	}
+
+	bands := make([][2]int, n)
+	startY := 0
+	for i, r := range rows {
+		bands[i] = [2]int{startY, startY + r}
+		startY += r
+	}
+	return bands
+}
+
+// 自适应重新分配：记录每个 worker 每次 ProcessPart 的实际速度（细胞/秒，含网络开销），
+// 每 RebalanceEvery 回合用测得的速度替换注册时的分数，拖后腿的 worker 下一段分到的行更少
+// 只影响 scatter 模式；halo 模式的行段在 StartSimulation 时固定
+
+// ewmaAlpha：新样本在移动平均里的权重
+const ewmaAlpha = 0.3
+
+type scheduler struct {
+	mu       sync.Mutex
+	measured map[string]float64 // 每个 worker 测得速度的指数移动平均
+	active   map[string]float64 // 当前分行用的权重，nil 表示还没重新分配过，用注册分数
+	turns    int                // 上次重新分配之后 evolve 了多少回合
+
+	rows map[string]map[string]int // 每个 job 最近一回合每个 worker 算了多少行，给 ListWorkers 看
+}
+
+var sched = &scheduler{measured: make(map[string]float64), rows: make(map[string]map[string]int)}
+
+// observe：记录一次成功的 ProcessPart
+func (s *scheduler) observe(addr string, cells int, elapsed time.Duration) {
+	if cells <= 0 || elapsed <= 0 {
+		return
+	}
+	rate := float64(cells) / elapsed.Seconds()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if old, ok := s.measured[addr]; ok {
+		rate = ewmaAlpha*rate + (1-ewmaAlpha)*old
+	}
+	s.measured[addr] = rate
+}
+
+// weights：这一回合分行用的权重，每 every 回合（every > 0 时）用测得的速度重新计算一次
+func (s *scheduler) weights(workers []WorkerClient, every int) []float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if every <= 0 {
+		s.active = nil
+		return workerWeights(workers)
+	}
+
+	s.turns++
+	if s.turns >= every && len(s.measured) > 0 {
+		s.turns = 0
+		s.active = make(map[string]float64, len(s.measured))
+		for addr, rate := range s.measured {
+			s.active[addr] = rate
+		}
+		logger.Debug("rebalanced row bands from measured throughput", "workers", len(s.active))
+	}
+	if s.active == nil {
+		return workerWeights(workers)
+	}
+
+	// 新加入、还没测过的 worker 按已测 worker 的平均速度算
+	known, sum := 0, 0.0
+	for _, w := range workers {
+		if rate, ok := s.active[w.addr]; ok {
+			known++
+			sum += rate
+		}
+	}
+	if known == 0 {
+		return workerWeights(workers)
+	}
+	weights := make([]float64, len(workers))
+	for i, w := range workers {
+		if rate, ok := s.active[w.addr]; ok {
+			weights[i] = rate
+		} else {
+			weights[i] = sum / float64(known)
+		}
+	}
+	return weights
+}
+
+// rate：worker 测得的速度（细胞/秒），还没测过时返回 0
+func (s *scheduler) rate(addr string) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.measured[addr]
+}
+
+// forget：worker 被移除后丢掉它的测量值
+func (s *scheduler) forget(addr string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.measured, addr)
+	delete(s.active, addr)
+	for _, rows := range s.rows {
+		delete(rows, addr)
+	}
+}
+
+// assign：记下 job 这一回合每个 worker 算了多少行
+func (s *scheduler) assign(job string, rows map[string]int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rows[job] = rows
+}
+
+// assigned：job 最近一回合 addr 算了多少行
+func (s *scheduler) assigned(job, addr string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rows[job][addr]
+}
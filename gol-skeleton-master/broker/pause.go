@@ -0,0 +1,82 @@
+package main
+
+import "time"
+
+// 暂停 / 继续：distributor 按 'p' 时调用（也可以走 HTTP 的 /pause、/resume），broker 这边真正停下来——
+// Detach 之后的后台推进会等到 Resume，ProcessTurns 在下一个回合边界提前返回；
+// NextTurn 一回合都不算，直接返回当前回合
+
+// StatusReply 必须和 distributor 那边保持一致
+type StatusReply struct {
+	Turn      int
+	Paused    bool
+	Detached  bool // 控制器已经退出，broker 在后台推进
+	Workers   int
+	Observers int
+	Rule      string // 模拟的规则，B/S 记法，还没开始模拟时为空
+
+	// 预热（见 warmup.go）：回合在等 worker 注册时 Waiting 是已经等了多久，0 表示没在等
+	Waiting    time.Duration
+	MinWorkers int // 回合至少要几个 worker
+}
+
+// Pause：暂停模拟，已经暂停时什么都不做
+func (s *simulation) Pause(_ struct{}, reply *bool) error {
+	s.mu.Lock()
+	if s.paused == nil {
+		s.paused = make(chan struct{})
+		s.log.Info("simulation paused", "turn", s.turn)
+	}
+	s.mu.Unlock()
+
+	*reply = true
+	return nil
+}
+
+// Resume：继续模拟，唤醒等待中的后台推进
+func (s *simulation) Resume(_ struct{}, reply *bool) error {
+	s.resume()
+	*reply = true
+	return nil
+}
+
+// resume：取消暂停，新模拟 / 新控制器接管时也会调用
+func (s *simulation) resume() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.paused != nil {
+		close(s.paused)
+		s.paused = nil
+		s.log.Info("simulation resumed", "turn", s.turn)
+	}
+}
+
+// pausedCh：暂停时返回一个 Resume 时会被关闭的 channel，没暂停时返回 nil
+func (s *simulation) pausedCh() chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.paused
+}
+
+// GetStatus：当前回合、是否暂停 / 后台推进、worker 和观察者数量
+func (s *simulation) GetStatus(_ struct{}, reply *StatusReply) error {
+	s.mu.Lock()
+	reply.Turn = s.turn
+	reply.Paused = s.paused != nil
+	reply.Detached = s.bg != nil
+	if s.currentWorld != nil {
+		reply.Rule = s.rule.String()
+	}
+	s.mu.Unlock()
+
+	workerMutex.Lock()
+	reply.Workers = len(workerList)
+	workerMutex.Unlock()
+	reply.Waiting = waitingFor(s.id)
+	reply.MinWorkers = max(currentConfig().MinWorkers, 1)
+
+	s.subs.mu.Lock()
+	reply.Observers = len(s.subs.subs)
+	s.subs.mu.Unlock()
+	return nil
+}
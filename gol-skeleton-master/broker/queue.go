@@ -0,0 +1,217 @@
+package main
+
+import (
+	"fmt"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// 批量任务：SubmitJob 交上来初始世界和回合数就返回，不用一直挂着控制器。任务按提交顺序一个接一个
+// 在 broker 上跑（和 Detach 之后一样在后台推进），JobProgress 查进度，跑完之后 JobResult 取结果。
+// 跑的时候它就是一个普通的 job：观察者可以订阅，控制器也可以 -resume -job 接管（接管后任务算结束）
+
+// 批量任务的状态，JobProgressReply.State
+const (
+	batchQueued   = "queued"
+	batchRunning  = "running"
+	batchDone     = "done"
+	batchFailed   = "failed"
+	batchTakeover = "taken over" // 跑完之前被控制器接管或者重新开始了
+)
+
+// SubmitArgs / SubmitReply / JobProgressReply / JobResultReply 必须和 distributor 那边保持一致
+type SubmitArgs struct {
+	ImageWidth  int
+	ImageHeight int
+	World       util.World
+	Turns       int           // 推进到第几回合
+	Boundary    util.Boundary // 世界边界之外看到什么，空字符串表示环绕
+	Rule        string        // B/S 记法的规则，空字符串表示 B3/S23
+	JobID       string        // 为空时 broker 起一个
+}
+
+type SubmitReply struct {
+	JobID string
+}
+
+type JobProgressReply struct {
+	State string // queued / running / done / failed / taken over
+	Turn  int    // 已经完成的回合数
+	Turns int    // 要推进到的回合数
+	Alive int    // 当前回合的存活细胞数
+	Err   string // failed 时的原因
+}
+
+type JobResultReply struct {
+	Turn  int
+	World util.World
+	Alive []util.Cell
+}
+
+// batch：一个提交上来的任务，started / done 关闭之后 bg / err 才能读
+type batch struct {
+	turns   int
+	started chan struct{} // 排到它时关闭
+	done    chan struct{} // 结束（不管成功与否）时关闭
+	bg      *background   // 推进它的后台循环
+	err     error         // 没能开始（比如世界不合法）的原因
+	reached bool          // 后台循环结束时推进到了 turns 回合，否则是被接管了
+}
+
+// SubmitJob：把任务排到队尾，立即返回它的 JobID。同一个 JobID 上一个任务还没结束时拒绝
+func (b *Broker) SubmitJob(args SubmitArgs, reply *SubmitReply) error {
+	if args.Turns < 0 {
+		return fmt.Errorf("invalid turns %d", args.Turns)
+	}
+	if len(args.World) != args.ImageHeight || (args.ImageHeight > 0 && len(args.World[0]) != args.ImageWidth) {
+		return fmt.Errorf("world is not %dx%d", args.ImageWidth, args.ImageHeight)
+	}
+
+	b.mu.Lock()
+	id := args.JobID
+	for id == "" || (args.JobID == "" && b.jobs[id] != nil) {
+		b.submitted++
+		id = fmt.Sprintf("batch-%d", b.submitted)
+	}
+	b.mu.Unlock()
+	s, err := b.job(id, true)
+	if err != nil {
+		return err
+	}
+
+	run := &batch{turns: args.Turns, started: make(chan struct{}), done: make(chan struct{})}
+	s.mu.Lock()
+	if prev := s.batch; prev != nil && !closed(prev.done) {
+		s.mu.Unlock()
+		return fmt.Errorf("job %q has not finished yet", id)
+	}
+	s.batch = run
+	s.mu.Unlock()
+
+	b.mu.Lock()
+	ahead := b.queueTail
+	b.queueTail = run.done
+	b.mu.Unlock()
+
+	params := WorldParams{
+		ImageWidth:  args.ImageWidth,
+		ImageHeight: args.ImageHeight,
+		World:       args.World,
+		Boundary:    args.Boundary,
+		Rule:        args.Rule,
+		JobID:       id,
+	}
+	s.log.Info("job submitted", "size", fmt.Sprintf("%dx%d", args.ImageWidth, args.ImageHeight), "turns", args.Turns)
+	go s.runBatch(run, ahead, params)
+
+	reply.JobID = id
+	return nil
+}
+
+// runBatch：等前面的任务（ahead）结束，开始模拟，在后台推进到 run.turns 回合
+func (s *simulation) runBatch(run *batch, ahead <-chan struct{}, params WorldParams) {
+	defer close(run.done)
+	if ahead != nil {
+		<-ahead
+	}
+
+	var ok bool
+	if err := s.StartSimulation(params, &ok); err != nil {
+		s.log.Error("job failed to start", "err", err)
+		run.err = err
+		close(run.started)
+		return
+	}
+	run.bg = s.startBackground(run.turns)
+	close(run.started)
+	<-run.bg.done
+	s.mu.Lock()
+	run.reached = s.turn >= run.turns
+	s.mu.Unlock()
+}
+
+// JobProgress：提交的任务排到哪、推进到第几回合了
+func (b *Broker) JobProgress(args JobArgs, reply *JobProgressReply) error {
+	s, err := b.job(args.JobID, false)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	run := s.batch
+	s.mu.Unlock()
+	if run == nil {
+		return fmt.Errorf("job %q was not submitted with SubmitJob", args.JobID)
+	}
+
+	reply.State, err = run.state()
+	if err != nil {
+		reply.Err = err.Error()
+	}
+	reply.Turns = run.turns
+	if reply.State != batchQueued {
+		reply.Turn, reply.Alive = s.aliveAt()
+	}
+	return nil
+}
+
+// state：任务现在的状态，failed 时连同原因
+func (run *batch) state() (string, error) {
+	switch {
+	case !closed(run.started):
+		return batchQueued, nil
+	case run.err != nil:
+		return batchFailed, run.err
+	case !closed(run.done):
+		return batchRunning, nil
+	case run.bg.err != nil:
+		return batchFailed, run.bg.err
+	case run.reached:
+		return batchDone, nil
+	}
+	return batchTakeover, nil
+}
+
+// JobResult：任务跑完之后的世界和活细胞；还没跑完或者失败了就报错
+func (b *Broker) JobResult(args JobArgs, reply *JobResultReply) error {
+	s, err := b.job(args.JobID, false)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	run := s.batch
+	s.mu.Unlock()
+	if run == nil {
+		return fmt.Errorf("job %q was not submitted with SubmitJob", args.JobID)
+	}
+	state, err := run.state()
+	if err != nil {
+		return fmt.Errorf("job %q failed: %v", args.JobID, err)
+	}
+	if state != batchDone {
+		return fmt.Errorf("job %q is %s, not done", args.JobID, state)
+	}
+
+	var world WorldReply
+	if err := s.GetWorld(struct{}{}, &world); err != nil {
+		return err
+	}
+	reply.Turn, reply.World = world.Turn, world.World
+	for y, row := range world.World {
+		for x, cell := range row {
+			if cell == 255 {
+				reply.Alive = append(reply.Alive, util.Cell{X: x, Y: y})
+			}
+		}
+	}
+	return nil
+}
+
+// closed：ch 是否已经关闭
+func closed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// 移出 worker：要回收一台机器（比如换掉一台 EC2）时不用停模拟。RetireWorker 先把它从 workerList 拿掉，
+// 之后的回合不再给它派任务；再等每个 job 正在算的回合算完，halo 模式下把它持有的行段分给别的 worker；
+// 最后让它排空退出，免得 -broker 启动的 worker 过一会儿又注册回来。返回时它已经不参加任何回合，可以直接关机
+
+// RetireWorker：把 args 指定的 worker 移出集群，等它不再参加任何回合才返回
+func (b *Broker) RetireWorker(args RegisterArgs, reply *bool) error {
+	address, err := args.address()
+	if err != nil {
+		return err
+	}
+	if err := b.retire(address); err != nil {
+		return err
+	}
+	*reply = true
+	return nil
+}
+
+// retire：RetireWorker 和 HTTP 的 POST /workers/retire 共用。
+// halo 模式下没有别的 worker 能接手它的行段时放回 workerList 并返回错误
+func (b *Broker) retire(address string) error {
+	w, ok := takeWorker(address)
+	if !ok {
+		return fmt.Errorf("worker %s is not registered", address)
+	}
+	logger.Info("retiring worker", "worker", address)
+	if err := b.moveOff(w); err != nil {
+		restoreWorker(w)
+		logger.Warn("retire worker failed, keeping it", "worker", address, "err", err)
+		return fmt.Errorf("retire %s: %w", address, err)
+	}
+
+	var drained bool
+	if err := w.client.Call("Worker.Drain", struct{}{}, &drained); err != nil {
+		logger.Warn("ask retired worker to drain failed", "worker", address, "err", err)
+	}
+	// 无状态 ProcessTurn 不拿 turnMu，可能还有发给它的任务在算，连接晚一点再关
+	time.AfterFunc(deregisterGrace, func() { _ = w.client.Close() })
+	logger.Info("worker retired", "worker", address)
+	return nil
+}
+
+// moveOff：w 已经不在 workerList 里了，等每个 job 正在算的回合算完，并把 halo 模式下 w 的行段分给别的 worker
+func (b *Broker) moveOff(w WorkerClient) error {
+	for _, s := range b.simulations() {
+		if err := s.moveOff(w); err != nil {
+			return fmt.Errorf("job %q: %w", s.id, err)
+		}
+	}
+	return nil
+}
+
+// moveOff：拿到 turnMu 就说明这个 job 正在算的回合算完了，之后的回合（scatter 模式）不会再用到 w；
+// halo 模式的拓扑用到 w 时在这里重新分段
+func (s *simulation) moveOff(w WorkerClient) error {
+	s.turnMu.Lock()
+	defer s.turnMu.Unlock()
+
+	s.mu.Lock()
+	topo := s.halo
+	turn := s.turn
+	s.mu.Unlock()
+	if topo == nil || !slices.ContainsFunc(topo.workers, func(t WorkerClient) bool { return t.addr == w.addr }) {
+		return nil
+	}
+	next, err := s.resplitHalo(topo, turn)
+	if err != nil {
+		return err
+	}
+	s.log.Info("bands moved off leaving worker", "turn", turn, "worker", w.addr, "workers", len(next.workers))
+	return nil
+}
+
+// restoreWorker：移出失败时把 w 放回 workerList，期间同一个地址重新注册过的话用新的连接
+func restoreWorker(w WorkerClient) {
+	workerMutex.Lock()
+	defer workerMutex.Unlock()
+	if slices.ContainsFunc(workerList, func(t WorkerClient) bool { return t.addr == w.addr }) {
+		_ = w.client.Close()
+		return
+	}
+	workerList = append(workerList, w)
+}
@@ -0,0 +1,64 @@
+package main
+
+import "slices"
+
+// 扩容：模拟跑到一半注册进来的 worker 从下一回合开始分到活。scatter 模式每回合都重新拷贝 workerList，
+// 新 worker 自然就用上了；halo 模式的行段在 StartSimulation 时就分好了，所以有新 worker 注册时，
+// 在回合之间把世界从 worker 上收回来，按新的 worker 列表重新分段。移出 worker 时也一样（见 retire.go）
+
+// regrowHalo：topo 建好之后有 worker 注册过时重新分段，返回之后要用的拓扑。
+// 重新分段失败时接着用旧的，等下一个 worker 注册再试。调用方需要持有 turnMu
+func (s *simulation) regrowHalo(topo *haloTopology, turn int) *haloTopology {
+	joined := workersJoined.Load()
+	if topo.joined == joined {
+		return topo
+	}
+	topo.joined = joined
+
+	next, err := s.resplitHalo(topo, turn)
+	if err != nil {
+		s.log.Warn("re-split bands for new workers failed, keeping bands", "turn", turn, "err", err)
+		return next
+	}
+	s.log.Info("bands re-split for new workers", "turn", turn, "workers", len(next.workers))
+	return next
+}
+
+// resplitHalo：把世界从 topo 的 worker 上收回来，按当前的 workerList 重新分段并换上新的拓扑。
+// 出错时返回还在用的拓扑（一般是 topo）和错误。调用方需要持有 turnMu
+func (s *simulation) resplitHalo(topo *haloTopology, turn int) (*haloTopology, error) {
+	world, err := topo.gather()
+	if err != nil {
+		return topo, err
+	}
+	s.mu.Lock()
+	params := WorldParams{
+		ImageWidth:  topo.width,
+		ImageHeight: topo.height,
+		World:       world,
+		Turn:        turn,
+		Boundary:    s.boundary,
+		Rule:        s.rule.String(),
+		JobID:       s.id,
+	}
+	s.mu.Unlock()
+
+	next, err := setupHalo(params, s.log.With("turn", turn))
+	if err != nil {
+		// setupHalo 失败时可能已经有 worker 换成了新行段，也放开了它们，
+		// 所以重新占回来，把收回来的世界按旧的行段再发一遍
+		topo.claim(slices.Clone(topo.workers))
+		if err := topo.setup(params); err != nil {
+			// 恢复不了的话下一回合的 step 会失败，交给 distributor 决定
+			s.log.Error("restore old bands failed", "turn", turn, "err", err)
+		}
+		topo.base = turn
+		return topo, err
+	}
+	next.base = turn
+	s.mu.Lock()
+	s.halo = next
+	s.mu.Unlock()
+	topo.release(next.workers...)
+	return next, nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// 关闭整个分布式系统：distributor 按 'k' 时调用 Shutdown，
+// broker 先让所有 worker 退出，再关掉自己的监听。
+// 收到 SIGTERM / SIGINT 时只关 broker 自己（见 stopOnSignal），worker 留着，broker 重启后它们重新注册
+
+// shutdownGrace：收到 Shutdown 之后等一下再关监听，让 RPC 的回复先发出去
+const shutdownGrace = 100 * time.Millisecond
+
+var (
+	shutdown     = make(chan struct{}) // 关闭时 close，main 的 accept 循环据此退出
+	shutdownOnce sync.Once
+	stopping     = make(chan struct{}) // 收到信号、不再接受新连接时 close
+
+	// stopTimeout：收到信号之后最多等正在算的回合多久，-stop-timeout 设置
+	stopTimeout = 30 * time.Second
+)
+
+// Shutdown：停掉所有 job 的后台推进，通知所有 worker 退出，然后关闭 broker
+func (b *Broker) Shutdown(_ struct{}, reply *bool) error {
+	for _, s := range b.simulations() {
+		s.stopBackground()
+	}
+
+	workerMutex.Lock()
+	workers := make([]WorkerClient, len(workerList))
+	copy(workers, workerList)
+	workerMutex.Unlock()
+
+	logger.Info("shutting down distributed system", "workers", len(workers))
+
+	// 并发通知，一个卡住的 worker 不会拖住整个关闭流程
+	var wg sync.WaitGroup
+	for _, w := range workers {
+		wg.Add(1)
+		go func(w WorkerClient) {
+			defer wg.Done()
+			var ok bool
+			if err := w.client.Call("Worker.Shutdown", struct{}{}, &ok); err != nil {
+				logger.Warn("shut down worker failed", "worker", w.addr, "err", err)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	shutdownOnce.Do(func() { close(shutdown) })
+	*reply = true
+	return nil
+}
+
+// stopOnSignal：SIGTERM / SIGINT 时调用 stop，Ctrl-C 不会把一回合断在中间；
+// 停止过程中再收到一次就直接退出
+func (b *Broker) stopOnSignal(stopAccepting func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+	go func() {
+		sig := <-signals
+		logger.Info("signal received, stopping", "signal", sig)
+		go b.stop(stopAccepting, stopTimeout)
+		sig = <-signals
+		logger.Warn("second signal received, exiting now", "signal", sig)
+		os.Exit(1)
+	}()
+}
+
+// stop：不再接受新连接，停掉所有 job 的后台推进，等正在算的回合算完（最多 timeout），
+// 配置了检查点的话把默认 job 的世界再写一次，然后和 Shutdown 一样关掉监听
+func (b *Broker) stop(stopAccepting func(), timeout time.Duration) {
+	stopAccepting()
+
+	// 拿到 turnMu 就说明这个 job 没有回合在算了；不再放开，之后的 ProcessTurn 等到进程退出
+	sims := b.simulations()
+	idle := make([]chan struct{}, len(sims))
+	for i, s := range sims {
+		idle[i] = make(chan struct{})
+		go func(s *simulation, idle chan struct{}) {
+			s.stopBackground()
+			s.turnMu.Lock()
+			close(idle)
+		}(s, idle[i])
+	}
+	expired := time.NewTimer(timeout)
+	defer expired.Stop()
+	late := false
+	path := currentConfig().Checkpoint.Path
+	for i, s := range sims {
+		if !late {
+			select {
+			case <-idle[i]:
+			case <-expired.C:
+				late = true
+			}
+		}
+		if late {
+			s.log.Warn("turn still running, stopping without it", "timeout", timeout)
+			continue
+		}
+		s.mu.Lock()
+		started := s.currentWorld != nil
+		s.mu.Unlock()
+		if s.id != "" || path == "" || !started {
+			continue
+		}
+		if turn, err := s.writeCheckpoint(path); err != nil {
+			s.log.Error("write checkpoint failed", "path", path, "turn", turn, "err", err)
+		} else {
+			s.log.Info("checkpoint written", "path", path, "turn", turn)
+		}
+	}
+
+	logger.Info("broker stopped")
+	shutdownOnce.Do(func() { close(shutdown) })
+}
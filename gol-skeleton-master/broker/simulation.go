@@ -0,0 +1,282 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// 有状态接口：世界只在 StartSimulation 时上传一次，之后保存在 broker，
+// 每回合只把翻转的细胞（delta）发回 distributor，需要完整世界时再 FetchWorld
+
+// NextTurnReply 必须和 distributor 那边保持一致
+type NextTurnReply struct {
+	Turn    int         // 本回合结束后已完成的回合数
+	Flipped []util.Cell // 本回合翻转的细胞
+	Timing  StepTiming  // 本回合在 broker 上的耗时
+}
+
+// StepTiming 必须和 distributor 那边保持一致：一回合在 broker 上花了多久，distributor 据此发 TurnTiming
+type StepTiming struct {
+	Elapsed        time.Duration // 从把任务分给 worker 到收齐结果；broker 自己算的回合就是算的时间
+	Slowest        string        // 最慢的 worker，broker 自己算的回合（稀疏世界、hashlife）为空
+	SlowestElapsed time.Duration // 最慢的 worker 的那次调用用了多久
+}
+
+// observe：记下 addr 上的一次调用用了 elapsed，比目前最慢的还慢就换成它
+func (t *StepTiming) observe(addr string, elapsed time.Duration) {
+	if addr != "" && elapsed > t.SlowestElapsed {
+		t.Slowest, t.SlowestElapsed = addr, elapsed
+	}
+}
+
+// StartSimulation：上传初始世界，回合数清零
+func (s *simulation) StartSimulation(params WorldParams, reply *bool) error {
+	if len(params.World) != params.ImageHeight {
+		return fmt.Errorf("world has %d rows, expected %d", len(params.World), params.ImageHeight)
+	}
+	boundary, err := util.ParseBoundary(string(params.Boundary))
+	if err != nil {
+		return err
+	}
+	params.Boundary = boundary
+	rule, err := util.ParseRule(params.Rule)
+	if err != nil {
+		return err
+	}
+	params.Rule = rule.String()
+
+	// 新模拟替换掉之前 Detach 后还在后台跑的那个，并且从执行状态开始
+	s.stopBackground()
+	s.resume()
+
+	s.turnMu.Lock()
+	defer s.turnMu.Unlock()
+
+	// engine = hashlife 时 broker 自己算；否则 halo 模式下行段交给 worker 长期持有
+	life := newLife(params.World, boundary, rule)
+	var topo *haloTopology
+	if life == nil && currentConfig().Mode == modeHalo {
+		topo, err = setupHalo(params, s.log)
+		switch {
+		case errors.Is(err, errHaloBusy):
+			// 别的 job 占着所有 worker：这个 job 按 scatter 模式和它们共用 worker
+			s.log.Warn("no free workers for halo mode, falling back to scatter", "err", err)
+		case err != nil:
+			return err
+		default:
+			topo.base = params.Turn
+		}
+	}
+
+	s.mu.Lock()
+	s.halo.release()
+	s.currentWorld = params.World
+	s.boundary = boundary
+	s.rule = rule
+	s.halo = topo
+	s.life = life
+	s.turn = params.Turn
+	s.mu.Unlock()
+	s.subs.resetAll()
+
+	*reply = true
+	return nil
+}
+
+// ProcessTurnsArgs / ProcessTurnsReply 必须和 distributor 那边保持一致
+type ProcessTurnsArgs struct {
+	Turns int // 这次调用要推进多少回合
+	JobID string
+}
+
+type ProcessTurnsReply struct {
+	Turn    int           // 最后一个回合结束后已完成的回合数
+	Flipped [][]util.Cell // 每一回合翻转的细胞，Flipped[i] 对应第 Turn-len(Flipped)+i+1 回合
+	Timings []StepTiming  // Timings[i] 是 Flipped[i] 那一回合的耗时
+}
+
+// NextTurn：在 broker 保存的世界上推进一回合，只返回翻转的细胞
+// 暂停时不推进，reply.Turn 还是当前回合
+func (s *simulation) NextTurn(_ struct{}, reply *NextTurnReply) error {
+	s.turnMu.Lock()
+	defer s.turnMu.Unlock()
+
+	if s.pausedCh() != nil {
+		s.mu.Lock()
+		reply.Turn = s.turn
+		s.mu.Unlock()
+		return nil
+	}
+	flipped, turn, timing, err := s.step()
+	if err != nil {
+		return err
+	}
+	reply.Turn = turn
+	reply.Flipped = flipped
+	reply.Timing = timing
+	return nil
+}
+
+// ProcessTurns：一次 RPC 推进多个回合，减少 distributor 和 broker 之间的往返次数
+// 和 NextTurn 一样只返回翻转的细胞，distributor 在本地世界上应用；要完整世界用 GetWorld
+// 中途被 Pause 时提前返回，Flipped 可能比 args.Turns 短（甚至为空）
+func (s *simulation) ProcessTurns(args ProcessTurnsArgs, reply *ProcessTurnsReply) error {
+	if args.Turns <= 0 {
+		return fmt.Errorf("invalid turn count %d", args.Turns)
+	}
+
+	s.turnMu.Lock()
+	defer s.turnMu.Unlock()
+
+	s.mu.Lock()
+	reply.Turn = s.turn
+	s.mu.Unlock()
+
+	reply.Flipped = make([][]util.Cell, 0, args.Turns)
+	reply.Timings = make([]StepTiming, 0, args.Turns)
+	for i := 0; i < args.Turns; i++ {
+		// 暂停了就在回合边界停下，已经算完的回合照常返回
+		if s.pausedCh() != nil {
+			break
+		}
+		flipped, turn, timing, err := s.step()
+		if err != nil {
+			return err
+		}
+		reply.Turn = turn
+		reply.Flipped = append(reply.Flipped, flipped)
+		reply.Timings = append(reply.Timings, timing)
+	}
+	return nil
+}
+
+// step：推进一回合，返回翻转的细胞、新的回合数和这一回合的耗时，调用方需要持有 turnMu
+func (s *simulation) step() ([]util.Cell, int, StepTiming, error) {
+	s.mu.Lock()
+	world := s.currentWorld
+	topo := s.halo
+	life := s.life
+	turn := s.turn
+	boundary := s.boundary
+	rule := s.rule
+	s.mu.Unlock()
+	if world == nil {
+		return nil, 0, StepTiming{}, fmt.Errorf("no simulation started, call Broker.StartSimulation first")
+	}
+	start := time.Now()
+
+	// halo 模式：世界在 worker 上，broker 只收翻转的细胞；有新 worker 注册时先重新分段
+	if topo != nil {
+		topo = s.regrowHalo(topo, turn)
+		flipped, alive, timing, err := topo.step(turn)
+		if err != nil {
+			s.log.Error("halo turn failed", "turn", turn+1, "err", err)
+			return nil, 0, StepTiming{}, err
+		}
+		timing.Elapsed = time.Since(start)
+		s.mu.Lock()
+		copy(topo.alive, alive)
+		s.turn++
+		turn = s.turn
+		s.mu.Unlock()
+		s.subs.publish(turn, flipped)
+		return flipped, turn, timing, nil
+	}
+
+	// hashlife：broker 本地推进，持有 mu 免得 GetAliveCellsCount 读到一半
+	if life != nil {
+		s.mu.Lock()
+		flipped := life.Step()
+		s.turn++
+		turn = s.turn
+		s.mu.Unlock()
+		s.subs.publish(turn, flipped)
+		return flipped, turn, StepTiming{Elapsed: time.Since(start)}, nil
+	}
+
+	// 稀疏的世界 broker 自己算，否则切块发给 worker
+	var timing StepTiming
+	newWorld, flipped, sparse := evolveSparse(world, boundary, rule)
+	if !sparse {
+		params := WorldParams{
+			ImageWidth:  len(world[0]),
+			ImageHeight: len(world),
+			World:       world,
+			Boundary:    boundary,
+			Rule:        rule.String(),
+			JobID:       s.id,
+		}
+		var err error
+		if newWorld, flipped, timing, err = evolve(params, s.log.With("turn", turn+1)); err != nil {
+			s.log.Error("turn failed", "turn", turn+1, "err", err)
+			return nil, 0, StepTiming{}, err
+		}
+	}
+	timing.Elapsed = time.Since(start)
+
+	s.mu.Lock()
+	s.currentWorld = newWorld
+	s.turn++
+	turn = s.turn
+	s.mu.Unlock()
+	s.subs.publish(turn, flipped)
+	return flipped, turn, timing, nil
+}
+
+// FetchWorld：返回当前的完整世界（halo 模式下从 worker 收集）
+func (s *simulation) FetchWorld(_ struct{}, reply *util.World) error {
+	s.turnMu.Lock()
+	defer s.turnMu.Unlock()
+
+	world, err := s.world()
+	if err != nil {
+		return err
+	}
+	*reply = world
+	return nil
+}
+
+// WorldReply 必须和 distributor 那边保持一致
+type WorldReply struct {
+	Turn  int
+	World util.World
+}
+
+// GetWorld：返回 broker 上权威的世界和对应的回合数，distributor 按 's' 保存时用它，保证写出的 PGM 和真实状态一致
+func (s *simulation) GetWorld(_ struct{}, reply *WorldReply) error {
+	s.turnMu.Lock()
+	defer s.turnMu.Unlock()
+
+	world, err := s.world()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	reply.Turn = s.turn
+	s.mu.Unlock()
+	reply.World = world
+	return nil
+}
+
+// world：当前的完整世界，调用方需要持有 turnMu（保证 halo 模式下收集时 worker 不在推进）
+func (s *simulation) world() ([][]uint8, error) {
+	s.mu.Lock()
+	world := s.currentWorld
+	topo := s.halo
+	life := s.life
+	s.mu.Unlock()
+
+	if world == nil {
+		return nil, fmt.Errorf("no simulation started")
+	}
+	if topo != nil {
+		return topo.gather()
+	}
+	if life != nil {
+		return life.World(), nil
+	}
+	return world, nil
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/rpc"
+	"slices"
+	"sync"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/transport"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// 推测执行：一回合要等最慢的那一段算完，一台过载的 worker 就能拖慢每一回合。
+// 某一段算的时间超过已经算完的段的中位数 SpeculateAfter 倍时，在一个这一回合没活干的 worker 上
+// 再算一份，谁先算完用谁的，另一份的结果丢掉。每段最多一个备份
+
+const (
+	// speculatePoll：多久看一次有没有掉队的段
+	speculatePoll = 5 * time.Millisecond
+	// speculateMin：用时不到这么久的段不算掉队，小世界每段只要几毫秒，抖一下不值得再算一份
+	speculateMin = 20 * time.Millisecond
+)
+
+// speculation：一回合里各段的进度，evolve 每回合新建一个
+type speculation struct {
+	mu    sync.Mutex
+	parts int             // 这一回合一共几段
+	done  []time.Duration // 已经算完的段各用了多久
+	busy  map[string]int  // 每个 worker 手上有几段在算
+}
+
+func newSpeculation(parts int) *speculation {
+	return &speculation{parts: parts, busy: make(map[string]int)}
+}
+
+func (s *speculation) start(addr string) {
+	s.mu.Lock()
+	s.busy[addr]++
+	s.mu.Unlock()
+}
+
+// finish：worker 算完（或者算失败）一段，ok 时记下用时
+func (s *speculation) finish(addr string, elapsed time.Duration, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.busy[addr]--
+	if ok {
+		s.done = append(s.done, elapsed)
+	}
+}
+
+// straggling：已经算了 elapsed 的段是不是掉队了。至少一半的段算完之后才有可比的中位数
+func (s *speculation) straggling(elapsed time.Duration, factor float64) bool {
+	if factor <= 0 || elapsed < speculateMin {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.done) == 0 || 2*len(s.done) < s.parts {
+		return false
+	}
+	sorted := slices.Clone(s.done)
+	slices.Sort(sorted)
+	median := sorted[len(sorted)/2]
+	return float64(elapsed) > factor*float64(median)
+}
+
+// idle：这一回合手上没活、没失败过、能跑 rule 的 worker，找到就算它开始了一段
+func (s *speculation) idle(workers []WorkerClient, failed *failedSet, rule util.Rule, ruleOK bool) (WorkerClient, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, w := range workers {
+		if s.busy[w.addr] > 0 || failed.has(w.addr) || (ruleOK && !w.caps.runs(rule)) {
+			continue
+		}
+		s.busy[w.addr]++
+		return w, true
+	}
+	return WorkerClient{}, false
+}
+
+// call：把 j 交给 w 算；掉队时在空闲的 worker 上算一份备份，返回先算完的结果和算它的 worker。
+// 两份都失败时返回 w 的错误，由 runTask 按 w 的失败处理。配置了 TaskTimeout 时最多等这么久，
+// 超时就丢下这次调用，把 w 记为可疑（见 timeout.go），返回 transport.ErrTimeout
+func (s *speculation) call(j job, w WorkerClient, workers []WorkerClient, failed *failedSet, log *slog.Logger) (PartReply, string, error) {
+	cfg := currentConfig()
+	start := time.Now()
+	s.start(w.addr)
+	var reply PartReply
+	primary := w.client.Go(j.method, w.encode(j.args), &reply, make(chan *rpc.Call, 1))
+
+	var poll <-chan time.Time // 不推测时为 nil
+	if cfg.SpeculateAfter > 0 {
+		ticker := time.NewTicker(speculatePoll)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+	var expired <-chan time.Time // 不限时为 nil
+	if timeout := time.Duration(cfg.Retry.TaskTimeout); timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	var backup WorkerClient
+	var backupReply PartReply
+	var backupDone chan *rpc.Call // 没有备份时为 nil，永远收不到
+	var primaryErr error
+	for {
+		select {
+		case <-primary.Done:
+			s.finish(w.addr, time.Since(start), primary.Error == nil)
+			if primary.Error == nil {
+				if backupDone != nil {
+					go s.abandon(backup, backupDone)
+				}
+				return reply, w.addr, nil
+			}
+			if backupDone == nil {
+				return PartReply{}, "", primary.Error
+			}
+			// 备份还在算，等它
+			primaryErr, primary.Done = primary.Error, nil
+
+		case c := <-backupDone:
+			s.finish(backup.addr, time.Since(start), c.Error == nil)
+			if c.Error == nil {
+				log.Info("backup task finished first", "worker", backup.addr, "straggler", w.addr, "elapsed", time.Since(start))
+				if primaryErr == nil {
+					go s.abandon(w, primary.Done)
+				}
+				return backupReply, backup.addr, nil
+			}
+			log.Warn("backup task failed", "worker", backup.addr, "err", c.Error)
+			if primaryErr != nil {
+				return PartReply{}, "", primaryErr
+			}
+			backupDone = nil
+
+		case <-expired:
+			if backupDone != nil {
+				go s.abandon(backup, backupDone)
+			}
+			if primaryErr != nil {
+				return PartReply{}, "", primaryErr
+			}
+			suspect(w.addr, primary.Done, s)
+			return PartReply{}, "", fmt.Errorf("%s on %s after %v: %w", j.method, w.addr, time.Since(start).Round(time.Millisecond), transport.ErrTimeout)
+
+		case <-poll:
+			if backup.addr != "" || primaryErr != nil || !s.straggling(time.Since(start), cfg.SpeculateAfter) {
+				continue
+			}
+			rule, err := util.ParseRule(j.rule)
+			b, ok := s.idle(workers, failed, rule, err == nil)
+			if !ok {
+				continue
+			}
+			log.Info("task straggling, running a backup", "worker", w.addr, "backup", b.addr, "elapsed", time.Since(start))
+			backup = b
+			backupDone = b.client.Go(j.method, b.encode(j.args), &backupReply, make(chan *rpc.Call, 1)).Done
+		}
+	}
+}
+
+// abandon：等没用上的那一份算完，worker 才算空下来
+func (s *speculation) abandon(w WorkerClient, done <-chan *rpc.Call) {
+	<-done
+	s.finish(w.addr, 0, false)
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// 分块传输：16k×16k 的世界整个放进一次 gob 回复会让两边的内存暴涨，
+// net/rpc 客户端（transport 的 chunkedClient）对大世界改用下面这几个 RPC，按行段分块上传 / 下载，每块带序号。
+// gRPC 用 UploadWorld / StreamWorld / ProcessTurnStream 这几个流式 RPC，不走这里
+
+// transferTimeout：这么久没有动静的传输视为客户端已经放弃，释放它占的内存
+const transferTimeout = time.Minute
+
+// 以下类型必须和 transport 那边保持一致
+type WorldChunk struct {
+	ID          int // 传输编号，上传第一块时为 0，由 broker 分配
+	Seq         int
+	StartY      int
+	Rows        util.World
+	ImageWidth  int // 上传时只在第一块上设置
+	ImageHeight int
+	Turn        int
+	Boundary    util.Boundary
+	Rule        string
+}
+
+type TransferArgs struct {
+	ID       int
+	MaxCells int    // OpenWorld：世界不超过这么多细胞时直接放在回复里，不用再分块下载
+	JobID    string // StartUploaded / ProcessUploaded / OpenWorld：哪个 job 的世界
+}
+
+type TransferReply struct {
+	ID          int // 为 0 表示 World 已经在回复里
+	ImageWidth  int
+	ImageHeight int
+	World       util.World
+}
+
+type ChunkArgs struct {
+	ID     int
+	Seq    int
+	StartY int
+	Rows   int
+}
+
+// transfer：一次进行中的上传或下载
+type transfer struct {
+	width, height int
+	turn          int           // 上传：StartSimulation 从第几回合开始
+	boundary      util.Boundary // 上传：世界的边界
+	rule          string        // 上传：演化规则
+	rows          [][]uint8     // 上传时是整个世界截到已经收到的行（后面的行还在底层数组里），逐块拷进来；下载时是完整的世界
+	seq           int           // 下一块的序号
+	touched       time.Time
+}
+
+// transfers：进行中的分块传输
+type transfers struct {
+	mu     sync.Mutex
+	nextID int
+	active map[int]*transfer
+}
+
+// open：登记一次新的传输，顺便清理超时的
+func (t *transfers) open(x *transfer) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.active == nil {
+		t.active = make(map[int]*transfer)
+	}
+	for id, old := range t.active {
+		if time.Since(old.touched) > transferTimeout {
+			delete(t.active, id)
+			logger.Info("transfer timed out", "transfer", id)
+		}
+	}
+	t.nextID++
+	x.touched = time.Now()
+	t.active[t.nextID] = x
+	return t.nextID
+}
+
+// get：按编号找传输，同时检查序号
+func (t *transfers) get(id, seq int) (*transfer, error) {
+	x, ok := t.active[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown transfer %d", id)
+	}
+	if seq != x.seq {
+		delete(t.active, id)
+		return nil, fmt.Errorf("transfer %d: got chunk %d, expected %d", id, seq, x.seq)
+	}
+	x.seq++
+	x.touched = time.Now()
+	return x, nil
+}
+
+// UploadChunk：上传一块行，Seq 0 开始一次新的上传，reply 返回传输编号
+func (b *Broker) UploadChunk(c WorldChunk, reply *int) error {
+	if c.Seq == 0 {
+		if c.ImageWidth <= 0 || c.ImageHeight <= 0 {
+			return fmt.Errorf("invalid upload: %dx%d", c.ImageWidth, c.ImageHeight)
+		}
+		c.ID = b.xfers.open(&transfer{width: c.ImageWidth, height: c.ImageHeight, turn: c.Turn, boundary: c.Boundary, rule: c.Rule, rows: util.NewWorld(c.ImageWidth, c.ImageHeight)[:0]})
+	}
+
+	b.xfers.mu.Lock()
+	defer b.xfers.mu.Unlock()
+	x, err := b.xfers.get(c.ID, c.Seq)
+	if err != nil {
+		return err
+	}
+	if c.StartY != len(x.rows) || len(x.rows)+len(c.Rows) > x.height {
+		delete(b.xfers.active, c.ID)
+		return fmt.Errorf("transfer %d: chunk %d at row %d does not fit", c.ID, c.Seq, c.StartY)
+	}
+	for _, row := range c.Rows {
+		if len(row) != x.width {
+			delete(b.xfers.active, c.ID)
+			return fmt.Errorf("transfer %d: row is %d cells wide, expected %d", c.ID, len(row), x.width)
+		}
+	}
+	// 拷进一开始就分配好的世界，而不是接上收到的行，这样整个世界还是排在一块缓冲区里
+	x.rows = x.rows[:len(x.rows)+len(c.Rows)]
+	for i, row := range c.Rows {
+		copy(x.rows[c.StartY+i], row)
+	}
+	*reply = c.ID
+	return nil
+}
+
+// uploaded：取出一次传完的上传
+func (b *Broker) uploaded(id int) (WorldParams, error) {
+	b.xfers.mu.Lock()
+	defer b.xfers.mu.Unlock()
+	x, ok := b.xfers.active[id]
+	if !ok {
+		return WorldParams{}, fmt.Errorf("unknown transfer %d", id)
+	}
+	delete(b.xfers.active, id)
+	if len(x.rows) != x.height {
+		return WorldParams{}, fmt.Errorf("transfer %d: only %d of %d rows uploaded", id, len(x.rows), x.height)
+	}
+	logger.Debug("chunked upload complete", "transfer", id, "chunks", x.seq, "width", x.width, "height", x.height)
+	return WorldParams{ImageWidth: x.width, ImageHeight: x.height, World: x.rows, Turn: x.turn, Boundary: x.boundary, Rule: x.rule}, nil
+}
+
+// StartUploaded：用上传好的世界开始模拟，和 StartSimulation 一样
+func (b *Broker) StartUploaded(args TransferArgs, reply *bool) error {
+	params, err := b.uploaded(args.ID)
+	if err != nil {
+		return err
+	}
+	params.JobID = args.JobID
+	return b.StartSimulation(params, reply)
+}
+
+// ProcessUploaded：在上传好的世界上算一回合（和 ProcessTurn 一样），结果留给 FetchChunk 分块下载
+func (b *Broker) ProcessUploaded(args TransferArgs, reply *TransferReply) error {
+	params, err := b.uploaded(args.ID)
+	if err != nil {
+		return err
+	}
+	params.JobID = args.JobID
+	var newWorld util.World
+	if err := b.ProcessTurn(params, &newWorld); err != nil {
+		return err
+	}
+	b.download(newWorld, reply)
+	return nil
+}
+
+// OpenWorld：准备分块下载当前世界（和 FetchWorld 一样），够小的话直接放在回复里
+func (b *Broker) OpenWorld(args TransferArgs, reply *TransferReply) error {
+	var world util.World
+	if err := b.FetchWorld(JobArgs{JobID: args.JobID}, &world); err != nil {
+		return err
+	}
+	if len(world) == 0 || len(world)*len(world[0]) <= args.MaxCells {
+		reply.ImageHeight = len(world)
+		if len(world) > 0 {
+			reply.ImageWidth = len(world[0])
+		}
+		reply.World = world
+		return nil
+	}
+	b.download(world, reply)
+	return nil
+}
+
+// download：把世界登记成一次下载，回复里带上编号和大小
+func (b *Broker) download(world [][]uint8, reply *TransferReply) {
+	reply.ImageHeight = len(world)
+	if len(world) > 0 {
+		reply.ImageWidth = len(world[0])
+	}
+	reply.ID = b.xfers.open(&transfer{width: reply.ImageWidth, height: reply.ImageHeight, rows: world})
+}
+
+// FetchChunk：下载 [StartY, StartY+Rows) 这几行，最后一块取走之后释放
+func (b *Broker) FetchChunk(args ChunkArgs, reply *WorldChunk) error {
+	b.xfers.mu.Lock()
+	defer b.xfers.mu.Unlock()
+	x, err := b.xfers.get(args.ID, args.Seq)
+	if err != nil {
+		return err
+	}
+	if args.StartY < 0 || args.Rows <= 0 || args.StartY >= x.height {
+		delete(b.xfers.active, args.ID)
+		return fmt.Errorf("transfer %d: no rows at %d", args.ID, args.StartY)
+	}
+	end := min(args.StartY+args.Rows, x.height)
+	reply.ID, reply.Seq, reply.StartY = args.ID, args.Seq, args.StartY
+	reply.Rows = x.rows[args.StartY:end]
+	if end == x.height {
+		delete(b.xfers.active, args.ID)
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// 观察者：除了驱动模拟的控制器之外，其它 distributor / SDL 客户端可以用 -observe 只读地挂在 broker 上，
+// Subscribe 拿到当前世界，之后 Poll 长轮询每回合翻转的细胞（net/rpc 没有服务端推送）。
+// gRPC 的观察者用 Watch 流，由 transport 在服务端循环调 Poll 往流里推，broker 这边不用区分
+
+const (
+	pollWait          = time.Second      // Poll 最多等多久新回合
+	maxPending        = 1024             // 观察者积压这么多回合还没取走，就丢掉积压让它重新拉完整世界
+	subscriberTimeout = 30 * time.Second // 这么久没 Poll 的观察者视为已经断开
+)
+
+// 以下类型必须和 distributor 那边保持一致
+type TurnDelta struct {
+	Turn    int
+	Flipped []util.Cell
+}
+
+type SubscriptionArgs struct {
+	ID    int // 观察者编号，每个 job 各自从 1 数起
+	JobID string
+}
+
+type SubscribeReply struct {
+	ID    int
+	Turn  int
+	World util.World // 还没有模拟时为 nil，开始后第一次 Poll 会带上世界
+	Rule  string     // World 的规则，观察者据此应用翻转（见 util.Rule.Flip）
+}
+
+type PollReply struct {
+	Turn   int
+	World  util.World  // 非 nil 表示需要重新同步：直接用这个世界替换本地的，Deltas 为空
+	Rule   string      // 和 World 一起设置
+	Deltas []TurnDelta // 上次 Poll 之后每一回合翻转的细胞
+}
+
+// subscriber：一个观察者的积压队列
+type subscriber struct {
+	pending  []TurnDelta
+	resync   bool
+	notify   chan struct{} // 有新回合时非阻塞地写一次，唤醒等待中的 Poll
+	lastPoll time.Time
+}
+
+// subscriptions：观察者登记表，step 每推进一回合就把翻转的细胞分发给所有观察者
+type subscriptions struct {
+	mu     sync.Mutex
+	nextID int
+	subs   map[int]*subscriber
+}
+
+// publish：分发一回合的翻转细胞，调用方需要持有 turnMu
+func (s *subscriptions) publish(turn int, flipped []util.Cell) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, sub := range s.subs {
+		if time.Since(sub.lastPoll) > subscriberTimeout {
+			delete(s.subs, id)
+			logger.Info("observer timed out", "observer", id)
+			continue
+		}
+		if !sub.resync {
+			if len(sub.pending) >= maxPending {
+				sub.pending, sub.resync = nil, true
+			} else {
+				sub.pending = append(sub.pending, TurnDelta{Turn: turn, Flipped: flipped})
+			}
+		}
+		select {
+		case sub.notify <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// resetAll：世界被整体替换（新模拟 / 无状态 ProcessTurn）之后，所有观察者都要重新同步
+func (s *subscriptions) resetAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sub := range s.subs {
+		sub.pending, sub.resync = nil, true
+		select {
+		case sub.notify <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (s *subscriptions) get(id int) (*subscriber, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sub, ok := s.subs[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown observer %d", id)
+	}
+	sub.lastPoll = time.Now()
+	return sub, nil
+}
+
+// Subscribe：登记一个观察者，返回当前回合和世界
+func (s *simulation) Subscribe(_ struct{}, reply *SubscribeReply) error {
+	// 拿 turnMu 保证快照和之后分发的回合是连续的
+	s.turnMu.Lock()
+	defer s.turnMu.Unlock()
+
+	sub := &subscriber{notify: make(chan struct{}, 1), lastPoll: time.Now()}
+	world, err := s.world()
+	if err != nil {
+		// 还没有模拟：先登记，开始后第一次 Poll 会重新同步
+		sub.resync = true
+		world = nil
+	}
+
+	s.subs.mu.Lock()
+	if s.subs.subs == nil {
+		s.subs.subs = make(map[int]*subscriber)
+	}
+	s.subs.nextID++
+	reply.ID = s.subs.nextID
+	s.subs.subs[reply.ID] = sub
+	s.subs.mu.Unlock()
+
+	s.mu.Lock()
+	reply.Turn = s.turn
+	if world != nil {
+		reply.Rule = s.rule.String()
+	}
+	s.mu.Unlock()
+	reply.World = world
+
+	s.log.Info("observer subscribed", "observer", reply.ID, "turn", reply.Turn)
+	return nil
+}
+
+// Poll：取走积压的回合，没有新回合时最多等 pollWait
+func (s *simulation) Poll(args SubscriptionArgs, reply *PollReply) error {
+	sub, err := s.subs.get(args.ID)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-sub.notify:
+	case <-time.After(pollWait):
+	}
+
+	s.subs.mu.Lock()
+	resync := sub.resync
+	if !resync {
+		reply.Deltas = sub.pending
+		sub.pending = nil
+	}
+	s.subs.mu.Unlock()
+
+	if !resync {
+		if n := len(reply.Deltas); n > 0 {
+			reply.Turn = reply.Deltas[n-1].Turn
+		} else {
+			s.mu.Lock()
+			reply.Turn = s.turn
+			s.mu.Unlock()
+		}
+		return nil
+	}
+
+	// 重新同步：拿 turnMu 保证世界和之后分发的回合对得上
+	s.turnMu.Lock()
+	defer s.turnMu.Unlock()
+	world, err := s.world()
+	if err != nil {
+		// 还是没有模拟，下次再试
+		return nil
+	}
+	s.subs.mu.Lock()
+	sub.pending, sub.resync = nil, false
+	s.subs.mu.Unlock()
+
+	s.mu.Lock()
+	reply.Turn = s.turn
+	reply.Rule = s.rule.String()
+	s.mu.Unlock()
+	reply.World = world
+	return nil
+}
+
+// Unsubscribe：观察者退出
+func (s *simulation) Unsubscribe(args SubscriptionArgs, reply *bool) error {
+	s.subs.mu.Lock()
+	delete(s.subs.subs, args.ID)
+	s.subs.mu.Unlock()
+
+	s.log.Info("observer unsubscribed", "observer", args.ID)
+	*reply = true
+	return nil
+}
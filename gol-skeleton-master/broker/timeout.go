@@ -0,0 +1,64 @@
+package main
+
+import (
+	"net/rpc"
+	"sync"
+)
+
+// 任务超时：一个卡住的 worker 会让 evolve 的 wg.Wait() 一直等下去。每段任务最多等 TaskTimeout，
+// 超时就丢下这次调用（它晚点返回的结果不要了），这一段交给别的 worker，超时的 worker 记为可疑：
+// 之后的回合先不给它分任务，直到丢下的调用都返回（说明它只是慢，没卡死），或者心跳把它踢掉
+
+var (
+	suspects   = make(map[string]int) // 可疑的 worker 手上还有几个丢下的调用没返回
+	suspectsMu sync.Mutex
+)
+
+// suspect：w 的调用 done 超时被丢下了，返回之前 w 都是可疑的
+func suspect(addr string, done <-chan *rpc.Call, spec *speculation) {
+	suspectsMu.Lock()
+	suspects[addr]++
+	suspectsMu.Unlock()
+	logger.Warn("worker task timed out, marking worker suspect", "worker", addr)
+
+	go func() {
+		spec.abandon(WorkerClient{addr: addr}, done)
+		suspectsMu.Lock()
+		defer suspectsMu.Unlock()
+		if _, ok := suspects[addr]; !ok {
+			return // 已经被移除了
+		}
+		if suspects[addr]--; suspects[addr] <= 0 {
+			delete(suspects, addr)
+			logger.Info("timed out task returned, worker no longer suspect", "worker", addr)
+		}
+	}()
+}
+
+// isSuspect：worker 有没有超时还没返回的调用
+func isSuspect(addr string) bool {
+	suspectsMu.Lock()
+	defer suspectsMu.Unlock()
+	return suspects[addr] > 0
+}
+
+// forgetSuspect：worker 被移除时清掉它的记录
+func forgetSuspect(addr string) {
+	suspectsMu.Lock()
+	delete(suspects, addr)
+	suspectsMu.Unlock()
+}
+
+// trusted：去掉可疑的 worker；全都可疑时原样返回，总比一个都没有好
+func trusted(workers []WorkerClient) []WorkerClient {
+	var ok []WorkerClient
+	for _, w := range workers {
+		if !isSuspect(w.addr) {
+			ok = append(ok, w)
+		}
+	}
+	if len(ok) == 0 {
+		return workers
+	}
+	return ok
+}
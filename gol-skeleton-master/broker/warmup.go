@@ -0,0 +1,110 @@
+package main
+
+import (
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// 预热：集群刚启动、worker 还在陆续注册时，回合不直接报 "no workers available" 让 distributor 退出，
+// 而是最多等 WaitWorkers，等到注册的 worker 够 min_workers 个（至少一个）。
+// 等的时候每隔 warmupReport 记一条日志，GetStatus 的 Waiting 告诉控制器还在预热
+
+// warmupReport：等 worker 的时候多久报告一次
+const warmupReport = 2 * time.Second
+
+var (
+	joined   = make(chan struct{})        // 有 worker 注册时 close，再换一个新的
+	waiting  = make(map[string]time.Time) // 正在等 worker 的 job，从什么时候开始等的
+	warmupMu sync.Mutex
+
+	// workersJoined：一共注册过几次 worker，halo 模式据此发现新 worker（见 scale.go）
+	workersJoined atomic.Int64
+)
+
+// workerJoined：registerWorker 成功之后调用，叫醒等 worker 的回合，halo 模式下一回合重新分段
+func workerJoined() {
+	workersJoined.Add(1)
+	warmupMu.Lock()
+	close(joined)
+	joined = make(chan struct{})
+	warmupMu.Unlock()
+}
+
+// waitingFor：job 的回合已经等了 worker 多久，0 表示没在等
+func waitingFor(job string) time.Duration {
+	warmupMu.Lock()
+	defer warmupMu.Unlock()
+	if since, ok := waiting[job]; ok {
+		return max(time.Since(since), time.Nanosecond)
+	}
+	return 0
+}
+
+// waitForWorkers：返回当前 workerList 的拷贝，worker 不够 min_workers 个时先等（配置了 WaitWorkers 的话），
+// 等不到就返回 CodeNoWorkers 的错误
+func waitForWorkers(job string, log *slog.Logger) ([]WorkerClient, error) {
+	cfg := currentConfig()
+	need := max(cfg.MinWorkers, 1)
+	var expired, report <-chan time.Time
+	start := time.Now()
+	for {
+		// 先拿 joined 再看 workerList，中间注册的 worker 也叫得醒
+		warmupMu.Lock()
+		wake := joined
+		warmupMu.Unlock()
+
+		workerMutex.Lock()
+		workers := make([]WorkerClient, len(workerList))
+		copy(workers, workerList)
+		workerMutex.Unlock()
+
+		if len(workers) >= need {
+			if expired != nil {
+				log.Info("enough workers registered, carrying on", "workers", len(workers), "waited", time.Since(start).Round(time.Millisecond))
+			}
+			return workers, nil
+		}
+		if expired == nil {
+			if cfg.WaitWorkers <= 0 {
+				return nil, notEnoughWorkers(len(workers), need)
+			}
+			timer := time.NewTimer(time.Duration(cfg.WaitWorkers))
+			defer timer.Stop()
+			ticker := time.NewTicker(warmupReport)
+			defer ticker.Stop()
+			expired, report = timer.C, ticker.C
+
+			warmupMu.Lock()
+			waiting[job] = start
+			warmupMu.Unlock()
+			defer func() {
+				warmupMu.Lock()
+				delete(waiting, job)
+				warmupMu.Unlock()
+			}()
+			log.Info("waiting for workers", "workers", len(workers), "need", need, "timeout", time.Duration(cfg.WaitWorkers))
+		}
+
+		select {
+		case <-wake:
+		case <-report:
+			log.Info("still waiting for workers", "workers", len(workers), "need", need, "waited", time.Since(start).Round(time.Second))
+		case <-expired:
+			return nil, util.Errorf(util.CodeNoWorkers, "only %d workers registered after waiting %v, need at least %d", len(workers), time.Duration(cfg.WaitWorkers), need)
+		case <-stopping:
+			return nil, notEnoughWorkers(len(workers), need)
+		}
+	}
+}
+
+// notEnoughWorkers：worker 不够时的错误
+func notEnoughWorkers(registered, need int) error {
+	if registered == 0 {
+		return util.Errorf(util.CodeNoWorkers, "no workers available")
+	}
+	return util.Errorf(util.CodeNoWorkers, "only %d workers registered, need at least %d", registered, need)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"time"
+)
+
+// 集群成员：ListWorkers 列出所有已注册的 worker、心跳情况和分到的行，给命令行工具和 HTTP 的 /workers 用
+
+// WorkerInfo / ListWorkersReply 必须和调用方那边保持一致
+type WorkerInfo struct {
+	Address    string        // host:port，gRPC worker 带 grpc:// 前缀，Unix 域套接字是 unix:///路径，反向连接是 reverse://id
+	Healthy    bool          // 最近一次心跳成功（还没 ping 过也算）
+	Missed     int           // 连续没响应的心跳次数
+	Suspect    bool          // 有超时的任务还没返回，暂时不给它分任务（见 timeout.go）
+	Latency    time.Duration // 最近一次心跳的往返时间
+	Score      float64       // 注册时上报的分数（细胞/秒），0 表示未知
+	Throughput float64       // 实际测得的速度（细胞/秒），scatter 模式算过回合之后才有
+	Rows       int           // 分给它的行：halo 模式是它持有的行段，scatter 模式是它最近一回合算的行，所有 job 加起来
+	Version    int           // 协议版本，0 表示没有协商过
+	Cores      int           // CPU 核数，0 表示未知
+	Codecs     []string      // 给它发任务时可用的世界编码
+	Rules      []string      // 能跑的规则
+}
+
+type ListWorkersReply struct {
+	Workers []WorkerInfo
+}
+
+// ListWorkers：所有已注册的 worker，按注册顺序
+func (b *Broker) ListWorkers(_ struct{}, reply *ListWorkersReply) error {
+	reply.Workers = b.workerInfos()
+	return nil
+}
+
+// workerInfos：当前 workerList 里每个 worker 的情况
+func (b *Broker) workerInfos() []WorkerInfo {
+	workerMutex.Lock()
+	workers := make([]WorkerClient, len(workerList))
+	copy(workers, workerList)
+	workerMutex.Unlock()
+
+	// halo 模式的行段按 job 记在拓扑里，scatter 模式的记在 sched 里，hashlife 不用 worker
+	rows := make(map[string]int)
+	for _, s := range b.simulations() {
+		s.mu.Lock()
+		if topo := s.halo; topo != nil {
+			for i, w := range topo.workers {
+				rows[w.addr] += topo.bands[i][1] - topo.bands[i][0]
+			}
+		} else if s.life == nil {
+			for _, w := range workers {
+				rows[w.addr] += sched.assigned(s.id, w.addr)
+			}
+		}
+		s.mu.Unlock()
+	}
+
+	infos := make([]WorkerInfo, len(workers))
+	for i, w := range workers {
+		h := workerHealthOf(w.addr)
+		infos[i] = WorkerInfo{
+			Address:    w.addr,
+			Healthy:    h.Missed == 0,
+			Missed:     h.Missed,
+			Suspect:    isSuspect(w.addr),
+			Latency:    h.Latency,
+			Score:      w.score,
+			Throughput: sched.rate(w.addr),
+			Rows:       rows[w.addr],
+			Version:    w.caps.version,
+			Cores:      w.caps.cores,
+		}
+		for _, c := range w.caps.codecs {
+			infos[i].Codecs = append(infos[i].Codecs, string(c))
+		}
+		for _, r := range w.caps.rules {
+			infos[i].Rules = append(infos[i].Rules, string(r))
+		}
+	}
+	return infos
+}
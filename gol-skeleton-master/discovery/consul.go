@@ -0,0 +1,188 @@
+package discovery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/transport"
+)
+
+// Service discovery through Consul's KV store. Each worker writes a key under a shared
+// prefix, held by a Consul session with a TTL: if the worker dies (or its autoscaling group
+// scales it away) the session expires and Consul deletes the key. The broker watches the
+// prefix with blocking queries and keeps its worker list in step with it, so neither side
+// needs the other's address.
+//
+// Only the standard library is available to this module, so this speaks Consul's HTTP API
+// directly instead of going through a client library. etcd is not supported.
+
+// Scheme marks a discovery address, e.g. consul://127.0.0.1:8500/gol/workers.
+const Scheme = "consul://"
+
+// DefaultPrefix is used when the address names no KV prefix.
+const DefaultPrefix = "gol/workers"
+
+// Entry is what a worker publishes about itself.
+type Entry struct {
+	Address string  `json:"address"` // what the broker dials; grpc://host:port for gRPC workers
+	Score   float64 `json:"score"`   // throughput in cells/sec, 0 = unknown
+}
+
+// Consul is a Consul agent and the KV prefix workers register under.
+type Consul struct {
+	host   string
+	prefix string
+	client *http.Client
+}
+
+// Parse reads a consul://host:port/prefix address.
+func Parse(addr string) (*Consul, error) {
+	if !strings.HasPrefix(addr, Scheme) {
+		return nil, fmt.Errorf("discovery: unsupported address %q (want %shost:port/prefix)", addr, Scheme)
+	}
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, fmt.Errorf("discovery: %v", err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("discovery: no Consul host in %q", addr)
+	}
+	prefix := strings.Trim(u.Path, "/")
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+	// Blocking queries hold the request open for up to wait; leave room for that.
+	return &Consul{host: u.Host, prefix: prefix, client: &http.Client{Timeout: 2 * time.Minute}}, nil
+}
+
+// Host is the host:port of the Consul agent.
+func (c *Consul) Host() string {
+	return c.host
+}
+
+func (c *Consul) String() string {
+	return Scheme + c.host + "/" + c.prefix
+}
+
+// do sends one request to the Consul HTTP API and decodes the JSON answer into out (if
+// non-nil). It returns the X-Consul-Index header, which blocking queries need.
+func (c *Consul) do(method, path string, query url.Values, body any, out any) (uint64, error) {
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		r = bytes.NewReader(data)
+	}
+	u := url.URL{Scheme: "http", Host: c.host, Path: path, RawQuery: query.Encode()}
+	req, err := http.NewRequest(method, u.String(), r)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	index, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+	switch {
+	case resp.StatusCode == http.StatusNotFound && method == http.MethodGet:
+		// An empty prefix is a 404, but still carries an index to block on.
+		return index, nil
+	case resp.StatusCode != http.StatusOK:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return index, fmt.Errorf("consul %s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return index, fmt.Errorf("consul %s %s: %v", method, path, err)
+		}
+	}
+	return index, nil
+}
+
+// Lease is a worker's registration, alive for as long as it keeps renewing it.
+type Lease struct {
+	c       *Consul
+	session string
+}
+
+// Register publishes e under the prefix, held by a new session that expires after ttl
+// without a Renew. The key is named after the worker's host:port.
+func (c *Consul) Register(e Entry, ttl time.Duration) (*Lease, error) {
+	var session struct{ ID string }
+	_, err := c.do(http.MethodPut, "/v1/session/create", nil, map[string]string{
+		"Name":      "gol-worker " + e.Address,
+		"TTL":       ttl.String(),
+		"Behavior":  "delete", // the key goes away with the session
+		"LockDelay": "0s",     // a restarted worker may take its key straight back
+	}, &session)
+	if err != nil {
+		return nil, err
+	}
+	lease := &Lease{c: c, session: session.ID}
+
+	var acquired bool
+	key := "/v1/kv/" + c.prefix + "/" + strings.TrimPrefix(e.Address, transport.GRPCScheme)
+	if _, err := c.do(http.MethodPut, key, url.Values{"acquire": {session.ID}}, e, &acquired); err != nil {
+		lease.Release()
+		return nil, err
+	}
+	if !acquired {
+		lease.Release()
+		return nil, fmt.Errorf("discovery: %s is held by another session", key)
+	}
+	return lease, nil
+}
+
+// Renew resets the session's TTL. An error means the registration is gone and the
+// worker has to Register again.
+func (l *Lease) Renew() error {
+	_, err := l.c.do(http.MethodPut, "/v1/session/renew/"+l.session, nil, nil, nil)
+	return err
+}
+
+// Release deletes the registration straight away instead of waiting for the TTL.
+func (l *Lease) Release() {
+	_, _ = l.c.do(http.MethodPut, "/v1/session/destroy/"+l.session, nil, nil, nil)
+}
+
+// Workers lists the registered workers, sorted by address. With a non-zero index it is a
+// blocking query: Consul answers once the prefix changes past index, or after wait. Pass
+// the returned index to the next call to wait for the next change.
+func (c *Consul) Workers(index uint64, wait time.Duration) ([]Entry, uint64, error) {
+	query := url.Values{"recurse": {"true"}}
+	if index > 0 {
+		query.Set("index", strconv.FormatUint(index, 10))
+		query.Set("wait", wait.String())
+	}
+	var pairs []struct {
+		Key   string
+		Value []byte
+	}
+	next, err := c.do(http.MethodGet, "/v1/kv/"+c.prefix+"/", query, nil, &pairs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	entries := make([]Entry, 0, len(pairs))
+	for _, p := range pairs {
+		var e Entry
+		if err := json.Unmarshal(p.Value, &e); err != nil || e.Address == "" {
+			continue // not one of ours
+		}
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Address < entries[j].Address })
+	return entries, next, nil
+}
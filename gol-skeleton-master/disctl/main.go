@@ -0,0 +1,310 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/transport"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// disctl：集群的管理命令行，直接调 broker（和 worker）的 RPC，不用 SSH 上去，也不用在 SDL 窗口里按键。
+//
+//	disctl -broker 10.0.0.1:8080 workers
+//	disctl add 10.0.0.7:8031        # broker 回拨这个 worker 并加入集群
+//	disctl drain 10.0.0.7:8031      # worker 注销、算完手上的任务再退出
+//	disctl retire 10.0.0.7:8031     # broker 等当前回合算完把它移出集群，返回后就可以关机
+//	disctl -job nightly pause
+
+// command：一个子命令，args 是子命令后面的参数
+type command struct {
+	usage string
+	help  string
+	run   func(c *ctl, args []string) error
+}
+
+var commands = map[string]command{
+	"workers":  {"workers", "list workers with their health, latency and assigned rows", (*ctl).workers},
+	"add":      {"add ADDR", "have the broker dial ADDR (host:port, grpc://host:port or unix:///path) and add it as a worker", (*ctl).add},
+	"remove":   {"remove ADDR", "stop giving ADDR (reverse://id for workers started with -reverse) work; a worker started with -broker registers again unless drained", (*ctl).remove},
+	"drain":    {"drain ADDR", "ask the worker at ADDR to deregister, finish its tasks in flight and exit", (*ctl).drain},
+	"retire":   {"retire ADDR", "have the broker take ADDR out of rotation once the current turn is done, move its halo bands to other workers and drain it; returns when the machine can be stopped", (*ctl).retire},
+	"status":   {"status", "show the turn, rule and state of the job", (*ctl).status},
+	"alive":    {"alive", "print the turn and number of alive cells of the job", (*ctl).alive},
+	"pause":    {"pause", "pause the job", (*ctl).pause},
+	"resume":   {"resume", "resume the job", (*ctl).resume},
+	"snapshot": {"snapshot [FILE]", "save the job's current world to FILE (" + strings.Join(gol.Formats(), ", ") + ", by extension; default WxHxTURN.pgm)", (*ctl).snapshot},
+	"shutdown": {"shutdown", "shut down the broker and all of its workers", (*ctl).shutdown},
+}
+
+// ctl：连接参数，broker 连接用到时才建立
+type ctl struct {
+	brokerAddr string
+	opts       transport.Options
+	job        string
+	broker     transport.Client
+}
+
+func main() {
+	brokerAddr := flag.String("broker", os.Getenv("GOL_BROKER_ADDR"), "broker address, host:port or grpc://host:port (default $GOL_BROKER_ADDR)")
+	token := flag.String("token", os.Getenv("GOL_TOKEN"), "shared secret for the broker and workers (default $GOL_TOKEN)")
+	job := flag.String("job", "", "job to act on (empty = the broker's default job)")
+	timeout := flag.Duration("timeout", 30*time.Second, "give up on a call after this long, 0 = wait as long as it takes")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "disctl: unknown command %q\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+
+	c := &ctl{brokerAddr: *brokerAddr, opts: transport.Options{Token: *token, Timeout: *timeout}, job: *job}
+	err := cmd.run(c, flag.Args()[1:])
+	if c.broker != nil {
+		_ = c.broker.Close()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "disctl %s: %v\n", flag.Arg(0), err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "usage: disctl [flags] COMMAND [ARGS]\n\ncommands:\n")
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, name := range []string{"workers", "add", "remove", "drain", "retire", "status", "alive", "pause", "resume", "snapshot", "shutdown"} {
+		fmt.Fprintf(tw, "  %s\t%s\n", commands[name].usage, commands[name].help)
+	}
+	_ = tw.Flush()
+	fmt.Fprintf(out, "\nflags:\n")
+	flag.PrintDefaults()
+}
+
+// call：调用 broker 的 method
+func (c *ctl) call(method string, args, reply interface{}) error {
+	if c.broker == nil {
+		if c.brokerAddr == "" {
+			return fmt.Errorf("no broker given, use -broker or $GOL_BROKER_ADDR")
+		}
+		client, err := transport.DialOptions(c.brokerAddr, c.opts)
+		if err != nil {
+			return fmt.Errorf("connect to broker %s: %v", c.brokerAddr, err)
+		}
+		c.broker = client
+	}
+	return c.broker.Call("Broker."+method, args, reply)
+}
+
+// want：检查参数个数
+func want(args []string, n int) error {
+	if len(args) != n {
+		return fmt.Errorf("want %d arguments, got %d", n, len(args))
+	}
+	return nil
+}
+
+func (c *ctl) workers(args []string) error {
+	if err := want(args, 0); err != nil {
+		return err
+	}
+	var reply transport.ListWorkersReply
+	if err := c.call("ListWorkers", struct{}{}, &reply); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ADDRESS\tHEALTHY\tSUSPECT\tMISSED\tLATENCY\tROWS\tSCORE\tMEASURED\tVERSION\tCORES\tCODECS\tRULES")
+	for _, w := range reply.Workers {
+		fmt.Fprintf(tw, "%s\t%t\t%t\t%d\t%v\t%d\t%.0f\t%.0f\t%d\t%d\t%s\t%s\n",
+			w.Address, w.Healthy, w.Suspect, w.Missed, w.Latency.Round(time.Microsecond), w.Rows, w.Score, w.Throughput,
+			w.Version, w.Cores, strings.Join(w.Codecs, ","), strings.Join(w.Rules, ","))
+	}
+	return tw.Flush()
+}
+
+// registerArgs：ADDR 拆成 RegisterWorker / DeregisterWorker 的参数
+func registerArgs(addr string) (transport.RegisterArgs, error) {
+	var args transport.RegisterArgs
+	if transport.IsUnix(addr) {
+		args.Address, args.Transport = strings.TrimPrefix(addr, transport.UnixScheme), "unix"
+		return args, nil
+	}
+	if transport.IsReverse(addr) {
+		args.Address, args.Transport = strings.TrimPrefix(addr, transport.ReverseScheme), "reverse"
+		return args, nil
+	}
+	if transport.IsGRPC(addr) {
+		addr, args.Transport = strings.TrimPrefix(addr, transport.GRPCScheme), "grpc"
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return args, err
+	}
+	if args.Port, err = strconv.Atoi(port); err != nil {
+		return args, fmt.Errorf("invalid port %q", port)
+	}
+	args.Address = host
+	return args, nil
+}
+
+func (c *ctl) add(args []string) error {
+	if err := want(args, 1); err != nil {
+		return err
+	}
+	reg, err := registerArgs(args[0])
+	if err != nil {
+		return err
+	}
+	var ok bool
+	return c.call("RegisterWorker", reg, &ok)
+}
+
+func (c *ctl) remove(args []string) error {
+	if err := want(args, 1); err != nil {
+		return err
+	}
+	reg, err := registerArgs(args[0])
+	if err != nil {
+		return err
+	}
+	var ok bool
+	return c.call("DeregisterWorker", reg, &ok)
+}
+
+// drain：直接连 worker，broker 那边由 worker 自己注销
+func (c *ctl) drain(args []string) error {
+	if err := want(args, 1); err != nil {
+		return err
+	}
+	worker, err := transport.DialOptions(args[0], c.opts)
+	if err != nil {
+		return fmt.Errorf("connect to worker %s: %v", args[0], err)
+	}
+	defer worker.Close()
+	var ok bool
+	return worker.Call("Worker.Drain", struct{}{}, &ok)
+}
+
+// retire：和 drain 不同，经过 broker，worker 连不上（NAT、反向连接）也行，halo 模式的行段不会丢
+func (c *ctl) retire(args []string) error {
+	if err := want(args, 1); err != nil {
+		return err
+	}
+	reg, err := registerArgs(args[0])
+	if err != nil {
+		return err
+	}
+	var ok bool
+	if err := c.call("RetireWorker", reg, &ok); err != nil {
+		return err
+	}
+	fmt.Printf("%s retired\n", args[0])
+	return nil
+}
+
+func (c *ctl) status(args []string) error {
+	if err := want(args, 0); err != nil {
+		return err
+	}
+	var reply transport.StatusReply
+	if err := c.call("GetStatus", transport.JobArgs{JobID: c.job}, &reply); err != nil {
+		return err
+	}
+	state := "running"
+	switch {
+	case reply.Paused:
+		state = "paused"
+	case reply.Detached:
+		state = "running detached"
+	}
+	if reply.Waiting > 0 {
+		state += fmt.Sprintf(", waiting %v for workers (need %d)", reply.Waiting.Round(time.Second), reply.MinWorkers)
+	}
+	rule := reply.Rule
+	if rule == "" {
+		rule = "-"
+	}
+	fmt.Printf("turn %d, %s, rule %s, %d workers, %d observers\n", reply.Turn, state, rule, reply.Workers, reply.Observers)
+	return nil
+}
+
+// alive：WatchAlive 一起返回回合数和存活细胞数，两者对得上
+func (c *ctl) alive(args []string) error {
+	if err := want(args, 0); err != nil {
+		return err
+	}
+	var report transport.AliveReport
+	if err := c.call("WatchAlive", transport.AliveArgs{JobID: c.job}, &report); err != nil {
+		return err
+	}
+	fmt.Printf("turn %d: %d alive cells\n", report.Turn, report.Count)
+	return nil
+}
+
+func (c *ctl) pause(args []string) error {
+	if err := want(args, 0); err != nil {
+		return err
+	}
+	var ok bool
+	return c.call("Pause", transport.JobArgs{JobID: c.job}, &ok)
+}
+
+func (c *ctl) resume(args []string) error {
+	if err := want(args, 0); err != nil {
+		return err
+	}
+	var ok bool
+	return c.call("Resume", transport.JobArgs{JobID: c.job}, &ok)
+}
+
+func (c *ctl) snapshot(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("want at most 1 argument, got %d", len(args))
+	}
+	var status transport.StatusReply
+	if err := c.call("GetStatus", transport.JobArgs{JobID: c.job}, &status); err != nil {
+		return err
+	}
+	rule, err := util.ParseRule(status.Rule)
+	if err != nil {
+		return err
+	}
+	var reply transport.WorldReply
+	if err := c.call("GetWorld", transport.JobArgs{JobID: c.job}, &reply); err != nil {
+		return err
+	}
+	if len(reply.World) == 0 {
+		return fmt.Errorf("no simulation started")
+	}
+
+	path := fmt.Sprintf("%dx%dx%d.pgm", len(reply.World[0]), len(reply.World), reply.Turn)
+	if len(args) == 1 {
+		path = args[0]
+	}
+	if err := gol.WriteWorld(path, reply.World, rule); err != nil {
+		return err
+	}
+	fmt.Printf("turn %d saved to %s\n", reply.Turn, path)
+	return nil
+}
+
+func (c *ctl) shutdown(args []string) error {
+	if err := want(args, 0); err != nil {
+		return err
+	}
+	var ok bool
+	return c.call("Shutdown", struct{}{}, &ok)
+}
@@ -0,0 +1,66 @@
+package engine
+
+import "fmt"
+
+// Boundary is what a cell on the edge of the world sees beyond it.
+type Boundary string
+
+const (
+	// BoundaryTorus wraps the world around: the row above the top row is the bottom row,
+	// and the column left of the first column is the last one.
+	BoundaryTorus Boundary = "torus"
+	// BoundaryDead treats everything outside the world as dead.
+	BoundaryDead Boundary = "dead"
+	// BoundaryMirror reflects the world at its edges: the row above the top row is the
+	// top row itself, and likewise for the other edges.
+	BoundaryMirror Boundary = "mirror"
+)
+
+// Boundaries lists the boundaries ParseBoundary accepts.
+func Boundaries() []Boundary {
+	return []Boundary{BoundaryTorus, BoundaryDead, BoundaryMirror}
+}
+
+// ParseBoundary reads a boundary name. The empty string is BoundaryTorus.
+func ParseBoundary(s string) (Boundary, error) {
+	if s == "" {
+		return BoundaryTorus, nil
+	}
+	for _, b := range Boundaries() {
+		if Boundary(s) == b {
+			return b, nil
+		}
+	}
+	return "", fmt.Errorf("unknown boundary %q, expected one of %v", s, Boundaries())
+}
+
+// Torus reports whether b wraps around. The zero Boundary does.
+func (b Boundary) Torus() bool {
+	return b == "" || b == BoundaryTorus
+}
+
+// Neighbour maps coordinate i, at most one cell outside [0, n), to the coordinate
+// whose cell is seen there. ok is false when a dead cell is seen instead.
+func (b Boundary) Neighbour(i, n int) (j int, ok bool) {
+	if i >= 0 && i < n {
+		return i, true
+	}
+	switch b {
+	case BoundaryDead:
+		return 0, false
+	case BoundaryMirror:
+		return min(max(i, 0), n-1), true
+	default:
+		return (i + n) % n, true
+	}
+}
+
+// Cell returns the cell of world seen at (x, y), either of which may be one outside the world.
+func (b Boundary) Cell(world [][]uint8, x, y int) uint8 {
+	y, okY := b.Neighbour(y, len(world))
+	x, okX := b.Neighbour(x, len(world[0]))
+	if !okX || !okY {
+		return 0
+	}
+	return world[y][x]
+}
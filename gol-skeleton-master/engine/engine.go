@@ -0,0 +1,142 @@
+// Package engine evolves Game of Life worlds. It is the simulator without the rest of this
+// repository: no SDL window, no io goroutines, no broker or workers, and nothing but the
+// standard library, so other projects can import it on its own.
+//
+// A world is a slice of equally long rows of bytes, one per cell: 255 is alive, 0 dead, and
+// with a Generations rule the greys in between are dying cells (see Rule). Evolve returns the
+// next generation:
+//
+//	rule, _ := engine.ParseRule("B36/S23")
+//	for turn := 0; turn < 100; turn++ {
+//		world = engine.Evolve(world, engine.BoundaryTorus, rule, runtime.NumCPU())
+//	}
+//
+// The exported names of this package are a stable API; the rest of the repository may change.
+package engine
+
+import "sync"
+
+// Evolve returns the generation after world under rule, with boundary deciding what cells
+// on the edges see beyond them. Rows are split into up to threads bands evolved in parallel;
+// below 2 it evolves them one after another. world is left as it was.
+func Evolve(world [][]uint8, boundary Boundary, rule Rule, threads int) [][]uint8 {
+	next := make([][]uint8, len(world))
+	if len(world) > 0 {
+		cells := make([]uint8, len(world)*len(world[0]))
+		for y := range next {
+			next[y] = cells[y*len(world[0]) : (y+1)*len(world[0])]
+		}
+	}
+	EvolveInto(next, world, boundary, rule, threads)
+	return next
+}
+
+// EvolveInto is Evolve writing the next generation into next, which must be as big as world
+// and not share any rows with it, e.g. to swap two worlds back and forth without allocating.
+//
+// Worlds whose rows lie back to back in one buffer, as Evolve makes them, are evolved by index
+// into that buffer, cell (x, y) at y*width+x, with only the cells on the left and right edges
+// going through boundary; other worlds are evolved a row at a time.
+func EvolveInto(next, world [][]uint8, boundary Boundary, rule Rule, threads int) {
+	cells, flatWorld := flat(world)
+	nextCells, flatNext := flat(next)
+	splitRows(len(world), threads, func(y0, y1 int) {
+		if flatWorld && flatNext {
+			evolveFlat(nextCells, cells, world, y0, y1, boundary, rule)
+			return
+		}
+		for y := y0; y < y1; y++ {
+			for x := range world[y] {
+				next[y][x] = rule.Step(world[y][x], LiveNeighbours(world, x, y, boundary))
+			}
+		}
+	})
+}
+
+// evolveFlat evolves rows [y0, y1) of world, whose cells are laid out flat in cells, into the
+// flat next.
+func evolveFlat(next, cells []uint8, world [][]uint8, y0, y1 int, boundary Boundary, rule Rule) {
+	height, width := len(world), len(world[0])
+	for y := y0; y < y1; y++ {
+		row := y * width
+		above, okAbove := boundary.Neighbour(y-1, height)
+		below, okBelow := boundary.Neighbour(y+1, height)
+		above, below = above*width, below*width
+		for x := 1; x < width-1; x++ {
+			n := live(cells[row+x-1]) + live(cells[row+x+1])
+			if okAbove {
+				n += live(cells[above+x-1]) + live(cells[above+x]) + live(cells[above+x+1])
+			}
+			if okBelow {
+				n += live(cells[below+x-1]) + live(cells[below+x]) + live(cells[below+x+1])
+			}
+			next[row+x] = rule.Step(cells[row+x], n)
+		}
+		// The edges see past the world, wherever boundary says.
+		for _, x := range []int{0, width - 1} {
+			next[row+x] = rule.Step(cells[row+x], LiveNeighbours(world, x, y, boundary))
+		}
+	}
+}
+
+// live is 1 for a live cell and 0 otherwise.
+func live(cell uint8) int {
+	if cell == 255 {
+		return 1
+	}
+	return 0
+}
+
+// flat returns the cells of world row after row if its rows lie back to back in one buffer.
+func flat(world [][]uint8) ([]uint8, bool) {
+	if len(world) == 0 || len(world[0]) == 0 {
+		return nil, false
+	}
+	width := len(world[0])
+	n := width * len(world)
+	if cap(world[0]) < n {
+		return nil, false
+	}
+	cells := world[0][:n]
+	for y, row := range world {
+		if len(row) != width || &row[0] != &cells[y*width] {
+			return nil, false
+		}
+	}
+	return cells, true
+}
+
+// LiveNeighbours counts the live cells among the eight around (x, y), with boundary deciding
+// those beyond the edges of world. Dying cells don't count.
+func LiveNeighbours(world [][]uint8, x, y int, boundary Boundary) int {
+	n := 0
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			if (dx != 0 || dy != 0) && boundary.Cell(world, x+dx, y+dy) == 255 {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// splitRows splits [0, height) as evenly as it can into at most threads bands, calls fn for
+// each in parallel and returns once they are all done.
+func splitRows(height, threads int, fn func(y0, y1 int)) {
+	n := min(threads, height)
+	if n <= 1 {
+		fn(0, height)
+		return
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		y0, y1 := i*height/n, (i+1)*height/n
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn(y0, y1)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,190 @@
+package engine
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// randomWorld returns a width×height world, its rows views into one buffer, with about half
+// its cells alive.
+func randomWorld(r *rand.Rand, width, height int) [][]uint8 {
+	cells := make([]uint8, width*height)
+	for i := range cells {
+		if r.Intn(2) == 0 {
+			cells[i] = 255
+		}
+	}
+	world := make([][]uint8, height)
+	for y := range world {
+		world[y] = cells[y*width : (y+1)*width]
+	}
+	return world
+}
+
+// rows returns a copy of world whose rows are each allocated on their own.
+func rows(world [][]uint8) [][]uint8 {
+	copied := make([][]uint8, len(world))
+	for y, row := range world {
+		copied[y] = slices.Clone(row)
+	}
+	return copied
+}
+
+func equalWorlds(a, b [][]uint8) bool {
+	return slices.EqualFunc(a, b, func(x, y []uint8) bool { return slices.Equal(x, y) })
+}
+
+// pattern builds a world from rows of 'O' (alive), 'o' (dying, as in a three-state rule) and
+// '.' (dead).
+func pattern(rows ...string) [][]uint8 {
+	world := make([][]uint8, len(rows))
+	for y, row := range rows {
+		world[y] = make([]uint8, len(row))
+		for x := range row {
+			switch row[x] {
+			case 'O':
+				world[y][x] = 255
+			case 'o':
+				world[y][x] = 127
+			}
+		}
+	}
+	return world
+}
+
+// TestEvolve tests patterns against the edges of the world, where each boundary gives a
+// different next generation, under Conway's rule and Brian's Brain, a Generations rule whose
+// live cells always start dying. Evolve and EvolveInto must agree on any number of threads
+// and leave world as it was.
+func TestEvolve(t *testing.T) {
+	brain, err := ParseRule("B2/S/C3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		rule  Rule
+		world [][]uint8
+		want  map[Boundary][][][]uint8 // the next generations, one after another
+	}{
+		{
+			name:  "blinker on the top edge",
+			rule:  Conway,
+			world: pattern(".OOO.", ".....", ".....", ".....", "....."),
+			want: map[Boundary][][][]uint8{
+				BoundaryTorus:  {pattern("..O..", "..O..", ".....", ".....", "..O..")},
+				BoundaryDead:   {pattern("..O..", "..O..", ".....", ".....", ".....")},
+				BoundaryMirror: {pattern(".O.O.", "..O..", ".....", ".....", ".....")},
+			},
+		},
+		{
+			name:  "brian's brain on the top edge",
+			rule:  brain,
+			world: pattern(".OO.", "....", "...."),
+			want: map[Boundary][][][]uint8{
+				BoundaryTorus:  {pattern(".oo.", ".OO.", ".OO."), pattern("O..O", "OooO", "OooO")},
+				BoundaryDead:   {pattern(".oo.", ".OO.", "...."), pattern("....", ".oo.", ".OO.")},
+				BoundaryMirror: {pattern("OooO", ".OO.", "...."), pattern("o..o", ".oo.", ".OO.")},
+			},
+		},
+	}
+	for _, test := range tests {
+		for boundary, generations := range test.want {
+			world := test.world
+			for turn, want := range generations {
+				before := rows(world)
+				for _, threads := range []int{1, 2, 5} {
+					if got := Evolve(world, boundary, test.rule, threads); !equalWorlds(got, want) {
+						t.Errorf("%s %s turn %d on %d threads: Evolve gave %v, want %v", test.name, boundary, turn+1, threads, got, want)
+					}
+					next := make([][]uint8, len(world)) // all alive, so every cell must be written
+					for y := range next {
+						next[y] = slices.Repeat([]uint8{255}, len(world[0]))
+					}
+					EvolveInto(next, world, boundary, test.rule, threads)
+					if !equalWorlds(next, want) {
+						t.Errorf("%s %s turn %d on %d threads: EvolveInto gave %v, want %v", test.name, boundary, turn+1, threads, next, want)
+					}
+				}
+				if !equalWorlds(world, before) {
+					t.Fatalf("%s %s turn %d: world changed while evolving it", test.name, boundary, turn+1)
+				}
+				world = want
+			}
+		}
+	}
+}
+
+// TestLiveNeighbours tests neighbour counts at the corners of a world, which see past two edges,
+// and in its middle, which sees none, for each boundary. Dying cells don't count.
+func TestLiveNeighbours(t *testing.T) {
+	world := pattern("O..O", ".o..", "O...")
+	cells := []struct{ x, y int }{{0, 0}, {3, 0}, {0, 2}, {3, 2}, {1, 1}}
+	want := map[Boundary][]int{
+		BoundaryTorus:  {2, 2, 2, 3, 2},
+		BoundaryDead:   {0, 0, 0, 0, 2},
+		BoundaryMirror: {3, 3, 3, 0, 2},
+	}
+	for boundary, counts := range want {
+		for i, c := range cells {
+			if got := LiveNeighbours(world, c.x, c.y, boundary); got != counts[i] {
+				t.Errorf("%s (%d, %d): %d live neighbours, want %d", boundary, c.x, c.y, got, counts[i])
+			}
+		}
+	}
+}
+
+// TestRuleKind tests that Conway's rule, other two-state rules and Generations rules are each
+// their own kind.
+func TestRuleKind(t *testing.T) {
+	for rule, want := range map[string]RuleKind{
+		"":              RuleConway,
+		"B3/S23":        RuleConway,
+		"B36/S23":       RuleLifeLike,
+		"B3/S012345678": RuleLifeLike,
+		"B2/S/C3":       RuleGenerations,
+	} {
+		r, err := ParseRule(rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := r.Kind(); got != want {
+			t.Errorf("%q: kind %s, want %s", rule, got, want)
+		}
+	}
+}
+
+// TestEvolveFlat tests that worlds laid out flat in one buffer, which are evolved by index,
+// evolve the same as worlds whose rows are allocated one by one, for every boundary, for
+// Generations rules and for worlds too narrow to have cells between their edges.
+func TestEvolveFlat(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for _, name := range []string{"B3/S23", "B36/S23", "B2/S345/C4"} {
+		rule, _ := ParseRule(name)
+		for _, boundary := range Boundaries() {
+			for _, size := range [][2]int{{1, 1}, {1, 5}, {2, 3}, {3, 2}, {17, 9}, {64, 65}} {
+				world := randomWorld(r, size[0], size[1])
+				for range 3 {
+					flatNext := Evolve(world, boundary, rule, 3)
+					next := rows(world)
+					EvolveInto(next, rows(world), boundary, rule, 3)
+					if !equalWorlds(flatNext, next) {
+						t.Fatalf("%s %s %dx%d: flat and row by row worlds differ", name, boundary, size[0], size[1])
+					}
+					world = flatNext
+				}
+			}
+		}
+	}
+}
+
+// BenchmarkEvolveInto measures one turn of a 512x512 soup on one goroutine.
+func BenchmarkEvolveInto(b *testing.B) {
+	world := randomWorld(rand.New(rand.NewSource(1)), 512, 512)
+	next := Evolve(world, BoundaryTorus, Conway, 1)
+	b.SetBytes(512 * 512)
+	for b.Loop() {
+		EvolveInto(next, world, BoundaryTorus, Conway, 1)
+	}
+}
@@ -0,0 +1,214 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Rule is a life-like rule: whether a cell is alive next turn depends only on whether it
+// is alive now and how many of its eight neighbours are. Bit n of Birth is set when a dead
+// cell with n live neighbours comes alive, bit n of Survival when a live one stays alive.
+//
+// Rules with more than two States are Generations rules: a live cell that does not survive
+// is not dead yet but dying, and decays through States-2 dying states, one per turn, before
+// it is dead. Dying cells neither count as live neighbours nor can be born. Cells stay one
+// byte: dead is 0, alive 255 and dying cells are the greys in between (see Decay).
+type Rule struct {
+	Birth, Survival uint16
+	States          int
+}
+
+// Conway is Conway's Game of Life, B3/S23, and what the empty rulestring means.
+var Conway = Rule{Birth: 1 << 3, Survival: 1<<2 | 1<<3, States: 2}
+
+// MaxStates is the most States a rule can have: every state needs its own byte value.
+const MaxStates = 256
+
+// Preset is a well-known rule that can be given by name instead of by rulestring.
+type Preset struct {
+	Name string // lower case without spaces, e.g. "daynight"
+	Rule string
+}
+
+// Presets lists the rules ParseRule accepts by name.
+func Presets() []Preset {
+	return []Preset{
+		{"conway", "B3/S23"},
+		{"highlife", "B36/S23"},
+		{"seeds", "B2/S"},
+		{"daynight", "B3678/S34678"},
+		{"lifewithoutdeath", "B3/S012345678"},
+	}
+}
+
+// presetRule returns the rulestring of the preset called name. Case, spaces and the
+// punctuation in names such as "Day & Night" or "life-without-death" are ignored.
+func presetRule(name string) (string, bool) {
+	name = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '&', '-', '_':
+			return -1
+		}
+		return r
+	}, strings.ToLower(name))
+	for _, p := range Presets() {
+		if p.Name == name {
+			return p.Rule, true
+		}
+	}
+	return "", false
+}
+
+// ParseRule reads a rulestring in B/S notation, e.g. "B3/S23" or "B36/S23", case insensitive.
+// The older S/B notation without letters, e.g. "23/3", is accepted too. A third part gives
+// the number of states of a Generations rule, e.g. "B2/S345/C4" or, in S/B/C notation,
+// "345/2/4" (Star Wars). The name of one of Presets(), e.g. "highlife", stands for its rule.
+// The empty string is Conway.
+func ParseRule(s string) (Rule, error) {
+	if s == "" {
+		return Conway, nil
+	}
+	if rule, ok := presetRule(s); ok {
+		return ParseRule(rule)
+	}
+	parts := strings.Split(strings.ToUpper(strings.TrimSpace(s)), "/")
+	if len(parts) != 2 && len(parts) != 3 {
+		return Rule{}, fmt.Errorf("rule %q: want B/S notation, e.g. B3/S23, B/S/C for Generations, or a preset name", s)
+	}
+	first, second := parts[0], parts[1]
+	var birth, survival string
+	switch {
+	case strings.HasPrefix(first, "B") && strings.HasPrefix(second, "S"):
+		birth, survival = first[1:], second[1:]
+	case strings.HasPrefix(first, "S") && strings.HasPrefix(second, "B"):
+		birth, survival = second[1:], first[1:]
+	default:
+		birth, survival = second, first // S/B: survival first
+	}
+
+	r := Rule{States: 2}
+	if len(parts) == 3 {
+		states, err := strconv.Atoi(strings.TrimPrefix(strings.TrimPrefix(parts[2], "C"), "G"))
+		if err != nil || states < 2 || states > MaxStates {
+			return Rule{}, fmt.Errorf("rule %q: number of states must be 2-%d", s, MaxStates)
+		}
+		r.States = states
+	}
+	var err error
+	if r.Birth, err = neighbourCounts(birth); err != nil {
+		return Rule{}, fmt.Errorf("rule %q: %v", s, err)
+	}
+	if r.Survival, err = neighbourCounts(survival); err != nil {
+		return Rule{}, fmt.Errorf("rule %q: %v", s, err)
+	}
+	return r, nil
+}
+
+// neighbourCounts reads digits 0-8 into a bit set.
+func neighbourCounts(digits string) (uint16, error) {
+	var set uint16
+	for _, d := range digits {
+		if d < '0' || d > '8' {
+			return 0, fmt.Errorf("%q is not a neighbour count 0-8", d)
+		}
+		set |= 1 << (d - '0')
+	}
+	return set, nil
+}
+
+// String writes r in B/S notation, e.g. "B3/S23", or B/S/C notation, e.g. "B2/S345/C4",
+// for Generations rules.
+func (r Rule) String() string {
+	var b strings.Builder
+	b.WriteByte('B')
+	writeCounts(&b, r.Birth)
+	b.WriteString("/S")
+	writeCounts(&b, r.Survival)
+	if r.Generations() {
+		b.WriteString("/C")
+		b.WriteString(strconv.Itoa(r.States))
+	}
+	return b.String()
+}
+
+func writeCounts(b *strings.Builder, set uint16) {
+	for n := 0; n <= 8; n++ {
+		if set>>n&1 != 0 {
+			b.WriteByte(byte('0' + n))
+		}
+	}
+}
+
+// Next reports whether a cell is alive next turn, given whether it is alive now and how
+// many of its neighbours are.
+func (r Rule) Next(alive bool, neighbours int) bool {
+	if alive {
+		return r.Survival>>neighbours&1 != 0
+	}
+	return r.Birth>>neighbours&1 != 0
+}
+
+// Generations reports whether r has dying states.
+func (r Rule) Generations() bool {
+	return r.States > 2
+}
+
+// RuleKind is a family of rules. Simulators that can't run every rule, such as workers
+// built before Generations rules, say which kinds they can.
+type RuleKind string
+
+const (
+	RuleConway      RuleKind = "conway"      // B3/S23 only
+	RuleLifeLike    RuleKind = "life-like"   // any two-state B/S rule
+	RuleGenerations RuleKind = "generations" // B/S/C rules with dying cells
+)
+
+// Kind reports which family r belongs to.
+func (r Rule) Kind() RuleKind {
+	switch {
+	case r.Generations():
+		return RuleGenerations
+	case r == Conway:
+		return RuleConway
+	}
+	return RuleLifeLike
+}
+
+// Decay returns the cell a live or dying cell v becomes when it does not survive. A cell
+// with k turns left to live, alive ones having States-1, is the byte 255*k/(States-1), so
+// live cells are 255 and decay, one grey at a time, to 0. Two-state rules decay straight to 0.
+func (r Rule) Decay(v uint8) uint8 {
+	if r.States <= 2 {
+		return 0
+	}
+	n := r.States - 1
+	k := (int(v)*n + 254) / 255 // inverts 255*k/n, rounded down
+	return uint8(255 * (k - 1) / n)
+}
+
+// Flip returns the cell v becomes when it changes: a dead cell is born, a live or dying one
+// decays. Cells only ever change this one way, so lists of changed cells (the flips the broker
+// sends) are enough to keep a copy of the world up to date.
+func (r Rule) Flip(v uint8) uint8 {
+	if v == 0 {
+		return 255
+	}
+	return r.Decay(v)
+}
+
+// Step returns the cell v becomes next turn, given how many of its neighbours are alive.
+func (r Rule) Step(v uint8, neighbours int) uint8 {
+	switch v {
+	case 0:
+		if r.Next(false, neighbours) {
+			return 255
+		}
+		return 0
+	case 255:
+		if r.Next(true, neighbours) {
+			return 255
+		}
+	}
+	return r.Decay(v)
+}
@@ -1,5 +1,16 @@
 module uk.ac.bris.cs/gameoflife
 
-go 1.17
+go 1.24.0
 
-require github.com/veandco/go-sdl2 v0.4.40
+require (
+	github.com/veandco/go-sdl2 v0.4.40
+	golang.org/x/net v0.48.0
+	google.golang.org/grpc v1.79.1
+	google.golang.org/protobuf v1.36.12
+)
+
+require (
+	golang.org/x/sys v0.39.0 // indirect
+	golang.org/x/text v0.32.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20251202230838-ff82c1b0f217 // indirect
+)
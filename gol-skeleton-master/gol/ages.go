@@ -0,0 +1,119 @@
+package gol
+
+import (
+	"bufio"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// ageGrid tracks how many consecutive turns every live cell has been alive when Params.Ages
+// is set. It keeps the turn each cell last came alive rather than the ages themselves, so
+// applying a turn's flips costs one write per cell born instead of a pass over the world;
+// ages are only worked out when they are reported or saved. A nil *ageGrid tracks nothing.
+type ageGrid struct {
+	born [][]int
+}
+
+// newAgeGrid starts tracking world as it is on turn, whose live cells all count as just born.
+// It returns nil unless p.Ages is set.
+func newAgeGrid(p Params, world [][]uint8, turn int) *ageGrid {
+	if !p.Ages {
+		return nil
+	}
+	a := &ageGrid{born: make([][]int, len(world))}
+	for y, row := range world {
+		a.born[y] = make([]int, len(row))
+		for x, cell := range row {
+			if cell == 255 {
+				a.born[y][x] = turn
+			}
+		}
+	}
+	return a
+}
+
+// flip records the births among flipped, which have just been applied to world to make it
+// the world of turn.
+func (a *ageGrid) flip(world [][]uint8, flipped []util.Cell, turn int) {
+	if a == nil {
+		return
+	}
+	for _, cell := range flipped {
+		if world[cell.Y][cell.X] == 255 {
+			a.born[cell.Y][cell.X] = turn
+		}
+	}
+}
+
+// report returns the CellAges event for world on turn, or nil if a tracks nothing.
+// A cell that came alive on turn is 1 turn old.
+func (a *ageGrid) report(world [][]uint8, turn int) *CellAges {
+	if a == nil {
+		return nil
+	}
+	r := &CellAges{CompletedTurns: turn}
+	for y, row := range world {
+		for x, cell := range row {
+			if cell == 255 {
+				r.Cells = append(r.Cells, util.Cell{X: x, Y: y})
+				r.Ages = append(r.Ages, turn-a.born[y][x]+1)
+			}
+		}
+	}
+	return r
+}
+
+// youngColour is a cell that has just come alive and oldColour one at least oldAge turns old,
+// still distinguishable from the black of dead cells.
+var (
+	youngColour = color.RGBA{R: 255, G: 255, B: 160, A: 255}
+	oldColour   = color.RGBA{R: 96, G: 0, B: 0, A: 255}
+)
+
+const oldAge = 1000
+
+// ageColour blends from youngColour to oldColour on a logarithmic scale, so the first few
+// turns of a cell's life stand out as much as the difference between old and very old.
+func ageColour(age int) color.RGBA {
+	t := min(math.Log(float64(max(age, 1)))/math.Log(oldAge), 1)
+	blend := func(young, old uint8) uint8 {
+		return uint8(math.Round(float64(young) + t*(float64(old)-float64(young))))
+	}
+	return color.RGBA{
+		R: blend(youngColour.R, oldColour.R),
+		G: blend(youngColour.G, oldColour.G),
+		B: blend(youngColour.B, oldColour.B),
+		A: 255,
+	}
+}
+
+// saveAges writes ages as a width×height colour PNG at path: dead cells are black and live
+// cells bright when young and dark when old (see ageColour).
+func saveAges(path string, width, height int, ages *CellAges) error {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 255 // opaque black
+	}
+	for i, cell := range ages.Cells {
+		img.SetRGBA(cell.X, cell.Y, ageColour(ages.Ages[i]))
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	w := bufio.NewWriter(file)
+	if err := png.Encode(w, img); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return file.Close()
+}
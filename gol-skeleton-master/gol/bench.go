@@ -0,0 +1,59 @@
+package gol
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// benchRecorder writes one CSV row per call the distributor makes to the broker when
+// Params.Benchmark is set:
+//
+//	turn,turns,wall_us,rpc_us,alive
+//
+// turn is the last turn the call completed and turns how many it completed (Params.TurnsPerCall),
+// wall_us the time since the previous row, covering everything the distributor did for those
+// turns, rpc_us the time spent in the call itself, and alive the number of live cells after it.
+type benchRecorder struct {
+	file  *os.File
+	w     *bufio.Writer
+	last  time.Time
+	alive int
+}
+
+// newBenchRecorder creates the CSV at path for a run starting from world.
+func newBenchRecorder(path string, world [][]uint8) (*benchRecorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	b := &benchRecorder{file: file, w: bufio.NewWriter(file), last: time.Now(), alive: countAlive(world)}
+	fmt.Fprintln(b.w, "turn,turns,wall_us,rpc_us,alive")
+	return b, nil
+}
+
+// flip counts the cells of one turn's flips into the alive count. It has to be called before
+// they are applied to world, which is the world of the previous turn.
+func (b *benchRecorder) flip(flipped []util.Cell, world [][]uint8) {
+	births, deaths := countFlips(flipped, world)
+	b.alive += births - deaths
+}
+
+// record adds the row for a call that took rpc and completed turns turns, up to turn.
+func (b *benchRecorder) record(turn, turns int, rpc time.Duration) {
+	now := time.Now()
+	fmt.Fprintf(b.w, "%d,%d,%d,%d,%d\n", turn, turns, now.Sub(b.last).Microseconds(), rpc.Microseconds(), b.alive)
+	b.last = now
+}
+
+// Close flushes the CSV.
+func (b *benchRecorder) Close() error {
+	if err := b.w.Flush(); err != nil {
+		_ = b.file.Close()
+		return err
+	}
+	return b.file.Close()
+}
@@ -0,0 +1,101 @@
+package gol
+
+import "sync"
+
+// EventBus fans the events of one Run out to any number of consumers, e.g. SDL, a test
+// harness and a recorder, each of which sees every event in order. Reading the events
+// channel of Run directly, whichever consumer reads an event first takes it from the others.
+//
+//	events := make(chan gol.Event)
+//	bus := gol.NewEventBus()
+//	window, recorder := bus.Subscribe(1000), bus.Subscribe(1000)
+//	go bus.Forward(events)
+//	go gol.Run(p, events, keyPresses)
+//
+// Delivery is blocking, as it is on the events channel of Run: a subscriber that stops
+// reading, once its buffer is full, holds up the others and Run itself. Consumers that are
+// done early should Unsubscribe.
+type EventBus struct {
+	mu     sync.Mutex
+	subs   []*subscription
+	closed bool // Forward has returned
+}
+
+type subscription struct {
+	events chan Event
+	done   chan struct{} // closed by Unsubscribe
+}
+
+// NewEventBus returns a bus with no subscribers.
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+// Subscribe returns a channel receiving every event forwarded from now on, buffering up to
+// buffer of them. It is closed once the events have all been forwarded, or straight away if
+// they already have.
+func (b *EventBus) Subscribe(buffer int) <-chan Event {
+	sub := &subscription{events: make(chan Event, buffer), done: make(chan struct{})}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(sub.events)
+		return sub.events
+	}
+	b.subs = append(b.subs, sub)
+	return sub.events
+}
+
+// Unsubscribe stops delivering events to a channel returned by Subscribe, which is then
+// closed by Forward. Events already buffered in it can still be read.
+func (b *EventBus) Unsubscribe(events <-chan Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, sub := range b.subs {
+		if sub.events == events {
+			select {
+			case <-sub.done:
+			default:
+				close(sub.done)
+			}
+		}
+	}
+}
+
+// Forward delivers every event from events to every subscriber until events is closed, and
+// then closes the subscribers' channels. It is the only goroutine that sends on them.
+func (b *EventBus) Forward(events <-chan Event) {
+	for event := range events {
+		for _, sub := range b.subscribers() {
+			select {
+			case sub.events <- event:
+			case <-sub.done:
+			}
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, sub := range b.subs {
+		close(sub.events)
+	}
+	b.subs, b.closed = nil, true
+}
+
+// subscribers returns the current subscribers, closing and dropping those that unsubscribed.
+func (b *EventBus) subscribers() []*subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	live := b.subs[:0]
+	for _, sub := range b.subs {
+		select {
+		case <-sub.done:
+			close(sub.events)
+		default:
+			live = append(live, sub)
+		}
+	}
+	clear(b.subs[len(live):])
+	b.subs = live
+	return append([]*subscription(nil), live...)
+}
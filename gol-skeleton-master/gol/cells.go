@@ -0,0 +1,82 @@
+package gol
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// Plaintext (.cells) patterns, one row of cells per line, handy for editing by hand:
+//
+//	!Name: Glider
+//	.O.
+//	..O
+//	OOO
+//
+// Lines starting with ! are comments. O is a live cell and . a dead one; rows may be
+// shorter than the widest one, the rest of the row being dead.
+
+// decodeCells reads a plaintext pattern into its bounding box, which may be at most
+// maxPatternCells: its widest row times its rows can dwarf the file.
+func decodeCells(data []byte) ([][]uint8, error) {
+	var rows []string
+	width := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if strings.HasPrefix(line, "!") {
+			continue
+		}
+		rows = append(rows, line)
+		width = max(width, len(line))
+	}
+	// Blank lines at the end are just the end of the file.
+	for len(rows) > 0 && rows[len(rows)-1] == "" {
+		rows = rows[:len(rows)-1]
+	}
+	if width == 0 {
+		return nil, fmt.Errorf("cells: empty pattern")
+	}
+	if err := checkPatternSize("cells", width, len(rows)); err != nil {
+		return nil, err
+	}
+
+	world := util.NewWorld(width, len(rows))
+	for y, line := range rows {
+		for x := 0; x < len(line); x++ {
+			switch line[x] {
+			case 'O', 'o', '*':
+				world[y][x] = 255
+			case '.':
+			default:
+				return nil, fmt.Errorf("cells: unexpected %q in row %d", line[x], y+1)
+			}
+		}
+	}
+	return world, nil
+}
+
+// encodeCells writes the whole world as a plaintext pattern, every row in full so the
+// file lines up when edited by hand. Dying cells of a Generations rule are written as dead.
+func encodeCells(w io.Writer, world [][]uint8, rule util.Rule) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "!%dx%d world, rule %s\n", len(world[0]), len(world), rule)
+	line := make([]byte, len(world[0])+1)
+	line[len(line)-1] = '\n'
+	for _, row := range world {
+		for x, cell := range row {
+			if cell == 255 {
+				line[x] = 'O'
+			} else {
+				line[x] = '.'
+			}
+		}
+		bw.Write(line)
+	}
+	return bw.Flush()
+}
@@ -1,14 +1,37 @@
 package gol
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/rpc"
+	"path/filepath"
 	"sync"
 	"time"
 
+	"uk.ac.bris.cs/gameoflife/transport"
 	"uk.ac.bris.cs/gameoflife/util"
 )
 
+var logger = util.Logger("distributor")
+
+// 连接 broker 失败时的重试间隔，从 dialBackoffMin 开始每次翻倍，最多 dialBackoffMax
+const (
+	dialBackoffMin = 250 * time.Millisecond
+	dialBackoffMax = 5 * time.Second
+)
+
+// errOutOfStep：broker 的回合数和本地对不上（之前超时的调用晚一步完成了），对齐之后可以继续
+var errOutOfStep = errors.New("out of step with the broker")
+
+// 速度控制：'-' 从不限速降到 slowTurnRate 回合/秒，'+' 翻倍到超过 maxTurnRate 时取消限速
+const (
+	slowTurnRate = 60
+	maxTurnRate  = 4096
+)
+
 type distributorChannels struct {
 	events     chan<- Event
 	ioCommand  chan<- ioCommand
@@ -16,80 +39,257 @@ type distributorChannels struct {
 	ioFilename chan<- string
 	ioOutput   chan<- uint8
 	ioInput    <-chan uint8
+	queue      *eventQueue // events 前面的队列，没有时为 nil（见 Params.EventQueue）
 }
 
 type WorldParams struct {
 	ImageWidth  int
 	ImageHeight int
-	World       [][]uint8
+	World       util.World
+	Turn        int           // 从快照恢复时 broker 从这一回合接着数
+	Boundary    util.Boundary // 世界边界之外看到什么，空字符串表示环绕
+	Rule        string        // B/S 记法的规则，空字符串表示 B3/S23
+	JobID       string        // broker 上哪个 job，空字符串表示默认 job
+}
+
+// JobArgs 必须和 broker 那边保持一致，只指明是哪个 job 的调用用它做参数
+type JobArgs struct {
+	JobID string
+}
+
+// NextTurnReply / StepTiming / ProcessTurnsArgs / ProcessTurnsReply 必须和 broker 那边保持一致
+type NextTurnReply struct {
+	Turn    int
+	Flipped []util.Cell
+	Timing  StepTiming
+}
+
+type StepTiming struct {
+	Elapsed        time.Duration
+	Slowest        string
+	SlowestElapsed time.Duration
+}
+
+type ProcessTurnsArgs struct {
+	Turns int
+	JobID string
+}
+
+type ProcessTurnsReply struct {
+	Turn    int
+	Flipped [][]util.Cell
+	Timings []StepTiming
+}
+
+// DetachArgs / AttachReply 必须和 broker 那边保持一致
+type DetachArgs struct {
+	Turns int
+	JobID string
+}
+
+type AttachReply struct {
+	Turn  int
+	World util.World
+	Rule  string
+}
+
+// WorldReply 必须和 broker 那边保持一致
+type WorldReply struct {
+	Turn  int
+	World util.World
+}
+
+// StatusReply 必须和 broker 那边保持一致，这里只用到其中几项
+type StatusReply struct {
+	Turn       int
+	Workers    int
+	Waiting    time.Duration // 回合在等 worker 注册时已经等了多久，0 表示没在等
+	MinWorkers int
+}
+
+// AliveArgs / AliveReport 必须和 broker 那边保持一致
+type AliveArgs struct {
+	Interval time.Duration
+	JobID    string
+}
+
+type AliveReport struct {
+	Turn  int
+	Count int
 }
 
 func distributor(p Params, c distributorChannels, keyPresses <-chan rune) {
 	var mu sync.Mutex
 
-	// 1. 初始化世界
-	world := make([][]uint8, p.ImageHeight)
-	for y := range world {
-		world[y] = make([]uint8, p.ImageWidth)
+	// -local：在进程内启动 broker，退出时关掉
+	if p.Local {
+		ln, err := StartLocalRPCServer(p.Threads, p.Engine)
+		if err != nil {
+			logger.Error("start local broker failed", "err", err)
+			fail(c, 0, "", err)
+			return
+		}
+		defer StopLocalRPCServer(ln)
+		p.BrokerAddr = ln.Addr().String()
 	}
 
-	// 2. 读取初始图像
-	c.ioCommand <- ioInput
-	c.ioFilename <- fmt.Sprintf("%dx%d", p.ImageWidth, p.ImageHeight)
-	for y := 0; y < p.ImageHeight; y++ {
-		for x := 0; x < p.ImageWidth; x++ {
-			world[y][x] = <-c.ioInput
+	// 1. 连接 Broker（地址来自 Params.BrokerAddr / GOL_BROKER_ADDR，默认 AWS 端），连不上时重试一阵
+	client := dialBroker(p, c)
+	if client == nil {
+		return
+	}
+	// 延迟关闭 RPC 连接：无论是否正常都关 防止长期占用 Broker 连接资源，避免tcp资源泄漏
+	defer client.Close()
+
+	// 2. -resume：接管上一个控制器按 'q' 之后 broker 还在后台推进的模拟；-resume 文件：从快照恢复
+	world, turn, resumed := attachToBroker(p, client)
+	restored := false
+	if !resumed {
+		world, turn, restored = restoreSnapshot(p)
+	}
+
+	// 3. 没有可接管的模拟、也没有快照时读取初始图像
+	if !resumed && !restored {
+		world = util.NewWorld(p.ImageWidth, p.ImageHeight)
+		c.ioCommand <- ioInput
+		c.ioFilename <- fmt.Sprintf("%dx%d", p.ImageWidth, p.ImageHeight)
+		for y := 0; y < p.ImageHeight; y++ {
+			for x := 0; x < p.ImageWidth; x++ {
+				world[y][x] = <-c.ioInput
+			}
 		}
 	}
 
-	// 3. 初始状态事件
-	turn := 0
+	// 4. 初始状态事件
 	c.events <- StateChange{turn, Executing}
 
-	// 4. 发送初始存活细胞（CellsFlipped），方便 SDL / 测试拿到初始状态
+	// 5. 发送初始存活细胞（CellsFlipped），方便 SDL / 测试拿到初始状态。
+	// Generations 规则下将死的灰色细胞也一起发，带上各自的值
+	rule := lifeRule(p)
 	var initialAlive []util.Cell
 	mu.Lock()
 	for y := 0; y < p.ImageHeight; y++ {
 		for x := 0; x < p.ImageWidth; x++ {
-			if world[y][x] == 255 {
+			if world[y][x] == 255 || (rule.Generations() && world[y][x] != 0) {
 				initialAlive = append(initialAlive, util.Cell{X: x, Y: y})
 			}
 		}
 	}
+	initialValues := cellValues(world, initialAlive, rule)
+	// -view-width / -view-height：窗口比世界小时只发视口里的翻转（见 viewport），不启用时为 nil
+	view := newViewport(p)
+	initialAlive, initialValues = view.filter(initialAlive, initialValues)
+	var initialFrame PixelData
+	if p.FrameEvery > 0 {
+		initialFrame = pixelData(world, turn, rule) // -frame-every：一开始先发一帧完整的世界
+	}
 	mu.Unlock()
+	if view != nil {
+		c.events <- view.event(turn)
+	}
 	if len(initialAlive) > 0 {
-		c.events <- CellsFlipped{CompletedTurns: turn, Cells: initialAlive}
+		c.events <- CellsFlipped{CompletedTurns: turn, Cells: initialAlive, Values: initialValues}
+	}
+	if p.FrameEvery > 0 {
+		c.events <- initialFrame
 	}
 	c.events <- TurnComplete{CompletedTurns: turn} // 用于同步系统状态，告知 SDL
 
-	// 5. 连接 Broker（AWS 端）
-	client, err := rpc.Dial("tcp", "54.87.214.152:8080")
+	// -ages：按本地收到的翻转记下每个活细胞是第几回合出生的（见 ageGrid），和 world 一样由 mu 保护
+	ages := newAgeGrid(p, world, turn)
+
+	// 初始世界只上传一次，之后每回合只收翻转的细胞
+	if !resumed {
+		var started bool
+		err := client.Call("Broker.StartSimulation", WorldParams{
+			ImageWidth:  p.ImageWidth,
+			ImageHeight: p.ImageHeight,
+			World:       world,
+			Turn:        turn,
+			Boundary:    p.Boundary,
+			Rule:        p.Rule,
+			JobID:       p.Job,
+		}, &started)
+		if err != nil {
+			logger.Error("start simulation on broker failed", "broker", brokerAddr(p), "code", errorCode(err), "err", err)
+			fail(c, turn, errorCode(err), err)
+			return
+		}
+	}
+
+	isPaused := false
+	viewing := false // 按 'v' 交出控制权之后为 true，'p' 也交给观察者处理
+
+	// -bench：每次调用 broker 记一行 CSV（见 benchRecorder）
+	var bench *benchRecorder
+	if p.Benchmark != "" {
+		var err error
+		if bench, err = newBenchRecorder(p.Benchmark, world); err != nil {
+			logger.Error("create benchmark csv failed", "path", p.Benchmark, "err", err)
+			fail(c, turn, "", err)
+			return
+		}
+		defer func() {
+			if err := bench.Close(); err != nil {
+				logger.Warn("write benchmark csv failed", "path", p.Benchmark, "err", err)
+			}
+		}()
+	}
+
+	// -stats / -stats-csv：每回合按翻转统计出生、死亡和密度（见 turnStats），不启用时为 nil
+	stats, err := newTurnStats(p, world)
 	if err != nil {
-		fmt.Println("Error connecting to server:", err)
+		logger.Error("create stats csv failed", "path", p.StatsCSV, "err", err)
+		fail(c, turn, "", err)
 		return
 	}
-	// 延迟关闭 RPC 连接：无论是否正常都关 防止长期占用 Broker 连接资源，避免tcp资源泄漏
-	defer client.Close()
+	defer func() {
+		if err := stats.Close(); err != nil {
+			logger.Warn("write stats csv failed", "path", p.StatsCSV, "err", err)
+		}
+	}()
 
-	isPaused := false
+	// -detect-period：按翻转维护世界的哈希，发现世界开始重复时发 Stabilized（见 stabilityDetector），不启用时为 nil
+	detector := newStabilityDetector(p, world, turn)
+
+	// -flip-regions：翻转太多的回合合并成脏区域发（见 flipRegions），不启用时为 nil
+	regions := newFlipRegions(p)
+	// sendRegions：按当前世界把攒着的脏区域发出去
+	sendRegions := func() {
+		mu.Lock()
+		event := regions.event(world, turn)
+		mu.Unlock()
+		c.events <- event
+	}
+	stable := false // -stop-when-stable 时发现重复就提前结束
 
-	// 6. 每 2 秒统计一次活细胞数量
-	ticker := time.NewTicker(2 * time.Second)
-	defer ticker.Stop()
+	// 6. 定期报告活细胞数量（默认每 2 秒，p.AliveInterval 可调或关掉）
+	//    数量由 broker 按这个间隔推过来（见 watchAlive），对得上 broker 上某一个完整的回合；
+	//    每次先发一个 TurnRate（两次统计之间每秒完成的回合数）
 	done := make(chan struct{})
+	meter := newTurnRateMeter(turn)
+	localAlive := func() AliveReport {
+		mu.Lock()
+		defer mu.Unlock()
+		return AliveReport{Turn: turn, Count: countAlive(world)}
+	}
+	aliveReports := watchAlive(p, client, localAlive, done)
 
 	go func() {
 		for {
 			select {
-			case <-ticker.C:
+			case alive := <-aliveReports:
 				mu.Lock()
-				aliveCount := countAlive(world)
-				currentTurn := turn
+				report := ages.report(world, turn)
 				mu.Unlock()
 
+				c.events <- meter.next(alive.Turn)
 				c.events <- AliveCellsCount{
-					CompletedTurns: currentTurn,
-					CellsCount:     aliveCount,
+					CompletedTurns: alive.Turn,
+					CellsCount:     alive.Count,
+				}
+				if report != nil {
+					c.events <- *report
 				}
 			case <-done:
 				return
@@ -103,7 +303,10 @@ func distributor(p Params, c distributorChannels, keyPresses <-chan rune) {
 
 	go func() {
 		for key := range keyPresses { // 循环读取 keyPresses 通道中的键盘输入
-			if key == 'p' {
+			mu.Lock()
+			control := !viewing
+			mu.Unlock()
+			if key == 'p' && control {
 				mu.Lock()
 				isPaused = !isPaused
 				currentTurn := turn
@@ -115,6 +318,16 @@ func distributor(p Params, c distributorChannels, keyPresses <-chan rune) {
 
 				// 立即通知暂停 / 继续
 				c.events <- StateChange{currentTurn, state}
+
+				// broker 那边也停下来（ProcessTurns 提前返回，后台推进等待）
+				method := "Broker.Resume"
+				if state == Paused {
+					method = "Broker.Pause"
+				}
+				var ok bool
+				if err := client.Call(method, JobArgs{JobID: p.Job}, &ok); err != nil {
+					logger.Warn("toggle pause on broker failed", "method", method, "err", err)
+				}
 			} else {
 				controlKeys <- key
 			}
@@ -125,39 +338,121 @@ func distributor(p Params, c distributorChannels, keyPresses <-chan rune) {
 	doneClosed := false
 	eventsClosed := false
 
-	// 处理除 'p' 之外的按键：s / q / k
+	// 限速：turnRate 是每秒回合数（0 不限速），nextCallAt 之前不发下一次 RPC。只在主循环里读写
+	turnRate := max(p.MaxFPS, 0)
+	var nextCallAt time.Time
+
+	// 保存世界（s / q / k、定期保存和最后一回合）用的第二份世界：保存都在主循环里同步做完，
+	// 所以每次拷进同一份缓冲区就行，不用每次分配
+	var saved [][]uint8
+
+	// 处理除 'p' 之外的按键：s / q / v / k / + / - 和平移、缩放视口的方向键 / z / x
 	handleKey := func(key rune) bool {
 		switch key {
+		case KeyPanLeft, KeyPanRight, KeyPanUp, KeyPanDown, KeyZoomIn, KeyZoomOut:
+			if view == nil {
+				logger.Info("the window shows the whole world, nothing to pan or zoom", "key", string(key))
+				break
+			}
+			if regions.held() {
+				sendRegions() // 攒着的区域是旧视口之前的回合
+			}
+			mu.Lock()
+			changed := view.key(key)
+			var events []Event
+			if changed {
+				events = view.redraw(world, turn, rule)
+			}
+			currentTurn := turn
+			mu.Unlock()
+			if changed {
+				for _, event := range events {
+					c.events <- event
+				}
+				c.events <- TurnComplete{CompletedTurns: currentTurn} // 让 SDL 马上重画
+			}
+
+		case '+', '-':
+			switch {
+			case key == '+' && turnRate > 0:
+				turnRate *= 2
+				if turnRate > maxTurnRate {
+					turnRate = 0
+				}
+			case key == '-' && turnRate == 0:
+				turnRate = slowTurnRate
+			case key == '-' && turnRate > 1:
+				turnRate /= 2
+			}
+			nextCallAt = time.Time{}
+			if turnRate == 0 {
+				logger.Info("turn rate limit off")
+			} else {
+				logger.Info("turn rate limited", "turnsPerSecond", turnRate)
+			}
+
 		case 's':
-			// 保存当前世界
+			// 保存 broker 上的权威世界，拿不到时退回本地副本
 			mu.Lock()
-			worldCopy := deepCopyWorldUint8(world) //保存的是“按下保存键瞬间”的世界状态，后续主协程修改 world 不会干扰保存结果
+			saved = copyWorldInto(saved, world) //保存的是“按下保存键瞬间”的世界状态，后续主协程修改 world 不会干扰保存结果
 			currentTurn := turn
+			report := ages.report(world, turn)
 			mu.Unlock()
-			saveWorld(p, c, worldCopy, currentTurn)
+			worldCopy, currentTurn := remoteWorld(p, client, saved, currentTurn)
+			saveWorld(p, c, worldCopy, currentTurn, report)
 
-		case 'q':
+		case 'q', 'v':
 			// 退出控制器：保存最终世界并发送 FinalTurnComplete + Quitting
 			if !doneClosed {
 				close(done)
 				doneClosed = true
 			}
+			// broker 在后台继续推进，之后可以用 -resume 重新连上
+			var detached bool
+			if err := client.Call("Broker.Detach", DetachArgs{Turns: p.Turns, JobID: p.Job}, &detached); err != nil {
+				logger.Warn("detach from broker failed, remote simulation stops here", "turn", turn, "err", err)
+			}
+			// 'v'：交出控制权之后不退出，接着当观察者看 broker 在后台推进（见 observe），
+			// 按键从此交给观察者。broker 没在后台推进或者订阅不了时和 'q' 一样
+			if key == 'v' && detached {
+				var sub SubscribeReply
+				err := client.Call("Broker.Subscribe", JobArgs{JobID: p.Job}, &sub)
+				if err == nil {
+					if regions.held() {
+						sendRegions() // 观察者从消费者眼下看到的世界接着翻转
+					}
+					mu.Lock()
+					viewing = true
+					mu.Unlock()
+					observe(p, c, client, sub, world, view, controlKeys)
+					return true
+				}
+				logger.Warn("subscribe to broker failed, quitting instead", "turn", turn, "err", err)
+			}
 			mu.Lock()
-			worldCopy := deepCopyWorldUint8(world)
+			saved = copyWorldInto(saved, world)
 			currentTurn := turn
+			report := ages.report(world, turn)
 			mu.Unlock()
-			finalizeGame(p, c, worldCopy, currentTurn)
+			finalizeGame(p, c, saved, currentTurn, report)
 			return true
 
 		case 'k':
 			// 关闭整个分布式系统：保存一次当前世界 + 等待 IO 空闲 + Quitting
 			mu.Lock()
-			worldCopy := deepCopyWorldUint8(world)
+			saved = copyWorldInto(saved, world)
 			currentTurn := turn
+			report := ages.report(world, turn)
 			mu.Unlock()
-			saveWorld(p, c, worldCopy, currentTurn)
-
-			fmt.Println("Shutting down gracefully...")
+			worldCopy, currentTurn := remoteWorld(p, client, saved, currentTurn)
+			saveWorld(p, c, worldCopy, currentTurn, report)
+
+			// 让 broker 关掉所有 worker 和它自己
+			logger.Info("shutting down gracefully", "turn", currentTurn)
+			var ok bool
+			if err := client.Call("Broker.Shutdown", struct{}{}, &ok); err != nil {
+				logger.Warn("shut down distributed system failed", "err", err)
+			}
 			_ = client.Close()
 
 			// 等待 IO 空闲，确保文件写完
@@ -181,8 +476,23 @@ func distributor(p Params, c distributorChannels, keyPresses <-chan rune) {
 		return false
 	}
 
-	// 8. 主回合循环：推进 Game of Life，并处理 s/q/k
-	for turn < p.Turns {
+	// 连续失败（超时）了几次，见 p.CallRetries
+	timeouts := 0
+
+	// 推进 broker 的调用，最多 p.Pipeline 个同时在路上（见 turnPipeline）
+	pipe := newTurnPipeline(p, client)
+	pipe.lag = c.queue.lag // 还在事件队列里的回合也算进 MaxBacklog
+	// 回合迟迟不返回时问一下 broker 是不是在等 worker 注册，是的话告诉控制器还在预热
+	pipe.stalled = func() {
+		var status StatusReply
+		if err := client.Call("Broker.GetStatus", JobArgs{JobID: p.Job}, &status); err != nil || status.Waiting <= 0 {
+			return
+		}
+		c.events <- WaitingForWorkers{CompletedTurns: turn, Workers: status.Workers, MinWorkers: status.MinWorkers, Waited: status.Waiting}
+	}
+
+	// 8. 主回合循环：推进 Game of Life，并处理 s/q/v/k
+	for turn < p.Turns && !stable {
 		select {
 		case key := <-controlKeys:
 			if handleKey(key) {
@@ -194,51 +504,180 @@ func distributor(p Params, c distributorChannels, keyPresses <-chan rune) {
 			paused := isPaused
 			mu.Unlock()
 
-			if paused {
-				// 暂停时什么都不算，稍微 sleep 防止空转
-				time.Sleep(10 * time.Millisecond)
-				continue
+			// 世界保存在 broker 上，这里只让它推进一回合（TurnsPerCall > 1 时一次推进多回合）。
+			// 没暂停、没被限速时把流水线填满，broker 算后面几回合的同时这边处理前面的；
+			// 要过的回合不超过 MaxBacklog，消费者或者 broker 慢的时候回复不会在内存里越攒越多
+			for !paused && pipe.room() && turn+pipe.pending() < p.Turns && (turnRate == 0 || !time.Now().Before(nextCallAt)) {
+				batch := p.TurnsPerCall
+				if batch < 1 {
+					batch = 1
+				}
+				if turnRate > 0 && batch > turnRate {
+					batch = turnRate // 限速时一批不超过一秒的量
+				}
+				if remaining := p.Turns - turn - pipe.pending(); batch > remaining {
+					batch = remaining
+				}
+				batch = pipe.fit(batch) // 不超过 -max-backlog 剩下的余量
+				pipe.send(batch)
+				if turnRate > 0 {
+					nextCallAt = time.Now().Add(time.Duration(batch) * time.Second / time.Duration(turnRate))
+				}
 			}
 
-			// 构造 RPC 参数（直接传 world 引用，在本回合结束前我们不会再改它）
-			mu.Lock()
-			params := WorldParams{
-				ImageWidth:  p.ImageWidth,
-				ImageHeight: p.ImageHeight,
-				World:       world,
+			if pipe.idle() {
+				if paused {
+					// 暂停时什么都不算，稍微 sleep 防止空转；攒着的脏区域先发掉，画面停在暂停的回合
+					if regions.held() {
+						sendRegions()
+					}
+					time.Sleep(10 * time.Millisecond)
+					continue
+				}
+				// 限速中，或者消费者落后了 MaxBacklog 回合：分小段睡，按键照样能及时处理
+				wait := 10 * time.Millisecond
+				if turnRate > 0 {
+					wait = min(time.Until(nextCallAt), wait)
+				}
+				time.Sleep(wait)
+				continue
 			}
-			mu.Unlock()
 
-			var newWorld [][]uint8
-			err := client.Call("Broker.ProcessTurn", params, &newWorld)
+			// 按回合顺序拿下一批结果；broker 被暂停了（比如通过 HTTP 的 /pause）时没有翻转
+			result := pipe.next(turn)
+			turnFlips, replyTurn, err := result.flipped, result.turn, result.err
+			if err == nil && replyTurn != turn+len(turnFlips) {
+				// 超时重试之后，之前那次调用可能在 broker 上晚一步完成了，flips 对不上本地世界
+				err = fmt.Errorf("broker is at turn %d, expected %d: %w", replyTurn, turn+len(turnFlips), errOutOfStep)
+			}
 			if err != nil {
-				fmt.Println("Error calling server:", err)
-				if !doneClosed {
-					close(done)
-					doneClosed = true
+				batch := max(p.TurnsPerCall, 1)
+				retry := timeouts < p.CallRetries && (errors.Is(err, transport.ErrTimeout) || errors.Is(err, errOutOfStep))
+				c.events <- BrokerError{CompletedTurns: turn, Err: err, Retrying: retry}
+				if !retry {
+					logger.Error("advance turn on broker failed", "turn", turn+1, "batch", batch, "code", errorCode(err), "err", err)
+					if !doneClosed {
+						close(done)
+						doneClosed = true
+					}
+					if regions.held() {
+						sendRegions() // 画面停在最后算完的回合
+					}
+					fail(c, turn, errorCode(err), err)
+					return
 				}
-				return
+				timeouts++
+				logger.Warn("advance turn on broker failed, catching up and retrying", "turn", turn+1, "batch", batch, "retry", timeouts, "err", err)
+
+				// 不知道这批回合 broker 算了没有：按 broker 上的世界对齐本地世界再继续
+				var reply WorldReply
+				if err := client.Call("Broker.GetWorld", JobArgs{JobID: p.Job}, &reply); err != nil {
+					continue // 还是超时的话下一轮再试，同样算一次重试
+				}
+				if regions.held() {
+					sendRegions() // 下面的 CellsFlipped 是相对现在的世界说的
+				}
+				mu.Lock()
+				flipped := diffWorld(world, reply.World)
+				for _, cell := range flipped {
+					world[cell.Y][cell.X] = reply.World[cell.Y][cell.X]
+				}
+				values := cellValues(world, flipped, rule)
+				shown, shownValues := view.filter(flipped, values)
+				turn = reply.Turn
+				ages.flip(world, flipped, turn) // 中间那几回合看不到，新出生的细胞只能从这一回合算起
+				stats.reset(world)              // 这几回合也没有 TurnStats
+				detector.reset(world, turn)     // 也没法比较这几回合的哈希
+				currentTurn := turn
+				var frame PixelData
+				if p.FrameEvery > 0 {
+					frame = pixelData(world, turn, rule) // 世界跳了几回合，按帧画的消费者也要马上跟上
+				}
+				mu.Unlock()
+				if len(shown) > 0 {
+					c.events <- CellsFlipped{CompletedTurns: currentTurn, Cells: shown, Values: shownValues}
+				}
+				if p.FrameEvery > 0 {
+					c.events <- frame
+				}
+				c.events <- TurnComplete{CompletedTurns: currentTurn}
+				continue
 			}
+			timeouts = 0
+			rpcTime := result.elapsed
 
-			// 对比 old vs new，找出翻转的细胞，并更新 world
-			var flipped []util.Cell
-			mu.Lock()
-			for y := 0; y < p.ImageHeight; y++ {
-				for x := 0; x < p.ImageWidth; x++ {
-					if world[y][x] != newWorld[y][x] {
-						flipped = append(flipped, util.Cell{X: x, Y: y})
+			// broker 只返回翻转的细胞：逐回合应用到本地 world（供 s/q/k 和存活统计使用），并发给 SDL
+			for i, flipped := range turnFlips {
+				mu.Lock()
+				if bench != nil {
+					bench.flip(flipped, world)
+				}
+				var statsEvent TurnStats
+				if stats != nil {
+					statsEvent = stats.flip(flipped, world, turn+1)
+				}
+				period := detector.flip(flipped, world)
+				values := applyFlips(world, flipped, rule)
+				turn++
+				ages.flip(world, flipped, turn)
+				currentTurn := turn
+				var frame *PixelData
+				if bench == nil && frameDue(p, currentTurn) {
+					f := pixelData(world, currentTurn, rule)
+					frame = &f
+				}
+				mu.Unlock()
+
+				// 基准测试时不发逐回合事件，只记 CSV
+				if bench == nil {
+					emitStart := time.Now()
+					if regions.take(flipped) {
+						if regions.due() {
+							sendRegions()
+						}
+					} else if shown, values := view.filter(flipped, values); len(shown) > 0 {
+						c.events <- CellsFlipped{CompletedTurns: currentTurn, Cells: shown, Values: values}
+					}
+					if p.Stats {
+						c.events <- statsEvent
+					}
+					if frame != nil {
+						c.events <- *frame
+					}
+					c.events <- TurnComplete{CompletedTurns: currentTurn}
+					if p.Timings {
+						c.events <- result.timing(i, currentTurn, time.Since(emitStart))
 					}
 				}
+
+				// 世界开始重复：基准测试时也报告，-stop-when-stable 时停在这一回合（broker 多算的回合不要了）
+				if period > 0 {
+					logger.Info("world stabilized", "turn", currentTurn, "period", period)
+					c.events <- Stabilized{CompletedTurns: currentTurn, Period: period}
+					if p.StopWhenStable {
+						stable = true
+						break
+					}
+				}
+
+				// 定期自动保存（最后一回合由 finalizeGame 保存）。本地 world 此刻正好是这一回合的世界
+				if p.SaveEvery > 0 && currentTurn%p.SaveEvery == 0 && currentTurn < p.Turns {
+					mu.Lock()
+					saved = copyWorldInto(saved, world)
+					report := ages.report(world, currentTurn)
+					mu.Unlock()
+					saveWorld(p, c, saved, currentTurn, report)
+				}
 			}
-			world = newWorld
-			turn++
-			currentTurn := turn
-			mu.Unlock()
 
-			if len(flipped) > 0 {
-				c.events <- CellsFlipped{CompletedTurns: currentTurn, Cells: flipped}
+			if bench != nil && len(turnFlips) > 0 {
+				bench.record(turn, len(turnFlips), rpcTime)
+			}
+
+			// broker 已经暂停，一回合都没算，稍等再试
+			if len(turnFlips) == 0 {
+				time.Sleep(10 * time.Millisecond)
 			}
-			c.events <- TurnComplete{CompletedTurns: currentTurn}
 		}
 	}
 
@@ -246,31 +685,199 @@ func distributor(p Params, c distributorChannels, keyPresses <-chan rune) {
 	if !doneClosed {
 		close(done)
 	}
+	if regions.held() {
+		sendRegions()
+	}
 	mu.Lock()
-	finalWorldCopy := deepCopyWorldUint8(world)
+	saved = copyWorldInto(saved, world)
 	finalTurn := turn
+	finalAges := ages.report(world, turn)
 	mu.Unlock()
-	finalizeGame(p, c, finalWorldCopy, finalTurn)
+	finalizeGame(p, c, saved, finalTurn, finalAges)
 }
 
-// deepCopyWorldUint8 对 [][]uint8 做深拷贝
-func deepCopyWorldUint8(src [][]uint8) [][]uint8 {
-	if src == nil {
+// dialBroker：连接 broker，失败时按指数退避重试，总共最多等 p.DialWait（见 dialWait），
+// 这样控制器可以比 broker 先启动。每次失败发一个 BrokerError；最后还是连不上就 fail，返回 nil
+func dialBroker(p Params, c distributorChannels) transport.Client {
+	deadline := time.Now().Add(dialWait(p))
+	backoff := dialBackoffMin
+	for {
+		client, err := transport.DialOptions(brokerAddr(p), brokerOptions(p))
+		if err == nil {
+			return client
+		}
+		retry := time.Now().Add(backoff).Before(deadline)
+		c.events <- BrokerError{CompletedTurns: 0, Err: err, Retrying: retry}
+		if !retry {
+			logger.Error("connect to broker failed", "broker", brokerAddr(p), "err", err)
+			fail(c, 0, util.CodeBrokerUnreachable, err)
+			return nil
+		}
+		logger.Warn("connect to broker failed, retrying", "broker", brokerAddr(p), "err", err, "retry_in", backoff)
+		time.Sleep(backoff)
+		backoff = min(2*backoff, dialBackoffMax)
+	}
+}
+
+// fail：出错之后没法继续时调用：发 ErrorEvent 和 Quitting 之后关闭 events，SDL 窗口随之关闭，不会一直挂着。
+// 和 broker 无关的错误 code 为空
+func fail(c distributorChannels, turn int, code util.ErrorCode, err error) {
+	c.events <- ErrorEvent{CompletedTurns: turn, Code: code, Err: err}
+	c.events <- StateChange{turn, Quitting}
+	close(c.events)
+}
+
+// errorCode：err 的错误码。broker 返回的错误带着错误码（见 util.CodeOf），超时的调用是
+// util.CodeTimeout（见 transport.ErrTimeout）；连接断开的调用说明 broker 不在了
+func errorCode(err error) util.ErrorCode {
+	if code := util.CodeOf(err); code != "" {
+		return code
+	}
+	var netErr net.Error
+	if errors.Is(err, rpc.ErrShutdown) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.As(err, &netErr) {
+		return util.CodeBrokerUnreachable
+	}
+	return ""
+}
+
+// watchAlive：一直挂着一个 Broker.WatchAlive，把 broker 每隔 aliveInterval 推过来的存活细胞数
+// 转发出去，直到 done 关闭。broker 不支持、出错或者超时不回的话，退回按本地世界（local）定时计数。
+// 关掉 AliveCellsCount 时返回 nil（永远收不到）
+func watchAlive(p Params, client transport.Client, local func() AliveReport, done <-chan struct{}) <-chan AliveReport {
+	interval := aliveInterval(p)
+	if interval == 0 {
 		return nil
-	} //// 边界处理：，避免后续索引 panic
-	h := len(src) //// 获取原始切片的高度（行数）：h 等于外层切片的长度
-	// 创建目标切片的外层结构
-	dst := make([][]uint8, h)
-	for i := 0; i < h; i++ {
-		row := make([]uint8, len(src[i]))
-		copy(row, src[i]) //// 拷贝原始行数据到目标行：copy 是值拷贝，将 src[i] 的所有元素复制到 row
-		dst[i] = row
-	}
-	return dst //// 返回深拷贝后的完整二维切片：dst 与 src 内存完全独立，数据完全一致
+	}
+	reports := make(chan AliveReport)
+	timeout := brokerOptions(p).Timeout
+	go func() {
+		var err error
+		for err == nil {
+			var report AliveReport
+			call := client.Go("Broker.WatchAlive", AliveArgs{Interval: interval, JobID: p.Job}, &report, make(chan *rpc.Call, 1))
+			var expired <-chan time.Time
+			if timeout > 0 {
+				expired = time.After(interval + timeout)
+			}
+			select {
+			case <-call.Done:
+				err = call.Error
+			case <-expired:
+				err = fmt.Errorf("%s after %v: %w", call.ServiceMethod, interval+timeout, transport.ErrTimeout)
+			case <-done:
+				return
+			}
+			if err == nil {
+				select {
+				case reports <- report:
+				case <-done:
+					return
+				}
+			}
+		}
+
+		logger.Warn("alive counts from broker failed, counting the local world instead", "err", err)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				select {
+				case reports <- local():
+				case <-done:
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return reports
+}
+
+// attachToBroker：p.Resume 时向 broker 要当前回合和世界，拿不到或尺寸不对就返回 false，从图像重新开始
+func attachToBroker(p Params, client transport.Client) ([][]uint8, int, bool) {
+	if !p.Resume {
+		return nil, 0, false
+	}
+	var reply AttachReply
+	if err := client.Call("Broker.Attach", JobArgs{JobID: p.Job}, &reply); err != nil {
+		logger.Warn("nothing to resume on broker, starting a new simulation", "err", err)
+		return nil, 0, false
+	}
+	if len(reply.World) != p.ImageHeight || (p.ImageHeight > 0 && len(reply.World[0]) != p.ImageWidth) {
+		logger.Warn("broker simulation has a different size, starting a new simulation", "height", len(reply.World))
+		return nil, 0, false
+	}
+	if rule, _ := util.ParseRule(reply.Rule); rule != lifeRule(p) {
+		logger.Warn("broker simulation uses a different rule, starting a new simulation", "rule", reply.Rule, "want", lifeRule(p))
+		return nil, 0, false
+	}
+	logger.Info("resumed simulation from broker", "turn", reply.Turn)
+	return reply.World, reply.Turn, true
+}
+
+// restoreSnapshot：p.ResumeFrom 时读取快照，读不了或尺寸不对就返回 false，从图像重新开始
+func restoreSnapshot(p Params) ([][]uint8, int, bool) {
+	if p.ResumeFrom == "" {
+		return nil, 0, false
+	}
+	s, err := LoadSnapshot(p.ResumeFrom)
+	if err != nil {
+		logger.Warn("cannot restore snapshot, starting a new simulation", "err", err)
+		return nil, 0, false
+	}
+	if s.Params.ImageWidth != p.ImageWidth || s.Params.ImageHeight != p.ImageHeight {
+		logger.Warn("snapshot has a different size, starting a new simulation", "snapshot", p.ResumeFrom,
+			"width", s.Params.ImageWidth, "height", s.Params.ImageHeight)
+		return nil, 0, false
+	}
+	if saved, _ := util.ParseRule(s.Rule); saved != lifeRule(p) {
+		logger.Warn("snapshot uses a different rule, starting a new simulation", "snapshot", p.ResumeFrom,
+			"rule", s.Rule, "want", lifeRule(p))
+		return nil, 0, false
+	}
+	logger.Info("restored simulation from snapshot", "snapshot", p.ResumeFrom, "turn", s.Turn)
+	return s.World, s.Turn, true
+}
+
+// remoteWorld：向 broker 要 p.Job 权威的世界和回合数，失败时返回传进来的本地副本
+func remoteWorld(p Params, client transport.Client, local [][]uint8, turn int) ([][]uint8, int) {
+	var reply WorldReply
+	if err := client.Call("Broker.GetWorld", JobArgs{JobID: p.Job}, &reply); err != nil {
+		logger.Warn("get world from broker failed, saving local copy", "turn", turn, "err", err)
+		return local, turn
+	}
+	return reply.World, reply.Turn
+}
+
+// deepCopyWorldUint8 对 [][]uint8 做深拷贝，副本的行同样排在一整块缓冲区里（见 util.NewWorld）
+func deepCopyWorldUint8(src [][]uint8) [][]uint8 {
+	return util.CopyWorld(src)
+}
+
+// copyWorldInto：把 src 拷进 dst 并返回 dst，dst 大小不对（比如还是 nil）时重新分配一份
+func copyWorldInto(dst, src [][]uint8) [][]uint8 {
+	if len(dst) != len(src) || (len(src) > 0 && len(dst[0]) != len(src[0])) {
+		return deepCopyWorldUint8(src)
+	}
+	if dstCells, ok := util.Flat(dst); ok {
+		if srcCells, ok := util.Flat(src); ok {
+			copy(dstCells, srcCells)
+			return dst
+		}
+	}
+	for y := range src {
+		copy(dst[y], src[y])
+	}
+	return dst
 }
 
 // 统计存活细胞总数
 func countAlive(world [][]uint8) int {
+	if cells, ok := util.Flat(world); ok {
+		return bytes.Count(cells, []byte{255})
+	}
 	count := 0
 	for _, row := range world {
 		for _, cell := range row {
@@ -295,8 +902,9 @@ func getAliveCells(world [][]uint8) []util.Cell {
 	return alive
 }
 
-// saveWorld：写出 world，并确保 IO 完成后才发 ImageOutputComplete
-func saveWorld(p Params, c distributorChannels, world [][]uint8, turn int) {
+// saveWorld：写出 world，并确保 IO 完成后才发 ImageOutputComplete。
+// ages 不为 nil（-ages）时旁边再写一张 <文件名>-ages.png
+func saveWorld(p Params, c distributorChannels, world [][]uint8, turn int, ages *CellAges) {
 	filename := fmt.Sprintf("%dx%dx%d", p.ImageWidth, p.ImageHeight, turn)
 
 	// 1. 通知 IO 开始输出
@@ -314,16 +922,31 @@ func saveWorld(p Params, c distributorChannels, world [][]uint8, turn int) {
 	c.ioCommand <- ioCheckIdle
 	<-c.ioIdle
 
+	// -snapshot 时同时在同一目录写一份快照（世界 + 回合数 + 参数），之后可以用 -resume out/<文件名>.gob 接着跑
+	if p.Snapshot {
+		if err := SaveSnapshot(filepath.Join(outDir(p), filename+".gob"), Snapshot{Params: p, Turn: turn, World: world}); err != nil {
+			logger.Warn("save snapshot failed", "turn", turn, "err", err)
+		}
+	}
+	if ages != nil {
+		if err := saveAges(filepath.Join(outDir(p), filename+"-ages.png"), p.ImageWidth, p.ImageHeight, ages); err != nil {
+			logger.Warn("save cell ages failed", "turn", turn, "err", err)
+		}
+	}
+
 	// 4. 再发 ImageOutputComplete（TestKeyboard 会读这个文件）
 	c.events <- ImageOutputComplete{CompletedTurns: turn, Filename: filename}
 }
 
-// finalizeGame：发送 FinalTurnComplete + 保存最终世界 + Quitting
-func finalizeGame(p Params, c distributorChannels, world [][]uint8, turn int) {
+// finalizeGame：发送 CellAges（-ages 时）+ FinalTurnComplete + 保存最终世界 + Quitting
+func finalizeGame(p Params, c distributorChannels, world [][]uint8, turn int, ages *CellAges) {
+	if ages != nil {
+		c.events <- *ages
+	}
 	finalAlive := getAliveCells(world)
 	c.events <- FinalTurnComplete{CompletedTurns: turn, Alive: finalAlive}
 
-	saveWorld(p, c, world, turn)
+	saveWorld(p, c, world, turn, ages)
 
 	c.events <- StateChange{turn, Quitting}
 	close(c.events)
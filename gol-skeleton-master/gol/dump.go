@@ -0,0 +1,77 @@
+package gol
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+)
+
+// DefaultDumpSize is the widest and highest a dumped frame is when DumpFrames is given 0.
+const DefaultDumpSize = 1024
+
+// DumpFrames writes every PixelData event from events as a greyscale PNG in dir, named
+// frame-<turn>.png with the turn zero-padded so the files sort in order, until events is
+// closed. Worlds wider or higher than size pixels are shrunk by a whole factor to fit: each
+// pixel is then the average of the block of cells it stands for, so the density of a region
+// still shows. It needs no window, so a headless run subscribed to the EventBus with
+// Params.FrameEvery set leaves a visual record of itself. Frames that can't be written are
+// logged and skipped; the other events are ignored.
+func DumpFrames(events <-chan Event, dir string, size int) {
+	if size <= 0 {
+		size = DefaultDumpSize
+	}
+	for event := range events {
+		frame, ok := event.(PixelData)
+		if !ok {
+			continue
+		}
+		path := filepath.Join(dir, fmt.Sprintf("frame-%08d.png", frame.CompletedTurns))
+		if err := writeFrame(path, downsample(frame.World(), size)); err != nil {
+			logger.Warn("dump frame failed", "turn", frame.CompletedTurns, "path", path, "err", err)
+		}
+	}
+}
+
+// downsample shrinks world by the least whole factor that makes it fit in size×size pixels,
+// averaging the cells of every factor×factor block (fewer at the right and bottom edges).
+func downsample(world [][]uint8, size int) *image.Gray {
+	height, width := len(world), 0
+	if height > 0 {
+		width = len(world[0])
+	}
+	factor := max((max(width, height)+size-1)/size, 1)
+	img := image.NewGray(image.Rect(0, 0, (width+factor-1)/factor, (height+factor-1)/factor))
+	for py := 0; py < img.Rect.Dy(); py++ {
+		for px := 0; px < img.Rect.Dx(); px++ {
+			sum, cells := 0, 0
+			for y := py * factor; y < min((py+1)*factor, height); y++ {
+				for x := px * factor; x < min((px+1)*factor, width); x++ {
+					sum += int(world[y][x])
+					cells++
+				}
+			}
+			img.Pix[py*img.Stride+px] = uint8(sum / cells)
+		}
+	}
+	return img
+}
+
+// writeFrame writes img as a PNG at path.
+func writeFrame(path string, img image.Image) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	w := bufio.NewWriter(file)
+	if err := png.Encode(w, img); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return file.Close()
+}
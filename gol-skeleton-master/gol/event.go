@@ -2,6 +2,7 @@ package gol
 
 import (
 	"fmt"
+	"time"
 
 	"uk.ac.bris.cs/gameoflife/util"
 )
@@ -22,6 +23,60 @@ type AliveCellsCount struct { // implements Event
 	CellsCount     int
 }
 
+// `TurnRate` is an Event reporting how many turns per second the simulation has been completing,
+// measured since the previous `TurnRate`. It is sent just before every `AliveCellsCount`.
+type TurnRate struct { // implements Event
+	CompletedTurns int
+	TurnsPerSecond float64
+}
+
+// `CellAges` is an Event reporting how many consecutive turns every live cell has been alive:
+// Ages[i] for Cells[i], 1 for a cell that has just come alive. It is only sent when Params.Ages
+// is set, just after every `AliveCellsCount` and just before `FinalTurnComplete`.
+type CellAges struct { // implements Event
+	CompletedTurns int
+	Cells          []util.Cell
+	Ages           []int
+}
+
+// `TurnStats` is an Event reporting how the population changed in a turn: how many cells were
+// born and how many died, the net Change, and how many cells are alive afterwards, also as a
+// fraction of the world (Density). It is only sent when Params.Stats is set, before the turn's `TurnComplete`.
+type TurnStats struct { // implements Event
+	CompletedTurns int
+	Births         int
+	Deaths         int
+	Change         int
+	Alive          int
+	Density        float64
+}
+
+// `TurnTiming` is an Event breaking down where the time of a turn went, for scalability
+// analysis. RPC is the round trip of the call that brought the turn from the broker, which
+// with Params.TurnsPerCall above 1 brought Batch turns at once; Broker is how long the broker
+// took from handing the turn out to having all the results back, and SlowestWorker the worker
+// whose call took longest, SlowestWorkerTime, empty when the broker evolved the turn itself.
+// Emit is how long sending the turn's other events took, i.e. how much the consumers held up
+// the distributor. It is only sent when Params.Timings is set, right after the turn's `TurnComplete`.
+type TurnTiming struct { // implements Event
+	CompletedTurns    int
+	RPC               time.Duration
+	Batch             int
+	Broker            time.Duration
+	SlowestWorker     string
+	SlowestWorkerTime time.Duration
+	Emit              time.Duration
+}
+
+// `Stabilized` is an Event notifying the user that the world has started repeating itself: the
+// world after CompletedTurns is the same as Period turns before, so Period is 1 for a world of
+// still lifes (or an empty one) and 2 for one of blinkers. It is only sent when Params.DetectPeriod
+// is set, at most once per run, and the run stops after it if Params.StopWhenStable is set.
+type Stabilized struct { // implements Event
+	CompletedTurns int
+	Period         int
+}
+
 // `ImageOutputComplete` is an Event notifying the user about the completion of output.
 // This Event should be sent every time an image has been saved.
 type ImageOutputComplete struct { // implements Event
@@ -29,6 +84,33 @@ type ImageOutputComplete struct { // implements Event
 	Filename       string
 }
 
+// `BrokerError` is an Event notifying the user that a call to the broker failed or timed out
+// (see Params.CallTimeout). Retrying tells whether the distributor carries on; if not, it stops.
+type BrokerError struct { // implements Event
+	CompletedTurns int
+	Err            error
+	Retrying       bool
+}
+
+// `WaitingForWorkers` is an Event sent every couple of seconds while the broker holds a turn
+// until enough workers have registered (see the broker's -wait-workers), so the user can tell
+// the system is warming up rather than stuck.
+type WaitingForWorkers struct { // implements Event
+	CompletedTurns int
+	Workers        int           // registered so far
+	MinWorkers     int           // needed to evolve a turn
+	Waited         time.Duration // since the turn started waiting
+}
+
+// `ErrorEvent` is an Event notifying the user that the run has stopped because of an error.
+// Code tells broker and worker problems apart (see util.ErrorCode) and is empty for other errors.
+// It is followed by a `StateChange` to Quitting, after which the events channel is closed.
+type ErrorEvent struct { // implements Event
+	CompletedTurns int
+	Code           util.ErrorCode
+	Err            error
+}
+
 // State represents a change in the state of execution.
 type State int
 
@@ -58,9 +140,56 @@ type CellFlipped struct { // implements Event
 // You can send many times of `CellsFlipped` event in a turn, i.e., each worker could send `CellsFlipped`.
 // **Please be careful not to send `CellFlipped` and `CellsFlipped` at the same time, as they may conflict.**
 // Choose one of them.
+//
+// With a Generations rule (see Params.Rule) cells do not just toggle between dead and alive,
+// so Values then holds the value each cell in Cells has now: 255 alive, 0 dead and the greys
+// in between dying. Values is nil for two-state rules.
 type CellsFlipped struct { // implements Event
 	CompletedTurns int
 	Cells          []util.Cell
+	Values         []uint8
+}
+
+// `RegionsFlipped` is an Event sent instead of `CellsFlipped` for turns that flip more than
+// Params.FlipRegions cells. Rather than the cells that flipped it holds the rectangles of the
+// world that changed, with the value every cell in them has now (as in CellsFlipped.Values),
+// so it is no bigger than the world however many cells flipped. It may cover several turns,
+// in which case the TurnComplete events of the earlier ones came before it.
+type RegionsFlipped struct { // implements Event
+	CompletedTurns int
+	Regions        []Region
+}
+
+// Region is a Width×Height rectangle of the world with its top-left corner at (X, Y), and
+// the values of its cells row by row.
+type Region struct {
+	X, Y, Width, Height int
+	Cells               []uint8
+}
+
+// `PixelData` is an Event carrying the whole world as it is after CompletedTurns, sent every
+// Params.FrameEvery turns, at the start and whenever the world jumps ahead (e.g. catching up
+// with the broker), just before that turn's `TurnComplete`. Renderers that would rather draw
+// whole frames than keep a world of their own up to date with `CellsFlipped`, e.g. a web page or
+// a video recorder, can use it instead and ignore the flips. Frame has one bit per cell, set for
+// cells that are not dead (see util.PackedWorld); with a Generations rule Values also holds
+// every cell's value row by row, and is nil otherwise. World unpacks either.
+type PixelData struct { // implements Event
+	CompletedTurns int
+	Frame          util.PackedWorld
+	Values         []uint8
+}
+
+// `ViewportChanged` is an Event sent when Params.ViewWidth / ViewHeight make the window smaller
+// than the world: first before any `CellsFlipped`, then whenever the viewport is panned or
+// zoomed (see KeyPanLeft and friends). From then on the distributor only sends the flips of
+// the Width×Height cells from (X, Y) on, each drawn Zoom pixels to a side. Right after it
+// comes a `CellsFlipped` with every cell in the new view that isn't dead, so consumers clear
+// what they show and start again from that.
+type ViewportChanged struct { // implements Event
+	CompletedTurns      int
+	X, Y, Width, Height int
+	Zoom                int
 }
 
 // `TurnComplete` is an Event notifying the GUI about turn completion.
@@ -109,6 +238,84 @@ func (event AliveCellsCount) GetCompletedTurns() int {
 	return event.CompletedTurns
 }
 
+func (event TurnRate) String() string {
+	return fmt.Sprintf("%.1f turns/sec", event.TurnsPerSecond)
+}
+
+func (event TurnRate) GetCompletedTurns() int {
+	return event.CompletedTurns
+}
+
+func (event BrokerError) String() string {
+	if event.Retrying {
+		return fmt.Sprintf("Broker error, retrying: %v", event.Err)
+	}
+	return fmt.Sprintf("Broker error: %v", event.Err)
+}
+
+func (event BrokerError) GetCompletedTurns() int {
+	return event.CompletedTurns
+}
+
+func (event WaitingForWorkers) String() string {
+	return fmt.Sprintf("Waiting for workers (%d of %d registered, %v)", event.Workers, event.MinWorkers, event.Waited.Round(time.Second))
+}
+
+func (event WaitingForWorkers) GetCompletedTurns() int {
+	return event.CompletedTurns
+}
+
+func (event ErrorEvent) String() string {
+	// errors passed back from the broker already carry their code in the text
+	if event.Code == "" || util.CodeOf(event.Err) == event.Code {
+		return fmt.Sprintf("Error: %v", event.Err)
+	}
+	return fmt.Sprintf("Error (%v): %v", event.Code, event.Err)
+}
+
+func (event ErrorEvent) GetCompletedTurns() int {
+	return event.CompletedTurns
+}
+
+func (event CellAges) String() string {
+	oldest := 0
+	for _, age := range event.Ages {
+		oldest = max(oldest, age)
+	}
+	return fmt.Sprintf("Oldest cell %v turns", oldest)
+}
+
+func (event CellAges) GetCompletedTurns() int {
+	return event.CompletedTurns
+}
+
+func (event TurnStats) String() string {
+	return fmt.Sprintf("Births %v Deaths %v Change %+v Density %.3f", event.Births, event.Deaths, event.Change, event.Density)
+}
+
+func (event TurnStats) GetCompletedTurns() int {
+	return event.CompletedTurns
+}
+
+func (event TurnTiming) String() string {
+	return fmt.Sprintf("RPC %v (%d turns) Broker %v Slowest %v %v Emit %v", event.RPC, event.Batch, event.Broker, event.SlowestWorker, event.SlowestWorkerTime, event.Emit)
+}
+
+func (event TurnTiming) GetCompletedTurns() int {
+	return event.CompletedTurns
+}
+
+func (event Stabilized) String() string {
+	if event.Period == 1 {
+		return "Stabilized, the world no longer changes"
+	}
+	return fmt.Sprintf("Stabilized, the world repeats every %v turns", event.Period)
+}
+
+func (event Stabilized) GetCompletedTurns() int {
+	return event.CompletedTurns
+}
+
 func (event ImageOutputComplete) String() string {
 	return fmt.Sprintf("File %v.pgm output done", event.Filename)
 }
@@ -133,6 +340,30 @@ func (event CellsFlipped) GetCompletedTurns() int {
 	return event.CompletedTurns
 }
 
+func (event RegionsFlipped) String() string {
+	return fmt.Sprintf("%d regions changed", len(event.Regions))
+}
+
+func (event RegionsFlipped) GetCompletedTurns() int {
+	return event.CompletedTurns
+}
+
+func (event PixelData) String() string {
+	return fmt.Sprintf("%dx%d frame", event.Frame.Width, event.Frame.Height)
+}
+
+func (event PixelData) GetCompletedTurns() int {
+	return event.CompletedTurns
+}
+
+func (event ViewportChanged) String() string {
+	return fmt.Sprintf("viewing %dx%d cells at (%d, %d), zoom %dx", event.Width, event.Height, event.X, event.Y, event.Zoom)
+}
+
+func (event ViewportChanged) GetCompletedTurns() int {
+	return event.CompletedTurns
+}
+
 func (event TurnComplete) String() string {
 	return ""
 }
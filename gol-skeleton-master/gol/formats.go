@@ -0,0 +1,262 @@
+package gol
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// worldFormat reads and writes worlds in one file format. Worlds are rows of
+// 0 (dead) / 255 (alive) bytes, as everywhere else. Formats that can record the rule
+// the world evolves by write the one they are given.
+type worldFormat struct {
+	decode func(data []byte) ([][]uint8, error)
+	encode func(w io.Writer, world [][]uint8, rule util.Rule) error
+
+	// pattern formats describe a pattern rather than a whole world: what decode returns
+	// is the pattern's bounding box, which is placed into the world (see placePattern).
+	pattern bool
+}
+
+// worldFormats are the formats Params.Format and Params.Input accept, by file extension.
+var worldFormats = map[string]worldFormat{
+	"pgm":   {decode: decodePgm, encode: encodePgm},
+	"png":   {decode: decodePng, encode: encodePng},
+	"rle":   {decode: decodeRle, encode: encodeRle, pattern: true},
+	"cells": {decode: decodeCells, encode: encodeCells, pattern: true},
+	"lif":   {decode: decodeLife106, encode: encodeLife106, pattern: true},
+}
+
+// defaultFormat is what worlds are saved as when Params.Format is empty.
+const defaultFormat = "pgm"
+
+// maxPatternCells bounds the size of the world a pattern file may describe, 16384x16384 cells,
+// well beyond the biggest image in images/. Pattern formats take their size from a header or
+// from their longest row rather than from how much data there is, so without it a one-line
+// file could ask for a terabyte.
+const maxPatternCells = 1 << 28
+
+// checkPatternSize returns an error, prefixed by the format's name, if a width×height world is
+// empty or bigger than maxPatternCells.
+func checkPatternSize(format string, width, height int) error {
+	if width <= 0 || height <= 0 {
+		return fmt.Errorf("%s: bad pattern size %dx%d", format, width, height)
+	}
+	if width > maxPatternCells/height {
+		return fmt.Errorf("%s: %dx%d pattern is bigger than the %d cells allowed", format, width, height, maxPatternCells)
+	}
+	return nil
+}
+
+// Formats lists the names accepted by Params.Format, for flag help and errors.
+func Formats() []string {
+	names := make([]string, 0, len(worldFormats))
+	for name := range worldFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// outputFormat is the format worlds are saved in for p, and its name (the file extension).
+func outputFormat(p Params) (string, worldFormat) {
+	name := strings.ToLower(p.Format)
+	if name == "" {
+		name = defaultFormat
+	}
+	format, ok := worldFormats[name]
+	if !ok {
+		panic(fmt.Sprintf("unknown output format %q (want one of %s)", p.Format, strings.Join(Formats(), ", ")))
+	}
+	return name, format
+}
+
+// ReadWorld reads the world in the file at path, in the format its extension names.
+// For pattern formats it is the pattern's bounding box, which may be empty, so placing it
+// places nothing.
+func ReadWorld(path string) ([][]uint8, error) {
+	format, err := inputFormat(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	world, err := format.decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if (len(world) == 0 || len(world[0]) == 0) && !format.pattern {
+		return nil, fmt.Errorf("%s: empty world", path)
+	}
+	return world, nil
+}
+
+// WriteWorld writes world to the file at path, in the format its extension names.
+// Formats that record the rule record rule.
+func WriteWorld(path string, world [][]uint8, rule util.Rule) error {
+	format, err := inputFormat(path)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := format.encode(&buf, world, rule); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// inputFormat is the format of the file at path, from its extension.
+func inputFormat(path string) (worldFormat, error) {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+	format, ok := worldFormats[ext]
+	if !ok {
+		return format, fmt.Errorf("%s: unknown format %q (want one of %s)", path, ext, strings.Join(Formats(), ", "))
+	}
+	return format, nil
+}
+
+// IsPattern reports whether the file at path holds a pattern (placed into a world of
+// ImageWidth×ImageHeight at OffsetX, OffsetY) rather than a whole world.
+func IsPattern(path string) bool {
+	format, err := inputFormat(path)
+	return err == nil && format.pattern
+}
+
+// ImageSize reads the width and height of the world in the file at path, so a run can be
+// sized to fit an arbitrary input file. For patterns it is the pattern's bounding box.
+func ImageSize(path string) (width, height int, err error) {
+	world, err := ReadWorld(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(world) == 0 || len(world[0]) == 0 {
+		return 0, 0, fmt.Errorf("%s: empty pattern has no size", path)
+	}
+	return len(world[0]), len(world), nil
+}
+
+// placePattern copies pattern into an empty width×height world with its top-left corner at
+// (x, y), wrapping around the edges like the world itself does.
+func placePattern(pattern [][]uint8, width, height, x, y int) [][]uint8 {
+	world := util.NewWorld(width, height)
+	stampPattern(world, pattern, x, y)
+	return world
+}
+
+// stampPattern brings the live cells of pattern to life in world with its top-left corner at
+// (x, y), wrapping around the edges. The cells under the pattern's dead cells are left alone.
+func stampPattern(world, pattern [][]uint8, x, y int) {
+	height, width := len(world), len(world[0])
+	for py, row := range pattern {
+		for px, cell := range row {
+			if cell != 0 {
+				world[((y+py)%height+height)%height][((x+px)%width+width)%width] = 255
+			}
+		}
+	}
+}
+
+// decodePgm reads a binary (P5) PGM image with a maxval of 255.
+// Comments (# to end of line) are allowed between header fields.
+func decodePgm(data []byte) ([][]uint8, error) {
+	var fields [4]string
+	for i := range fields {
+		// Skip whitespace and comments, then read one token.
+		for len(data) > 0 {
+			if data[0] == '#' {
+				if end := bytes.IndexByte(data, '\n'); end >= 0 {
+					data = data[end:]
+				} else {
+					data = nil
+				}
+				continue
+			}
+			if !isPgmSpace(data[0]) {
+				break
+			}
+			data = data[1:]
+		}
+		end := 0
+		for end < len(data) && !isPgmSpace(data[end]) {
+			end++
+		}
+		fields[i], data = string(data[:end]), data[end:]
+	}
+
+	if fields[0] != "P5" {
+		return nil, fmt.Errorf("not a pgm file")
+	}
+	width, errW := strconv.Atoi(fields[1])
+	height, errH := strconv.Atoi(fields[2])
+	if errW != nil || errH != nil || width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("bad pgm size %q x %q", fields[1], fields[2])
+	}
+	if maxval, _ := strconv.Atoi(fields[3]); maxval != 255 {
+		return nil, fmt.Errorf("incorrect pgm maxval/bit depth")
+	}
+	// Exactly one whitespace byte separates the header from the pixels.
+	if len(data) < 1+width*height {
+		return nil, fmt.Errorf("pgm has fewer than %dx%d pixels", width, height)
+	}
+	world := util.NewWorld(width, height)
+	cells, _ := util.Flat(world)
+	copy(cells, data[1:1+width*height])
+	return world, nil
+}
+
+func isPgmSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\v' || b == '\f'
+}
+
+// encodePgm writes a binary (P5) PGM image.
+func encodePgm(w io.Writer, world [][]uint8, _ util.Rule) error {
+	if _, err := fmt.Fprintf(w, "P5\n%d %d\n255\n", len(world[0]), len(world)); err != nil {
+		return err
+	}
+	for _, row := range world {
+		if _, err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodePng reads a PNG image. Pixels brighter than mid-grey are alive, so black-and-white
+// images map white to alive, as in PGM files; transparent pixels are dead.
+func decodePng(data []byte) ([][]uint8, error) {
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	bounds := img.Bounds()
+	world := util.NewWorld(bounds.Dx(), bounds.Dy())
+	for y := range world {
+		for x := range world[y] {
+			if color.GrayModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.Gray).Y >= 128 {
+				world[y][x] = 255
+			}
+		}
+	}
+	return world, nil
+}
+
+// encodePng writes an 8-bit greyscale PNG image, white for alive cells.
+func encodePng(w io.Writer, world [][]uint8, _ util.Rule) error {
+	img := image.NewGray(image.Rect(0, 0, len(world[0]), len(world)))
+	for y, row := range world {
+		copy(img.Pix[y*img.Stride:], row)
+	}
+	return png.Encode(w, img)
+}
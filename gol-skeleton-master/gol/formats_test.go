@@ -0,0 +1,170 @@
+package gol
+
+import (
+	"bytes"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// pattern builds a world from rows of 'O' (alive) and '.' (dead).
+func pattern(rows ...string) [][]uint8 {
+	world := util.NewWorld(len(rows[0]), len(rows))
+	for y, row := range rows {
+		for x := range row {
+			if row[x] == 'O' {
+				world[y][x] = 255
+			}
+		}
+	}
+	return world
+}
+
+func equalWorld(a, b [][]uint8) bool {
+	return slices.EqualFunc(a, b, func(x, y []uint8) bool { return slices.Equal(x, y) })
+}
+
+// decodeTest is one file for a decoder: it decodes to want, or fails with an error containing
+// err.
+type decodeTest struct {
+	name string
+	data string
+	want [][]uint8
+	err  string
+}
+
+func runDecodeTests(t *testing.T, decode func([]byte) ([][]uint8, error), tests []decodeTest) {
+	t.Helper()
+	for _, test := range tests {
+		got, err := decode([]byte(test.data))
+		switch {
+		case test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)):
+			t.Errorf("%s: got error %v, want one containing %q", test.name, err, test.err)
+		case test.err == "" && err != nil:
+			t.Errorf("%s: %v", test.name, err)
+		case test.err == "" && !equalWorld(got, test.want):
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+// TestDecodeRle tests RLE patterns, well-formed and not, including headers asking for more
+// cells than anyone could allocate.
+func TestDecodeRle(t *testing.T) {
+	runDecodeTests(t, decodeRle, []decodeTest{
+		{name: "glider", data: "#N Glider\n#C a comment\nx = 3, y = 3, rule = B3/S23\nbob$2bo$3o!\n", want: pattern(".O.", "..O", "OOO")},
+		{name: "no rule", data: "x=3,y=1\n3o!", want: pattern("OOO")},
+		{name: "body over lines", data: "x = 5, y = 2\n2o\n3b$\n5o!", want: pattern("OO...", "OOOOO")},
+		{name: "blank rows", data: "x = 2, y = 4\no2$bo!", want: pattern("O.", "..", ".O", "..")},
+		{name: "other letters alive", data: "x = 2, y = 1\nAz!", want: pattern("OO")},
+		{name: "text after end", data: "x = 1, y = 1\no! ignored", want: pattern("O")},
+		{name: "no header", data: "#C only a comment\n", err: "no header"},
+		{name: "bad header", data: "x 3, y 3\no!", err: "bad header"},
+		{name: "bad number", data: "x = three, y = 3\no!", err: "bad header"},
+		{name: "no height", data: "x = 3\no!", err: "bad pattern size"},
+		{name: "zero size", data: "x = 0, y = 3\n!", err: "bad pattern size"},
+		{name: "bad rule", data: "x = 1, y = 1, rule = B9/S\no!", err: "rle"},
+		{name: "outside the box", data: "x = 2, y = 1\n3o!", err: "outside"},
+		{name: "below the box", data: "x = 2, y = 1\n$o!", err: "outside"},
+		{name: "unexpected", data: "x = 2, y = 1\no?!", err: "unexpected"},
+		{name: "no end", data: "x = 2, y = 1\n2o", err: "missing '!'"},
+		{name: "huge header", data: "x = 1000000, y = 1000000\no!", err: "bigger than"},
+		{name: "overflowing header", data: "x = 9223372036854775807, y = 2\no!", err: "bigger than"},
+		{name: "huge count", data: "x = 2, y = 1\n99999999999999999999o!", err: "too large"},
+	})
+}
+
+// TestDecodeCells tests plaintext patterns, well-formed and not.
+func TestDecodeCells(t *testing.T) {
+	runDecodeTests(t, decodeCells, []decodeTest{
+		{name: "glider", data: "!Name: Glider\n!\n.O.\n..O\nOOO\n", want: pattern(".O.", "..O", "OOO")},
+		{name: "short rows", data: "O\n..O\n\n.O", want: pattern("O..", "..O", "...", ".O.")},
+		{name: "trailing blank lines", data: "OO\r\n\n\n", want: pattern("OO")},
+		{name: "other live marks", data: "o*O", want: pattern("OOO")},
+		{name: "empty", data: "", err: "empty pattern"},
+		{name: "only comments", data: "!Name: nothing\n", err: "empty pattern"},
+		{name: "unexpected", data: ".O.\n.X.\n", err: "unexpected 'X' in row 2"},
+		{name: "huge", data: strings.Repeat(".", 20000) + strings.Repeat("\n.", 20000), err: "bigger than"},
+	})
+}
+
+// TestDecodeLife106 tests Life 1.06 patterns, well-formed and not.
+func TestDecodeLife106(t *testing.T) {
+	runDecodeTests(t, decodeLife106, []decodeTest{
+		{name: "glider", data: "#Life 1.06\n1 0\n2 1\n0 2\n1 2\n2 2\n", want: pattern(".O.", "..O", "OOO")},
+		{name: "negative", data: "#Life 1.06\n#D a comment\n-1 -1\n\n 1  0 \n", want: pattern("O..", "..O")},
+		{name: "no cells", data: "#Life 1.06\n", want: nil},
+		{name: "sized", data: "#Life 1.06\n#S 4 3\n2 1\n3 2\n", want: pattern("....", "..O.", "...O")},
+		{name: "sized without cells", data: "#Life 1.06\n#S 2 1\n", want: pattern("..")},
+		{name: "size after cells", data: "#Life 1.06\n1 1\n#S 3 2\n", want: pattern("...", ".O.")},
+		{name: "no header", data: "0 0\n", err: "header"},
+		{name: "bad size", data: "#Life 1.06\n#S 3\n", err: "line 2"},
+		{name: "zero size", data: "#Life 1.06\n#S 0 3\n", err: "bad pattern size"},
+		{name: "huge size", data: "#Life 1.06\n#S 1000000 1000000\n", err: "bigger than"},
+		{name: "outside the size", data: "#Life 1.06\n#S 2 2\n2 0\n", err: "outside"},
+		{name: "negative in the size", data: "#Life 1.06\n#S 2 2\n0 -1\n", err: "outside"},
+		{name: "far apart", data: "#Life 1.06\n0 0\n1000000 1000000\n", err: "bigger than"},
+		{name: "overflowing", data: "#Life 1.06\n-9223372036854775807 0\n9223372036854775807 0\n", err: "bad pattern size"},
+		{name: "one number", data: "#Life 1.06\n1\n", err: "line 2"},
+		{name: "not numbers", data: "#Life 1.06\n0 0\na b\n", err: "line 3"},
+	})
+}
+
+// TestFormatRoundTrip tests that every format reads back the world it wrote, whatever its
+// size, including ones with live cells on every edge and nothing alive at all.
+func TestFormatRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	worlds := [][][]uint8{pattern("O"), pattern("."), pattern("OO", "OO"), pattern("....", "....", "...."), pattern("O...O", ".....", "O...O"),
+		pattern(".....", "...OO", "....O", ".....")}
+	for _, size := range [][2]int{{7, 3}, {64, 64}, {100, 9}} {
+		world := util.NewWorld(size[0], size[1])
+		for y := range world {
+			for x := range world[y] {
+				if r.Intn(2) == 0 {
+					world[y][x] = 255
+				}
+			}
+		}
+		worlds = append(worlds, world)
+	}
+	for _, name := range Formats() {
+		format := worldFormats[name]
+		for _, world := range worlds {
+			var buf bytes.Buffer
+			if err := format.encode(&buf, world, util.Conway); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			got, err := format.decode(buf.Bytes())
+			if err != nil {
+				t.Fatalf("%s %dx%d: %v\n%s", name, len(world[0]), len(world), err, buf.String())
+			}
+			if !equalWorld(got, world) {
+				t.Errorf("%s %dx%d: read back a different world\n%s", name, len(world[0]), len(world), buf.String())
+			}
+		}
+	}
+}
+
+// TestReadEmptyPattern tests that a pattern with no live cells can be read and placed, but not
+// used to size a world.
+func TestReadEmptyPattern(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.lif")
+	if err := os.WriteFile(path, []byte(life106Header+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	pattern, err := ReadWorld(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if world := placePattern(pattern, 3, 2, 1, 1); !equalWorld(world, util.NewWorld(3, 2)) {
+		t.Errorf("placing an empty pattern gave %v", world)
+	}
+	if _, _, err := ImageSize(path); err == nil {
+		t.Errorf("expected an error sizing a world to an empty pattern")
+	}
+}
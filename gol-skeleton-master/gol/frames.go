@@ -0,0 +1,34 @@
+package gol
+
+import "uk.ac.bris.cs/gameoflife/util"
+
+// frameDue reports whether a PixelData event is due after turn, every Params.FrameEvery turns.
+func frameDue(p Params, turn int) bool {
+	return p.FrameEvery > 0 && turn%p.FrameEvery == 0
+}
+
+// pixelData returns the PixelData event for world as it is after turn. It copies what it
+// needs, so world can go on changing once it returns.
+func pixelData(world [][]uint8, turn int, rule util.Rule) PixelData {
+	frame := PixelData{CompletedTurns: turn, Frame: util.Pack(world)}
+	if rule.Generations() {
+		frame.Values = make([]uint8, 0, frame.Frame.Width*frame.Frame.Height)
+		for _, row := range world {
+			frame.Values = append(frame.Values, row...)
+		}
+	}
+	return frame
+}
+
+// World returns the frame as a world of 0 (dead) / 255 (alive) bytes, with the greys of dying
+// cells for Generations rules.
+func (event PixelData) World() [][]uint8 {
+	if event.Values == nil {
+		return event.Frame.Unpack()
+	}
+	world := util.NewWorld(event.Frame.Width, event.Frame.Height)
+	for y := range world {
+		copy(world[y], event.Values[y*event.Frame.Width:])
+	}
+	return world
+}
@@ -1,11 +1,300 @@
 package gol
 
+import (
+	"os"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/transport"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
 // Params provides the details of how to run the Game of Life and which image to load.
 type Params struct {
 	Turns       int
 	Threads     int
 	ImageWidth  int
 	ImageHeight int
+	BrokerAddr  string // host:port (or grpc://host:port) of the broker; empty falls back to $GOL_BROKER_ADDR, then defaultBrokerAddr
+	Token       string // shared secret for a broker started with -token; empty falls back to $GOL_TOKEN
+	Compress    string // compress traffic to the broker, e.g. "flate" (worth it over a WAN link); empty falls back to $GOL_COMPRESS
+
+	// TCP tunes the connection to the broker: keep-alives, TCP_NODELAY and buffer sizes. Large
+	// worlds over a high-latency link want bigger buffers than the defaults. The zero value keeps them.
+	TCP transport.TCPOptions
+
+	// Job names the simulation on the broker, which can run several side by side, each with its own
+	// world, turn and workers. Distributors and observers with the same Job share one simulation;
+	// empty is the broker's default one.
+	Job string
+
+	// TurnsPerCall is how many turns the broker evolves per RPC; values below 2 mean one turn per call.
+	// Larger batches save round trips but keypresses are only handled between batches.
+	TurnsPerCall int
+
+	// Pipeline is how many calls advancing the broker the distributor keeps in flight, so the next
+	// turns are already being evolved while it reports the last ones; values below 2 mean one at a time.
+	Pipeline int
+
+	// MaxBacklog caps how many turns the distributor has asked the broker for and not yet passed on
+	// as events, so that with a deep Pipeline and big TurnsPerCall a slow consumer or a slow broker
+	// can't make replies pile up in memory: calls are made smaller, or held back, to stay within it.
+	// 0 means no limit beyond Pipeline × TurnsPerCall. Turns still in the EventQueue count too.
+	MaxBacklog int
+
+	// EventQueue is how many events are queued between the distributor and the events channel, so
+	// that a consumer stalling for a moment doesn't stop turns being evolved and keypresses handled.
+	// 0 means 1024 and a negative queue sends on the channel directly. EventOverflow is what happens
+	// when it is full, one of OverflowPolicies(): "block" (also what the empty string means) holds
+	// the distributor up until there is room, "drop" drops the oldest progress report, e.g. a
+	// TurnComplete, instead, and "coalesce" also merges frames that queue up, so a slow consumer
+	// shows the latest turn rather than replaying every one. See eventQueue.
+	EventQueue    int
+	EventOverflow string
+
+	// Resume takes over the simulation the broker kept evolving after the last controller pressed 'q',
+	// instead of loading the image and starting from turn 0. Falls back to a new simulation if there is none.
+	Resume bool
+
+	// ResumeFrom continues from a snapshot file written by an earlier run (see SaveSnapshot)
+	// instead of loading the image; the broker carries on from the snapshot's turn.
+	ResumeFrom string
+
+	// Snapshot also writes a snapshot (see SaveSnapshot) next to every image the run saves, named
+	// like the image with .gob added, so that ResumeFrom can carry on from it. Off by default:
+	// a snapshot is as big as the world.
+	Snapshot bool
+
+	// Input is the image to start from, in any of Formats() (chosen by its extension). Empty loads
+	// images/<ImageWidth>x<ImageHeight>.pgm; otherwise ImageWidth and ImageHeight have to match
+	// the image (see ImageSize). Patterns (see IsPattern) are instead placed into an otherwise
+	// empty ImageWidth×ImageHeight world with their top-left corner at (OffsetX, OffsetY).
+	Input string
+
+	OffsetX, OffsetY int
+
+	// Random, if set, fills the ImageWidth×ImageHeight world pseudo-randomly instead of loading
+	// Input (see RandomWorld).
+	Random *RandomWorld
+
+	// Place stamps patterns into the initial world, whichever way it was made, or into an empty
+	// one if neither Input nor Random is set (see Placement).
+	Place []Placement
+
+	// Boundary is what cells on the edge of the world see beyond it: the world wraps around
+	// (util.BoundaryTorus, also what the empty string means), is surrounded by dead cells
+	// (util.BoundaryDead) or is reflected at its edges (util.BoundaryMirror).
+	Boundary util.Boundary
+
+	// Rule is the rule the world evolves by in B/S notation (see util.ParseRule), e.g. "B36/S23"
+	// for HighLife, or B/S/C notation for Generations rules, e.g. "B2/S345/C4" for Star Wars,
+	// whose dying cells are the greys between 0 and 255 (and so saved as such in PGM images).
+	// The name of one of util.Presets(), e.g. "highlife", works too.
+	// Empty means Conway's Game of Life, B3/S23.
+	Rule string
+
+	// Format is the format worlds are saved in on 's', 'q' and completion, one of Formats().
+	// Empty means pgm.
+	Format string
+
+	// OutDir is the directory worlds and snapshots are saved in, created if need be.
+	// Empty means out, so that concurrent experiments can each be given their own.
+	OutDir string
+
+	// SaveEvery saves the world (and a snapshot) every SaveEvery turns, as if 's' had been pressed,
+	// so long unattended runs leave intermediate results behind. 0 only saves on 's', 'q' and completion.
+	SaveEvery int
+
+	// MaxFPS limits how many turns per second the distributor asks the broker for, so small
+	// worlds can be watched; '+' and '-' double and halve it while running. 0 means no limit.
+	MaxFPS int
+
+	// AliveInterval is how often AliveCellsCount events are sent. 0 means every 2 seconds,
+	// and a negative interval turns them off (e.g. for benchmarks).
+	AliveInterval time.Duration
+
+	// Ages tracks how many consecutive turns every cell has been alive: CellAges events are sent
+	// along with AliveCellsCount and on completion, and every saved world gets a colour PNG of
+	// the ages next to it, <name>-ages.png (see saveAges).
+	Ages bool
+
+	// Stats sends a TurnStats event every turn with its births, deaths and density, and StatsCSV,
+	// if set, writes the same figures to this CSV file, one row per turn (see statsRecorder).
+	Stats    bool
+	StatsCSV string
+
+	// Timings sends a TurnTiming event after every turn with how long its call to the broker,
+	// the broker, its slowest worker and sending its events took.
+	Timings bool
+
+	// FlipRegions coalesces the flips of any turn flipping more than this many cells into
+	// RegionsFlipped events, sent at most about once a frame, instead of CellsFlipped ones, for
+	// huge worlds where the flipped cells would take more memory than the world (see
+	// flipRegions). 0 always sends CellsFlipped.
+	FlipRegions int
+
+	// FrameEvery sends a PixelData event with the whole world every FrameEvery turns, for renderers
+	// that would rather draw frames than apply CellsFlipped. 0 sends none.
+	FrameEvery int
+
+	// ViewWidth and ViewHeight make the window showing the world this many pixels wide and high
+	// when the world is bigger; 0 fits the world. Only the flips of the part of the world in view
+	// are sent then, and the arrow keys pan the view and z / x zoom it (see viewport).
+	ViewWidth  int
+	ViewHeight int
+
+	// DetectPeriod watches for the world repeating itself within this many turns, e.g. 1 for
+	// still lifes, 2 for blinkers, and sends a Stabilized event when it does (see stabilityDetector).
+	// StopWhenStable then ends the run early, as if it had reached Turns. 0 turns detection off.
+	DetectPeriod   int
+	StopWhenStable bool
+
+	// Benchmark runs without the per-turn CellsFlipped / TurnComplete events and AliveCellsCount
+	// reports, and instead writes the wall time, RPC latency and alive count of every call to the
+	// broker to this CSV file (see benchRecorder).
+	Benchmark string
+
+	// CallTimeout bounds every call to the broker. 0 means defaultCallTimeout and a negative
+	// timeout waits forever. A call that times out is reported with a BrokerError event.
+	CallTimeout time.Duration
+
+	// CallRetries is how many timed-out turns in a row the distributor retries, after catching up
+	// with whatever the broker did complete, before giving up. 0 gives up on the first timeout.
+	CallRetries int
+
+	// DialWait is how long the distributor keeps retrying, with exponential backoff, when the broker
+	// cannot be reached at startup, so it can be started before the broker. 0 means defaultDialWait
+	// and a negative wait tries once. Every failed attempt is reported with a BrokerError event.
+	DialWait time.Duration
+
+	// Local runs the broker in-process (see LocalBroker) on a free port instead of dialling BrokerAddr,
+	// and stops it on exit, so no broker or worker processes are needed.
+	Local bool
+
+	// Engine is how the in-process broker evolves the world, one of Engines(): "bytes" (also what
+	// the empty string means) works out every cell every turn, and "hashlife" keeps the world as a
+	// HashLife quadtree (see package hashlife), so repetitive or sparse worlds run millions of turns
+	// quickly. HashLife needs a square torus with a power-of-two side and B3/S23; other worlds fall
+	// back to bytes. Only used with Local: a remote broker is given its engine with its own -engine.
+	Engine string
+
+	// Observe attaches read-only to whatever simulation the broker is running: turns evolved by the
+	// controlling distributor are shown but only 's' and 'q' keypresses are handled. A controller
+	// pressing 'v' becomes such an observer, leaving the broker to evolve the rest of the turns.
+	Observe bool
+}
+
+// defaultBrokerAddr is the broker dialled when neither Params.BrokerAddr nor $GOL_BROKER_ADDR is set.
+const defaultBrokerAddr = "54.87.214.152:8080"
+
+// defaultCallTimeout bounds calls to the broker when Params.CallTimeout is 0. It is generous:
+// a call can carry a whole world, or a batch of TurnsPerCall turns of a big one.
+const defaultCallTimeout = time.Minute
+
+// defaultDialWait is how long connecting to the broker is retried when Params.DialWait is 0.
+const defaultDialWait = 30 * time.Second
+
+// dialWait resolves how long connecting to the broker is retried.
+func dialWait(p Params) time.Duration {
+	if p.DialWait == 0 {
+		return defaultDialWait
+	}
+	return max(p.DialWait, 0)
+}
+
+// defaultAliveInterval is how often AliveCellsCount events are sent when Params.AliveInterval is 0.
+const defaultAliveInterval = 2 * time.Second
+
+// aliveInterval returns how often AliveCellsCount events are sent, or 0 when they are turned
+// off, as they are for benchmarks.
+func aliveInterval(p Params) time.Duration {
+	switch {
+	case p.AliveInterval < 0 || p.Benchmark != "":
+		return 0
+	case p.AliveInterval == 0:
+		return defaultAliveInterval
+	}
+	return p.AliveInterval
+}
+
+// aliveTicks returns a channel that ticks whenever an AliveCellsCount event is due, and a
+// function to stop it. The channel is nil, so never ready, when the events are turned off.
+func aliveTicks(p Params) (<-chan time.Time, func()) {
+	interval := aliveInterval(p)
+	if interval == 0 {
+		return nil, func() {}
+	}
+	ticker := time.NewTicker(interval)
+	return ticker.C, ticker.Stop
+}
+
+// turnRateMeter measures the TurnRate between successive calls of next.
+type turnRateMeter struct {
+	turn int
+	at   time.Time
+}
+
+func newTurnRateMeter(turn int) *turnRateMeter {
+	return &turnRateMeter{turn: turn, at: time.Now()}
+}
+
+// next returns the TurnRate event for turn, completed now.
+func (m *turnRateMeter) next(turn int) TurnRate {
+	now := time.Now()
+	rate := TurnRate{CompletedTurns: turn}
+	if elapsed := now.Sub(m.at).Seconds(); elapsed > 0 {
+		rate.TurnsPerSecond = float64(turn-m.turn) / elapsed
+	}
+	m.turn, m.at = turn, now
+	return rate
+}
+
+// lifeRule resolves the rule p evolves by. An invalid Params.Rule is the zero Rule here, but
+// the broker refuses to start a simulation with it.
+func lifeRule(p Params) util.Rule {
+	rule, _ := util.ParseRule(p.Rule)
+	return rule
+}
+
+// defaultOutDir is where worlds are saved when Params.OutDir is empty.
+const defaultOutDir = "out"
+
+// outDir resolves the directory worlds and snapshots are saved in.
+func outDir(p Params) string {
+	if p.OutDir != "" {
+		return p.OutDir
+	}
+	return defaultOutDir
+}
+
+// brokerAddr resolves which broker the distributor should connect to.
+func brokerAddr(p Params) string {
+	if p.BrokerAddr != "" {
+		return p.BrokerAddr
+	}
+	if addr := os.Getenv("GOL_BROKER_ADDR"); addr != "" {
+		return addr
+	}
+	return defaultBrokerAddr
+}
+
+// brokerOptions resolves the shared secret, compression, call timeout and TCP tuning used on the broker connection.
+func brokerOptions(p Params) transport.Options {
+	opts := transport.Options{Token: p.Token, Compress: p.Compress, Timeout: max(p.CallTimeout, 0), TCP: p.TCP}
+	if p.CallTimeout == 0 {
+		opts.Timeout = defaultCallTimeout
+	}
+	if p.Local {
+		// The in-process broker neither checks tokens nor compresses.
+		opts.Token, opts.Compress = "", ""
+		return opts
+	}
+	if opts.Token == "" {
+		opts.Token = os.Getenv("GOL_TOKEN")
+	}
+	if opts.Compress == "" {
+		opts.Compress = os.Getenv("GOL_COMPRESS")
+	}
+	return opts
 }
 
 // Run starts the processing of Game of Life. It should initialise channels and goroutines.
@@ -33,5 +322,15 @@ func Run(p Params, events chan<- Event, keyPresses <-chan rune) {
 		ioOutput:   ioOutput,
 		ioInput:    ioInput,
 	}
+	// 事件先进队列，由单独的 goroutine 发给 events，消费者卡一下不会卡住回合和按键
+	if queue := startEventQueue(p, events); queue != nil {
+		distributorChannels.events = queue.in
+		distributorChannels.queue = queue
+		defer func() { <-queue.done }()
+	}
+	if p.Observe {
+		observer(p, distributorChannels, keyPresses)
+		return
+	}
 	distributor(p, distributorChannels, keyPresses)
-}
\ No newline at end of file
+}
@@ -1,11 +1,11 @@
 package gol
 
 import (
+	"bufio"
 	"fmt"
 	"log"
 	"os"
-	"strconv"
-	"strings"
+	"path/filepath"
 
 	"uk.ac.bris.cs/gameoflife/util"
 )
@@ -40,25 +40,17 @@ const (
 	ioCheckIdle
 )
 
-// writePgmImage receives an array of bytes and writes it to a pgm file.
-func (io *ioState) writePgmImage() {
-	_ = os.Mkdir("out", os.ModePerm)
+// writeImage receives an array of bytes and writes it to <dir>/<filename>.<format>,
+// where the directory is Params.OutDir (out by default) and the format is Params.Format
+// (pgm by default).
+func (io *ioState) writeImage() {
+	dir := outDir(io.params)
+	_ = os.MkdirAll(dir, os.ModePerm)
 
 	// Request a filename from the distributor.
 	filename := <-io.channels.filename
-
-	file, ioError := os.Create("out/" + filename + ".pgm")
-	util.Check(ioError)
-	defer file.Close()
-
-	_, _ = file.WriteString("P5\n")
-	//_, _ = file.WriteString("# PGM file writer by pnmmodules (https://github.com/owainkenwayucl/pnmmodules).\n")
-	_, _ = file.WriteString(strconv.Itoa(io.params.ImageWidth))
-	_, _ = file.WriteString(" ")
-	_, _ = file.WriteString(strconv.Itoa(io.params.ImageHeight))
-	_, _ = file.WriteString("\n")
-	_, _ = file.WriteString(strconv.Itoa(255))
-	_, _ = file.WriteString("\n")
+	name, format := outputFormat(io.params)
+	filename += "." + name
 
 	world := make([][]byte, io.params.ImageHeight)
 	for i := range world {
@@ -72,56 +64,75 @@ func (io *ioState) writePgmImage() {
 		}
 	}
 
-	for y := 0; y < io.params.ImageHeight; y++ {
-		for x := 0; x < io.params.ImageWidth; x++ {
-			_, ioError = file.Write([]byte{world[y][x]})
-			util.Check(ioError)
-		}
-	}
+	file, ioError := os.Create(filepath.Join(dir, filename))
+	util.Check(ioError)
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	rule, err := util.ParseRule(io.params.Rule)
+	util.Check(err)
+	util.Check(format.encode(w, world, rule))
+	util.Check(w.Flush())
 
 	ioError = file.Sync()
 	util.Check(ioError)
 
-	log.Printf("[IO] File %v.pgm output done", filename)
+	log.Printf("[IO] File %v output done", filename)
 }
 
-// readPgmImage opens a pgm file and sends its data as an array of bytes.
-func (io *ioState) readPgmImage() {
+// readImage opens an image and sends its data as an array of bytes.
+// The file is Params.Input if set, otherwise images/<filename>.pgm. If Params.Random is set
+// the world is generated instead (see RandomWorld), and if only Params.Place is set it starts
+// empty. The patterns of Params.Place are then stamped into it.
+func (io *ioState) readImage() {
 
 	// Request a filename from the distributor.
 	filename := <-io.channels.filename
-
-	data, ioError := os.ReadFile("images/" + filename + ".pgm")
-	util.Check(ioError)
-
-	fields := strings.Fields(string(data))
-
-	if fields[0] != "P5" {
-		panic(fmt.Sprintf("[IO] %v %v is not a pgm file", util.Red("ERROR"), filename))
+	path := "images/" + filename + ".pgm"
+	if io.params.Input != "" {
+		path = io.params.Input
 	}
 
-	width, _ := strconv.Atoi(fields[1])
-	if width != io.params.ImageWidth {
-		panic(fmt.Sprintf("[IO] %v Incorrect pgm width", util.Red("ERROR")))
+	var world [][]uint8
+	source := "File " + path
+	if io.params.Random != nil {
+		world = io.params.Random.generate(io.params.ImageWidth, io.params.ImageHeight)
+		source = "Random world " + io.params.Random.String()
+	} else if io.params.Input == "" && len(io.params.Place) > 0 {
+		world = placePattern(nil, io.params.ImageWidth, io.params.ImageHeight, 0, 0)
+		source = "Empty world"
+	} else {
+		var err error
+		world, err = ReadWorld(path)
+		if err != nil {
+			panic(fmt.Sprintf("[IO] %v %v", util.Red("ERROR"), err))
+		}
+		if IsPattern(path) {
+			world = placePattern(world, io.params.ImageWidth, io.params.ImageHeight, io.params.OffsetX, io.params.OffsetY)
+		}
 	}
-
-	height, _ := strconv.Atoi(fields[2])
-	if height != io.params.ImageHeight {
-		panic(fmt.Sprintf("[IO] %v Incorrect pgm height", util.Red("ERROR")))
+	for _, place := range io.params.Place {
+		pattern, err := place.load()
+		if err != nil {
+			panic(fmt.Sprintf("[IO] %v %v", util.Red("ERROR"), err))
+		}
+		stampPattern(world, pattern, place.X, place.Y)
+		source += ", " + place.String()
 	}
-
-	maxval, _ := strconv.Atoi(fields[3])
-	if maxval != 255 {
-		panic(fmt.Sprintf("[IO] %v Incorrect pgm maxval/bit depth", util.Red("ERROR")))
+	if len(world[0]) != io.params.ImageWidth {
+		panic(fmt.Sprintf("[IO] %v Incorrect image width", util.Red("ERROR")))
+	}
+	if len(world) != io.params.ImageHeight {
+		panic(fmt.Sprintf("[IO] %v Incorrect image height", util.Red("ERROR")))
 	}
 
-	image := []byte(fields[4])
-
-	for _, b := range image {
-		io.channels.input <- b
+	for _, row := range world {
+		for _, b := range row {
+			io.channels.input <- b
+		}
 	}
 
-	log.Printf("[IO] File %v.pgm input done", filename)
+	log.Printf("[IO] %v input done", source)
 }
 
 // startIo should be the entrypoint of the io goroutine.
@@ -135,9 +146,9 @@ func startIo(p Params, c ioChannels) {
 		// Block and wait for requests from the distributor
 		switch command {
 		case ioInput:
-			io.readPgmImage()
+			io.readImage()
 		case ioOutput:
-			io.writePgmImage()
+			io.writeImage()
 		case ioCheckIdle:
 			io.channels.idle <- true
 		}
@@ -0,0 +1,71 @@
+package gol
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// library holds a few classic patterns, in RLE (see decodeRle), that Params.Place can stamp
+// into the initial world by name.
+var library = map[string]string{
+	"glider":     "x = 3, y = 3\nbo$2bo$3o!",
+	"lwss":       "x = 5, y = 4\nbo2bo$o4b$o3bo$4o!",
+	"gosper":     "x = 36, y = 9\n24bo$22bobo$12b2o6b2o12b2o$11bo3bo4b2o12b2o$2o8bo5bo3b2o$2o8bo3bob2o4bobo$10bo5bo7bo$11bo3bo$12b2o!",
+	"pulsar":     "x = 13, y = 13\n2b3o3b3o2$o4bobo4bo$o4bobo4bo$o4bobo4bo$2b3o3b3o2$2b3o3b3o$o4bobo4bo$o4bobo4bo$o4bobo4bo2$2b3o3b3o!",
+	"rpentomino": "x = 3, y = 3\nb2o$2o$bo!",
+	"acorn":      "x = 7, y = 3\nbo$3bo$2o2b3o!",
+}
+
+// Patterns lists the names of the patterns in the library, for flag help and errors.
+func Patterns() []string {
+	names := make([]string, 0, len(library))
+	for name := range library {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Placement is one pattern to stamp into the initial world (see Params.Place): Pattern is the
+// name of a library pattern (see Patterns) or the path of a pattern file (see IsPattern), and
+// (X, Y) where its top-left corner goes.
+type Placement struct {
+	Pattern string
+	X, Y    int
+}
+
+// ParsePlacement parses a -place value, pattern@x,y, e.g. gosper@100,100, and checks the
+// pattern can be loaded.
+func ParsePlacement(s string) (Placement, error) {
+	name, at, ok := strings.Cut(s, "@")
+	var place Placement
+	if !ok {
+		return place, fmt.Errorf("bad placement %q, expected pattern@x,y", s)
+	}
+	place.Pattern = name
+	if _, err := fmt.Sscanf(at, "%d,%d", &place.X, &place.Y); err != nil {
+		return place, fmt.Errorf("bad placement %q, expected pattern@x,y", s)
+	}
+	if _, err := place.load(); err != nil {
+		return place, err
+	}
+	return place, nil
+}
+
+// String formats place the way ParsePlacement reads it.
+func (place Placement) String() string {
+	return fmt.Sprintf("%v@%v,%v", place.Pattern, place.X, place.Y)
+}
+
+// load returns the bounding box of the pattern, from the library if it has one of that name
+// (ignoring case), otherwise from the file.
+func (place Placement) load() ([][]uint8, error) {
+	if rle, ok := library[strings.ToLower(place.Pattern)]; ok {
+		return decodeRle([]byte(rle))
+	}
+	if !IsPattern(place.Pattern) {
+		return nil, fmt.Errorf("unknown pattern %q, expected a pattern file or one of %v", place.Pattern, strings.Join(Patterns(), ", "))
+	}
+	return ReadWorld(place.Pattern)
+}
@@ -0,0 +1,109 @@
+package gol
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// Life 1.06 (.lif) patterns: a header line, then the x y coordinates of every live cell.
+//
+//	#Life 1.06
+//	1 0
+//	2 1
+//	0 2
+//	1 2
+//	2 2
+//
+// Coordinates may be negative; the pattern is the bounding box of its cells. Worlds are
+// written as the cells getAliveCells returns, in the same row-major order as
+// FinalTurnComplete.Alive, so a saved final state diffs cleanly against other implementations.
+//
+// Worlds are also written with a "#S width height" comment after the header, which other
+// readers skip. When it is there the pattern is the whole width×height world, the coordinates
+// are cells of it and there may be none at all, so a saved world reads back as it was rather
+// than cropped to its live cells.
+
+const (
+	life106Header = "#Life 1.06"
+	life106Size   = "#S"
+)
+
+// decodeLife106 reads a Life 1.06 pattern into the world its #S line gives, or else into its
+// bounding box, which is empty if it has no cells.
+func decodeLife106(data []byte) ([][]uint8, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	if !scanner.Scan() || strings.TrimSpace(scanner.Text()) != life106Header {
+		return nil, fmt.Errorf("life: missing %q header", life106Header)
+	}
+	width, height := -1, -1
+	var xs, ys []int
+	for line := 2; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if size, ok := strings.CutPrefix(text, life106Size+" "); ok && width < 0 {
+			if _, err := fmt.Sscan(size, &width, &height); err != nil {
+				return nil, fmt.Errorf("life: line %d: want %s width height, got %q", line, life106Size, text)
+			}
+			if err := checkPatternSize("life", width, height); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("life: line %d: want x y, got %q", line, text)
+		}
+		x, errX := strconv.Atoi(fields[0])
+		y, errY := strconv.Atoi(fields[1])
+		if errX != nil || errY != nil {
+			return nil, fmt.Errorf("life: line %d: want x y, got %q", line, text)
+		}
+		xs, ys = append(xs, x), append(ys, y)
+	}
+
+	if width >= 0 {
+		world := util.NewWorld(width, height)
+		for i := range xs {
+			if xs[i] < 0 || xs[i] >= width || ys[i] < 0 || ys[i] >= height {
+				return nil, fmt.Errorf("life: cell %d %d outside the %dx%d world", xs[i], ys[i], width, height)
+			}
+			world[ys[i]][xs[i]] = 255
+		}
+		return world, nil
+	}
+	if len(xs) == 0 {
+		return nil, nil
+	}
+	minX, maxX, minY, maxY := xs[0], xs[0], ys[0], ys[0]
+	for i := range xs {
+		minX, maxX = min(minX, xs[i]), max(maxX, xs[i])
+		minY, maxY = min(minY, ys[i]), max(maxY, ys[i])
+	}
+	if err := checkPatternSize("life", maxX-minX+1, maxY-minY+1); err != nil {
+		return nil, err
+	}
+	world := util.NewWorld(maxX-minX+1, maxY-minY+1)
+	for i := range xs {
+		world[ys[i]-minY][xs[i]-minX] = 255
+	}
+	return world, nil
+}
+
+// encodeLife106 writes the size of the world and its live cells, by their coordinates in it.
+func encodeLife106(w io.Writer, world [][]uint8, _ util.Rule) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, life106Header)
+	fmt.Fprintln(bw, life106Size, len(world[0]), len(world))
+	for _, cell := range getAliveCells(world) {
+		fmt.Fprintf(bw, "%d %d\n", cell.X, cell.Y)
+	}
+	return bw.Flush()
+}
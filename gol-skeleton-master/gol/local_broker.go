@@ -0,0 +1,316 @@
+package gol
+
+import (
+	"fmt"
+	"net"
+	"net/rpc"
+	"sync"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/engine"
+	"uk.ac.bris.cs/gameoflife/hashlife"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// 进程内的 broker（Params.Local / -local）：distributor 在一个空闲端口上启动它，照常通过 RPC 连上去，
+// 退出时关掉。它自己算每一回合，不需要外部的 broker 和 worker，方便测试和离线开发。
+// 实现的是 distributor 用到的那部分 broker 接口；世界超过 transport 的分块阈值（约 2048x2048）时
+// 客户端会改走分块上传，本地 broker 不支持
+
+// LocalBroker 实现 RPC 接口，模拟远程服务器。世界和回合数都在实例里（Init 设置），
+// 同一进程里的多个 LocalBroker（比如并发跑的测试）互不干扰
+//
+// 世界是双缓冲的：每回合把下一代算进 next，再和 world 交换，不用每回合分配一整个新世界。
+// 算的时候只持有 turnMu，mu 只在交换时持有，GetAliveCellsCount 之类的调用不用等一整回合
+//
+// engine 是 EngineHashLife 并且世界支持时，Init 之后改由 life（HashLife 宇宙）推进，
+// 每回合只把翻转的细胞改进 world，其余接口照旧读 world
+type LocalBroker struct {
+	threads int    // 每一回合分给几个 goroutine 算（Params.Threads）
+	engine  string // Params.Engine
+
+	turnMu sync.Mutex         // 一次只推进一回合，持有期间 next 归正在算的那一回合
+	next   [][]uint8          // 下一代写到这里，和 world 一样大；nil 表示下一回合要重新分配
+	life   *hashlife.Universe // 不为 nil 时由它推进，next 不用
+
+	mu       sync.Mutex
+	world    [][]uint8 // Init 之后 broker 保存的世界
+	turn     int
+	boundary util.Boundary
+	rule     string
+	paused   bool
+}
+
+// Init：设置这个 broker 的世界和回合数，之后 NextTurn / ProcessTurns 在它上面推进
+func (b *LocalBroker) Init(params WorldParams, ok *bool) error {
+	if len(params.World) != params.ImageHeight || (params.ImageHeight > 0 && len(params.World[0]) != params.ImageWidth) {
+		return fmt.Errorf("world is not %dx%d", params.ImageWidth, params.ImageHeight)
+	}
+	boundary, err := util.ParseBoundary(string(params.Boundary))
+	if err != nil {
+		return err
+	}
+	rule, err := util.ParseRule(params.Rule)
+	if err != nil {
+		return err
+	}
+	b.turnMu.Lock()
+	defer b.turnMu.Unlock()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.world = params.World
+	b.next = nil
+	b.life = newLocalLife(b.engine, params.World, boundary, rule)
+	b.turn = params.Turn
+	b.boundary = boundary
+	b.rule = params.Rule
+	b.paused = false
+	*ok = true
+	return nil
+}
+
+// StartSimulation：distributor 用的名字，和 Init 一样
+func (b *LocalBroker) StartSimulation(params WorldParams, started *bool) error {
+	return b.Init(params, started)
+}
+
+// ProcessTurn 本地计算下一代（与分布式版本一致），结果也成为这个 broker 的当前世界
+func (b *LocalBroker) ProcessTurn(params WorldParams, reply *[][]uint8) error {
+	if _, err := util.ParseBoundary(string(params.Boundary)); err != nil {
+		return err
+	}
+	if _, err := util.ParseRule(params.Rule); err != nil {
+		return err
+	}
+	next := ProcessTurnLocal(params, b.threads)
+	b.turnMu.Lock()
+	defer b.turnMu.Unlock()
+	b.mu.Lock()
+	b.world = next
+	b.next = nil // 世界的大小可能变了
+	b.life = nil // 世界换掉了，之后的回合照常算
+	b.boundary = params.Boundary
+	b.rule = params.Rule
+	b.turn++
+	b.mu.Unlock()
+	*reply = deepCopyWorldUint8(next) // next 之后会被当成缓冲区覆盖，回复要用副本
+	return nil
+}
+
+// GetAliveCellsCount 返回当前世界中活细胞数量，还没有世界时为 0
+func (b *LocalBroker) GetAliveCellsCount(_ struct{}, reply *int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	*reply = 0
+	if b.world != nil {
+		*reply = countAlive(b.world)
+	}
+	return nil
+}
+
+// WatchAlive：等 args.Interval 之后报告当前的回合数和存活细胞数，两者在 mu 下一起读，对得上同一回合
+func (b *LocalBroker) WatchAlive(args AliveArgs, reply *AliveReport) error {
+	time.Sleep(args.Interval)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	reply.Turn = b.turn
+	if b.world != nil {
+		reply.Count = countAlive(b.world)
+	}
+	return nil
+}
+
+// NextTurn：推进一回合，只返回翻转的细胞
+func (b *LocalBroker) NextTurn(_ struct{}, reply *NextTurnReply) error {
+	b.turnMu.Lock()
+	defer b.turnMu.Unlock()
+	start := time.Now()
+	flipped, turn, err := b.step()
+	if err != nil {
+		return err
+	}
+	reply.Turn = turn
+	reply.Flipped = flipped
+	reply.Timing = StepTiming{Elapsed: time.Since(start)}
+	return nil
+}
+
+// ProcessTurns：一次推进多个回合，暂停时在回合边界提前返回
+func (b *LocalBroker) ProcessTurns(args ProcessTurnsArgs, reply *ProcessTurnsReply) error {
+	if args.Turns <= 0 {
+		return fmt.Errorf("invalid turn count %d", args.Turns)
+	}
+	b.turnMu.Lock()
+	defer b.turnMu.Unlock()
+	reply.Flipped = make([][]util.Cell, 0, args.Turns)
+	b.mu.Lock()
+	reply.Turn = b.turn
+	b.mu.Unlock()
+	for i := 0; i < args.Turns && !b.isPaused(); i++ {
+		start := time.Now()
+		flipped, turn, err := b.step()
+		if err != nil {
+			return err
+		}
+		reply.Flipped = append(reply.Flipped, flipped)
+		reply.Timings = append(reply.Timings, StepTiming{Elapsed: time.Since(start)})
+		reply.Turn = turn
+	}
+	return nil
+}
+
+// isPaused：ProcessTurns 每回合之前看一下
+func (b *LocalBroker) isPaused() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.paused
+}
+
+// step：把下一代算进 next，再和 world 交换，返回翻转的细胞和新的回合数，调用方需要持有 turnMu。
+// world 只在持有 turnMu 时被换掉，所以计算时不用持有 mu
+func (b *LocalBroker) step() ([]util.Cell, int, error) {
+	b.mu.Lock()
+	world := b.world
+	params := WorldParams{World: world, Boundary: b.boundary, Rule: b.rule}
+	b.mu.Unlock()
+	if world == nil {
+		return nil, 0, fmt.Errorf("no simulation started")
+	}
+	if b.life != nil {
+		flipped := b.life.Step()
+		b.mu.Lock()
+		for _, c := range flipped {
+			world[c.Y][c.X] ^= 255
+		}
+		b.turn++
+		turn := b.turn
+		b.mu.Unlock()
+		return flipped, turn, nil
+	}
+	params.ImageWidth, params.ImageHeight = len(world[0]), len(world)
+	if b.next == nil {
+		b.next = util.NewWorld(params.ImageWidth, params.ImageHeight)
+	}
+	processTurnInto(b.next, params, b.threads)
+	flipped := diffWorld(world, b.next)
+
+	b.mu.Lock()
+	b.world, b.next = b.next, world
+	b.turn++
+	turn := b.turn
+	b.mu.Unlock()
+	return flipped, turn, nil
+}
+
+// GetWorld：当前世界和回合数
+func (b *LocalBroker) GetWorld(_ struct{}, reply *WorldReply) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.world == nil {
+		return fmt.Errorf("no simulation started")
+	}
+	reply.Turn = b.turn
+	reply.World = deepCopyWorldUint8(b.world) // world 之后会被当成 next 覆盖，回复要用副本
+	return nil
+}
+
+// Pause / Resume：只影响 ProcessTurns
+func (b *LocalBroker) Pause(_ struct{}, ok *bool) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.paused, *ok = true, true
+	return nil
+}
+
+func (b *LocalBroker) Resume(_ struct{}, ok *bool) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.paused, *ok = false, true
+	return nil
+}
+
+// Detach / Attach：本地 broker 随 distributor 一起退出，没有可以接管的后台模拟
+func (b *LocalBroker) Detach(_ DetachArgs, detached *bool) error {
+	*detached = false
+	return nil
+}
+
+func (b *LocalBroker) Attach(_ struct{}, _ *AttachReply) error {
+	return fmt.Errorf("local broker has no simulation to resume")
+}
+
+// Shutdown：没有 worker 要关，distributor 退出时会关掉监听
+func (b *LocalBroker) Shutdown(_ struct{}, ok *bool) error {
+	*ok = true
+	return nil
+}
+
+// 启动一个本地 RPC 服务，监听 127.0.0.1 上的空闲端口，地址见返回的 ln.Addr()；
+// 每一回合用 threads 个 goroutine 算，engine 见 Params.Engine
+func StartLocalRPCServer(threads int, engine string) (net.Listener, error) {
+	server := rpc.NewServer()
+	err := server.RegisterName("Broker", &LocalBroker{threads: threads, engine: engine})
+	if err != nil {
+		return nil, err
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("local broker started", "addr", ln.Addr())
+	go server.Accept(ln)
+	return ln, nil
+}
+
+// 停止服务
+func StopLocalRPCServer(ln net.Listener) {
+	if ln != nil {
+		_ = ln.Close()
+		logger.Info("local broker stopped", "addr", ln.Addr())
+	}
+}
+
+// ProcessTurnLocal: 本地实现单步演化，行按 threads 分段并行计算（threads < 2 时串行），边界按 params.Boundary 处理。
+// params.Rule 必须是合法的规则（见 util.ParseRule），LocalBroker 在 Init / ProcessTurn 时已经检查过。
+// 演化本身在 engine 包里（engine.Evolve），不需要 WorldParams 的库可以直接用它。
+// 它没有状态，总是逐个细胞算：HashLife 快在跨回合的缓存，只算一回合每次都要从头建树，反而更慢
+func ProcessTurnLocal(params WorldParams, threads int) [][]uint8 {
+	rule, _ := util.ParseRule(params.Rule)
+	return engine.Evolve(params.World, params.Boundary, rule, threads)
+}
+
+// processTurnInto：和 ProcessTurnLocal 一样，但下一代写进调用方给的 newWorld（和 params.World 一样大，不能是同一个）
+func processTurnInto(newWorld [][]uint8, params WorldParams, threads int) {
+	rule, _ := util.ParseRule(params.Rule)
+	engine.EvolveInto(newWorld, params.World, params.Boundary, rule, threads)
+}
+
+// Engines 列出 Params.Engine 可以取的值，给命令行帮助和报错用
+func Engines() []string {
+	return []string{EngineBytes, EngineHashLife}
+}
+
+// Params.Engine 的取值
+const (
+	EngineBytes    = "bytes"    // 每回合用 engine 包逐个细胞算（空字符串也是它）
+	EngineHashLife = "hashlife" // 用 HashLife 宇宙推进（见 hashlife 包），世界不支持时退回 EngineBytes
+)
+
+// newLocalLife：engine 是 EngineHashLife 且世界、边界和规则都支持时建一个 HashLife 宇宙，否则返回 nil（逐个细胞算）。
+// 条件和远程 broker 的 -engine hashlife 一样：边长是 2 的幂的正方形、上下左右环绕、B3/S23
+func newLocalLife(engine string, world [][]uint8, boundary util.Boundary, rule util.Rule) *hashlife.Universe {
+	if engine != EngineHashLife || len(world) == 0 {
+		return nil
+	}
+	if !boundary.Torus() || rule != util.Conway {
+		logger.Warn("hashlife only supports B3/S23 on the torus, evolving every cell", "boundary", boundary, "rule", rule)
+		return nil
+	}
+	life, err := hashlife.New(world)
+	if err != nil {
+		logger.Warn("hashlife needs a square world with a power-of-two side, evolving every cell", "width", len(world[0]), "height", len(world))
+		return nil
+	}
+	logger.Info("local broker simulating with hashlife", "size", life.Size())
+	return life
+}
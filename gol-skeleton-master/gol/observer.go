@@ -0,0 +1,233 @@
+package gol
+
+import (
+	"fmt"
+
+	"uk.ac.bris.cs/gameoflife/transport"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// 观察者（Params.Observe / -observe）：只读地挂在 broker 上，把别的控制器推进的每一回合
+// 转成 CellsFlipped / TurnComplete 事件发给 SDL。按键只处理 s（保存）、q（退出观察）
+// 和平移、缩放视口的键，p / k 属于控制器。net/rpc 上是长轮询 Broker.Poll；gRPC 上同样调 Poll，但读的是 broker
+// 一直往 Broker.Watch 流里推的回合，不用每批都问一次
+
+// 以下类型必须和 broker 那边保持一致
+type TurnDelta struct {
+	Turn    int
+	Flipped []util.Cell
+}
+
+type SubscriptionArgs struct {
+	ID    int // 每个 job 各自编号
+	JobID string
+}
+
+type SubscribeReply struct {
+	ID    int
+	Turn  int
+	World util.World
+	Rule  string
+}
+
+type PollReply struct {
+	Turn   int
+	World  util.World
+	Rule   string
+	Deltas []TurnDelta
+}
+
+func observer(p Params, c distributorChannels, keyPresses <-chan rune) {
+	client := dialBroker(p, c)
+	if client == nil {
+		return
+	}
+	defer client.Close()
+
+	var sub SubscribeReply
+	if err := client.Call("Broker.Subscribe", JobArgs{JobID: p.Job}, &sub); err != nil {
+		logger.Error("subscribe to broker failed", "broker", brokerAddr(p), "err", err)
+		fail(c, 0, errorCode(err), err)
+		return
+	}
+	view := newViewport(p)
+	if view != nil {
+		c.events <- view.event(sub.Turn)
+	}
+	observe(p, c, client, sub, util.NewWorld(p.ImageWidth, p.ImageHeight), view, keyPresses)
+}
+
+// observe：从 world（消费者眼下看到的世界）开始，跟着 broker 推进的回合发事件，直到按 q 或者出错，
+// 最后发 Quitting 并关闭 events。sub 是刚刚 Subscribe 的结果。-observe 从空世界开始，
+// 控制器按 'v' 交出控制权之后从它自己的世界接着看。view 是消费者眼下的视口（见 viewport），
+// 观察时照样能平移、缩放
+func observe(p Params, c distributorChannels, client transport.Client, sub SubscribeReply, world [][]uint8, view *viewport, keyPresses <-chan rune) {
+	logger.Info("observing simulation", "observer", sub.ID, "turn", sub.Turn)
+
+	turn := sub.Turn
+	// 翻转怎么应用取决于 broker 上的规则（Generations 规则的细胞会衰减），每次重新同步时跟着更新
+	rule := util.Conway
+	// -ages：细胞年龄同样按收到的翻转来记，重新同步时看不到的那几回合里出生的细胞从同步那一回合算起
+	ages := newAgeGrid(p, world, turn)
+	c.events <- StateChange{turn, Executing}
+
+	// 用 broker 的完整世界替换本地世界，差异作为一次 CellsFlipped 发出去
+	resync := func(newWorld [][]uint8, newTurn int, newRule string) error {
+		if len(newWorld) != p.ImageHeight || (p.ImageHeight > 0 && len(newWorld[0]) != p.ImageWidth) {
+			return fmt.Errorf("broker simulation is %d rows high, observer expects %dx%d", len(newWorld), p.ImageWidth, p.ImageHeight)
+		}
+		r, err := util.ParseRule(newRule)
+		if err != nil {
+			return fmt.Errorf("broker simulation: %v", err)
+		}
+		rule = r
+		flipped := diffWorld(world, newWorld)
+		world = deepCopyWorldUint8(newWorld)
+		turn = newTurn
+		ages.flip(world, flipped, turn)
+		if shown, values := view.filter(flipped, cellValues(world, flipped, rule)); len(shown) > 0 {
+			c.events <- CellsFlipped{CompletedTurns: turn, Cells: shown, Values: values}
+		}
+		if p.FrameEvery > 0 {
+			c.events <- pixelData(world, turn, rule)
+		}
+		c.events <- TurnComplete{CompletedTurns: turn}
+		return nil
+	}
+
+	// quit：出错退出时 err 不为 nil，先发 ErrorEvent
+	quit := func(err error) {
+		var ok bool
+		_ = client.Call("Broker.Unsubscribe", SubscriptionArgs{ID: sub.ID, JobID: p.Job}, &ok)
+		if err != nil {
+			fail(c, turn, errorCode(err), err)
+			return
+		}
+		c.events <- StateChange{turn, Quitting}
+		close(c.events)
+	}
+
+	if sub.World != nil {
+		if err := resync(sub.World, sub.Turn, sub.Rule); err != nil {
+			logger.Error("observe failed", "err", err)
+			quit(err)
+			return
+		}
+	}
+
+	// 长轮询放在单独的 goroutine，主循环照样能及时响应按键
+	polls := make(chan PollReply)
+	pollErr := make(chan error, 1)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			var reply PollReply
+			if err := client.Call("Broker.Poll", SubscriptionArgs{ID: sub.ID, JobID: p.Job}, &reply); err != nil {
+				pollErr <- err
+				return
+			}
+			select {
+			case polls <- reply:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	aliveTick, stopAliveTick := aliveTicks(p)
+	defer stopAliveTick()
+	meter := newTurnRateMeter(turn)
+
+	for {
+		select {
+		case reply := <-polls:
+			if reply.World != nil {
+				if err := resync(reply.World, reply.Turn, reply.Rule); err != nil {
+					logger.Error("observe failed", "err", err)
+					quit(err)
+					return
+				}
+				continue
+			}
+			for _, d := range reply.Deltas {
+				values := applyFlips(world, d.Flipped, rule)
+				turn = d.Turn
+				ages.flip(world, d.Flipped, turn)
+				if shown, values := view.filter(d.Flipped, values); len(shown) > 0 {
+					c.events <- CellsFlipped{CompletedTurns: turn, Cells: shown, Values: values}
+				}
+				if frameDue(p, turn) {
+					c.events <- pixelData(world, turn, rule)
+				}
+				c.events <- TurnComplete{CompletedTurns: turn}
+			}
+
+		case err := <-pollErr:
+			logger.Error("poll broker failed", "observer", sub.ID, "code", errorCode(err), "err", err)
+			quit(err)
+			return
+
+		case <-aliveTick:
+			c.events <- meter.next(turn)
+			c.events <- AliveCellsCount{CompletedTurns: turn, CellsCount: countAlive(world)}
+			if report := ages.report(world, turn); report != nil {
+				c.events <- *report
+			}
+
+		case key := <-keyPresses:
+			switch key {
+			case 's':
+				// 本地世界可能落后于 broker 几回合，保存 broker 上的
+				saved, savedTurn := remoteWorld(p, client, deepCopyWorldUint8(world), turn)
+				saveWorld(p, c, saved, savedTurn, ages.report(world, turn))
+			case 'q':
+				quit(nil)
+				return
+			case KeyPanLeft, KeyPanRight, KeyPanUp, KeyPanDown, KeyZoomIn, KeyZoomOut:
+				if view != nil && view.key(key) {
+					for _, event := range view.redraw(world, turn, rule) {
+						c.events <- event
+					}
+					c.events <- TurnComplete{CompletedTurns: turn}
+				}
+			default:
+				logger.Info("observers only handle s, q and the viewport keys", "key", string(key))
+			}
+		}
+	}
+}
+
+// diffWorld：oldWorld 变成 newWorld 需要翻转的细胞
+func diffWorld(oldWorld, newWorld [][]uint8) []util.Cell {
+	var flipped []util.Cell
+	for y := range newWorld {
+		for x := range newWorld[y] {
+			if oldWorld[y][x] != newWorld[y][x] {
+				flipped = append(flipped, util.Cell{X: x, Y: y})
+			}
+		}
+	}
+	return flipped
+}
+
+// applyFlips：按 rule 把 broker 发来的翻转应用到 world（两态规则就是 0 和 255 互换，见 util.Rule.Flip），
+// 返回这些细胞的新值作为 CellsFlipped.Values；两态规则不需要，返回 nil
+func applyFlips(world [][]uint8, flipped []util.Cell, rule util.Rule) []uint8 {
+	for _, cell := range flipped {
+		world[cell.Y][cell.X] = rule.Flip(world[cell.Y][cell.X])
+	}
+	return cellValues(world, flipped, rule)
+}
+
+// cellValues：Generations 规则下 cells 在 world 里的值（CellsFlipped.Values），两态规则返回 nil
+func cellValues(world [][]uint8, cells []util.Cell, rule util.Rule) []uint8 {
+	if !rule.Generations() {
+		return nil
+	}
+	values := make([]uint8, len(cells))
+	for i, cell := range cells {
+		values[i] = world[cell.Y][cell.X]
+	}
+	return values
+}
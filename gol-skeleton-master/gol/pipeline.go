@@ -0,0 +1,196 @@
+package gol
+
+import (
+	"fmt"
+	"net/rpc"
+	"slices"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/transport"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// turnPipeline keeps up to Params.Pipeline calls that advance the broker in flight, so that
+// the broker is already evolving the next turns while the distributor applies and reports
+// the last ones, instead of the network, the workers and the events taking turns. The broker
+// runs concurrent calls one at a time but not necessarily in the order they were sent, so
+// results are handed out in turn order. With a depth of 1 it makes one call at a time.
+// Params.MaxBacklog further caps the turns it has asked for and not handed out yet.
+type turnPipeline struct {
+	client  transport.Client
+	job     string // Params.Job
+	depth   int
+	backlog int           // Params.MaxBacklog, 0 = no limit
+	lag     func() int    // turns handed out that the consumer hasn't taken yet, counted towards backlog
+	timeout time.Duration // how long to wait for a call, 0 = forever
+	calls   []*turnCall   // in flight, in the order they were sent
+	early   []turnResult  // came back ahead of a turn that is still in flight
+
+	// stalled, if set, is called every stallPoll while a call is taking that long, e.g. to find
+	// out whether the broker is holding it waiting for workers.
+	stalled func()
+}
+
+// stallPoll is how often turnPipeline.stalled is called while waiting for a call.
+const stallPoll = 2 * time.Second
+
+// turnCall is a Broker.NextTurn call or a Broker.ProcessTurns call. NextTurn is only sent one
+// call at a time (see send), as its reply while the broker is paused, the turn the broker is
+// at and no flips, can't be told apart from another call's turn that flipped nothing.
+type turnCall struct {
+	call  *rpc.Call
+	batch int
+	sent  time.Time
+	one   NextTurnReply
+	many  ProcessTurnsReply
+	next  bool // NextTurn rather than ProcessTurns
+}
+
+// turnResult is what one call did: flipped holds the cells flipped by each of the turns
+// it evolved, the last of which is turn, and timings how long each took on the broker
+// (shorter than flipped if the broker doesn't say).
+type turnResult struct {
+	turn    int
+	flipped [][]util.Cell
+	timings []StepTiming
+	elapsed time.Duration
+	err     error
+}
+
+// timing returns the TurnTiming event for the i-th turn of the result, turn, whose events
+// took emit to send.
+func (r turnResult) timing(i, turn int, emit time.Duration) TurnTiming {
+	event := TurnTiming{CompletedTurns: turn, RPC: r.elapsed, Batch: len(r.flipped), Emit: emit}
+	if i < len(r.timings) {
+		t := r.timings[i]
+		event.Broker, event.SlowestWorker, event.SlowestWorkerTime = t.Elapsed, t.Slowest, t.SlowestElapsed
+	}
+	return event
+}
+
+func newTurnPipeline(p Params, client transport.Client) *turnPipeline {
+	return &turnPipeline{client: client, job: p.Job, depth: max(p.Pipeline, 1), backlog: max(p.MaxBacklog, 0), timeout: brokerOptions(p).Timeout}
+}
+
+// room reports whether another call can be sent.
+func (tp *turnPipeline) room() bool {
+	return len(tp.calls) < tp.depth && (tp.backlog == 0 || tp.backlogged() < tp.backlog)
+}
+
+// fit shrinks batch to the turns the backlog limit still allows, at least 1 when there is room.
+func (tp *turnPipeline) fit(batch int) int {
+	if tp.backlog > 0 {
+		batch = min(batch, tp.backlog-tp.backlogged())
+	}
+	return batch
+}
+
+// backlogged is how many turns count towards the backlog limit.
+func (tp *turnPipeline) backlogged() int {
+	n := tp.pending()
+	if tp.lag != nil {
+		n += tp.lag()
+	}
+	return n
+}
+
+// idle reports whether there is nothing to wait for.
+func (tp *turnPipeline) idle() bool {
+	return len(tp.calls) == 0 && len(tp.early) == 0
+}
+
+// pending is how many turns have been asked for and not handed out yet.
+func (tp *turnPipeline) pending() int {
+	n := 0
+	for _, c := range tp.calls {
+		n += c.batch
+	}
+	for _, r := range tp.early {
+		n += len(r.flipped)
+	}
+	return n
+}
+
+// send asks the broker for batch more turns.
+func (tp *turnPipeline) send(batch int) {
+	c := &turnCall{batch: batch, sent: time.Now(), next: batch == 1 && tp.depth == 1}
+	done := make(chan *rpc.Call, 1)
+	if c.next {
+		c.call = tp.client.Go("Broker.NextTurn", JobArgs{JobID: tp.job}, &c.one, done)
+	} else {
+		c.call = tp.client.Go("Broker.ProcessTurns", ProcessTurnsArgs{Turns: batch, JobID: tp.job}, &c.many, done)
+	}
+	tp.calls = append(tp.calls, c)
+}
+
+// next returns the result that carries on from turn, waiting for as many calls as it has
+// to. Calls that evolved nothing, as they do while the broker is paused, are dropped; if no
+// call evolved anything the result has no flips. If the results don't carry on from turn,
+// the first of them is returned for the caller to find out of step. After an error the
+// calls still in flight are abandoned.
+func (tp *turnPipeline) next(turn int) turnResult {
+	for {
+		for i, r := range tp.early {
+			if r.turn-len(r.flipped) == turn {
+				tp.early = slices.Delete(tp.early, i, i+1)
+				return r
+			}
+		}
+		if len(tp.calls) == 0 {
+			if len(tp.early) == 0 {
+				return turnResult{turn: turn}
+			}
+			r := tp.early[0]
+			tp.early = nil
+			return r
+		}
+
+		c := tp.calls[0]
+		tp.calls = tp.calls[1:]
+		r := tp.wait(c)
+		if r.err != nil {
+			tp.calls, tp.early = nil, nil
+			return r
+		}
+		// A paused broker evolves nothing: ProcessTurns replies with no turns, NextTurn with
+		// the turn the distributor is already at
+		if len(r.flipped) > 0 && r.turn > turn {
+			tp.early = append(tp.early, r)
+		}
+	}
+}
+
+// wait waits for c, for at most the call timeout since it was sent.
+func (tp *turnPipeline) wait(c *turnCall) turnResult {
+	var expired <-chan time.Time
+	if tp.timeout > 0 {
+		timer := time.NewTimer(tp.timeout - time.Since(c.sent))
+		defer timer.Stop()
+		expired = timer.C
+	}
+	var poll <-chan time.Time
+	if tp.stalled != nil {
+		ticker := time.NewTicker(stallPoll)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+wait:
+	for {
+		select {
+		case <-c.call.Done:
+			break wait
+		case <-poll:
+			tp.stalled()
+		case <-expired:
+			return turnResult{err: fmt.Errorf("%s after %v: %w", c.call.ServiceMethod, tp.timeout, transport.ErrTimeout)}
+		}
+	}
+
+	r := turnResult{elapsed: time.Since(c.sent), err: c.call.Error}
+	if c.next {
+		r.turn, r.flipped, r.timings = c.one.Turn, [][]util.Cell{c.one.Flipped}, []StepTiming{c.one.Timing}
+	} else {
+		r.turn, r.flipped, r.timings = c.many.Turn, c.many.Flipped, c.many.Timings
+	}
+	return r
+}
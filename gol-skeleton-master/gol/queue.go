@@ -0,0 +1,259 @@
+package gol
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// eventQueue sits between the distributor and the events channel of Run, so that a consumer
+// that stalls for a moment, e.g. an SDL window being dragged or a test harness busy checking a
+// world, doesn't stop turns being evolved and keypresses handled. A goroutine of its own passes
+// the distributor's events on in order, queueing up to Params.EventQueue of them; what happens
+// when the queue is full is up to Params.EventOverflow:
+//
+//	block  the distributor waits for the consumer to make room, as it would on an unbuffered
+//	       channel: nothing is lost, but turns stop being processed meanwhile (the default)
+//	drop   the oldest queued progress report (TurnComplete, AliveCellsCount, TurnRate,
+//	       TurnStats, TurnTiming, CellAges, WaitingForWorkers or PixelData) is dropped to make room, since a
+//	       later one supersedes it. Events that change the world the consumer shows or the state of the
+//	       run are never dropped; only when the queue holds nothing else does the distributor wait
+//	coalesce  frames are merged as soon as they queue up, whether or not the queue is full: the
+//	       CellsFlipped of a turn goes into that of the frame before it, cells flipped back
+//	       dropping out, and only the last TurnComplete is kept, so a consumer that renders
+//	       slower than small worlds evolve shows the latest turn rather than replaying a backlog
+//	       of them. Any other event between two frames, e.g. a TurnStats, keeps them apart.
+//	       When the queue is full all the same, it drops progress reports as drop does
+//
+// Either way the distributor's memory stays bounded by the queue, and Params.MaxBacklog counts
+// the turns still queued towards its limit.
+type eventQueue struct {
+	in     chan Event   // the distributor's end, closed when it is done
+	out    chan<- Event // the events channel of Run, closed once everything is passed on
+	size   int
+	policy string
+	done   chan struct{} // closed after out
+
+	// CompletedTurns of the last TurnComplete queued and of the last one taken by the consumer
+	// (or dropped), for lag.
+	queued, taken atomic.Int64
+	dropped       int
+	merged        int // frames coalesced into the one before them
+}
+
+// Overflow policies, see eventQueue.
+const (
+	overflowBlock    = "block"
+	overflowDrop     = "drop"
+	overflowCoalesce = "coalesce"
+)
+
+// defaultEventQueue is how many events are queued when Params.EventQueue is 0: a couple of
+// seconds' worth of turns of a small world.
+const defaultEventQueue = 1024
+
+// OverflowPolicies lists the names accepted by Params.EventOverflow, for flag help and errors.
+func OverflowPolicies() []string {
+	return []string{overflowBlock, overflowDrop, overflowCoalesce}
+}
+
+// overflowPolicy resolves Params.EventOverflow.
+func overflowPolicy(p Params) string {
+	name := strings.ToLower(p.EventOverflow)
+	if name == "" {
+		return overflowBlock
+	}
+	if !slices.Contains(OverflowPolicies(), name) {
+		panic(fmt.Sprintf("unknown event overflow policy %q (want one of %s)", p.EventOverflow, strings.Join(OverflowPolicies(), ", ")))
+	}
+	return name
+}
+
+// startEventQueue starts passing events on to out. It returns nil, for the distributor to send
+// on out directly, when Params.EventQueue is negative.
+func startEventQueue(p Params, out chan<- Event) *eventQueue {
+	size := p.EventQueue
+	switch {
+	case size < 0:
+		return nil
+	case size == 0:
+		size = defaultEventQueue
+	}
+	q := &eventQueue{
+		in:     make(chan Event),
+		out:    out,
+		size:   size,
+		policy: overflowPolicy(p),
+		done:   make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+// run passes events on until in is closed and the queue is empty, then closes out.
+func (q *eventQueue) run() {
+	defer close(q.done)
+	var queue []Event
+	in := q.in
+	for in != nil || len(queue) > 0 {
+		receive := in
+		if q.full(queue) {
+			receive = nil
+		}
+		var send chan<- Event
+		var head Event
+		if len(queue) > 0 {
+			send, head = q.out, queue[0]
+		}
+
+		select {
+		case event, ok := <-receive:
+			if !ok {
+				in = nil
+				continue
+			}
+			queue = q.push(queue, event)
+		case send <- head:
+			queue[0] = nil
+			queue = queue[1:]
+			if tc, ok := head.(TurnComplete); ok {
+				q.taken.Store(int64(tc.CompletedTurns))
+			}
+		}
+	}
+	if q.dropped > 0 || q.merged > 0 {
+		logger.Info("consumer too slow for some events", "dropped", q.dropped, "coalescedFrames", q.merged)
+	}
+	close(q.out)
+}
+
+// push queues event, making room for it as the policy says if the queue is full.
+func (q *eventQueue) push(queue []Event, event Event) []Event {
+	if tc, ok := event.(TurnComplete); ok {
+		q.queued.Store(int64(tc.CompletedTurns))
+	}
+	if q.policy == overflowCoalesce {
+		if merged, ok := q.coalesce(queue, event); ok {
+			return merged
+		}
+	}
+	if len(queue) >= q.size {
+		queue = q.drop(queue)
+	}
+	return append(queue, event)
+}
+
+// coalesce merges event into the frame at the end of queue, a TurnComplete possibly preceded
+// by a CellsFlipped, and reports whether it could.
+func (q *eventQueue) coalesce(queue []Event, event Event) ([]Event, bool) {
+	n := len(queue)
+	if n == 0 {
+		return queue, false
+	}
+	last, ok := queue[n-1].(TurnComplete)
+	if !ok {
+		return queue, false
+	}
+	switch e := event.(type) {
+	case TurnComplete:
+		queue[n-1] = e
+	case CellsFlipped:
+		var flipped CellsFlipped
+		merge := false
+		if n >= 2 {
+			flipped, merge = queue[n-2].(CellsFlipped)
+		}
+		switch {
+		case !merge:
+			queue[n-1] = e // the frame flipped nothing
+		case (flipped.Values == nil) != (e.Values == nil):
+			return queue, false
+		default:
+			queue[n-2] = mergeFlips(flipped, e)
+			queue[n-1] = nil
+			queue = queue[:n-1]
+		}
+	default:
+		return queue, false
+	}
+	// The consumer won't see the earlier TurnComplete: for lag it is as good as taken
+	q.taken.Store(int64(last.CompletedTurns))
+	q.merged++
+	return queue, true
+}
+
+// mergeFlips returns the cells flipped by a and then b. With two-state rules a cell flipped by
+// both is back as it was and left out; with Generations rules (Values set) it has b's value.
+func mergeFlips(a, b CellsFlipped) CellsFlipped {
+	merged := CellsFlipped{CompletedTurns: b.CompletedTurns}
+	if a.Values == nil {
+		odd := make(map[util.Cell]bool, len(a.Cells)+len(b.Cells))
+		for _, cell := range a.Cells {
+			odd[cell] = !odd[cell]
+		}
+		for _, cell := range b.Cells {
+			odd[cell] = !odd[cell]
+		}
+		for _, cells := range [][]util.Cell{a.Cells, b.Cells} {
+			for _, cell := range cells {
+				if odd[cell] {
+					merged.Cells = append(merged.Cells, cell)
+					odd[cell] = false
+				}
+			}
+		}
+		return merged
+	}
+
+	at := make(map[util.Cell]int, len(a.Cells)+len(b.Cells))
+	for _, e := range []CellsFlipped{a, b} {
+		for i, cell := range e.Cells {
+			if j, ok := at[cell]; ok {
+				merged.Values[j] = e.Values[i]
+				continue
+			}
+			at[cell] = len(merged.Cells)
+			merged.Cells = append(merged.Cells, cell)
+			merged.Values = append(merged.Values, e.Values[i])
+		}
+	}
+	return merged
+}
+
+// full reports whether the distributor has to wait before queueing another event.
+func (q *eventQueue) full(queue []Event) bool {
+	if len(queue) < q.size {
+		return false
+	}
+	return q.policy == overflowBlock || !slices.ContainsFunc(queue, droppable)
+}
+
+// drop removes the oldest droppable event from queue.
+func (q *eventQueue) drop(queue []Event) []Event {
+	i := slices.IndexFunc(queue, droppable)
+	if tc, ok := queue[i].(TurnComplete); ok {
+		q.taken.Store(int64(tc.CompletedTurns))
+	}
+	q.dropped++
+	return slices.Delete(queue, i, i+1)
+}
+
+// droppable reports whether event is a progress report a later one supersedes.
+func droppable(event Event) bool {
+	switch event.(type) {
+	case TurnComplete, AliveCellsCount, TurnRate, TurnStats, TurnTiming, CellAges, WaitingForWorkers, PixelData:
+		return true
+	}
+	return false
+}
+
+// lag is how many turns are queued that the consumer hasn't taken yet; 0 without a queue.
+func (q *eventQueue) lag() int {
+	if q == nil {
+		return 0
+	}
+	return int(q.queued.Load() - q.taken.Load())
+}
@@ -0,0 +1,124 @@
+package gol
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/engine"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// turnEvents evolves a random soup for turns turns and returns the world after every turn,
+// from turn 0, and the events the distributor sends for them: a CellsFlipped (with Values under
+// a Generations rule) and a TurnComplete per turn, with an AliveCellsCount every few turns.
+func turnEvents(rule util.Rule, turns int) ([][][]uint8, []Event) {
+	r := rand.New(rand.NewSource(1))
+	world := util.NewWorld(32, 32)
+	for y := range world {
+		for x := range world[y] {
+			if r.Intn(3) == 0 {
+				world[y][x] = 255
+			}
+		}
+	}
+	worlds := [][][]uint8{world}
+	var events []Event
+	for turn := 1; turn <= turns; turn++ {
+		next := engine.Evolve(world, util.BoundaryTorus, rule, 1)
+		cells := diffWorld(world, next)
+		if len(cells) > 0 {
+			events = append(events, CellsFlipped{CompletedTurns: turn, Cells: cells, Values: cellValues(next, cells, rule)})
+		}
+		events = append(events, TurnComplete{CompletedTurns: turn})
+		if turn%7 == 0 {
+			events = append(events, AliveCellsCount{CompletedTurns: turn, CellsCount: countAlive(next)})
+		}
+		worlds = append(worlds, next)
+		world = next
+	}
+	return worlds, events
+}
+
+// TestEventQueuePolicies tests that whatever the overflow policy, a consumer too slow for the
+// distributor, applying the flips it is sent to its own board, has the real world on that board
+// at every TurnComplete it gets and at the end, for both two-state and Generations rules.
+func TestEventQueuePolicies(t *testing.T) {
+	for _, name := range []string{"B3/S23", "B2/S345/C4"} {
+		rule, _ := util.ParseRule(name)
+		worlds, events := turnEvents(rule, 300)
+		for _, policy := range OverflowPolicies() {
+			out := make(chan Event)
+			q := startEventQueue(Params{EventQueue: 8, EventOverflow: policy}, out)
+			go func() {
+				for _, event := range events {
+					q.in <- event
+				}
+				close(q.in)
+			}()
+
+			board := util.CopyWorld(worlds[0])
+			turn, seen := 0, 0
+			for event := range out {
+				time.Sleep(50 * time.Microsecond)
+				switch e := event.(type) {
+				case CellsFlipped:
+					for i, cell := range e.Cells {
+						if e.Values != nil {
+							board[cell.Y][cell.X] = e.Values[i]
+						} else {
+							board[cell.Y][cell.X] = rule.Flip(board[cell.Y][cell.X])
+						}
+					}
+				case TurnComplete:
+					if e.CompletedTurns <= turn {
+						t.Fatalf("%s %s: turn %d completed after turn %d", name, policy, e.CompletedTurns, turn)
+					}
+					turn = e.CompletedTurns
+					seen++
+					if !equalWorld(board, worlds[turn]) {
+						t.Fatalf("%s %s: board differs from the world at turn %d", name, policy, turn)
+					}
+				}
+			}
+			<-q.done
+
+			if !equalWorld(board, worlds[len(worlds)-1]) {
+				t.Errorf("%s %s: board differs from the final world", name, policy)
+			}
+			switch policy {
+			case overflowBlock:
+				if seen != len(worlds)-1 || q.dropped > 0 || q.merged > 0 {
+					t.Errorf("%s block: saw %d of %d turns, dropped %d, merged %d", name, seen, len(worlds)-1, q.dropped, q.merged)
+				}
+			case overflowDrop:
+				if q.dropped == 0 || q.merged > 0 {
+					t.Errorf("%s drop: dropped %d, merged %d; the consumer should have fallen behind", name, q.dropped, q.merged)
+				}
+			case overflowCoalesce:
+				if q.merged == 0 {
+					t.Errorf("%s coalesce: no frames merged; the consumer should have fallen behind", name)
+				}
+			}
+		}
+	}
+}
+
+// TestMergeFlips tests that with two-state rules cells flipped twice drop out and with
+// Generations rules the later value wins, each cell listed once.
+func TestMergeFlips(t *testing.T) {
+	a := CellsFlipped{CompletedTurns: 1, Cells: []util.Cell{{X: 1, Y: 1}, {X: 2, Y: 2}, {X: 3, Y: 3}}}
+	b := CellsFlipped{CompletedTurns: 2, Cells: []util.Cell{{X: 2, Y: 2}, {X: 4, Y: 4}, {X: 3, Y: 3}, {X: 3, Y: 3}}}
+	merged := mergeFlips(a, b)
+	if want := []util.Cell{{X: 1, Y: 1}, {X: 3, Y: 3}, {X: 4, Y: 4}}; merged.CompletedTurns != 2 || !slices.Equal(merged.Cells, want) || merged.Values != nil {
+		t.Errorf("two-state: got %+v, want cells %v of turn 2", merged, want)
+	}
+
+	a.Values = []uint8{255, 128, 0}
+	b.Values = []uint8{64, 255, 128, 0}
+	merged = mergeFlips(a, b)
+	if want := []util.Cell{{X: 1, Y: 1}, {X: 2, Y: 2}, {X: 3, Y: 3}, {X: 4, Y: 4}}; !slices.Equal(merged.Cells, want) || !slices.Equal(merged.Values, []uint8{255, 64, 0, 255}) {
+		t.Errorf("generations: got %+v", merged)
+	}
+}
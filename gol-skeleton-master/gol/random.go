@@ -0,0 +1,74 @@
+package gol
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// RandomWorld fills the world pseudo-randomly instead of loading an image (see Params.Random):
+// every cell is alive with probability Density. The same Seed always gives the same world of
+// a given size, so runs can be repeated exactly, e.g. for scaling experiments.
+type RandomWorld struct {
+	Density float64
+	Seed    uint64
+}
+
+// defaultDensity is the density ParseRandom uses when none is given.
+const defaultDensity = 0.5
+
+// ParseRandom parses a -random value, key=value settings separated by spaces or commas:
+//
+//	density=0.3 seed=42
+//
+// density is between 0 and 1 (0.5 if left out). Leaving out seed picks one from the clock,
+// which String reports so the world can be recreated.
+func ParseRandom(s string) (*RandomWorld, error) {
+	r := &RandomWorld{Density: defaultDensity, Seed: uint64(time.Now().UnixNano())}
+	for _, setting := range strings.FieldsFunc(s, func(c rune) bool { return c == ' ' || c == ',' }) {
+		key, value, ok := strings.Cut(setting, "=")
+		if !ok {
+			return nil, fmt.Errorf("bad random setting %q, expected key=value", setting)
+		}
+		var err error
+		switch strings.ToLower(key) {
+		case "density":
+			r.Density, err = strconv.ParseFloat(value, 64)
+			if err == nil && !(r.Density >= 0 && r.Density <= 1) {
+				err = fmt.Errorf("density %v is not between 0 and 1", value)
+			}
+		case "seed":
+			r.Seed, err = strconv.ParseUint(value, 10, 64)
+		default:
+			err = fmt.Errorf("unknown random setting %q, expected density or seed", key)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// String formats r the way ParseRandom reads it.
+func (r RandomWorld) String() string {
+	return fmt.Sprintf("density=%v seed=%v", r.Density, r.Seed)
+}
+
+// generate returns a width×height world filled as r describes. The cells are drawn row by
+// row from a PCG generator seeded with r.Seed, which math/rand/v2 keeps stable across releases.
+func (r RandomWorld) generate(width, height int) [][]uint8 {
+	rng := rand.New(rand.NewPCG(r.Seed, 0))
+	world := util.NewWorld(width, height)
+	for y := range world {
+		for x := range world[y] {
+			if rng.Float64() < r.Density {
+				world[y][x] = 255
+			}
+		}
+	}
+	return world
+}
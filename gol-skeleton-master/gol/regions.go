@@ -0,0 +1,92 @@
+package gol
+
+import (
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// regionTile is the size of the square tiles flipRegions tracks changes by, and
+// regionInterval the least time between the RegionsFlipped events it sends, about a frame.
+const (
+	regionTile     = 32
+	regionInterval = time.Second / 60
+)
+
+// flipRegions coalesces the flips of huge turns into RegionsFlipped events when
+// Params.FlipRegions is set. A turn flipping more than FlipRegions cells only marks the tiles
+// it touched as dirty; the dirty tiles of all the turns since the last event are then sent
+// together, as the world's values, at most once every regionInterval. So memory no longer
+// grows with the number of flips, and a consumer that can't keep up with every turn sees the
+// world at the turns it can. A nil *flipRegions coalesces nothing.
+type flipRegions struct {
+	threshold  int
+	cols, rows int
+	dirty      []bool // dirty[ty*cols+tx] for the tile at (tx, ty)
+	pending    bool   // some tile is dirty
+	last       time.Time
+}
+
+// newFlipRegions returns nil unless p.FlipRegions is positive.
+func newFlipRegions(p Params) *flipRegions {
+	if p.FlipRegions <= 0 {
+		return nil
+	}
+	cols, rows := (p.ImageWidth+regionTile-1)/regionTile, (p.ImageHeight+regionTile-1)/regionTile
+	return &flipRegions{threshold: p.FlipRegions, cols: cols, rows: rows, dirty: make([]bool, cols*rows)}
+}
+
+// take reports whether one turn's flips go into a RegionsFlipped event rather than a
+// CellsFlipped one, and if so marks their tiles dirty. Once one turn has been coalesced, the
+// following ones are too until the event is sent, so that consumers see the turns in order.
+func (r *flipRegions) take(flipped []util.Cell) bool {
+	if r == nil || (!r.pending && len(flipped) <= r.threshold) {
+		return false
+	}
+	for _, cell := range flipped {
+		r.dirty[(cell.Y/regionTile)*r.cols+cell.X/regionTile] = true
+	}
+	r.pending = true
+	return true
+}
+
+// due reports whether the dirty tiles should be sent now.
+func (r *flipRegions) due() bool {
+	return r != nil && r.pending && time.Since(r.last) >= regionInterval
+}
+
+// held reports whether flips are being held back in dirty tiles, which have to be sent
+// before anything else changes the world the consumers see.
+func (r *flipRegions) held() bool {
+	return r != nil && r.pending
+}
+
+// event returns the RegionsFlipped event for world on turn, with a region for every run of
+// dirty tiles along a row of tiles, and marks every tile clean.
+func (r *flipRegions) event(world [][]uint8, turn int) RegionsFlipped {
+	height, width := len(world), len(world[0])
+	e := RegionsFlipped{CompletedTurns: turn}
+	for ty := 0; ty < r.rows; ty++ {
+		for tx := 0; tx < r.cols; tx++ {
+			if !r.dirty[ty*r.cols+tx] {
+				continue
+			}
+			end := tx
+			for end < r.cols && r.dirty[ty*r.cols+end] {
+				r.dirty[ty*r.cols+end] = false
+				end++
+			}
+			region := Region{X: tx * regionTile, Y: ty * regionTile}
+			region.Width = min(end*regionTile, width) - region.X
+			region.Height = min((ty+1)*regionTile, height) - region.Y
+			region.Cells = make([]uint8, 0, region.Width*region.Height)
+			for y := region.Y; y < region.Y+region.Height; y++ {
+				region.Cells = append(region.Cells, world[y][region.X:region.X+region.Width]...)
+			}
+			e.Regions = append(e.Regions, region)
+			tx = end
+		}
+	}
+	r.pending, r.last = false, time.Now()
+	return e
+}
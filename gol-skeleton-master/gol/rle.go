@@ -0,0 +1,164 @@
+package gol
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// Run Length Encoded (.rle) patterns, as used by Golly and LifeWiki:
+//
+//	#C a glider
+//	x = 3, y = 3, rule = B3/S23
+//	bob$2bo$3o!
+//
+// Lines starting with # are comments. The header gives the bounding box, then the cells
+// follow row by row: b is a dead cell, o (or any other letter) a live one, $ ends a row,
+// and ! ends the pattern. Each of them may be preceded by a repeat count.
+
+// rleLineLength is the longest line encodeRle writes, as the format recommends.
+const rleLineLength = 70
+
+// decodeRle reads an RLE pattern into its bounding box, which may be at most maxPatternCells.
+func decodeRle(data []byte) ([][]uint8, error) {
+	var width, height int
+	var body strings.Builder
+	header := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case !header:
+			var err error
+			if width, height, err = parseRleHeader(line); err != nil {
+				return nil, err
+			}
+			header = true
+		default:
+			body.WriteString(line)
+		}
+	}
+	if !header {
+		return nil, fmt.Errorf("rle: no header line")
+	}
+
+	world := util.NewWorld(width, height)
+	x, y, count := 0, 0, 0
+	for _, r := range body.String() {
+		switch {
+		case r >= '0' && r <= '9':
+			if count = 10*count + int(r-'0'); count > maxPatternCells {
+				return nil, fmt.Errorf("rle: repeat count too large")
+			}
+			continue
+		case r == ' ' || r == '\t':
+			continue
+		case r == '!':
+			return world, nil
+		}
+		n := max(count, 1)
+		count = 0
+		switch {
+		case r == '$':
+			y, x = y+n, 0
+		case r == 'b' || r == '.':
+			x += n
+		case r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z':
+			if y >= height || x+n > width {
+				return nil, fmt.Errorf("rle: cells outside the %dx%d bounding box", width, height)
+			}
+			for i := 0; i < n; i++ {
+				world[y][x+i] = 255
+			}
+			x += n
+		default:
+			return nil, fmt.Errorf("rle: unexpected %q", r)
+		}
+	}
+	return nil, fmt.Errorf("rle: missing '!' at the end of the pattern")
+}
+
+// parseRleHeader reads "x = m, y = n[, rule = B3/S23]". The rule only has to be valid:
+// the pattern evolves by Params.Rule wherever it is placed.
+func parseRleHeader(line string) (width, height int, err error) {
+	width, height = -1, -1
+	for _, field := range strings.Split(line, ",") {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return 0, 0, fmt.Errorf("rle: bad header %q", line)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		switch key {
+		case "x":
+			width, err = strconv.Atoi(value)
+		case "y":
+			height, err = strconv.Atoi(value)
+		case "rule":
+			if _, err := util.ParseRule(value); err != nil {
+				return 0, 0, fmt.Errorf("rle: %v", err)
+			}
+		}
+		if err != nil {
+			return 0, 0, fmt.Errorf("rle: bad header %q", line)
+		}
+	}
+	if width <= 0 || height <= 0 {
+		return 0, 0, fmt.Errorf("rle: bad pattern size in %q", line)
+	}
+	return width, height, checkPatternSize("rle", width, height)
+}
+
+// encodeRle writes the whole world as one RLE pattern evolving by rule. Only live cells are
+// recorded: dying cells of a Generations rule are written as dead.
+func encodeRle(w io.Writer, world [][]uint8, rule util.Rule) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "x = %d, y = %d, rule = %s\n", len(world[0]), len(world), rule)
+
+	line := 0
+	emit := func(count int, tag byte) {
+		token := string(tag)
+		if count > 1 {
+			token = strconv.Itoa(count) + token
+		}
+		if line+len(token) > rleLineLength {
+			bw.WriteByte('\n')
+			line = 0
+		}
+		bw.WriteString(token)
+		line += len(token)
+	}
+
+	rows := 0 // row ends not written yet, so that blank rows merge into one "n$"
+	for _, row := range world {
+		for x := 0; x < len(row); {
+			alive := row[x] == 255
+			n := 1
+			for x+n < len(row) && (row[x+n] == 255) == alive {
+				n++
+			}
+			if alive || x+n < len(row) { // trailing dead cells are left out
+				if rows > 0 {
+					emit(rows, '$')
+					rows = 0
+				}
+				if alive {
+					emit(n, 'o')
+				} else {
+					emit(n, 'b')
+				}
+			}
+			x += n
+		}
+		rows++
+	}
+	emit(1, '!')
+	bw.WriteByte('\n')
+	return bw.Flush()
+}
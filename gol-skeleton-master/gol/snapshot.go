@@ -0,0 +1,68 @@
+package gol
+
+import (
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// Snapshot is everything needed to carry on a simulation later: the parameters it ran
+// with, how many turns it had completed, the rule, and the world itself (bit-packed on disk).
+type Snapshot struct {
+	Params Params
+	Turn   int
+	Rule   string
+	World  util.World
+}
+
+// SaveSnapshot writes s to path. It writes a temporary file first and renames it, so a
+// crash halfway through never leaves a truncated snapshot behind.
+func SaveSnapshot(path string, s Snapshot) error {
+	if s.Rule == "" {
+		rule, err := util.ParseRule(s.Params.Rule)
+		if err != nil {
+			return err
+		}
+		s.Rule = rule.String()
+	}
+	// The token is a secret and the snapshot path only mattered to the run that loaded it.
+	s.Params.Token, s.Params.ResumeFrom = "", ""
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(tmp).Encode(s); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadSnapshot reads a snapshot written by SaveSnapshot and checks it can be resumed.
+func LoadSnapshot(path string) (Snapshot, error) {
+	var s Snapshot
+	f, err := os.Open(path)
+	if err != nil {
+		return s, err
+	}
+	defer f.Close()
+
+	if err := gob.NewDecoder(f).Decode(&s); err != nil {
+		return s, fmt.Errorf("decode snapshot %s: %v", path, err)
+	}
+	if _, err := util.ParseRule(s.Rule); err != nil {
+		return s, fmt.Errorf("snapshot %s: %v", path, err)
+	}
+	if len(s.World) != s.Params.ImageHeight || (len(s.World) > 0 && len(s.World[0]) != s.Params.ImageWidth) {
+		return s, fmt.Errorf("snapshot %s: world does not match its %dx%d parameters", path, s.Params.ImageWidth, s.Params.ImageHeight)
+	}
+	return s, nil
+}
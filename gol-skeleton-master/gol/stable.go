@@ -0,0 +1,82 @@
+package gol
+
+import "uk.ac.bris.cs/gameoflife/util"
+
+// stabilityDetector notices the world repeating itself when Params.DetectPeriod is set. It
+// keeps a Zobrist hash of the world, the XOR of a pseudo-random number for every cell that is
+// not dead, which each turn's flips update without a pass over the world, and the hashes of
+// the last DetectPeriod turns to compare it with. A nil *stabilityDetector detects nothing.
+type stabilityDetector struct {
+	rule   util.Rule
+	width  int
+	hash   uint64
+	recent []uint64 // recent[t%len(recent)] is the hash of turn t, for the last len(recent) turns
+	first  int      // the earliest turn in recent
+	turn   int      // the turn hash is for
+	found  bool
+}
+
+// newStabilityDetector starts watching world as it is on turn. It returns nil unless
+// p.DetectPeriod is positive.
+func newStabilityDetector(p Params, world [][]uint8, turn int) *stabilityDetector {
+	if p.DetectPeriod <= 0 {
+		return nil
+	}
+	s := &stabilityDetector{rule: lifeRule(p), width: p.ImageWidth, recent: make([]uint64, p.DetectPeriod)}
+	s.reset(world, turn)
+	return s
+}
+
+// reset hashes world from scratch, as it is on turn, and forgets earlier turns. It is used
+// after turns the distributor did not see the flips of.
+func (s *stabilityDetector) reset(world [][]uint8, turn int) {
+	if s == nil {
+		return
+	}
+	s.hash = 0
+	for y, row := range world {
+		for x, cell := range row {
+			s.hash ^= cellHash(y*s.width+x, cell)
+		}
+	}
+	s.turn, s.first = turn, turn
+	s.recent[turn%len(s.recent)] = s.hash
+}
+
+// flip hashes in one turn's flips, before they are applied to world, and returns the period
+// the world repeats with if this turn is the first to repeat an earlier one within the last
+// DetectPeriod turns, or 0.
+func (s *stabilityDetector) flip(flipped []util.Cell, world [][]uint8) (period int) {
+	if s == nil {
+		return 0
+	}
+	for _, cell := range flipped {
+		i, old := cell.Y*s.width+cell.X, world[cell.Y][cell.X]
+		s.hash ^= cellHash(i, old) ^ cellHash(i, s.rule.Flip(old))
+	}
+	s.turn++
+	if !s.found {
+		for p := 1; p <= len(s.recent) && s.turn-p >= s.first; p++ {
+			if s.recent[(s.turn-p)%len(s.recent)] == s.hash {
+				s.found, period = true, p
+				break
+			}
+		}
+	}
+	s.recent[s.turn%len(s.recent)] = s.hash
+	return period
+}
+
+// cellHash is the number cell i contributes to the hash when it holds v. Dead cells contribute
+// nothing, so an empty world hashes to 0 and a turn only touches the cells that flipped.
+func cellHash(i int, v uint8) uint64 {
+	if v == 0 {
+		return 0
+	}
+	// splitmix64's finaliser, a cheap mix good enough for 64-bit Zobrist keys
+	z := uint64(i)<<8 | uint64(v)
+	z += 0x9e3779b97f4a7c15
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return z ^ z>>31
+}
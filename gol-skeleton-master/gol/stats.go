@@ -0,0 +1,119 @@
+package gol
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// countFlips returns how many of one turn's flips are births and how many deaths. It has to
+// be called before they are applied to world, which is the world of the previous turn. The
+// flips are all the distributor needs, so this costs nothing like a scan of the world.
+// Dying cells (Generations rules) that decay further were not alive and count as neither.
+func countFlips(flipped []util.Cell, world [][]uint8) (births, deaths int) {
+	for _, cell := range flipped {
+		switch world[cell.Y][cell.X] {
+		case 0:
+			births++
+		case 255:
+			deaths++
+		}
+	}
+	return births, deaths
+}
+
+// turnStats keeps the alive count up to date from each turn's flips and works out the
+// TurnStats event for the turn, when Params.Stats or Params.StatsCSV is set. A nil
+// *turnStats keeps nothing.
+type turnStats struct {
+	alive int
+	cells int
+	csv   *statsRecorder
+}
+
+// newTurnStats starts counting from world, creating the CSV if Params.StatsCSV is set.
+// It returns nil if neither Params.Stats nor Params.StatsCSV is set.
+func newTurnStats(p Params, world [][]uint8) (*turnStats, error) {
+	if !p.Stats && p.StatsCSV == "" {
+		return nil, nil
+	}
+	s := &turnStats{alive: countAlive(world), cells: p.ImageWidth * p.ImageHeight}
+	if p.StatsCSV != "" {
+		var err error
+		if s.csv, err = newStatsRecorder(p.StatsCSV); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// flip counts one turn's flips, before they are applied to world, and returns the statistics
+// of the turn they complete.
+func (s *turnStats) flip(flipped []util.Cell, world [][]uint8, turn int) TurnStats {
+	births, deaths := countFlips(flipped, world)
+	s.alive += births - deaths
+	stats := TurnStats{
+		CompletedTurns: turn,
+		Births:         births,
+		Deaths:         deaths,
+		Change:         births - deaths,
+		Alive:          s.alive,
+	}
+	if s.cells > 0 {
+		stats.Density = float64(s.alive) / float64(s.cells)
+	}
+	if s.csv != nil {
+		s.csv.record(stats)
+	}
+	return stats
+}
+
+// reset recounts the live cells of world after turns the distributor did not see the flips of.
+func (s *turnStats) reset(world [][]uint8) {
+	if s != nil {
+		s.alive = countAlive(world)
+	}
+}
+
+// Close flushes the CSV, if any.
+func (s *turnStats) Close() error {
+	if s == nil || s.csv == nil {
+		return nil
+	}
+	return s.csv.Close()
+}
+
+// statsRecorder writes one CSV row per turn when Params.StatsCSV is set:
+//
+//	turn,births,deaths,change,alive,density
+type statsRecorder struct {
+	file *os.File
+	w    *bufio.Writer
+}
+
+// newStatsRecorder creates the CSV at path.
+func newStatsRecorder(path string) (*statsRecorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &statsRecorder{file: file, w: bufio.NewWriter(file)}
+	fmt.Fprintln(r.w, "turn,births,deaths,change,alive,density")
+	return r, nil
+}
+
+// record adds the row for one turn.
+func (r *statsRecorder) record(s TurnStats) {
+	fmt.Fprintf(r.w, "%d,%d,%d,%d,%d,%.6f\n", s.CompletedTurns, s.Births, s.Deaths, s.Change, s.Alive, s.Density)
+}
+
+// Close flushes the CSV.
+func (r *statsRecorder) Close() error {
+	if err := r.w.Flush(); err != nil {
+		_ = r.file.Close()
+		return err
+	}
+	return r.file.Close()
+}
@@ -0,0 +1,131 @@
+package gol
+
+import (
+	"fmt"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// RunOptions are the optional extras of RunSync. The zero value runs to the end untouched.
+type RunOptions struct {
+	// Keys, if set, passes keypresses on to the run as the keyPresses channel of Run does,
+	// e.g. 'q' to stop early.
+	Keys <-chan rune
+
+	// OnEvent, if set, is called with every event of the run, in order, e.g. to report
+	// progress. The run waits for it to return.
+	OnEvent func(Event)
+}
+
+// Stats sums up a run of RunSync.
+type Stats struct {
+	Turns          int           // turns completed, fewer than Params.Turns if the run stopped early
+	Alive          int           // live cells in the final world
+	Elapsed        time.Duration // from starting the run to the final turn
+	TurnsPerSecond float64       // over the whole run
+	Period         int           // the period of the Stabilized event, 0 if there wasn't one
+	Output         string        // the file the final world was saved as (see ImageOutputComplete)
+}
+
+// RunSync runs the simulation described by p, as Run does, and waits for it to finish, for
+// library users and tests that just want the answer rather than the events. It returns the
+// final world, 255 for live cells, the greys of dying ones with a Generations rule and 0 for
+// the rest, worked out from the events of the run, so the whole world is shown (ViewWidth and
+// ViewHeight are ignored). With Params.Benchmark no per-turn events are sent and the world
+// only has the live cells of FinalTurnComplete. err is that of the ErrorEvent of a failed run,
+// or, before anything is run, of an input image or pattern that can't be read (see checkInput).
+func RunSync(p Params, opts RunOptions) (world [][]uint8, stats Stats, err error) {
+	if err := checkInput(p); err != nil {
+		return nil, stats, err
+	}
+	p.ViewWidth, p.ViewHeight = 0, 0
+	events := make(chan Event, 1000)
+	start := time.Now()
+	go Run(p, events, opts.Keys)
+
+	rule := lifeRule(p)
+	world = util.NewWorld(p.ImageWidth, p.ImageHeight)
+	first := -1 // the turn the run started from, e.g. when resuming
+	for event := range events {
+		if opts.OnEvent != nil {
+			opts.OnEvent(event)
+		}
+		switch e := event.(type) {
+		case CellsFlipped:
+			for i, cell := range e.Cells {
+				if e.Values != nil {
+					world[cell.Y][cell.X] = e.Values[i]
+				} else {
+					world[cell.Y][cell.X] = rule.Flip(world[cell.Y][cell.X])
+				}
+			}
+		case CellFlipped:
+			world[e.Cell.Y][e.Cell.X] = rule.Flip(world[e.Cell.Y][e.Cell.X])
+		case RegionsFlipped:
+			for _, region := range e.Regions {
+				for i, v := range region.Cells {
+					world[region.Y+i/region.Width][region.X+i%region.Width] = v
+				}
+			}
+		case TurnComplete:
+			if first < 0 {
+				first = e.CompletedTurns
+			}
+		case Stabilized:
+			stats.Period = e.Period
+		case FinalTurnComplete:
+			stats.Elapsed = time.Since(start)
+			if p.Benchmark != "" {
+				world = util.NewWorld(p.ImageWidth, p.ImageHeight)
+				for _, cell := range e.Alive {
+					world[cell.Y][cell.X] = 255
+				}
+			}
+		case ImageOutputComplete:
+			stats.Output = e.Filename
+		case ErrorEvent:
+			err = e.Err
+		case StateChange:
+			stats.Turns = e.CompletedTurns // the last event is the StateChange to Quitting
+		}
+	}
+	stats.Alive = countAlive(world)
+	if stats.Elapsed == 0 {
+		stats.Elapsed = time.Since(start)
+	}
+	if seconds := stats.Elapsed.Seconds(); seconds > 0 && first >= 0 {
+		stats.TurnsPerSecond = float64(stats.Turns-first) / seconds
+	}
+	return world, stats, err
+}
+
+// checkInput reads the image and patterns a new run of p starts from, as the io goroutine will,
+// and returns the error it would panic with: a file that is missing, unreadable or not
+// ImageWidth×ImageHeight. Runs resuming from the broker or a snapshot may not read them at all
+// and aren't checked.
+func checkInput(p Params) error {
+	if p.Resume || p.ResumeFrom != "" || p.Observe {
+		return nil
+	}
+	for _, place := range p.Place {
+		if _, err := place.load(); err != nil {
+			return err
+		}
+	}
+	if p.Random != nil || (p.Input == "" && len(p.Place) > 0) {
+		return nil
+	}
+	path := p.Input
+	if path == "" {
+		path = fmt.Sprintf("images/%dx%d.pgm", p.ImageWidth, p.ImageHeight)
+	}
+	world, err := ReadWorld(path)
+	if err != nil || IsPattern(path) {
+		return err
+	}
+	if len(world[0]) != p.ImageWidth || len(world) != p.ImageHeight {
+		return fmt.Errorf("%s: world is %dx%d, not %dx%d", path, len(world[0]), len(world), p.ImageWidth, p.ImageHeight)
+	}
+	return nil
+}
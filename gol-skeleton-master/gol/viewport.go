@@ -0,0 +1,141 @@
+package gol
+
+import "uk.ac.bris.cs/gameoflife/util"
+
+// Keys that pan and zoom the viewport while running; sdl sends the arrow keys as the arrow
+// runes. '+' and '-' already change the turn rate, so zooming is on z and x.
+const (
+	KeyPanLeft  = '←'
+	KeyPanRight = '→'
+	KeyPanUp    = '↑'
+	KeyPanDown  = '↓'
+	KeyZoomIn   = 'z'
+	KeyZoomOut  = 'x'
+)
+
+// maxZoom is how many pixels to a side a cell gets at most.
+const maxZoom = 16
+
+// viewport is the part of the world shown in a window smaller than it, set by Params.ViewWidth
+// and ViewHeight. The world is still evolved and kept whole; the distributor only leaves out of
+// CellsFlipped the cells outside the viewport, so a huge world doesn't send a window millions
+// of flips it can't show. Zooming in doubles the pixels to a side of a cell, keeping the
+// middle of the view where it was, and panning moves the view a quarter of its size. Each
+// time it changes a ViewportChanged event is sent, followed by every cell in the new view that
+// isn't dead. A nil *viewport shows the whole world and filters nothing.
+type viewport struct {
+	width, height int // of the window, in pixels
+	worldW        int
+	worldH        int
+	x, y          int // the top-left cell shown
+	zoom          int // pixels to a side of a cell
+}
+
+// ViewSize returns the size in pixels of the window showing the world: the whole world, one
+// pixel per cell, unless Params.ViewWidth or ViewHeight make it smaller.
+func ViewSize(p Params) (width, height int) {
+	width, height = p.ImageWidth, p.ImageHeight
+	if p.ViewWidth > 0 {
+		width = min(width, p.ViewWidth)
+	}
+	if p.ViewHeight > 0 {
+		height = min(height, p.ViewHeight)
+	}
+	return width, height
+}
+
+// newViewport returns nil unless the window is smaller than the world.
+func newViewport(p Params) *viewport {
+	width, height := ViewSize(p)
+	if width == p.ImageWidth && height == p.ImageHeight {
+		return nil
+	}
+	return &viewport{width: width, height: height, worldW: p.ImageWidth, worldH: p.ImageHeight, zoom: 1}
+}
+
+// size returns how many columns and rows of cells are in view, counting cells only partly in
+// the window.
+func (v *viewport) size() (cols, rows int) {
+	return min((v.width+v.zoom-1)/v.zoom, v.worldW), min((v.height+v.zoom-1)/v.zoom, v.worldH)
+}
+
+// key pans or zooms for one of the Key runes and reports whether the view changed.
+func (v *viewport) key(key rune) bool {
+	x, y, zoom := v.x, v.y, v.zoom
+	cols, rows := v.size()
+	switch key {
+	case KeyPanLeft:
+		v.x -= max(cols/4, 1)
+	case KeyPanRight:
+		v.x += max(cols/4, 1)
+	case KeyPanUp:
+		v.y -= max(rows/4, 1)
+	case KeyPanDown:
+		v.y += max(rows/4, 1)
+	case KeyZoomIn, KeyZoomOut:
+		if key == KeyZoomIn && v.zoom < maxZoom {
+			v.zoom *= 2
+		} else if key == KeyZoomOut && v.zoom > 1 {
+			v.zoom /= 2
+		}
+		centreX, centreY := v.x+cols/2, v.y+rows/2
+		cols, rows = v.size()
+		v.x, v.y = centreX-cols/2, centreY-rows/2
+	default:
+		return false
+	}
+	cols, rows = v.size()
+	v.x = max(min(v.x, v.worldW-cols), 0)
+	v.y = max(min(v.y, v.worldH-rows), 0)
+	return v.x != x || v.y != y || v.zoom != zoom
+}
+
+// visible reports whether cell is in view.
+func (v *viewport) visible(cell util.Cell) bool {
+	cols, rows := v.size()
+	return cell.X >= v.x && cell.X < v.x+cols && cell.Y >= v.y && cell.Y < v.y+rows
+}
+
+// filter returns the cells, and their values if there are any, that are in view.
+func (v *viewport) filter(cells []util.Cell, values []uint8) ([]util.Cell, []uint8) {
+	if v == nil {
+		return cells, values
+	}
+	var inView []util.Cell
+	var inViewValues []uint8
+	for i, cell := range cells {
+		if !v.visible(cell) {
+			continue
+		}
+		inView = append(inView, cell)
+		if values != nil {
+			inViewValues = append(inViewValues, values[i])
+		}
+	}
+	return inView, inViewValues
+}
+
+// event returns the ViewportChanged event for the view as it is.
+func (v *viewport) event(turn int) ViewportChanged {
+	cols, rows := v.size()
+	return ViewportChanged{CompletedTurns: turn, X: v.x, Y: v.y, Width: cols, Height: rows, Zoom: v.zoom}
+}
+
+// redraw returns the events that show the view afresh after it changed: ViewportChanged, then
+// a CellsFlipped with every cell of world in view that isn't dead.
+func (v *viewport) redraw(world [][]uint8, turn int, rule util.Rule) []Event {
+	events := []Event{v.event(turn)}
+	cols, rows := v.size()
+	var cells []util.Cell
+	for y := v.y; y < v.y+rows; y++ {
+		for x := v.x; x < v.x+cols; x++ {
+			if world[y][x] != 0 {
+				cells = append(cells, util.Cell{X: x, Y: y})
+			}
+		}
+	}
+	if len(cells) > 0 {
+		events = append(events, CellsFlipped{CompletedTurns: turn, Cells: cells, Values: cellValues(world, cells, rule)})
+	}
+	return events
+}
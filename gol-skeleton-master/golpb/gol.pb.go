@@ -0,0 +1,3514 @@
+// gRPC definitions for the distributed Game of Life.
+// These mirror the net/rpc types used by distributor, broker and worker so that
+// components written in other languages can join the system.
+//
+// Regenerate golpb/ after editing:
+//   protoc -I proto --go_out=golpb --go_opt=paths=source_relative \
+//          --go-grpc_out=golpb --go-grpc_opt=paths=source_relative proto/gol.proto
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        v5.29.3
+// source: gol.proto
+
+package golpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Events mirror gol/event.go so that non-Go front ends can consume them.
+type State int32
+
+const (
+	State_PAUSED    State = 0
+	State_EXECUTING State = 1
+	State_QUITTING  State = 2
+)
+
+// Enum value maps for State.
+var (
+	State_name = map[int32]string{
+		0: "PAUSED",
+		1: "EXECUTING",
+		2: "QUITTING",
+	}
+	State_value = map[string]int32{
+		"PAUSED":    0,
+		"EXECUTING": 1,
+		"QUITTING":  2,
+	}
+)
+
+func (x State) Enum() *State {
+	p := new(State)
+	*p = x
+	return p
+}
+
+func (x State) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (State) Descriptor() protoreflect.EnumDescriptor {
+	return file_gol_proto_enumTypes[0].Descriptor()
+}
+
+func (State) Type() protoreflect.EnumType {
+	return &file_gol_proto_enumTypes[0]
+}
+
+func (x State) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use State.Descriptor instead.
+func (State) EnumDescriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{0}
+}
+
+type Empty struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Empty) Reset() {
+	*x = Empty{}
+	mi := &file_gol_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Empty) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Empty) ProtoMessage() {}
+
+func (x *Empty) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Empty.ProtoReflect.Descriptor instead.
+func (*Empty) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{0}
+}
+
+// Names the simulation a call is about, on a broker hosting several; empty is the default one.
+type JobArgs struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JobArgs) Reset() {
+	*x = JobArgs{}
+	mi := &file_gol_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobArgs) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobArgs) ProtoMessage() {}
+
+func (x *JobArgs) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobArgs.ProtoReflect.Descriptor instead.
+func (*JobArgs) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *JobArgs) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type WorldParams struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ImageWidth  int32                  `protobuf:"varint,1,opt,name=image_width,json=imageWidth,proto3" json:"image_width,omitempty"`
+	ImageHeight int32                  `protobuf:"varint,2,opt,name=image_height,json=imageHeight,proto3" json:"image_height,omitempty"`
+	World       *World                 `protobuf:"bytes,3,opt,name=world,proto3" json:"world,omitempty"`
+	// StartSimulation: the turn the world is at (non-zero when resuming a snapshot).
+	Turn int32 `protobuf:"varint,4,opt,name=turn,proto3" json:"turn,omitempty"`
+	// What cells on the edge see beyond it: "torus" (or empty), "dead" or "mirror".
+	Boundary string `protobuf:"bytes,5,opt,name=boundary,proto3" json:"boundary,omitempty"`
+	// The rule in B/S notation, e.g. "B36/S23"; empty is B3/S23.
+	Rule          string `protobuf:"bytes,6,opt,name=rule,proto3" json:"rule,omitempty"`
+	JobId         string `protobuf:"bytes,7,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WorldParams) Reset() {
+	*x = WorldParams{}
+	mi := &file_gol_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorldParams) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorldParams) ProtoMessage() {}
+
+func (x *WorldParams) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorldParams.ProtoReflect.Descriptor instead.
+func (*WorldParams) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *WorldParams) GetImageWidth() int32 {
+	if x != nil {
+		return x.ImageWidth
+	}
+	return 0
+}
+
+func (x *WorldParams) GetImageHeight() int32 {
+	if x != nil {
+		return x.ImageHeight
+	}
+	return 0
+}
+
+func (x *WorldParams) GetWorld() *World {
+	if x != nil {
+		return x.World
+	}
+	return nil
+}
+
+func (x *WorldParams) GetTurn() int32 {
+	if x != nil {
+		return x.Turn
+	}
+	return 0
+}
+
+func (x *WorldParams) GetBoundary() string {
+	if x != nil {
+		return x.Boundary
+	}
+	return ""
+}
+
+func (x *WorldParams) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+func (x *WorldParams) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+// A world (or a band / tile of one) packed one bit per cell: cell (x, y) is bit
+// i % 64 of bits[i / 64], where i = y * width + x. 1 is alive.
+type World struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Width  int32                  `protobuf:"varint,1,opt,name=width,proto3" json:"width,omitempty"`
+	Height int32                  `protobuf:"varint,2,opt,name=height,proto3" json:"height,omitempty"`
+	Bits   []uint64               `protobuf:"fixed64,3,rep,packed,name=bits,proto3" json:"bits,omitempty"`
+	// Mostly empty worlds leave bits empty and list their live cells instead: the index
+	// y*width+x of the first, then the gap from each live cell to the next.
+	Cells []uint64 `protobuf:"varint,4,rep,packed,name=cells,proto3" json:"cells,omitempty"`
+	// Worlds with dying cells (Generations rules) leave both empty and send every cell as a
+	// byte instead, row by row.
+	States        []byte `protobuf:"bytes,5,opt,name=states,proto3" json:"states,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *World) Reset() {
+	*x = World{}
+	mi := &file_gol_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *World) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*World) ProtoMessage() {}
+
+func (x *World) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use World.ProtoReflect.Descriptor instead.
+func (*World) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *World) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *World) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *World) GetBits() []uint64 {
+	if x != nil {
+		return x.Bits
+	}
+	return nil
+}
+
+func (x *World) GetCells() []uint64 {
+	if x != nil {
+		return x.Cells
+	}
+	return nil
+}
+
+func (x *World) GetStates() []byte {
+	if x != nil {
+		return x.States
+	}
+	return nil
+}
+
+// A chunk of consecutive rows, used by the streaming world transfer RPCs.
+type RowChunk struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Seq    int32                  `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	StartY int32                  `protobuf:"varint,2,opt,name=start_y,json=startY,proto3" json:"start_y,omitempty"`
+	Rows   *World                 `protobuf:"bytes,3,opt,name=rows,proto3" json:"rows,omitempty"`
+	// Only set on the first chunk of an upload.
+	ImageWidth    int32  `protobuf:"varint,4,opt,name=image_width,json=imageWidth,proto3" json:"image_width,omitempty"`
+	ImageHeight   int32  `protobuf:"varint,5,opt,name=image_height,json=imageHeight,proto3" json:"image_height,omitempty"`
+	Turn          int32  `protobuf:"varint,6,opt,name=turn,proto3" json:"turn,omitempty"`
+	Boundary      string `protobuf:"bytes,7,opt,name=boundary,proto3" json:"boundary,omitempty"`
+	Rule          string `protobuf:"bytes,8,opt,name=rule,proto3" json:"rule,omitempty"`
+	JobId         string `protobuf:"bytes,9,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RowChunk) Reset() {
+	*x = RowChunk{}
+	mi := &file_gol_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RowChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RowChunk) ProtoMessage() {}
+
+func (x *RowChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RowChunk.ProtoReflect.Descriptor instead.
+func (*RowChunk) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *RowChunk) GetSeq() int32 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *RowChunk) GetStartY() int32 {
+	if x != nil {
+		return x.StartY
+	}
+	return 0
+}
+
+func (x *RowChunk) GetRows() *World {
+	if x != nil {
+		return x.Rows
+	}
+	return nil
+}
+
+func (x *RowChunk) GetImageWidth() int32 {
+	if x != nil {
+		return x.ImageWidth
+	}
+	return 0
+}
+
+func (x *RowChunk) GetImageHeight() int32 {
+	if x != nil {
+		return x.ImageHeight
+	}
+	return 0
+}
+
+func (x *RowChunk) GetTurn() int32 {
+	if x != nil {
+		return x.Turn
+	}
+	return 0
+}
+
+func (x *RowChunk) GetBoundary() string {
+	if x != nil {
+		return x.Boundary
+	}
+	return ""
+}
+
+func (x *RowChunk) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+func (x *RowChunk) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type Cell struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	X             int32                  `protobuf:"varint,1,opt,name=x,proto3" json:"x,omitempty"`
+	Y             int32                  `protobuf:"varint,2,opt,name=y,proto3" json:"y,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Cell) Reset() {
+	*x = Cell{}
+	mi := &file_gol_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Cell) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Cell) ProtoMessage() {}
+
+func (x *Cell) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Cell.ProtoReflect.Descriptor instead.
+func (*Cell) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Cell) GetX() int32 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *Cell) GetY() int32 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+type Count struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Value         int64                  `protobuf:"varint,1,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Count) Reset() {
+	*x = Count{}
+	mi := &file_gol_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Count) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Count) ProtoMessage() {}
+
+func (x *Count) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Count.ProtoReflect.Descriptor instead.
+func (*Count) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Count) GetValue() int64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+type Ok struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ok            bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Ok) Reset() {
+	*x = Ok{}
+	mi := &file_gol_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Ok) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ok) ProtoMessage() {}
+
+func (x *Ok) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ok.ProtoReflect.Descriptor instead.
+func (*Ok) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Ok) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+type RegisterArgs struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Address string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Port    int32                  `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"`
+	// "rpc" (default) or "grpc": how the broker should dial the worker back.
+	// "unix" dials the Unix domain socket at address with net/rpc, port unused.
+	// "reverse" uses the connection the worker opened to the broker under the id in address, port unused.
+	Transport string `protobuf:"bytes,3,opt,name=transport,proto3" json:"transport,omitempty"`
+	// Benchmark throughput in cells/sec; the broker sizes row bands by it. 0 = unknown.
+	Score float64 `protobuf:"fixed64,4,opt,name=score,proto3" json:"score,omitempty"`
+	// What the worker can do, so a mixed fleet can be upgraded one worker at a time.
+	// Workers that leave these unset are treated as protocol version 0.
+	Version int32 `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"`
+	// World encodings it can decode over net/rpc: "raw", "packed", "sparse", "rle".
+	Codecs []string `protobuf:"bytes,6,rep,name=codecs,proto3" json:"codecs,omitempty"`
+	Cores  int32    `protobuf:"varint,7,opt,name=cores,proto3" json:"cores,omitempty"`
+	// Rule kinds it can run: "conway", "life-like", "generations".
+	Rules         []string `protobuf:"bytes,8,rep,name=rules,proto3" json:"rules,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterArgs) Reset() {
+	*x = RegisterArgs{}
+	mi := &file_gol_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterArgs) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterArgs) ProtoMessage() {}
+
+func (x *RegisterArgs) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterArgs.ProtoReflect.Descriptor instead.
+func (*RegisterArgs) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RegisterArgs) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *RegisterArgs) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *RegisterArgs) GetTransport() string {
+	if x != nil {
+		return x.Transport
+	}
+	return ""
+}
+
+func (x *RegisterArgs) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *RegisterArgs) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *RegisterArgs) GetCodecs() []string {
+	if x != nil {
+		return x.Codecs
+	}
+	return nil
+}
+
+func (x *RegisterArgs) GetCores() int32 {
+	if x != nil {
+		return x.Cores
+	}
+	return 0
+}
+
+func (x *RegisterArgs) GetRules() []string {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+type NextTurnReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Turn          int32                  `protobuf:"varint,1,opt,name=turn,proto3" json:"turn,omitempty"`
+	Flipped       []*Cell                `protobuf:"bytes,2,rep,name=flipped,proto3" json:"flipped,omitempty"`
+	Timing        *StepTiming            `protobuf:"bytes,3,opt,name=timing,proto3" json:"timing,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NextTurnReply) Reset() {
+	*x = NextTurnReply{}
+	mi := &file_gol_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NextTurnReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NextTurnReply) ProtoMessage() {}
+
+func (x *NextTurnReply) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NextTurnReply.ProtoReflect.Descriptor instead.
+func (*NextTurnReply) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *NextTurnReply) GetTurn() int32 {
+	if x != nil {
+		return x.Turn
+	}
+	return 0
+}
+
+func (x *NextTurnReply) GetFlipped() []*Cell {
+	if x != nil {
+		return x.Flipped
+	}
+	return nil
+}
+
+func (x *NextTurnReply) GetTiming() *StepTiming {
+	if x != nil {
+		return x.Timing
+	}
+	return nil
+}
+
+// How long one turn took on the broker.
+type StepTiming struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// From handing the turn out to the workers to having all their results.
+	ElapsedNs int64 `protobuf:"varint,1,opt,name=elapsed_ns,json=elapsedNs,proto3" json:"elapsed_ns,omitempty"`
+	// The worker whose call took longest, empty for turns the broker evolved itself.
+	Slowest          string `protobuf:"bytes,2,opt,name=slowest,proto3" json:"slowest,omitempty"`
+	SlowestElapsedNs int64  `protobuf:"varint,3,opt,name=slowest_elapsed_ns,json=slowestElapsedNs,proto3" json:"slowest_elapsed_ns,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *StepTiming) Reset() {
+	*x = StepTiming{}
+	mi := &file_gol_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StepTiming) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StepTiming) ProtoMessage() {}
+
+func (x *StepTiming) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StepTiming.ProtoReflect.Descriptor instead.
+func (*StepTiming) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *StepTiming) GetElapsedNs() int64 {
+	if x != nil {
+		return x.ElapsedNs
+	}
+	return 0
+}
+
+func (x *StepTiming) GetSlowest() string {
+	if x != nil {
+		return x.Slowest
+	}
+	return ""
+}
+
+func (x *StepTiming) GetSlowestElapsedNs() int64 {
+	if x != nil {
+		return x.SlowestElapsedNs
+	}
+	return 0
+}
+
+type ProcessTurnsArgs struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Turns         int32                  `protobuf:"varint,1,opt,name=turns,proto3" json:"turns,omitempty"`
+	JobId         string                 `protobuf:"bytes,2,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProcessTurnsArgs) Reset() {
+	*x = ProcessTurnsArgs{}
+	mi := &file_gol_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessTurnsArgs) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessTurnsArgs) ProtoMessage() {}
+
+func (x *ProcessTurnsArgs) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessTurnsArgs.ProtoReflect.Descriptor instead.
+func (*ProcessTurnsArgs) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ProcessTurnsArgs) GetTurns() int32 {
+	if x != nil {
+		return x.Turns
+	}
+	return 0
+}
+
+func (x *ProcessTurnsArgs) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type TurnFlips struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cells         []*Cell                `protobuf:"bytes,1,rep,name=cells,proto3" json:"cells,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TurnFlips) Reset() {
+	*x = TurnFlips{}
+	mi := &file_gol_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TurnFlips) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TurnFlips) ProtoMessage() {}
+
+func (x *TurnFlips) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TurnFlips.ProtoReflect.Descriptor instead.
+func (*TurnFlips) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *TurnFlips) GetCells() []*Cell {
+	if x != nil {
+		return x.Cells
+	}
+	return nil
+}
+
+// Only the flips of each turn; the controller applies them to its own copy of the world.
+type ProcessTurnsReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Turn          int32                  `protobuf:"varint,1,opt,name=turn,proto3" json:"turn,omitempty"`
+	Flipped       []*TurnFlips           `protobuf:"bytes,3,rep,name=flipped,proto3" json:"flipped,omitempty"`
+	Timings       []*StepTiming          `protobuf:"bytes,4,rep,name=timings,proto3" json:"timings,omitempty"` // timings[i] for flipped[i]
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProcessTurnsReply) Reset() {
+	*x = ProcessTurnsReply{}
+	mi := &file_gol_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessTurnsReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessTurnsReply) ProtoMessage() {}
+
+func (x *ProcessTurnsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessTurnsReply.ProtoReflect.Descriptor instead.
+func (*ProcessTurnsReply) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ProcessTurnsReply) GetTurn() int32 {
+	if x != nil {
+		return x.Turn
+	}
+	return 0
+}
+
+func (x *ProcessTurnsReply) GetFlipped() []*TurnFlips {
+	if x != nil {
+		return x.Flipped
+	}
+	return nil
+}
+
+func (x *ProcessTurnsReply) GetTimings() []*StepTiming {
+	if x != nil {
+		return x.Timings
+	}
+	return nil
+}
+
+type DetachArgs struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Turns         int32                  `protobuf:"varint,1,opt,name=turns,proto3" json:"turns,omitempty"`
+	JobId         string                 `protobuf:"bytes,2,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DetachArgs) Reset() {
+	*x = DetachArgs{}
+	mi := &file_gol_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DetachArgs) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DetachArgs) ProtoMessage() {}
+
+func (x *DetachArgs) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DetachArgs.ProtoReflect.Descriptor instead.
+func (*DetachArgs) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *DetachArgs) GetTurns() int32 {
+	if x != nil {
+		return x.Turns
+	}
+	return 0
+}
+
+func (x *DetachArgs) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type AttachReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Turn          int32                  `protobuf:"varint,1,opt,name=turn,proto3" json:"turn,omitempty"`
+	World         *World                 `protobuf:"bytes,2,opt,name=world,proto3" json:"world,omitempty"`
+	Rule          string                 `protobuf:"bytes,3,opt,name=rule,proto3" json:"rule,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AttachReply) Reset() {
+	*x = AttachReply{}
+	mi := &file_gol_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttachReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttachReply) ProtoMessage() {}
+
+func (x *AttachReply) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttachReply.ProtoReflect.Descriptor instead.
+func (*AttachReply) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *AttachReply) GetTurn() int32 {
+	if x != nil {
+		return x.Turn
+	}
+	return 0
+}
+
+func (x *AttachReply) GetWorld() *World {
+	if x != nil {
+		return x.World
+	}
+	return nil
+}
+
+func (x *AttachReply) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+type SubscriptionArgs struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Numbered per job.
+	Id            int32  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	JobId         string `protobuf:"bytes,2,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscriptionArgs) Reset() {
+	*x = SubscriptionArgs{}
+	mi := &file_gol_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscriptionArgs) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscriptionArgs) ProtoMessage() {}
+
+func (x *SubscriptionArgs) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscriptionArgs.ProtoReflect.Descriptor instead.
+func (*SubscriptionArgs) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *SubscriptionArgs) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *SubscriptionArgs) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type SubscribeReply struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Turn  int32                  `protobuf:"varint,2,opt,name=turn,proto3" json:"turn,omitempty"`
+	// Empty until a simulation has been started.
+	World *World `protobuf:"bytes,3,opt,name=world,proto3" json:"world,omitempty"`
+	// The rule world evolves by, set with it.
+	Rule          string `protobuf:"bytes,4,opt,name=rule,proto3" json:"rule,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeReply) Reset() {
+	*x = SubscribeReply{}
+	mi := &file_gol_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeReply) ProtoMessage() {}
+
+func (x *SubscribeReply) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeReply.ProtoReflect.Descriptor instead.
+func (*SubscribeReply) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *SubscribeReply) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *SubscribeReply) GetTurn() int32 {
+	if x != nil {
+		return x.Turn
+	}
+	return 0
+}
+
+func (x *SubscribeReply) GetWorld() *World {
+	if x != nil {
+		return x.World
+	}
+	return nil
+}
+
+func (x *SubscribeReply) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+type TurnDelta struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Turn          int32                  `protobuf:"varint,1,opt,name=turn,proto3" json:"turn,omitempty"`
+	Flipped       []*Cell                `protobuf:"bytes,2,rep,name=flipped,proto3" json:"flipped,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TurnDelta) Reset() {
+	*x = TurnDelta{}
+	mi := &file_gol_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TurnDelta) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TurnDelta) ProtoMessage() {}
+
+func (x *TurnDelta) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TurnDelta.ProtoReflect.Descriptor instead.
+func (*TurnDelta) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *TurnDelta) GetTurn() int32 {
+	if x != nil {
+		return x.Turn
+	}
+	return 0
+}
+
+func (x *TurnDelta) GetFlipped() []*Cell {
+	if x != nil {
+		return x.Flipped
+	}
+	return nil
+}
+
+type PollReply struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Turn  int32                  `protobuf:"varint,1,opt,name=turn,proto3" json:"turn,omitempty"`
+	// Set when the observer has to resync; deltas is then empty.
+	World  *World       `protobuf:"bytes,2,opt,name=world,proto3" json:"world,omitempty"`
+	Deltas []*TurnDelta `protobuf:"bytes,3,rep,name=deltas,proto3" json:"deltas,omitempty"`
+	// The rule world evolves by, set with it.
+	Rule          string `protobuf:"bytes,4,opt,name=rule,proto3" json:"rule,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PollReply) Reset() {
+	*x = PollReply{}
+	mi := &file_gol_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PollReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PollReply) ProtoMessage() {}
+
+func (x *PollReply) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PollReply.ProtoReflect.Descriptor instead.
+func (*PollReply) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *PollReply) GetTurn() int32 {
+	if x != nil {
+		return x.Turn
+	}
+	return 0
+}
+
+func (x *PollReply) GetWorld() *World {
+	if x != nil {
+		return x.World
+	}
+	return nil
+}
+
+func (x *PollReply) GetDeltas() []*TurnDelta {
+	if x != nil {
+		return x.Deltas
+	}
+	return nil
+}
+
+func (x *PollReply) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+type AliveArgs struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// How long the broker waits before reporting, in nanoseconds.
+	Interval      int64  `protobuf:"varint,1,opt,name=interval,proto3" json:"interval,omitempty"`
+	JobId         string `protobuf:"bytes,2,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AliveArgs) Reset() {
+	*x = AliveArgs{}
+	mi := &file_gol_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AliveArgs) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AliveArgs) ProtoMessage() {}
+
+func (x *AliveArgs) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AliveArgs.ProtoReflect.Descriptor instead.
+func (*AliveArgs) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *AliveArgs) GetInterval() int64 {
+	if x != nil {
+		return x.Interval
+	}
+	return 0
+}
+
+func (x *AliveArgs) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+// The alive cells at the end of turn.
+type AliveReport struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Turn          int32                  `protobuf:"varint,1,opt,name=turn,proto3" json:"turn,omitempty"`
+	Count         int64                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AliveReport) Reset() {
+	*x = AliveReport{}
+	mi := &file_gol_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AliveReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AliveReport) ProtoMessage() {}
+
+func (x *AliveReport) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AliveReport.ProtoReflect.Descriptor instead.
+func (*AliveReport) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *AliveReport) GetTurn() int32 {
+	if x != nil {
+		return x.Turn
+	}
+	return 0
+}
+
+func (x *AliveReport) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+// A simulation to queue on the broker, which evolves it to turns with no controller attached.
+type SubmitArgs struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ImageWidth  int32                  `protobuf:"varint,1,opt,name=image_width,json=imageWidth,proto3" json:"image_width,omitempty"`
+	ImageHeight int32                  `protobuf:"varint,2,opt,name=image_height,json=imageHeight,proto3" json:"image_height,omitempty"`
+	World       *World                 `protobuf:"bytes,3,opt,name=world,proto3" json:"world,omitempty"`
+	Turns       int32                  `protobuf:"varint,4,opt,name=turns,proto3" json:"turns,omitempty"`
+	Boundary    string                 `protobuf:"bytes,5,opt,name=boundary,proto3" json:"boundary,omitempty"`
+	Rule        string                 `protobuf:"bytes,6,opt,name=rule,proto3" json:"rule,omitempty"`
+	// Empty lets the broker name the job.
+	JobId         string `protobuf:"bytes,7,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitArgs) Reset() {
+	*x = SubmitArgs{}
+	mi := &file_gol_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitArgs) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitArgs) ProtoMessage() {}
+
+func (x *SubmitArgs) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitArgs.ProtoReflect.Descriptor instead.
+func (*SubmitArgs) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *SubmitArgs) GetImageWidth() int32 {
+	if x != nil {
+		return x.ImageWidth
+	}
+	return 0
+}
+
+func (x *SubmitArgs) GetImageHeight() int32 {
+	if x != nil {
+		return x.ImageHeight
+	}
+	return 0
+}
+
+func (x *SubmitArgs) GetWorld() *World {
+	if x != nil {
+		return x.World
+	}
+	return nil
+}
+
+func (x *SubmitArgs) GetTurns() int32 {
+	if x != nil {
+		return x.Turns
+	}
+	return 0
+}
+
+func (x *SubmitArgs) GetBoundary() string {
+	if x != nil {
+		return x.Boundary
+	}
+	return ""
+}
+
+func (x *SubmitArgs) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+func (x *SubmitArgs) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type SubmitReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitReply) Reset() {
+	*x = SubmitReply{}
+	mi := &file_gol_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitReply) ProtoMessage() {}
+
+func (x *SubmitReply) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitReply.ProtoReflect.Descriptor instead.
+func (*SubmitReply) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *SubmitReply) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type JobProgressReply struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// "queued", "running", "done", "failed" or "taken over" (by a controller, before it was done).
+	State string `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"`
+	Turn  int32  `protobuf:"varint,2,opt,name=turn,proto3" json:"turn,omitempty"`
+	Turns int32  `protobuf:"varint,3,opt,name=turns,proto3" json:"turns,omitempty"`
+	Alive int64  `protobuf:"varint,4,opt,name=alive,proto3" json:"alive,omitempty"`
+	// Why it failed.
+	Err           string `protobuf:"bytes,5,opt,name=err,proto3" json:"err,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JobProgressReply) Reset() {
+	*x = JobProgressReply{}
+	mi := &file_gol_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobProgressReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobProgressReply) ProtoMessage() {}
+
+func (x *JobProgressReply) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobProgressReply.ProtoReflect.Descriptor instead.
+func (*JobProgressReply) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *JobProgressReply) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *JobProgressReply) GetTurn() int32 {
+	if x != nil {
+		return x.Turn
+	}
+	return 0
+}
+
+func (x *JobProgressReply) GetTurns() int32 {
+	if x != nil {
+		return x.Turns
+	}
+	return 0
+}
+
+func (x *JobProgressReply) GetAlive() int64 {
+	if x != nil {
+		return x.Alive
+	}
+	return 0
+}
+
+func (x *JobProgressReply) GetErr() string {
+	if x != nil {
+		return x.Err
+	}
+	return ""
+}
+
+type JobResultReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Turn          int32                  `protobuf:"varint,1,opt,name=turn,proto3" json:"turn,omitempty"`
+	World         *World                 `protobuf:"bytes,2,opt,name=world,proto3" json:"world,omitempty"`
+	Alive         []*Cell                `protobuf:"bytes,3,rep,name=alive,proto3" json:"alive,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JobResultReply) Reset() {
+	*x = JobResultReply{}
+	mi := &file_gol_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobResultReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobResultReply) ProtoMessage() {}
+
+func (x *JobResultReply) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobResultReply.ProtoReflect.Descriptor instead.
+func (*JobResultReply) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *JobResultReply) GetTurn() int32 {
+	if x != nil {
+		return x.Turn
+	}
+	return 0
+}
+
+func (x *JobResultReply) GetWorld() *World {
+	if x != nil {
+		return x.World
+	}
+	return nil
+}
+
+func (x *JobResultReply) GetAlive() []*Cell {
+	if x != nil {
+		return x.Alive
+	}
+	return nil
+}
+
+type StatusReply struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Turn      int32                  `protobuf:"varint,1,opt,name=turn,proto3" json:"turn,omitempty"`
+	Paused    bool                   `protobuf:"varint,2,opt,name=paused,proto3" json:"paused,omitempty"`
+	Detached  bool                   `protobuf:"varint,3,opt,name=detached,proto3" json:"detached,omitempty"`
+	Workers   int32                  `protobuf:"varint,4,opt,name=workers,proto3" json:"workers,omitempty"`
+	Observers int32                  `protobuf:"varint,5,opt,name=observers,proto3" json:"observers,omitempty"`
+	Rule      string                 `protobuf:"bytes,6,opt,name=rule,proto3" json:"rule,omitempty"`
+	// How long the job's turn has waited for workers to register, 0 = not waiting.
+	WaitingNs     int64 `protobuf:"varint,7,opt,name=waiting_ns,json=waitingNs,proto3" json:"waiting_ns,omitempty"`
+	MinWorkers    int32 `protobuf:"varint,8,opt,name=min_workers,json=minWorkers,proto3" json:"min_workers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatusReply) Reset() {
+	*x = StatusReply{}
+	mi := &file_gol_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatusReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatusReply) ProtoMessage() {}
+
+func (x *StatusReply) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatusReply.ProtoReflect.Descriptor instead.
+func (*StatusReply) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *StatusReply) GetTurn() int32 {
+	if x != nil {
+		return x.Turn
+	}
+	return 0
+}
+
+func (x *StatusReply) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+func (x *StatusReply) GetDetached() bool {
+	if x != nil {
+		return x.Detached
+	}
+	return false
+}
+
+func (x *StatusReply) GetWorkers() int32 {
+	if x != nil {
+		return x.Workers
+	}
+	return 0
+}
+
+func (x *StatusReply) GetObservers() int32 {
+	if x != nil {
+		return x.Observers
+	}
+	return 0
+}
+
+func (x *StatusReply) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+func (x *StatusReply) GetWaitingNs() int64 {
+	if x != nil {
+		return x.WaitingNs
+	}
+	return 0
+}
+
+func (x *StatusReply) GetMinWorkers() int32 {
+	if x != nil {
+		return x.MinWorkers
+	}
+	return 0
+}
+
+type WorkerInfo struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Address string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Healthy bool                   `protobuf:"varint,2,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	Missed  int32                  `protobuf:"varint,3,opt,name=missed,proto3" json:"missed,omitempty"`
+	// Round trip of the last heartbeat.
+	LatencyNs  int64   `protobuf:"varint,4,opt,name=latency_ns,json=latencyNs,proto3" json:"latency_ns,omitempty"`
+	Score      float64 `protobuf:"fixed64,5,opt,name=score,proto3" json:"score,omitempty"`
+	Throughput float64 `protobuf:"fixed64,6,opt,name=throughput,proto3" json:"throughput,omitempty"`
+	// Rows held (halo mode) or computed in the last turn (scatter mode), over all jobs.
+	Rows    int32    `protobuf:"varint,7,opt,name=rows,proto3" json:"rows,omitempty"`
+	Version int32    `protobuf:"varint,8,opt,name=version,proto3" json:"version,omitempty"`
+	Cores   int32    `protobuf:"varint,9,opt,name=cores,proto3" json:"cores,omitempty"`
+	Codecs  []string `protobuf:"bytes,10,rep,name=codecs,proto3" json:"codecs,omitempty"`
+	Rules   []string `protobuf:"bytes,11,rep,name=rules,proto3" json:"rules,omitempty"`
+	// A part sent to it timed out and has not come back; it gets no new parts until it does.
+	Suspect       bool `protobuf:"varint,12,opt,name=suspect,proto3" json:"suspect,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WorkerInfo) Reset() {
+	*x = WorkerInfo{}
+	mi := &file_gol_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorkerInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorkerInfo) ProtoMessage() {}
+
+func (x *WorkerInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorkerInfo.ProtoReflect.Descriptor instead.
+func (*WorkerInfo) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *WorkerInfo) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *WorkerInfo) GetHealthy() bool {
+	if x != nil {
+		return x.Healthy
+	}
+	return false
+}
+
+func (x *WorkerInfo) GetMissed() int32 {
+	if x != nil {
+		return x.Missed
+	}
+	return 0
+}
+
+func (x *WorkerInfo) GetLatencyNs() int64 {
+	if x != nil {
+		return x.LatencyNs
+	}
+	return 0
+}
+
+func (x *WorkerInfo) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *WorkerInfo) GetThroughput() float64 {
+	if x != nil {
+		return x.Throughput
+	}
+	return 0
+}
+
+func (x *WorkerInfo) GetRows() int32 {
+	if x != nil {
+		return x.Rows
+	}
+	return 0
+}
+
+func (x *WorkerInfo) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *WorkerInfo) GetCores() int32 {
+	if x != nil {
+		return x.Cores
+	}
+	return 0
+}
+
+func (x *WorkerInfo) GetCodecs() []string {
+	if x != nil {
+		return x.Codecs
+	}
+	return nil
+}
+
+func (x *WorkerInfo) GetRules() []string {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+func (x *WorkerInfo) GetSuspect() bool {
+	if x != nil {
+		return x.Suspect
+	}
+	return false
+}
+
+type ListWorkersReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Workers       []*WorkerInfo          `protobuf:"bytes,1,rep,name=workers,proto3" json:"workers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListWorkersReply) Reset() {
+	*x = ListWorkersReply{}
+	mi := &file_gol_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWorkersReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWorkersReply) ProtoMessage() {}
+
+func (x *ListWorkersReply) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWorkersReply.ProtoReflect.Descriptor instead.
+func (*ListWorkersReply) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ListWorkersReply) GetWorkers() []*WorkerInfo {
+	if x != nil {
+		return x.Workers
+	}
+	return nil
+}
+
+type WorldReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Turn          int32                  `protobuf:"varint,1,opt,name=turn,proto3" json:"turn,omitempty"`
+	World         *World                 `protobuf:"bytes,2,opt,name=world,proto3" json:"world,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WorldReply) Reset() {
+	*x = WorldReply{}
+	mi := &file_gol_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorldReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorldReply) ProtoMessage() {}
+
+func (x *WorldReply) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorldReply.ProtoReflect.Descriptor instead.
+func (*WorldReply) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *WorldReply) GetTurn() int32 {
+	if x != nil {
+		return x.Turn
+	}
+	return 0
+}
+
+func (x *WorldReply) GetWorld() *World {
+	if x != nil {
+		return x.World
+	}
+	return nil
+}
+
+// Task is a row band plus one halo row above and below.
+type Task struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StartY        int32                  `protobuf:"varint,1,opt,name=start_y,json=startY,proto3" json:"start_y,omitempty"`
+	EndY          int32                  `protobuf:"varint,2,opt,name=end_y,json=endY,proto3" json:"end_y,omitempty"`
+	WorldPart     *World                 `protobuf:"bytes,3,opt,name=world_part,json=worldPart,proto3" json:"world_part,omitempty"`
+	Boundary      string                 `protobuf:"bytes,4,opt,name=boundary,proto3" json:"boundary,omitempty"`
+	Rule          string                 `protobuf:"bytes,5,opt,name=rule,proto3" json:"rule,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Task) Reset() {
+	*x = Task{}
+	mi := &file_gol_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Task) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Task) ProtoMessage() {}
+
+func (x *Task) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Task.ProtoReflect.Descriptor instead.
+func (*Task) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *Task) GetStartY() int32 {
+	if x != nil {
+		return x.StartY
+	}
+	return 0
+}
+
+func (x *Task) GetEndY() int32 {
+	if x != nil {
+		return x.EndY
+	}
+	return 0
+}
+
+func (x *Task) GetWorldPart() *World {
+	if x != nil {
+		return x.WorldPart
+	}
+	return nil
+}
+
+func (x *Task) GetBoundary() string {
+	if x != nil {
+		return x.Boundary
+	}
+	return ""
+}
+
+func (x *Task) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+// TileTask is a rectangle plus a one-cell halo on all four sides.
+type TileTask struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StartX        int32                  `protobuf:"varint,1,opt,name=start_x,json=startX,proto3" json:"start_x,omitempty"`
+	EndX          int32                  `protobuf:"varint,2,opt,name=end_x,json=endX,proto3" json:"end_x,omitempty"`
+	StartY        int32                  `protobuf:"varint,3,opt,name=start_y,json=startY,proto3" json:"start_y,omitempty"`
+	EndY          int32                  `protobuf:"varint,4,opt,name=end_y,json=endY,proto3" json:"end_y,omitempty"`
+	Cells         *World                 `protobuf:"bytes,5,opt,name=cells,proto3" json:"cells,omitempty"`
+	Rule          string                 `protobuf:"bytes,6,opt,name=rule,proto3" json:"rule,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TileTask) Reset() {
+	*x = TileTask{}
+	mi := &file_gol_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TileTask) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TileTask) ProtoMessage() {}
+
+func (x *TileTask) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TileTask.ProtoReflect.Descriptor instead.
+func (*TileTask) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *TileTask) GetStartX() int32 {
+	if x != nil {
+		return x.StartX
+	}
+	return 0
+}
+
+func (x *TileTask) GetEndX() int32 {
+	if x != nil {
+		return x.EndX
+	}
+	return 0
+}
+
+func (x *TileTask) GetStartY() int32 {
+	if x != nil {
+		return x.StartY
+	}
+	return 0
+}
+
+func (x *TileTask) GetEndY() int32 {
+	if x != nil {
+		return x.EndY
+	}
+	return 0
+}
+
+func (x *TileTask) GetCells() *World {
+	if x != nil {
+		return x.Cells
+	}
+	return nil
+}
+
+func (x *TileTask) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+// PartReply is the next generation of a Task or TileTask and the cells in it that
+// changed, in world coordinates.
+type PartReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rows          *World                 `protobuf:"bytes,1,opt,name=rows,proto3" json:"rows,omitempty"`
+	Flipped       []*Cell                `protobuf:"bytes,2,rep,name=flipped,proto3" json:"flipped,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PartReply) Reset() {
+	*x = PartReply{}
+	mi := &file_gol_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PartReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PartReply) ProtoMessage() {}
+
+func (x *PartReply) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PartReply.ProtoReflect.Descriptor instead.
+func (*PartReply) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *PartReply) GetRows() *World {
+	if x != nil {
+		return x.Rows
+	}
+	return nil
+}
+
+func (x *PartReply) GetFlipped() []*Cell {
+	if x != nil {
+		return x.Flipped
+	}
+	return nil
+}
+
+type BandSetup struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StartY        int32                  `protobuf:"varint,1,opt,name=start_y,json=startY,proto3" json:"start_y,omitempty"`
+	EndY          int32                  `protobuf:"varint,2,opt,name=end_y,json=endY,proto3" json:"end_y,omitempty"`
+	Rows          *World                 `protobuf:"bytes,3,opt,name=rows,proto3" json:"rows,omitempty"`
+	Above         string                 `protobuf:"bytes,4,opt,name=above,proto3" json:"above,omitempty"`
+	Below         string                 `protobuf:"bytes,5,opt,name=below,proto3" json:"below,omitempty"`
+	Height        int32                  `protobuf:"varint,6,opt,name=height,proto3" json:"height,omitempty"`
+	Boundary      string                 `protobuf:"bytes,7,opt,name=boundary,proto3" json:"boundary,omitempty"`
+	Rule          string                 `protobuf:"bytes,8,opt,name=rule,proto3" json:"rule,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BandSetup) Reset() {
+	*x = BandSetup{}
+	mi := &file_gol_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BandSetup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BandSetup) ProtoMessage() {}
+
+func (x *BandSetup) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BandSetup.ProtoReflect.Descriptor instead.
+func (*BandSetup) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *BandSetup) GetStartY() int32 {
+	if x != nil {
+		return x.StartY
+	}
+	return 0
+}
+
+func (x *BandSetup) GetEndY() int32 {
+	if x != nil {
+		return x.EndY
+	}
+	return 0
+}
+
+func (x *BandSetup) GetRows() *World {
+	if x != nil {
+		return x.Rows
+	}
+	return nil
+}
+
+func (x *BandSetup) GetAbove() string {
+	if x != nil {
+		return x.Above
+	}
+	return ""
+}
+
+func (x *BandSetup) GetBelow() string {
+	if x != nil {
+		return x.Below
+	}
+	return ""
+}
+
+func (x *BandSetup) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *BandSetup) GetBoundary() string {
+	if x != nil {
+		return x.Boundary
+	}
+	return ""
+}
+
+func (x *BandSetup) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+type EdgeArgs struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Turn          int32                  `protobuf:"varint,1,opt,name=turn,proto3" json:"turn,omitempty"`
+	Top           bool                   `protobuf:"varint,2,opt,name=top,proto3" json:"top,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EdgeArgs) Reset() {
+	*x = EdgeArgs{}
+	mi := &file_gol_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EdgeArgs) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EdgeArgs) ProtoMessage() {}
+
+func (x *EdgeArgs) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EdgeArgs.ProtoReflect.Descriptor instead.
+func (*EdgeArgs) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *EdgeArgs) GetTurn() int32 {
+	if x != nil {
+		return x.Turn
+	}
+	return 0
+}
+
+func (x *EdgeArgs) GetTop() bool {
+	if x != nil {
+		return x.Top
+	}
+	return false
+}
+
+type Row struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cells         []byte                 `protobuf:"bytes,1,opt,name=cells,proto3" json:"cells,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Row) Reset() {
+	*x = Row{}
+	mi := &file_gol_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Row) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Row) ProtoMessage() {}
+
+func (x *Row) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Row.ProtoReflect.Descriptor instead.
+func (*Row) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *Row) GetCells() []byte {
+	if x != nil {
+		return x.Cells
+	}
+	return nil
+}
+
+type StepArgs struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Turn          int32                  `protobuf:"varint,1,opt,name=turn,proto3" json:"turn,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StepArgs) Reset() {
+	*x = StepArgs{}
+	mi := &file_gol_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StepArgs) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StepArgs) ProtoMessage() {}
+
+func (x *StepArgs) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StepArgs.ProtoReflect.Descriptor instead.
+func (*StepArgs) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *StepArgs) GetTurn() int32 {
+	if x != nil {
+		return x.Turn
+	}
+	return 0
+}
+
+type StepReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Flipped       []*Cell                `protobuf:"bytes,1,rep,name=flipped,proto3" json:"flipped,omitempty"`
+	AliveCount    int32                  `protobuf:"varint,2,opt,name=alive_count,json=aliveCount,proto3" json:"alive_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StepReply) Reset() {
+	*x = StepReply{}
+	mi := &file_gol_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StepReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StepReply) ProtoMessage() {}
+
+func (x *StepReply) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StepReply.ProtoReflect.Descriptor instead.
+func (*StepReply) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *StepReply) GetFlipped() []*Cell {
+	if x != nil {
+		return x.Flipped
+	}
+	return nil
+}
+
+func (x *StepReply) GetAliveCount() int32 {
+	if x != nil {
+		return x.AliveCount
+	}
+	return 0
+}
+
+type AliveCellsCount struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	CompletedTurns int32                  `protobuf:"varint,1,opt,name=completed_turns,json=completedTurns,proto3" json:"completed_turns,omitempty"`
+	CellsCount     int32                  `protobuf:"varint,2,opt,name=cells_count,json=cellsCount,proto3" json:"cells_count,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *AliveCellsCount) Reset() {
+	*x = AliveCellsCount{}
+	mi := &file_gol_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AliveCellsCount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AliveCellsCount) ProtoMessage() {}
+
+func (x *AliveCellsCount) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AliveCellsCount.ProtoReflect.Descriptor instead.
+func (*AliveCellsCount) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *AliveCellsCount) GetCompletedTurns() int32 {
+	if x != nil {
+		return x.CompletedTurns
+	}
+	return 0
+}
+
+func (x *AliveCellsCount) GetCellsCount() int32 {
+	if x != nil {
+		return x.CellsCount
+	}
+	return 0
+}
+
+type ImageOutputComplete struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	CompletedTurns int32                  `protobuf:"varint,1,opt,name=completed_turns,json=completedTurns,proto3" json:"completed_turns,omitempty"`
+	Filename       string                 `protobuf:"bytes,2,opt,name=filename,proto3" json:"filename,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ImageOutputComplete) Reset() {
+	*x = ImageOutputComplete{}
+	mi := &file_gol_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImageOutputComplete) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImageOutputComplete) ProtoMessage() {}
+
+func (x *ImageOutputComplete) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImageOutputComplete.ProtoReflect.Descriptor instead.
+func (*ImageOutputComplete) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *ImageOutputComplete) GetCompletedTurns() int32 {
+	if x != nil {
+		return x.CompletedTurns
+	}
+	return 0
+}
+
+func (x *ImageOutputComplete) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+type StateChange struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	CompletedTurns int32                  `protobuf:"varint,1,opt,name=completed_turns,json=completedTurns,proto3" json:"completed_turns,omitempty"`
+	NewState       State                  `protobuf:"varint,2,opt,name=new_state,json=newState,proto3,enum=gol.State" json:"new_state,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *StateChange) Reset() {
+	*x = StateChange{}
+	mi := &file_gol_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StateChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StateChange) ProtoMessage() {}
+
+func (x *StateChange) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StateChange.ProtoReflect.Descriptor instead.
+func (*StateChange) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *StateChange) GetCompletedTurns() int32 {
+	if x != nil {
+		return x.CompletedTurns
+	}
+	return 0
+}
+
+func (x *StateChange) GetNewState() State {
+	if x != nil {
+		return x.NewState
+	}
+	return State_PAUSED
+}
+
+type CellsFlipped struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	CompletedTurns int32                  `protobuf:"varint,1,opt,name=completed_turns,json=completedTurns,proto3" json:"completed_turns,omitempty"`
+	Cells          []*Cell                `protobuf:"bytes,2,rep,name=cells,proto3" json:"cells,omitempty"`
+	// Generations rules only: the value each cell has now (see gol.CellsFlipped).
+	Values        []byte `protobuf:"bytes,3,opt,name=values,proto3" json:"values,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CellsFlipped) Reset() {
+	*x = CellsFlipped{}
+	mi := &file_gol_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CellsFlipped) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CellsFlipped) ProtoMessage() {}
+
+func (x *CellsFlipped) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CellsFlipped.ProtoReflect.Descriptor instead.
+func (*CellsFlipped) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *CellsFlipped) GetCompletedTurns() int32 {
+	if x != nil {
+		return x.CompletedTurns
+	}
+	return 0
+}
+
+func (x *CellsFlipped) GetCells() []*Cell {
+	if x != nil {
+		return x.Cells
+	}
+	return nil
+}
+
+func (x *CellsFlipped) GetValues() []byte {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+type TurnComplete struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	CompletedTurns int32                  `protobuf:"varint,1,opt,name=completed_turns,json=completedTurns,proto3" json:"completed_turns,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *TurnComplete) Reset() {
+	*x = TurnComplete{}
+	mi := &file_gol_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TurnComplete) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TurnComplete) ProtoMessage() {}
+
+func (x *TurnComplete) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TurnComplete.ProtoReflect.Descriptor instead.
+func (*TurnComplete) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *TurnComplete) GetCompletedTurns() int32 {
+	if x != nil {
+		return x.CompletedTurns
+	}
+	return 0
+}
+
+type FinalTurnComplete struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	CompletedTurns int32                  `protobuf:"varint,1,opt,name=completed_turns,json=completedTurns,proto3" json:"completed_turns,omitempty"`
+	Alive          []*Cell                `protobuf:"bytes,2,rep,name=alive,proto3" json:"alive,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *FinalTurnComplete) Reset() {
+	*x = FinalTurnComplete{}
+	mi := &file_gol_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FinalTurnComplete) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FinalTurnComplete) ProtoMessage() {}
+
+func (x *FinalTurnComplete) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FinalTurnComplete.ProtoReflect.Descriptor instead.
+func (*FinalTurnComplete) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *FinalTurnComplete) GetCompletedTurns() int32 {
+	if x != nil {
+		return x.CompletedTurns
+	}
+	return 0
+}
+
+func (x *FinalTurnComplete) GetAlive() []*Cell {
+	if x != nil {
+		return x.Alive
+	}
+	return nil
+}
+
+type Event struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*Event_AliveCellsCount
+	//	*Event_ImageOutputComplete
+	//	*Event_StateChange
+	//	*Event_CellsFlipped
+	//	*Event_TurnComplete
+	//	*Event_FinalTurnComplete
+	Event         isEvent_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_gol_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_gol_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_gol_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *Event) GetEvent() isEvent_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *Event) GetAliveCellsCount() *AliveCellsCount {
+	if x != nil {
+		if x, ok := x.Event.(*Event_AliveCellsCount); ok {
+			return x.AliveCellsCount
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetImageOutputComplete() *ImageOutputComplete {
+	if x != nil {
+		if x, ok := x.Event.(*Event_ImageOutputComplete); ok {
+			return x.ImageOutputComplete
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetStateChange() *StateChange {
+	if x != nil {
+		if x, ok := x.Event.(*Event_StateChange); ok {
+			return x.StateChange
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetCellsFlipped() *CellsFlipped {
+	if x != nil {
+		if x, ok := x.Event.(*Event_CellsFlipped); ok {
+			return x.CellsFlipped
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetTurnComplete() *TurnComplete {
+	if x != nil {
+		if x, ok := x.Event.(*Event_TurnComplete); ok {
+			return x.TurnComplete
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetFinalTurnComplete() *FinalTurnComplete {
+	if x != nil {
+		if x, ok := x.Event.(*Event_FinalTurnComplete); ok {
+			return x.FinalTurnComplete
+		}
+	}
+	return nil
+}
+
+type isEvent_Event interface {
+	isEvent_Event()
+}
+
+type Event_AliveCellsCount struct {
+	AliveCellsCount *AliveCellsCount `protobuf:"bytes,1,opt,name=alive_cells_count,json=aliveCellsCount,proto3,oneof"`
+}
+
+type Event_ImageOutputComplete struct {
+	ImageOutputComplete *ImageOutputComplete `protobuf:"bytes,2,opt,name=image_output_complete,json=imageOutputComplete,proto3,oneof"`
+}
+
+type Event_StateChange struct {
+	StateChange *StateChange `protobuf:"bytes,3,opt,name=state_change,json=stateChange,proto3,oneof"`
+}
+
+type Event_CellsFlipped struct {
+	CellsFlipped *CellsFlipped `protobuf:"bytes,4,opt,name=cells_flipped,json=cellsFlipped,proto3,oneof"`
+}
+
+type Event_TurnComplete struct {
+	TurnComplete *TurnComplete `protobuf:"bytes,5,opt,name=turn_complete,json=turnComplete,proto3,oneof"`
+}
+
+type Event_FinalTurnComplete struct {
+	FinalTurnComplete *FinalTurnComplete `protobuf:"bytes,6,opt,name=final_turn_complete,json=finalTurnComplete,proto3,oneof"`
+}
+
+func (*Event_AliveCellsCount) isEvent_Event() {}
+
+func (*Event_ImageOutputComplete) isEvent_Event() {}
+
+func (*Event_StateChange) isEvent_Event() {}
+
+func (*Event_CellsFlipped) isEvent_Event() {}
+
+func (*Event_TurnComplete) isEvent_Event() {}
+
+func (*Event_FinalTurnComplete) isEvent_Event() {}
+
+var File_gol_proto protoreflect.FileDescriptor
+
+const file_gol_proto_rawDesc = "" +
+	"\n" +
+	"\tgol.proto\x12\x03gol\"\a\n" +
+	"\x05Empty\" \n" +
+	"\aJobArgs\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"\xce\x01\n" +
+	"\vWorldParams\x12\x1f\n" +
+	"\vimage_width\x18\x01 \x01(\x05R\n" +
+	"imageWidth\x12!\n" +
+	"\fimage_height\x18\x02 \x01(\x05R\vimageHeight\x12 \n" +
+	"\x05world\x18\x03 \x01(\v2\n" +
+	".gol.WorldR\x05world\x12\x12\n" +
+	"\x04turn\x18\x04 \x01(\x05R\x04turn\x12\x1a\n" +
+	"\bboundary\x18\x05 \x01(\tR\bboundary\x12\x12\n" +
+	"\x04rule\x18\x06 \x01(\tR\x04rule\x12\x15\n" +
+	"\x06job_id\x18\a \x01(\tR\x05jobId\"w\n" +
+	"\x05World\x12\x14\n" +
+	"\x05width\x18\x01 \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\x02 \x01(\x05R\x06height\x12\x12\n" +
+	"\x04bits\x18\x03 \x03(\x06R\x04bits\x12\x14\n" +
+	"\x05cells\x18\x04 \x03(\x04R\x05cells\x12\x16\n" +
+	"\x06states\x18\x05 \x01(\fR\x06states\"\xf4\x01\n" +
+	"\bRowChunk\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x05R\x03seq\x12\x17\n" +
+	"\astart_y\x18\x02 \x01(\x05R\x06startY\x12\x1e\n" +
+	"\x04rows\x18\x03 \x01(\v2\n" +
+	".gol.WorldR\x04rows\x12\x1f\n" +
+	"\vimage_width\x18\x04 \x01(\x05R\n" +
+	"imageWidth\x12!\n" +
+	"\fimage_height\x18\x05 \x01(\x05R\vimageHeight\x12\x12\n" +
+	"\x04turn\x18\x06 \x01(\x05R\x04turn\x12\x1a\n" +
+	"\bboundary\x18\a \x01(\tR\bboundary\x12\x12\n" +
+	"\x04rule\x18\b \x01(\tR\x04rule\x12\x15\n" +
+	"\x06job_id\x18\t \x01(\tR\x05jobId\"\"\n" +
+	"\x04Cell\x12\f\n" +
+	"\x01x\x18\x01 \x01(\x05R\x01x\x12\f\n" +
+	"\x01y\x18\x02 \x01(\x05R\x01y\"\x1d\n" +
+	"\x05Count\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\x03R\x05value\"\x14\n" +
+	"\x02Ok\x12\x0e\n" +
+	"\x02ok\x18\x01 \x01(\bR\x02ok\"\xce\x01\n" +
+	"\fRegisterArgs\x12\x18\n" +
+	"\aaddress\x18\x01 \x01(\tR\aaddress\x12\x12\n" +
+	"\x04port\x18\x02 \x01(\x05R\x04port\x12\x1c\n" +
+	"\ttransport\x18\x03 \x01(\tR\ttransport\x12\x14\n" +
+	"\x05score\x18\x04 \x01(\x01R\x05score\x12\x18\n" +
+	"\aversion\x18\x05 \x01(\x05R\aversion\x12\x16\n" +
+	"\x06codecs\x18\x06 \x03(\tR\x06codecs\x12\x14\n" +
+	"\x05cores\x18\a \x01(\x05R\x05cores\x12\x14\n" +
+	"\x05rules\x18\b \x03(\tR\x05rules\"q\n" +
+	"\rNextTurnReply\x12\x12\n" +
+	"\x04turn\x18\x01 \x01(\x05R\x04turn\x12#\n" +
+	"\aflipped\x18\x02 \x03(\v2\t.gol.CellR\aflipped\x12'\n" +
+	"\x06timing\x18\x03 \x01(\v2\x0f.gol.StepTimingR\x06timing\"s\n" +
+	"\n" +
+	"StepTiming\x12\x1d\n" +
+	"\n" +
+	"elapsed_ns\x18\x01 \x01(\x03R\telapsedNs\x12\x18\n" +
+	"\aslowest\x18\x02 \x01(\tR\aslowest\x12,\n" +
+	"\x12slowest_elapsed_ns\x18\x03 \x01(\x03R\x10slowestElapsedNs\"?\n" +
+	"\x10ProcessTurnsArgs\x12\x14\n" +
+	"\x05turns\x18\x01 \x01(\x05R\x05turns\x12\x15\n" +
+	"\x06job_id\x18\x02 \x01(\tR\x05jobId\",\n" +
+	"\tTurnFlips\x12\x1f\n" +
+	"\x05cells\x18\x01 \x03(\v2\t.gol.CellR\x05cells\"\x82\x01\n" +
+	"\x11ProcessTurnsReply\x12\x12\n" +
+	"\x04turn\x18\x01 \x01(\x05R\x04turn\x12(\n" +
+	"\aflipped\x18\x03 \x03(\v2\x0e.gol.TurnFlipsR\aflipped\x12)\n" +
+	"\atimings\x18\x04 \x03(\v2\x0f.gol.StepTimingR\atimingsJ\x04\b\x02\x10\x03\"9\n" +
+	"\n" +
+	"DetachArgs\x12\x14\n" +
+	"\x05turns\x18\x01 \x01(\x05R\x05turns\x12\x15\n" +
+	"\x06job_id\x18\x02 \x01(\tR\x05jobId\"W\n" +
+	"\vAttachReply\x12\x12\n" +
+	"\x04turn\x18\x01 \x01(\x05R\x04turn\x12 \n" +
+	"\x05world\x18\x02 \x01(\v2\n" +
+	".gol.WorldR\x05world\x12\x12\n" +
+	"\x04rule\x18\x03 \x01(\tR\x04rule\"9\n" +
+	"\x10SubscriptionArgs\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x15\n" +
+	"\x06job_id\x18\x02 \x01(\tR\x05jobId\"j\n" +
+	"\x0eSubscribeReply\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x12\n" +
+	"\x04turn\x18\x02 \x01(\x05R\x04turn\x12 \n" +
+	"\x05world\x18\x03 \x01(\v2\n" +
+	".gol.WorldR\x05world\x12\x12\n" +
+	"\x04rule\x18\x04 \x01(\tR\x04rule\"D\n" +
+	"\tTurnDelta\x12\x12\n" +
+	"\x04turn\x18\x01 \x01(\x05R\x04turn\x12#\n" +
+	"\aflipped\x18\x02 \x03(\v2\t.gol.CellR\aflipped\"}\n" +
+	"\tPollReply\x12\x12\n" +
+	"\x04turn\x18\x01 \x01(\x05R\x04turn\x12 \n" +
+	"\x05world\x18\x02 \x01(\v2\n" +
+	".gol.WorldR\x05world\x12&\n" +
+	"\x06deltas\x18\x03 \x03(\v2\x0e.gol.TurnDeltaR\x06deltas\x12\x12\n" +
+	"\x04rule\x18\x04 \x01(\tR\x04rule\">\n" +
+	"\tAliveArgs\x12\x1a\n" +
+	"\binterval\x18\x01 \x01(\x03R\binterval\x12\x15\n" +
+	"\x06job_id\x18\x02 \x01(\tR\x05jobId\"7\n" +
+	"\vAliveReport\x12\x12\n" +
+	"\x04turn\x18\x01 \x01(\x05R\x04turn\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x03R\x05count\"\xcf\x01\n" +
+	"\n" +
+	"SubmitArgs\x12\x1f\n" +
+	"\vimage_width\x18\x01 \x01(\x05R\n" +
+	"imageWidth\x12!\n" +
+	"\fimage_height\x18\x02 \x01(\x05R\vimageHeight\x12 \n" +
+	"\x05world\x18\x03 \x01(\v2\n" +
+	".gol.WorldR\x05world\x12\x14\n" +
+	"\x05turns\x18\x04 \x01(\x05R\x05turns\x12\x1a\n" +
+	"\bboundary\x18\x05 \x01(\tR\bboundary\x12\x12\n" +
+	"\x04rule\x18\x06 \x01(\tR\x04rule\x12\x15\n" +
+	"\x06job_id\x18\a \x01(\tR\x05jobId\"$\n" +
+	"\vSubmitReply\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"z\n" +
+	"\x10JobProgressReply\x12\x14\n" +
+	"\x05state\x18\x01 \x01(\tR\x05state\x12\x12\n" +
+	"\x04turn\x18\x02 \x01(\x05R\x04turn\x12\x14\n" +
+	"\x05turns\x18\x03 \x01(\x05R\x05turns\x12\x14\n" +
+	"\x05alive\x18\x04 \x01(\x03R\x05alive\x12\x10\n" +
+	"\x03err\x18\x05 \x01(\tR\x03err\"g\n" +
+	"\x0eJobResultReply\x12\x12\n" +
+	"\x04turn\x18\x01 \x01(\x05R\x04turn\x12 \n" +
+	"\x05world\x18\x02 \x01(\v2\n" +
+	".gol.WorldR\x05world\x12\x1f\n" +
+	"\x05alive\x18\x03 \x03(\v2\t.gol.CellR\x05alive\"\xe1\x01\n" +
+	"\vStatusReply\x12\x12\n" +
+	"\x04turn\x18\x01 \x01(\x05R\x04turn\x12\x16\n" +
+	"\x06paused\x18\x02 \x01(\bR\x06paused\x12\x1a\n" +
+	"\bdetached\x18\x03 \x01(\bR\bdetached\x12\x18\n" +
+	"\aworkers\x18\x04 \x01(\x05R\aworkers\x12\x1c\n" +
+	"\tobservers\x18\x05 \x01(\x05R\tobservers\x12\x12\n" +
+	"\x04rule\x18\x06 \x01(\tR\x04rule\x12\x1d\n" +
+	"\n" +
+	"waiting_ns\x18\a \x01(\x03R\twaitingNs\x12\x1f\n" +
+	"\vmin_workers\x18\b \x01(\x05R\n" +
+	"minWorkers\"\xb9\x02\n" +
+	"\n" +
+	"WorkerInfo\x12\x18\n" +
+	"\aaddress\x18\x01 \x01(\tR\aaddress\x12\x18\n" +
+	"\ahealthy\x18\x02 \x01(\bR\ahealthy\x12\x16\n" +
+	"\x06missed\x18\x03 \x01(\x05R\x06missed\x12\x1d\n" +
+	"\n" +
+	"latency_ns\x18\x04 \x01(\x03R\tlatencyNs\x12\x14\n" +
+	"\x05score\x18\x05 \x01(\x01R\x05score\x12\x1e\n" +
+	"\n" +
+	"throughput\x18\x06 \x01(\x01R\n" +
+	"throughput\x12\x12\n" +
+	"\x04rows\x18\a \x01(\x05R\x04rows\x12\x18\n" +
+	"\aversion\x18\b \x01(\x05R\aversion\x12\x14\n" +
+	"\x05cores\x18\t \x01(\x05R\x05cores\x12\x16\n" +
+	"\x06codecs\x18\n" +
+	" \x03(\tR\x06codecs\x12\x14\n" +
+	"\x05rules\x18\v \x03(\tR\x05rules\x12\x18\n" +
+	"\asuspect\x18\f \x01(\bR\asuspect\"=\n" +
+	"\x10ListWorkersReply\x12)\n" +
+	"\aworkers\x18\x01 \x03(\v2\x0f.gol.WorkerInfoR\aworkers\"B\n" +
+	"\n" +
+	"WorldReply\x12\x12\n" +
+	"\x04turn\x18\x01 \x01(\x05R\x04turn\x12 \n" +
+	"\x05world\x18\x02 \x01(\v2\n" +
+	".gol.WorldR\x05world\"\x8f\x01\n" +
+	"\x04Task\x12\x17\n" +
+	"\astart_y\x18\x01 \x01(\x05R\x06startY\x12\x13\n" +
+	"\x05end_y\x18\x02 \x01(\x05R\x04endY\x12)\n" +
+	"\n" +
+	"world_part\x18\x03 \x01(\v2\n" +
+	".gol.WorldR\tworldPart\x12\x1a\n" +
+	"\bboundary\x18\x04 \x01(\tR\bboundary\x12\x12\n" +
+	"\x04rule\x18\x05 \x01(\tR\x04rule\"\x9c\x01\n" +
+	"\bTileTask\x12\x17\n" +
+	"\astart_x\x18\x01 \x01(\x05R\x06startX\x12\x13\n" +
+	"\x05end_x\x18\x02 \x01(\x05R\x04endX\x12\x17\n" +
+	"\astart_y\x18\x03 \x01(\x05R\x06startY\x12\x13\n" +
+	"\x05end_y\x18\x04 \x01(\x05R\x04endY\x12 \n" +
+	"\x05cells\x18\x05 \x01(\v2\n" +
+	".gol.WorldR\x05cells\x12\x12\n" +
+	"\x04rule\x18\x06 \x01(\tR\x04rule\"P\n" +
+	"\tPartReply\x12\x1e\n" +
+	"\x04rows\x18\x01 \x01(\v2\n" +
+	".gol.WorldR\x04rows\x12#\n" +
+	"\aflipped\x18\x02 \x03(\v2\t.gol.CellR\aflipped\"\xcd\x01\n" +
+	"\tBandSetup\x12\x17\n" +
+	"\astart_y\x18\x01 \x01(\x05R\x06startY\x12\x13\n" +
+	"\x05end_y\x18\x02 \x01(\x05R\x04endY\x12\x1e\n" +
+	"\x04rows\x18\x03 \x01(\v2\n" +
+	".gol.WorldR\x04rows\x12\x14\n" +
+	"\x05above\x18\x04 \x01(\tR\x05above\x12\x14\n" +
+	"\x05below\x18\x05 \x01(\tR\x05below\x12\x16\n" +
+	"\x06height\x18\x06 \x01(\x05R\x06height\x12\x1a\n" +
+	"\bboundary\x18\a \x01(\tR\bboundary\x12\x12\n" +
+	"\x04rule\x18\b \x01(\tR\x04rule\"0\n" +
+	"\bEdgeArgs\x12\x12\n" +
+	"\x04turn\x18\x01 \x01(\x05R\x04turn\x12\x10\n" +
+	"\x03top\x18\x02 \x01(\bR\x03top\"\x1b\n" +
+	"\x03Row\x12\x14\n" +
+	"\x05cells\x18\x01 \x01(\fR\x05cells\"\x1e\n" +
+	"\bStepArgs\x12\x12\n" +
+	"\x04turn\x18\x01 \x01(\x05R\x04turn\"Q\n" +
+	"\tStepReply\x12#\n" +
+	"\aflipped\x18\x01 \x03(\v2\t.gol.CellR\aflipped\x12\x1f\n" +
+	"\valive_count\x18\x02 \x01(\x05R\n" +
+	"aliveCount\"[\n" +
+	"\x0fAliveCellsCount\x12'\n" +
+	"\x0fcompleted_turns\x18\x01 \x01(\x05R\x0ecompletedTurns\x12\x1f\n" +
+	"\vcells_count\x18\x02 \x01(\x05R\n" +
+	"cellsCount\"Z\n" +
+	"\x13ImageOutputComplete\x12'\n" +
+	"\x0fcompleted_turns\x18\x01 \x01(\x05R\x0ecompletedTurns\x12\x1a\n" +
+	"\bfilename\x18\x02 \x01(\tR\bfilename\"_\n" +
+	"\vStateChange\x12'\n" +
+	"\x0fcompleted_turns\x18\x01 \x01(\x05R\x0ecompletedTurns\x12'\n" +
+	"\tnew_state\x18\x02 \x01(\x0e2\n" +
+	".gol.StateR\bnewState\"p\n" +
+	"\fCellsFlipped\x12'\n" +
+	"\x0fcompleted_turns\x18\x01 \x01(\x05R\x0ecompletedTurns\x12\x1f\n" +
+	"\x05cells\x18\x02 \x03(\v2\t.gol.CellR\x05cells\x12\x16\n" +
+	"\x06values\x18\x03 \x01(\fR\x06values\"7\n" +
+	"\fTurnComplete\x12'\n" +
+	"\x0fcompleted_turns\x18\x01 \x01(\x05R\x0ecompletedTurns\"]\n" +
+	"\x11FinalTurnComplete\x12'\n" +
+	"\x0fcompleted_turns\x18\x01 \x01(\x05R\x0ecompletedTurns\x12\x1f\n" +
+	"\x05alive\x18\x02 \x03(\v2\t.gol.CellR\x05alive\"\x99\x03\n" +
+	"\x05Event\x12B\n" +
+	"\x11alive_cells_count\x18\x01 \x01(\v2\x14.gol.AliveCellsCountH\x00R\x0faliveCellsCount\x12N\n" +
+	"\x15image_output_complete\x18\x02 \x01(\v2\x18.gol.ImageOutputCompleteH\x00R\x13imageOutputComplete\x125\n" +
+	"\fstate_change\x18\x03 \x01(\v2\x10.gol.StateChangeH\x00R\vstateChange\x128\n" +
+	"\rcells_flipped\x18\x04 \x01(\v2\x11.gol.CellsFlippedH\x00R\fcellsFlipped\x128\n" +
+	"\rturn_complete\x18\x05 \x01(\v2\x11.gol.TurnCompleteH\x00R\fturnComplete\x12H\n" +
+	"\x13final_turn_complete\x18\x06 \x01(\v2\x16.gol.FinalTurnCompleteH\x00R\x11finalTurnCompleteB\a\n" +
+	"\x05event*0\n" +
+	"\x05State\x12\n" +
+	"\n" +
+	"\x06PAUSED\x10\x00\x12\r\n" +
+	"\tEXECUTING\x10\x01\x12\f\n" +
+	"\bQUITTING\x10\x022\xfe\t\n" +
+	"\x06Broker\x12+\n" +
+	"\vProcessTurn\x12\x10.gol.WorldParams\x1a\n" +
+	".gol.World\x12.\n" +
+	"\x12GetAliveCellsCount\x12\f.gol.JobArgs\x1a\n" +
+	".gol.Count\x12,\n" +
+	"\x0eRegisterWorker\x12\x11.gol.RegisterArgs\x1a\a.gol.Ok\x12.\n" +
+	"\x10DeregisterWorker\x12\x11.gol.RegisterArgs\x1a\a.gol.Ok\x12*\n" +
+	"\fRetireWorker\x12\x11.gol.RegisterArgs\x1a\a.gol.Ok\x12,\n" +
+	"\x0fStartSimulation\x12\x10.gol.WorldParams\x1a\a.gol.Ok\x12,\n" +
+	"\bNextTurn\x12\f.gol.JobArgs\x1a\x12.gol.NextTurnReply\x12=\n" +
+	"\fProcessTurns\x12\x15.gol.ProcessTurnsArgs\x1a\x16.gol.ProcessTurnsReply\x12&\n" +
+	"\n" +
+	"FetchWorld\x12\f.gol.JobArgs\x1a\n" +
+	".gol.World\x12\"\n" +
+	"\x06Detach\x12\x0f.gol.DetachArgs\x1a\a.gol.Ok\x12(\n" +
+	"\x06Attach\x12\f.gol.JobArgs\x1a\x10.gol.AttachReply\x12.\n" +
+	"\tSubscribe\x12\f.gol.JobArgs\x1a\x13.gol.SubscribeReply\x12-\n" +
+	"\x04Poll\x12\x15.gol.SubscriptionArgs\x1a\x0e.gol.PollReply\x120\n" +
+	"\x05Watch\x12\x15.gol.SubscriptionArgs\x1a\x0e.gol.PollReply0\x01\x12-\n" +
+	"\vUnsubscribe\x12\x15.gol.SubscriptionArgs\x1a\a.gol.Ok\x12\x1e\n" +
+	"\x05Pause\x12\f.gol.JobArgs\x1a\a.gol.Ok\x12\x1f\n" +
+	"\x06Resume\x12\f.gol.JobArgs\x1a\a.gol.Ok\x12+\n" +
+	"\tGetStatus\x12\f.gol.JobArgs\x1a\x10.gol.StatusReply\x120\n" +
+	"\vListWorkers\x12\n" +
+	".gol.Empty\x1a\x15.gol.ListWorkersReply\x12\x1f\n" +
+	"\bShutdown\x12\n" +
+	".gol.Empty\x1a\a.gol.Ok\x12)\n" +
+	"\bGetWorld\x12\f.gol.JobArgs\x1a\x0f.gol.WorldReply\x12.\n" +
+	"\n" +
+	"WatchAlive\x12\x0e.gol.AliveArgs\x1a\x10.gol.AliveReport\x12.\n" +
+	"\tSubmitJob\x12\x0f.gol.SubmitArgs\x1a\x10.gol.SubmitReply\x122\n" +
+	"\vJobProgress\x12\f.gol.JobArgs\x1a\x15.gol.JobProgressReply\x12.\n" +
+	"\tJobResult\x12\f.gol.JobArgs\x1a\x13.gol.JobResultReply\x12'\n" +
+	"\vUploadWorld\x12\r.gol.RowChunk\x1a\a.gol.Ok(\x01\x12,\n" +
+	"\vStreamWorld\x12\f.gol.JobArgs\x1a\r.gol.RowChunk0\x01\x125\n" +
+	"\x11ProcessTurnStream\x12\r.gol.RowChunk\x1a\r.gol.RowChunk(\x010\x012\xd5\x02\n" +
+	"\x06Worker\x12\x1b\n" +
+	"\x04Ping\x12\n" +
+	".gol.Empty\x1a\a.gol.Ok\x12(\n" +
+	"\vProcessPart\x12\t.gol.Task\x1a\x0e.gol.PartReply\x12,\n" +
+	"\vProcessTile\x12\r.gol.TileTask\x1a\x0e.gol.PartReply\x12'\n" +
+	"\tSetupBand\x12\x0e.gol.BandSetup\x1a\n" +
+	".gol.Count\x12\"\n" +
+	"\aGetEdge\x12\r.gol.EdgeArgs\x1a\b.gol.Row\x12%\n" +
+	"\x04Step\x12\r.gol.StepArgs\x1a\x0e.gol.StepReply\x12#\n" +
+	"\tFetchBand\x12\n" +
+	".gol.Empty\x1a\n" +
+	".gol.World\x12\x1f\n" +
+	"\bShutdown\x12\n" +
+	".gol.Empty\x1a\a.gol.Ok\x12\x1c\n" +
+	"\x05Drain\x12\n" +
+	".gol.Empty\x1a\a.gol.OkB Z\x1euk.ac.bris.cs/gameoflife/golpbb\x06proto3"
+
+var (
+	file_gol_proto_rawDescOnce sync.Once
+	file_gol_proto_rawDescData []byte
+)
+
+func file_gol_proto_rawDescGZIP() []byte {
+	file_gol_proto_rawDescOnce.Do(func() {
+		file_gol_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_gol_proto_rawDesc), len(file_gol_proto_rawDesc)))
+	})
+	return file_gol_proto_rawDescData
+}
+
+var file_gol_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_gol_proto_msgTypes = make([]protoimpl.MessageInfo, 45)
+var file_gol_proto_goTypes = []any{
+	(State)(0),                  // 0: gol.State
+	(*Empty)(nil),               // 1: gol.Empty
+	(*JobArgs)(nil),             // 2: gol.JobArgs
+	(*WorldParams)(nil),         // 3: gol.WorldParams
+	(*World)(nil),               // 4: gol.World
+	(*RowChunk)(nil),            // 5: gol.RowChunk
+	(*Cell)(nil),                // 6: gol.Cell
+	(*Count)(nil),               // 7: gol.Count
+	(*Ok)(nil),                  // 8: gol.Ok
+	(*RegisterArgs)(nil),        // 9: gol.RegisterArgs
+	(*NextTurnReply)(nil),       // 10: gol.NextTurnReply
+	(*StepTiming)(nil),          // 11: gol.StepTiming
+	(*ProcessTurnsArgs)(nil),    // 12: gol.ProcessTurnsArgs
+	(*TurnFlips)(nil),           // 13: gol.TurnFlips
+	(*ProcessTurnsReply)(nil),   // 14: gol.ProcessTurnsReply
+	(*DetachArgs)(nil),          // 15: gol.DetachArgs
+	(*AttachReply)(nil),         // 16: gol.AttachReply
+	(*SubscriptionArgs)(nil),    // 17: gol.SubscriptionArgs
+	(*SubscribeReply)(nil),      // 18: gol.SubscribeReply
+	(*TurnDelta)(nil),           // 19: gol.TurnDelta
+	(*PollReply)(nil),           // 20: gol.PollReply
+	(*AliveArgs)(nil),           // 21: gol.AliveArgs
+	(*AliveReport)(nil),         // 22: gol.AliveReport
+	(*SubmitArgs)(nil),          // 23: gol.SubmitArgs
+	(*SubmitReply)(nil),         // 24: gol.SubmitReply
+	(*JobProgressReply)(nil),    // 25: gol.JobProgressReply
+	(*JobResultReply)(nil),      // 26: gol.JobResultReply
+	(*StatusReply)(nil),         // 27: gol.StatusReply
+	(*WorkerInfo)(nil),          // 28: gol.WorkerInfo
+	(*ListWorkersReply)(nil),    // 29: gol.ListWorkersReply
+	(*WorldReply)(nil),          // 30: gol.WorldReply
+	(*Task)(nil),                // 31: gol.Task
+	(*TileTask)(nil),            // 32: gol.TileTask
+	(*PartReply)(nil),           // 33: gol.PartReply
+	(*BandSetup)(nil),           // 34: gol.BandSetup
+	(*EdgeArgs)(nil),            // 35: gol.EdgeArgs
+	(*Row)(nil),                 // 36: gol.Row
+	(*StepArgs)(nil),            // 37: gol.StepArgs
+	(*StepReply)(nil),           // 38: gol.StepReply
+	(*AliveCellsCount)(nil),     // 39: gol.AliveCellsCount
+	(*ImageOutputComplete)(nil), // 40: gol.ImageOutputComplete
+	(*StateChange)(nil),         // 41: gol.StateChange
+	(*CellsFlipped)(nil),        // 42: gol.CellsFlipped
+	(*TurnComplete)(nil),        // 43: gol.TurnComplete
+	(*FinalTurnComplete)(nil),   // 44: gol.FinalTurnComplete
+	(*Event)(nil),               // 45: gol.Event
+}
+var file_gol_proto_depIdxs = []int32{
+	4,  // 0: gol.WorldParams.world:type_name -> gol.World
+	4,  // 1: gol.RowChunk.rows:type_name -> gol.World
+	6,  // 2: gol.NextTurnReply.flipped:type_name -> gol.Cell
+	11, // 3: gol.NextTurnReply.timing:type_name -> gol.StepTiming
+	6,  // 4: gol.TurnFlips.cells:type_name -> gol.Cell
+	13, // 5: gol.ProcessTurnsReply.flipped:type_name -> gol.TurnFlips
+	11, // 6: gol.ProcessTurnsReply.timings:type_name -> gol.StepTiming
+	4,  // 7: gol.AttachReply.world:type_name -> gol.World
+	4,  // 8: gol.SubscribeReply.world:type_name -> gol.World
+	6,  // 9: gol.TurnDelta.flipped:type_name -> gol.Cell
+	4,  // 10: gol.PollReply.world:type_name -> gol.World
+	19, // 11: gol.PollReply.deltas:type_name -> gol.TurnDelta
+	4,  // 12: gol.SubmitArgs.world:type_name -> gol.World
+	4,  // 13: gol.JobResultReply.world:type_name -> gol.World
+	6,  // 14: gol.JobResultReply.alive:type_name -> gol.Cell
+	28, // 15: gol.ListWorkersReply.workers:type_name -> gol.WorkerInfo
+	4,  // 16: gol.WorldReply.world:type_name -> gol.World
+	4,  // 17: gol.Task.world_part:type_name -> gol.World
+	4,  // 18: gol.TileTask.cells:type_name -> gol.World
+	4,  // 19: gol.PartReply.rows:type_name -> gol.World
+	6,  // 20: gol.PartReply.flipped:type_name -> gol.Cell
+	4,  // 21: gol.BandSetup.rows:type_name -> gol.World
+	6,  // 22: gol.StepReply.flipped:type_name -> gol.Cell
+	0,  // 23: gol.StateChange.new_state:type_name -> gol.State
+	6,  // 24: gol.CellsFlipped.cells:type_name -> gol.Cell
+	6,  // 25: gol.FinalTurnComplete.alive:type_name -> gol.Cell
+	39, // 26: gol.Event.alive_cells_count:type_name -> gol.AliveCellsCount
+	40, // 27: gol.Event.image_output_complete:type_name -> gol.ImageOutputComplete
+	41, // 28: gol.Event.state_change:type_name -> gol.StateChange
+	42, // 29: gol.Event.cells_flipped:type_name -> gol.CellsFlipped
+	43, // 30: gol.Event.turn_complete:type_name -> gol.TurnComplete
+	44, // 31: gol.Event.final_turn_complete:type_name -> gol.FinalTurnComplete
+	3,  // 32: gol.Broker.ProcessTurn:input_type -> gol.WorldParams
+	2,  // 33: gol.Broker.GetAliveCellsCount:input_type -> gol.JobArgs
+	9,  // 34: gol.Broker.RegisterWorker:input_type -> gol.RegisterArgs
+	9,  // 35: gol.Broker.DeregisterWorker:input_type -> gol.RegisterArgs
+	9,  // 36: gol.Broker.RetireWorker:input_type -> gol.RegisterArgs
+	3,  // 37: gol.Broker.StartSimulation:input_type -> gol.WorldParams
+	2,  // 38: gol.Broker.NextTurn:input_type -> gol.JobArgs
+	12, // 39: gol.Broker.ProcessTurns:input_type -> gol.ProcessTurnsArgs
+	2,  // 40: gol.Broker.FetchWorld:input_type -> gol.JobArgs
+	15, // 41: gol.Broker.Detach:input_type -> gol.DetachArgs
+	2,  // 42: gol.Broker.Attach:input_type -> gol.JobArgs
+	2,  // 43: gol.Broker.Subscribe:input_type -> gol.JobArgs
+	17, // 44: gol.Broker.Poll:input_type -> gol.SubscriptionArgs
+	17, // 45: gol.Broker.Watch:input_type -> gol.SubscriptionArgs
+	17, // 46: gol.Broker.Unsubscribe:input_type -> gol.SubscriptionArgs
+	2,  // 47: gol.Broker.Pause:input_type -> gol.JobArgs
+	2,  // 48: gol.Broker.Resume:input_type -> gol.JobArgs
+	2,  // 49: gol.Broker.GetStatus:input_type -> gol.JobArgs
+	1,  // 50: gol.Broker.ListWorkers:input_type -> gol.Empty
+	1,  // 51: gol.Broker.Shutdown:input_type -> gol.Empty
+	2,  // 52: gol.Broker.GetWorld:input_type -> gol.JobArgs
+	21, // 53: gol.Broker.WatchAlive:input_type -> gol.AliveArgs
+	23, // 54: gol.Broker.SubmitJob:input_type -> gol.SubmitArgs
+	2,  // 55: gol.Broker.JobProgress:input_type -> gol.JobArgs
+	2,  // 56: gol.Broker.JobResult:input_type -> gol.JobArgs
+	5,  // 57: gol.Broker.UploadWorld:input_type -> gol.RowChunk
+	2,  // 58: gol.Broker.StreamWorld:input_type -> gol.JobArgs
+	5,  // 59: gol.Broker.ProcessTurnStream:input_type -> gol.RowChunk
+	1,  // 60: gol.Worker.Ping:input_type -> gol.Empty
+	31, // 61: gol.Worker.ProcessPart:input_type -> gol.Task
+	32, // 62: gol.Worker.ProcessTile:input_type -> gol.TileTask
+	34, // 63: gol.Worker.SetupBand:input_type -> gol.BandSetup
+	35, // 64: gol.Worker.GetEdge:input_type -> gol.EdgeArgs
+	37, // 65: gol.Worker.Step:input_type -> gol.StepArgs
+	1,  // 66: gol.Worker.FetchBand:input_type -> gol.Empty
+	1,  // 67: gol.Worker.Shutdown:input_type -> gol.Empty
+	1,  // 68: gol.Worker.Drain:input_type -> gol.Empty
+	4,  // 69: gol.Broker.ProcessTurn:output_type -> gol.World
+	7,  // 70: gol.Broker.GetAliveCellsCount:output_type -> gol.Count
+	8,  // 71: gol.Broker.RegisterWorker:output_type -> gol.Ok
+	8,  // 72: gol.Broker.DeregisterWorker:output_type -> gol.Ok
+	8,  // 73: gol.Broker.RetireWorker:output_type -> gol.Ok
+	8,  // 74: gol.Broker.StartSimulation:output_type -> gol.Ok
+	10, // 75: gol.Broker.NextTurn:output_type -> gol.NextTurnReply
+	14, // 76: gol.Broker.ProcessTurns:output_type -> gol.ProcessTurnsReply
+	4,  // 77: gol.Broker.FetchWorld:output_type -> gol.World
+	8,  // 78: gol.Broker.Detach:output_type -> gol.Ok
+	16, // 79: gol.Broker.Attach:output_type -> gol.AttachReply
+	18, // 80: gol.Broker.Subscribe:output_type -> gol.SubscribeReply
+	20, // 81: gol.Broker.Poll:output_type -> gol.PollReply
+	20, // 82: gol.Broker.Watch:output_type -> gol.PollReply
+	8,  // 83: gol.Broker.Unsubscribe:output_type -> gol.Ok
+	8,  // 84: gol.Broker.Pause:output_type -> gol.Ok
+	8,  // 85: gol.Broker.Resume:output_type -> gol.Ok
+	27, // 86: gol.Broker.GetStatus:output_type -> gol.StatusReply
+	29, // 87: gol.Broker.ListWorkers:output_type -> gol.ListWorkersReply
+	8,  // 88: gol.Broker.Shutdown:output_type -> gol.Ok
+	30, // 89: gol.Broker.GetWorld:output_type -> gol.WorldReply
+	22, // 90: gol.Broker.WatchAlive:output_type -> gol.AliveReport
+	24, // 91: gol.Broker.SubmitJob:output_type -> gol.SubmitReply
+	25, // 92: gol.Broker.JobProgress:output_type -> gol.JobProgressReply
+	26, // 93: gol.Broker.JobResult:output_type -> gol.JobResultReply
+	8,  // 94: gol.Broker.UploadWorld:output_type -> gol.Ok
+	5,  // 95: gol.Broker.StreamWorld:output_type -> gol.RowChunk
+	5,  // 96: gol.Broker.ProcessTurnStream:output_type -> gol.RowChunk
+	8,  // 97: gol.Worker.Ping:output_type -> gol.Ok
+	33, // 98: gol.Worker.ProcessPart:output_type -> gol.PartReply
+	33, // 99: gol.Worker.ProcessTile:output_type -> gol.PartReply
+	7,  // 100: gol.Worker.SetupBand:output_type -> gol.Count
+	36, // 101: gol.Worker.GetEdge:output_type -> gol.Row
+	38, // 102: gol.Worker.Step:output_type -> gol.StepReply
+	4,  // 103: gol.Worker.FetchBand:output_type -> gol.World
+	8,  // 104: gol.Worker.Shutdown:output_type -> gol.Ok
+	8,  // 105: gol.Worker.Drain:output_type -> gol.Ok
+	69, // [69:106] is the sub-list for method output_type
+	32, // [32:69] is the sub-list for method input_type
+	32, // [32:32] is the sub-list for extension type_name
+	32, // [32:32] is the sub-list for extension extendee
+	0,  // [0:32] is the sub-list for field type_name
+}
+
+func init() { file_gol_proto_init() }
+func file_gol_proto_init() {
+	if File_gol_proto != nil {
+		return
+	}
+	file_gol_proto_msgTypes[44].OneofWrappers = []any{
+		(*Event_AliveCellsCount)(nil),
+		(*Event_ImageOutputComplete)(nil),
+		(*Event_StateChange)(nil),
+		(*Event_CellsFlipped)(nil),
+		(*Event_TurnComplete)(nil),
+		(*Event_FinalTurnComplete)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_gol_proto_rawDesc), len(file_gol_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   45,
+			NumExtensions: 0,
+			NumServices:   2,
+		},
+		GoTypes:           file_gol_proto_goTypes,
+		DependencyIndexes: file_gol_proto_depIdxs,
+		EnumInfos:         file_gol_proto_enumTypes,
+		MessageInfos:      file_gol_proto_msgTypes,
+	}.Build()
+	File_gol_proto = out.File
+	file_gol_proto_goTypes = nil
+	file_gol_proto_depIdxs = nil
+}
@@ -0,0 +1,1575 @@
+// gRPC definitions for the distributed Game of Life.
+// These mirror the net/rpc types used by distributor, broker and worker so that
+// components written in other languages can join the system.
+//
+// Regenerate golpb/ after editing:
+//   protoc -I proto --go_out=golpb --go_opt=paths=source_relative \
+//          --go-grpc_out=golpb --go-grpc_opt=paths=source_relative proto/gol.proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: gol.proto
+
+package golpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Broker_ProcessTurn_FullMethodName        = "/gol.Broker/ProcessTurn"
+	Broker_GetAliveCellsCount_FullMethodName = "/gol.Broker/GetAliveCellsCount"
+	Broker_RegisterWorker_FullMethodName     = "/gol.Broker/RegisterWorker"
+	Broker_DeregisterWorker_FullMethodName   = "/gol.Broker/DeregisterWorker"
+	Broker_RetireWorker_FullMethodName       = "/gol.Broker/RetireWorker"
+	Broker_StartSimulation_FullMethodName    = "/gol.Broker/StartSimulation"
+	Broker_NextTurn_FullMethodName           = "/gol.Broker/NextTurn"
+	Broker_ProcessTurns_FullMethodName       = "/gol.Broker/ProcessTurns"
+	Broker_FetchWorld_FullMethodName         = "/gol.Broker/FetchWorld"
+	Broker_Detach_FullMethodName             = "/gol.Broker/Detach"
+	Broker_Attach_FullMethodName             = "/gol.Broker/Attach"
+	Broker_Subscribe_FullMethodName          = "/gol.Broker/Subscribe"
+	Broker_Poll_FullMethodName               = "/gol.Broker/Poll"
+	Broker_Watch_FullMethodName              = "/gol.Broker/Watch"
+	Broker_Unsubscribe_FullMethodName        = "/gol.Broker/Unsubscribe"
+	Broker_Pause_FullMethodName              = "/gol.Broker/Pause"
+	Broker_Resume_FullMethodName             = "/gol.Broker/Resume"
+	Broker_GetStatus_FullMethodName          = "/gol.Broker/GetStatus"
+	Broker_ListWorkers_FullMethodName        = "/gol.Broker/ListWorkers"
+	Broker_Shutdown_FullMethodName           = "/gol.Broker/Shutdown"
+	Broker_GetWorld_FullMethodName           = "/gol.Broker/GetWorld"
+	Broker_WatchAlive_FullMethodName         = "/gol.Broker/WatchAlive"
+	Broker_SubmitJob_FullMethodName          = "/gol.Broker/SubmitJob"
+	Broker_JobProgress_FullMethodName        = "/gol.Broker/JobProgress"
+	Broker_JobResult_FullMethodName          = "/gol.Broker/JobResult"
+	Broker_UploadWorld_FullMethodName        = "/gol.Broker/UploadWorld"
+	Broker_StreamWorld_FullMethodName        = "/gol.Broker/StreamWorld"
+	Broker_ProcessTurnStream_FullMethodName  = "/gol.Broker/ProcessTurnStream"
+)
+
+// BrokerClient is the client API for Broker service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type BrokerClient interface {
+	ProcessTurn(ctx context.Context, in *WorldParams, opts ...grpc.CallOption) (*World, error)
+	GetAliveCellsCount(ctx context.Context, in *JobArgs, opts ...grpc.CallOption) (*Count, error)
+	RegisterWorker(ctx context.Context, in *RegisterArgs, opts ...grpc.CallOption) (*Ok, error)
+	// Stops handing the worker new parts; sent by a draining worker before it exits.
+	DeregisterWorker(ctx context.Context, in *RegisterArgs, opts ...grpc.CallOption) (*Ok, error)
+	// Takes the worker out of rotation: waits for every job's turn in progress, moves halo bands
+	// to the other workers and asks it to drain. Returns once no turn uses the worker.
+	RetireWorker(ctx context.Context, in *RegisterArgs, opts ...grpc.CallOption) (*Ok, error)
+	StartSimulation(ctx context.Context, in *WorldParams, opts ...grpc.CallOption) (*Ok, error)
+	NextTurn(ctx context.Context, in *JobArgs, opts ...grpc.CallOption) (*NextTurnReply, error)
+	ProcessTurns(ctx context.Context, in *ProcessTurnsArgs, opts ...grpc.CallOption) (*ProcessTurnsReply, error)
+	FetchWorld(ctx context.Context, in *JobArgs, opts ...grpc.CallOption) (*World, error)
+	Detach(ctx context.Context, in *DetachArgs, opts ...grpc.CallOption) (*Ok, error)
+	Attach(ctx context.Context, in *JobArgs, opts ...grpc.CallOption) (*AttachReply, error)
+	Subscribe(ctx context.Context, in *JobArgs, opts ...grpc.CallOption) (*SubscribeReply, error)
+	Poll(ctx context.Context, in *SubscriptionArgs, opts ...grpc.CallOption) (*PollReply, error)
+	// Poll as a stream: the broker pushes every batch of turns as soon as it has one, until the
+	// observer unsubscribes or goes away.
+	Watch(ctx context.Context, in *SubscriptionArgs, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PollReply], error)
+	Unsubscribe(ctx context.Context, in *SubscriptionArgs, opts ...grpc.CallOption) (*Ok, error)
+	Pause(ctx context.Context, in *JobArgs, opts ...grpc.CallOption) (*Ok, error)
+	Resume(ctx context.Context, in *JobArgs, opts ...grpc.CallOption) (*Ok, error)
+	GetStatus(ctx context.Context, in *JobArgs, opts ...grpc.CallOption) (*StatusReply, error)
+	ListWorkers(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ListWorkersReply, error)
+	Shutdown(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Ok, error)
+	GetWorld(ctx context.Context, in *JobArgs, opts ...grpc.CallOption) (*WorldReply, error)
+	WatchAlive(ctx context.Context, in *AliveArgs, opts ...grpc.CallOption) (*AliveReport, error)
+	// Batch jobs, run one after another in the order they were submitted.
+	SubmitJob(ctx context.Context, in *SubmitArgs, opts ...grpc.CallOption) (*SubmitReply, error)
+	JobProgress(ctx context.Context, in *JobArgs, opts ...grpc.CallOption) (*JobProgressReply, error)
+	// Fails until the job is done.
+	JobResult(ctx context.Context, in *JobArgs, opts ...grpc.CallOption) (*JobResultReply, error)
+	// Streaming variants for worlds too large for a single message.
+	UploadWorld(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[RowChunk, Ok], error)
+	StreamWorld(ctx context.Context, in *JobArgs, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RowChunk], error)
+	// ProcessTurn with the world streamed in and the next one streamed back.
+	ProcessTurnStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[RowChunk, RowChunk], error)
+}
+
+type brokerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBrokerClient(cc grpc.ClientConnInterface) BrokerClient {
+	return &brokerClient{cc}
+}
+
+func (c *brokerClient) ProcessTurn(ctx context.Context, in *WorldParams, opts ...grpc.CallOption) (*World, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(World)
+	err := c.cc.Invoke(ctx, Broker_ProcessTurn_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) GetAliveCellsCount(ctx context.Context, in *JobArgs, opts ...grpc.CallOption) (*Count, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Count)
+	err := c.cc.Invoke(ctx, Broker_GetAliveCellsCount_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) RegisterWorker(ctx context.Context, in *RegisterArgs, opts ...grpc.CallOption) (*Ok, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Ok)
+	err := c.cc.Invoke(ctx, Broker_RegisterWorker_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) DeregisterWorker(ctx context.Context, in *RegisterArgs, opts ...grpc.CallOption) (*Ok, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Ok)
+	err := c.cc.Invoke(ctx, Broker_DeregisterWorker_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) RetireWorker(ctx context.Context, in *RegisterArgs, opts ...grpc.CallOption) (*Ok, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Ok)
+	err := c.cc.Invoke(ctx, Broker_RetireWorker_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) StartSimulation(ctx context.Context, in *WorldParams, opts ...grpc.CallOption) (*Ok, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Ok)
+	err := c.cc.Invoke(ctx, Broker_StartSimulation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) NextTurn(ctx context.Context, in *JobArgs, opts ...grpc.CallOption) (*NextTurnReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NextTurnReply)
+	err := c.cc.Invoke(ctx, Broker_NextTurn_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) ProcessTurns(ctx context.Context, in *ProcessTurnsArgs, opts ...grpc.CallOption) (*ProcessTurnsReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ProcessTurnsReply)
+	err := c.cc.Invoke(ctx, Broker_ProcessTurns_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) FetchWorld(ctx context.Context, in *JobArgs, opts ...grpc.CallOption) (*World, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(World)
+	err := c.cc.Invoke(ctx, Broker_FetchWorld_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) Detach(ctx context.Context, in *DetachArgs, opts ...grpc.CallOption) (*Ok, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Ok)
+	err := c.cc.Invoke(ctx, Broker_Detach_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) Attach(ctx context.Context, in *JobArgs, opts ...grpc.CallOption) (*AttachReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AttachReply)
+	err := c.cc.Invoke(ctx, Broker_Attach_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) Subscribe(ctx context.Context, in *JobArgs, opts ...grpc.CallOption) (*SubscribeReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SubscribeReply)
+	err := c.cc.Invoke(ctx, Broker_Subscribe_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) Poll(ctx context.Context, in *SubscriptionArgs, opts ...grpc.CallOption) (*PollReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PollReply)
+	err := c.cc.Invoke(ctx, Broker_Poll_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) Watch(ctx context.Context, in *SubscriptionArgs, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PollReply], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Broker_ServiceDesc.Streams[0], Broker_Watch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscriptionArgs, PollReply]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Broker_WatchClient = grpc.ServerStreamingClient[PollReply]
+
+func (c *brokerClient) Unsubscribe(ctx context.Context, in *SubscriptionArgs, opts ...grpc.CallOption) (*Ok, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Ok)
+	err := c.cc.Invoke(ctx, Broker_Unsubscribe_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) Pause(ctx context.Context, in *JobArgs, opts ...grpc.CallOption) (*Ok, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Ok)
+	err := c.cc.Invoke(ctx, Broker_Pause_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) Resume(ctx context.Context, in *JobArgs, opts ...grpc.CallOption) (*Ok, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Ok)
+	err := c.cc.Invoke(ctx, Broker_Resume_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) GetStatus(ctx context.Context, in *JobArgs, opts ...grpc.CallOption) (*StatusReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StatusReply)
+	err := c.cc.Invoke(ctx, Broker_GetStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) ListWorkers(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ListWorkersReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListWorkersReply)
+	err := c.cc.Invoke(ctx, Broker_ListWorkers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) Shutdown(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Ok, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Ok)
+	err := c.cc.Invoke(ctx, Broker_Shutdown_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) GetWorld(ctx context.Context, in *JobArgs, opts ...grpc.CallOption) (*WorldReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WorldReply)
+	err := c.cc.Invoke(ctx, Broker_GetWorld_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) WatchAlive(ctx context.Context, in *AliveArgs, opts ...grpc.CallOption) (*AliveReport, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AliveReport)
+	err := c.cc.Invoke(ctx, Broker_WatchAlive_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) SubmitJob(ctx context.Context, in *SubmitArgs, opts ...grpc.CallOption) (*SubmitReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SubmitReply)
+	err := c.cc.Invoke(ctx, Broker_SubmitJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) JobProgress(ctx context.Context, in *JobArgs, opts ...grpc.CallOption) (*JobProgressReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JobProgressReply)
+	err := c.cc.Invoke(ctx, Broker_JobProgress_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) JobResult(ctx context.Context, in *JobArgs, opts ...grpc.CallOption) (*JobResultReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JobResultReply)
+	err := c.cc.Invoke(ctx, Broker_JobResult_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) UploadWorld(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[RowChunk, Ok], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Broker_ServiceDesc.Streams[1], Broker_UploadWorld_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[RowChunk, Ok]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Broker_UploadWorldClient = grpc.ClientStreamingClient[RowChunk, Ok]
+
+func (c *brokerClient) StreamWorld(ctx context.Context, in *JobArgs, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RowChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Broker_ServiceDesc.Streams[2], Broker_StreamWorld_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[JobArgs, RowChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Broker_StreamWorldClient = grpc.ServerStreamingClient[RowChunk]
+
+func (c *brokerClient) ProcessTurnStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[RowChunk, RowChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Broker_ServiceDesc.Streams[3], Broker_ProcessTurnStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[RowChunk, RowChunk]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Broker_ProcessTurnStreamClient = grpc.BidiStreamingClient[RowChunk, RowChunk]
+
+// BrokerServer is the server API for Broker service.
+// All implementations must embed UnimplementedBrokerServer
+// for forward compatibility.
+type BrokerServer interface {
+	ProcessTurn(context.Context, *WorldParams) (*World, error)
+	GetAliveCellsCount(context.Context, *JobArgs) (*Count, error)
+	RegisterWorker(context.Context, *RegisterArgs) (*Ok, error)
+	// Stops handing the worker new parts; sent by a draining worker before it exits.
+	DeregisterWorker(context.Context, *RegisterArgs) (*Ok, error)
+	// Takes the worker out of rotation: waits for every job's turn in progress, moves halo bands
+	// to the other workers and asks it to drain. Returns once no turn uses the worker.
+	RetireWorker(context.Context, *RegisterArgs) (*Ok, error)
+	StartSimulation(context.Context, *WorldParams) (*Ok, error)
+	NextTurn(context.Context, *JobArgs) (*NextTurnReply, error)
+	ProcessTurns(context.Context, *ProcessTurnsArgs) (*ProcessTurnsReply, error)
+	FetchWorld(context.Context, *JobArgs) (*World, error)
+	Detach(context.Context, *DetachArgs) (*Ok, error)
+	Attach(context.Context, *JobArgs) (*AttachReply, error)
+	Subscribe(context.Context, *JobArgs) (*SubscribeReply, error)
+	Poll(context.Context, *SubscriptionArgs) (*PollReply, error)
+	// Poll as a stream: the broker pushes every batch of turns as soon as it has one, until the
+	// observer unsubscribes or goes away.
+	Watch(*SubscriptionArgs, grpc.ServerStreamingServer[PollReply]) error
+	Unsubscribe(context.Context, *SubscriptionArgs) (*Ok, error)
+	Pause(context.Context, *JobArgs) (*Ok, error)
+	Resume(context.Context, *JobArgs) (*Ok, error)
+	GetStatus(context.Context, *JobArgs) (*StatusReply, error)
+	ListWorkers(context.Context, *Empty) (*ListWorkersReply, error)
+	Shutdown(context.Context, *Empty) (*Ok, error)
+	GetWorld(context.Context, *JobArgs) (*WorldReply, error)
+	WatchAlive(context.Context, *AliveArgs) (*AliveReport, error)
+	// Batch jobs, run one after another in the order they were submitted.
+	SubmitJob(context.Context, *SubmitArgs) (*SubmitReply, error)
+	JobProgress(context.Context, *JobArgs) (*JobProgressReply, error)
+	// Fails until the job is done.
+	JobResult(context.Context, *JobArgs) (*JobResultReply, error)
+	// Streaming variants for worlds too large for a single message.
+	UploadWorld(grpc.ClientStreamingServer[RowChunk, Ok]) error
+	StreamWorld(*JobArgs, grpc.ServerStreamingServer[RowChunk]) error
+	// ProcessTurn with the world streamed in and the next one streamed back.
+	ProcessTurnStream(grpc.BidiStreamingServer[RowChunk, RowChunk]) error
+	mustEmbedUnimplementedBrokerServer()
+}
+
+// UnimplementedBrokerServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedBrokerServer struct{}
+
+func (UnimplementedBrokerServer) ProcessTurn(context.Context, *WorldParams) (*World, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ProcessTurn not implemented")
+}
+func (UnimplementedBrokerServer) GetAliveCellsCount(context.Context, *JobArgs) (*Count, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAliveCellsCount not implemented")
+}
+func (UnimplementedBrokerServer) RegisterWorker(context.Context, *RegisterArgs) (*Ok, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterWorker not implemented")
+}
+func (UnimplementedBrokerServer) DeregisterWorker(context.Context, *RegisterArgs) (*Ok, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeregisterWorker not implemented")
+}
+func (UnimplementedBrokerServer) RetireWorker(context.Context, *RegisterArgs) (*Ok, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RetireWorker not implemented")
+}
+func (UnimplementedBrokerServer) StartSimulation(context.Context, *WorldParams) (*Ok, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartSimulation not implemented")
+}
+func (UnimplementedBrokerServer) NextTurn(context.Context, *JobArgs) (*NextTurnReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NextTurn not implemented")
+}
+func (UnimplementedBrokerServer) ProcessTurns(context.Context, *ProcessTurnsArgs) (*ProcessTurnsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ProcessTurns not implemented")
+}
+func (UnimplementedBrokerServer) FetchWorld(context.Context, *JobArgs) (*World, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FetchWorld not implemented")
+}
+func (UnimplementedBrokerServer) Detach(context.Context, *DetachArgs) (*Ok, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Detach not implemented")
+}
+func (UnimplementedBrokerServer) Attach(context.Context, *JobArgs) (*AttachReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Attach not implemented")
+}
+func (UnimplementedBrokerServer) Subscribe(context.Context, *JobArgs) (*SubscribeReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedBrokerServer) Poll(context.Context, *SubscriptionArgs) (*PollReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Poll not implemented")
+}
+func (UnimplementedBrokerServer) Watch(*SubscriptionArgs, grpc.ServerStreamingServer[PollReply]) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedBrokerServer) Unsubscribe(context.Context, *SubscriptionArgs) (*Ok, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Unsubscribe not implemented")
+}
+func (UnimplementedBrokerServer) Pause(context.Context, *JobArgs) (*Ok, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Pause not implemented")
+}
+func (UnimplementedBrokerServer) Resume(context.Context, *JobArgs) (*Ok, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Resume not implemented")
+}
+func (UnimplementedBrokerServer) GetStatus(context.Context, *JobArgs) (*StatusReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (UnimplementedBrokerServer) ListWorkers(context.Context, *Empty) (*ListWorkersReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWorkers not implemented")
+}
+func (UnimplementedBrokerServer) Shutdown(context.Context, *Empty) (*Ok, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Shutdown not implemented")
+}
+func (UnimplementedBrokerServer) GetWorld(context.Context, *JobArgs) (*WorldReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWorld not implemented")
+}
+func (UnimplementedBrokerServer) WatchAlive(context.Context, *AliveArgs) (*AliveReport, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WatchAlive not implemented")
+}
+func (UnimplementedBrokerServer) SubmitJob(context.Context, *SubmitArgs) (*SubmitReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitJob not implemented")
+}
+func (UnimplementedBrokerServer) JobProgress(context.Context, *JobArgs) (*JobProgressReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method JobProgress not implemented")
+}
+func (UnimplementedBrokerServer) JobResult(context.Context, *JobArgs) (*JobResultReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method JobResult not implemented")
+}
+func (UnimplementedBrokerServer) UploadWorld(grpc.ClientStreamingServer[RowChunk, Ok]) error {
+	return status.Errorf(codes.Unimplemented, "method UploadWorld not implemented")
+}
+func (UnimplementedBrokerServer) StreamWorld(*JobArgs, grpc.ServerStreamingServer[RowChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamWorld not implemented")
+}
+func (UnimplementedBrokerServer) ProcessTurnStream(grpc.BidiStreamingServer[RowChunk, RowChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method ProcessTurnStream not implemented")
+}
+func (UnimplementedBrokerServer) mustEmbedUnimplementedBrokerServer() {}
+func (UnimplementedBrokerServer) testEmbeddedByValue()                {}
+
+// UnsafeBrokerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BrokerServer will
+// result in compilation errors.
+type UnsafeBrokerServer interface {
+	mustEmbedUnimplementedBrokerServer()
+}
+
+func RegisterBrokerServer(s grpc.ServiceRegistrar, srv BrokerServer) {
+	// If the following call pancis, it indicates UnimplementedBrokerServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Broker_ServiceDesc, srv)
+}
+
+func _Broker_ProcessTurn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WorldParams)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).ProcessTurn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_ProcessTurn_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).ProcessTurn(ctx, req.(*WorldParams))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_GetAliveCellsCount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).GetAliveCellsCount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_GetAliveCellsCount_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).GetAliveCellsCount(ctx, req.(*JobArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_RegisterWorker_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).RegisterWorker(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_RegisterWorker_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).RegisterWorker(ctx, req.(*RegisterArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_DeregisterWorker_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).DeregisterWorker(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_DeregisterWorker_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).DeregisterWorker(ctx, req.(*RegisterArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_RetireWorker_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).RetireWorker(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_RetireWorker_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).RetireWorker(ctx, req.(*RegisterArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_StartSimulation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WorldParams)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).StartSimulation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_StartSimulation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).StartSimulation(ctx, req.(*WorldParams))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_NextTurn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).NextTurn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_NextTurn_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).NextTurn(ctx, req.(*JobArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_ProcessTurns_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProcessTurnsArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).ProcessTurns(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_ProcessTurns_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).ProcessTurns(ctx, req.(*ProcessTurnsArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_FetchWorld_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).FetchWorld(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_FetchWorld_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).FetchWorld(ctx, req.(*JobArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_Detach_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DetachArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).Detach(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_Detach_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).Detach(ctx, req.(*DetachArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_Attach_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).Attach(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_Attach_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).Attach(ctx, req.(*JobArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_Subscribe_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).Subscribe(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_Subscribe_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).Subscribe(ctx, req.(*JobArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_Poll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubscriptionArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).Poll(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_Poll_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).Poll(ctx, req.(*SubscriptionArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscriptionArgs)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BrokerServer).Watch(m, &grpc.GenericServerStream[SubscriptionArgs, PollReply]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Broker_WatchServer = grpc.ServerStreamingServer[PollReply]
+
+func _Broker_Unsubscribe_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubscriptionArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).Unsubscribe(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_Unsubscribe_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).Unsubscribe(ctx, req.(*SubscriptionArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_Pause_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).Pause(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_Pause_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).Pause(ctx, req.(*JobArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_Resume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).Resume(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_Resume_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).Resume(ctx, req.(*JobArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_GetStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).GetStatus(ctx, req.(*JobArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_ListWorkers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).ListWorkers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_ListWorkers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).ListWorkers(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_Shutdown_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).Shutdown(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_Shutdown_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).Shutdown(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_GetWorld_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).GetWorld(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_GetWorld_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).GetWorld(ctx, req.(*JobArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_WatchAlive_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AliveArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).WatchAlive(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_WatchAlive_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).WatchAlive(ctx, req.(*AliveArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_SubmitJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).SubmitJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_SubmitJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).SubmitJob(ctx, req.(*SubmitArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_JobProgress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).JobProgress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_JobProgress_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).JobProgress(ctx, req.(*JobArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_JobResult_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).JobResult(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_JobResult_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).JobResult(ctx, req.(*JobArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_UploadWorld_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(BrokerServer).UploadWorld(&grpc.GenericServerStream[RowChunk, Ok]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Broker_UploadWorldServer = grpc.ClientStreamingServer[RowChunk, Ok]
+
+func _Broker_StreamWorld_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(JobArgs)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BrokerServer).StreamWorld(m, &grpc.GenericServerStream[JobArgs, RowChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Broker_StreamWorldServer = grpc.ServerStreamingServer[RowChunk]
+
+func _Broker_ProcessTurnStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(BrokerServer).ProcessTurnStream(&grpc.GenericServerStream[RowChunk, RowChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Broker_ProcessTurnStreamServer = grpc.BidiStreamingServer[RowChunk, RowChunk]
+
+// Broker_ServiceDesc is the grpc.ServiceDesc for Broker service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Broker_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gol.Broker",
+	HandlerType: (*BrokerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ProcessTurn",
+			Handler:    _Broker_ProcessTurn_Handler,
+		},
+		{
+			MethodName: "GetAliveCellsCount",
+			Handler:    _Broker_GetAliveCellsCount_Handler,
+		},
+		{
+			MethodName: "RegisterWorker",
+			Handler:    _Broker_RegisterWorker_Handler,
+		},
+		{
+			MethodName: "DeregisterWorker",
+			Handler:    _Broker_DeregisterWorker_Handler,
+		},
+		{
+			MethodName: "RetireWorker",
+			Handler:    _Broker_RetireWorker_Handler,
+		},
+		{
+			MethodName: "StartSimulation",
+			Handler:    _Broker_StartSimulation_Handler,
+		},
+		{
+			MethodName: "NextTurn",
+			Handler:    _Broker_NextTurn_Handler,
+		},
+		{
+			MethodName: "ProcessTurns",
+			Handler:    _Broker_ProcessTurns_Handler,
+		},
+		{
+			MethodName: "FetchWorld",
+			Handler:    _Broker_FetchWorld_Handler,
+		},
+		{
+			MethodName: "Detach",
+			Handler:    _Broker_Detach_Handler,
+		},
+		{
+			MethodName: "Attach",
+			Handler:    _Broker_Attach_Handler,
+		},
+		{
+			MethodName: "Subscribe",
+			Handler:    _Broker_Subscribe_Handler,
+		},
+		{
+			MethodName: "Poll",
+			Handler:    _Broker_Poll_Handler,
+		},
+		{
+			MethodName: "Unsubscribe",
+			Handler:    _Broker_Unsubscribe_Handler,
+		},
+		{
+			MethodName: "Pause",
+			Handler:    _Broker_Pause_Handler,
+		},
+		{
+			MethodName: "Resume",
+			Handler:    _Broker_Resume_Handler,
+		},
+		{
+			MethodName: "GetStatus",
+			Handler:    _Broker_GetStatus_Handler,
+		},
+		{
+			MethodName: "ListWorkers",
+			Handler:    _Broker_ListWorkers_Handler,
+		},
+		{
+			MethodName: "Shutdown",
+			Handler:    _Broker_Shutdown_Handler,
+		},
+		{
+			MethodName: "GetWorld",
+			Handler:    _Broker_GetWorld_Handler,
+		},
+		{
+			MethodName: "WatchAlive",
+			Handler:    _Broker_WatchAlive_Handler,
+		},
+		{
+			MethodName: "SubmitJob",
+			Handler:    _Broker_SubmitJob_Handler,
+		},
+		{
+			MethodName: "JobProgress",
+			Handler:    _Broker_JobProgress_Handler,
+		},
+		{
+			MethodName: "JobResult",
+			Handler:    _Broker_JobResult_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _Broker_Watch_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "UploadWorld",
+			Handler:       _Broker_UploadWorld_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "StreamWorld",
+			Handler:       _Broker_StreamWorld_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ProcessTurnStream",
+			Handler:       _Broker_ProcessTurnStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "gol.proto",
+}
+
+const (
+	Worker_Ping_FullMethodName        = "/gol.Worker/Ping"
+	Worker_ProcessPart_FullMethodName = "/gol.Worker/ProcessPart"
+	Worker_ProcessTile_FullMethodName = "/gol.Worker/ProcessTile"
+	Worker_SetupBand_FullMethodName   = "/gol.Worker/SetupBand"
+	Worker_GetEdge_FullMethodName     = "/gol.Worker/GetEdge"
+	Worker_Step_FullMethodName        = "/gol.Worker/Step"
+	Worker_FetchBand_FullMethodName   = "/gol.Worker/FetchBand"
+	Worker_Shutdown_FullMethodName    = "/gol.Worker/Shutdown"
+	Worker_Drain_FullMethodName       = "/gol.Worker/Drain"
+)
+
+// WorkerClient is the client API for Worker service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type WorkerClient interface {
+	Ping(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Ok, error)
+	ProcessPart(ctx context.Context, in *Task, opts ...grpc.CallOption) (*PartReply, error)
+	ProcessTile(ctx context.Context, in *TileTask, opts ...grpc.CallOption) (*PartReply, error)
+	SetupBand(ctx context.Context, in *BandSetup, opts ...grpc.CallOption) (*Count, error)
+	GetEdge(ctx context.Context, in *EdgeArgs, opts ...grpc.CallOption) (*Row, error)
+	Step(ctx context.Context, in *StepArgs, opts ...grpc.CallOption) (*StepReply, error)
+	FetchBand(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*World, error)
+	Shutdown(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Ok, error)
+	// Deregisters from the broker, finishes the parts in flight, then exits.
+	Drain(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Ok, error)
+}
+
+type workerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewWorkerClient(cc grpc.ClientConnInterface) WorkerClient {
+	return &workerClient{cc}
+}
+
+func (c *workerClient) Ping(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Ok, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Ok)
+	err := c.cc.Invoke(ctx, Worker_Ping_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *workerClient) ProcessPart(ctx context.Context, in *Task, opts ...grpc.CallOption) (*PartReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PartReply)
+	err := c.cc.Invoke(ctx, Worker_ProcessPart_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *workerClient) ProcessTile(ctx context.Context, in *TileTask, opts ...grpc.CallOption) (*PartReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PartReply)
+	err := c.cc.Invoke(ctx, Worker_ProcessTile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *workerClient) SetupBand(ctx context.Context, in *BandSetup, opts ...grpc.CallOption) (*Count, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Count)
+	err := c.cc.Invoke(ctx, Worker_SetupBand_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *workerClient) GetEdge(ctx context.Context, in *EdgeArgs, opts ...grpc.CallOption) (*Row, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Row)
+	err := c.cc.Invoke(ctx, Worker_GetEdge_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *workerClient) Step(ctx context.Context, in *StepArgs, opts ...grpc.CallOption) (*StepReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StepReply)
+	err := c.cc.Invoke(ctx, Worker_Step_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *workerClient) FetchBand(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*World, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(World)
+	err := c.cc.Invoke(ctx, Worker_FetchBand_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *workerClient) Shutdown(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Ok, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Ok)
+	err := c.cc.Invoke(ctx, Worker_Shutdown_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *workerClient) Drain(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Ok, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Ok)
+	err := c.cc.Invoke(ctx, Worker_Drain_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WorkerServer is the server API for Worker service.
+// All implementations must embed UnimplementedWorkerServer
+// for forward compatibility.
+type WorkerServer interface {
+	Ping(context.Context, *Empty) (*Ok, error)
+	ProcessPart(context.Context, *Task) (*PartReply, error)
+	ProcessTile(context.Context, *TileTask) (*PartReply, error)
+	SetupBand(context.Context, *BandSetup) (*Count, error)
+	GetEdge(context.Context, *EdgeArgs) (*Row, error)
+	Step(context.Context, *StepArgs) (*StepReply, error)
+	FetchBand(context.Context, *Empty) (*World, error)
+	Shutdown(context.Context, *Empty) (*Ok, error)
+	// Deregisters from the broker, finishes the parts in flight, then exits.
+	Drain(context.Context, *Empty) (*Ok, error)
+	mustEmbedUnimplementedWorkerServer()
+}
+
+// UnimplementedWorkerServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedWorkerServer struct{}
+
+func (UnimplementedWorkerServer) Ping(context.Context, *Empty) (*Ok, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Ping not implemented")
+}
+func (UnimplementedWorkerServer) ProcessPart(context.Context, *Task) (*PartReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ProcessPart not implemented")
+}
+func (UnimplementedWorkerServer) ProcessTile(context.Context, *TileTask) (*PartReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ProcessTile not implemented")
+}
+func (UnimplementedWorkerServer) SetupBand(context.Context, *BandSetup) (*Count, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetupBand not implemented")
+}
+func (UnimplementedWorkerServer) GetEdge(context.Context, *EdgeArgs) (*Row, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetEdge not implemented")
+}
+func (UnimplementedWorkerServer) Step(context.Context, *StepArgs) (*StepReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Step not implemented")
+}
+func (UnimplementedWorkerServer) FetchBand(context.Context, *Empty) (*World, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FetchBand not implemented")
+}
+func (UnimplementedWorkerServer) Shutdown(context.Context, *Empty) (*Ok, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Shutdown not implemented")
+}
+func (UnimplementedWorkerServer) Drain(context.Context, *Empty) (*Ok, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Drain not implemented")
+}
+func (UnimplementedWorkerServer) mustEmbedUnimplementedWorkerServer() {}
+func (UnimplementedWorkerServer) testEmbeddedByValue()                {}
+
+// UnsafeWorkerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to WorkerServer will
+// result in compilation errors.
+type UnsafeWorkerServer interface {
+	mustEmbedUnimplementedWorkerServer()
+}
+
+func RegisterWorkerServer(s grpc.ServiceRegistrar, srv WorkerServer) {
+	// If the following call pancis, it indicates UnimplementedWorkerServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Worker_ServiceDesc, srv)
+}
+
+func _Worker_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkerServer).Ping(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Worker_Ping_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkerServer).Ping(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Worker_ProcessPart_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Task)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkerServer).ProcessPart(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Worker_ProcessPart_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkerServer).ProcessPart(ctx, req.(*Task))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Worker_ProcessTile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TileTask)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkerServer).ProcessTile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Worker_ProcessTile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkerServer).ProcessTile(ctx, req.(*TileTask))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Worker_SetupBand_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BandSetup)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkerServer).SetupBand(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Worker_SetupBand_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkerServer).SetupBand(ctx, req.(*BandSetup))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Worker_GetEdge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EdgeArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkerServer).GetEdge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Worker_GetEdge_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkerServer).GetEdge(ctx, req.(*EdgeArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Worker_Step_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StepArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkerServer).Step(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Worker_Step_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkerServer).Step(ctx, req.(*StepArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Worker_FetchBand_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkerServer).FetchBand(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Worker_FetchBand_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkerServer).FetchBand(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Worker_Shutdown_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkerServer).Shutdown(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Worker_Shutdown_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkerServer).Shutdown(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Worker_Drain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkerServer).Drain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Worker_Drain_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkerServer).Drain(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Worker_ServiceDesc is the grpc.ServiceDesc for Worker service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Worker_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gol.Worker",
+	HandlerType: (*WorkerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Ping",
+			Handler:    _Worker_Ping_Handler,
+		},
+		{
+			MethodName: "ProcessPart",
+			Handler:    _Worker_ProcessPart_Handler,
+		},
+		{
+			MethodName: "ProcessTile",
+			Handler:    _Worker_ProcessTile_Handler,
+		},
+		{
+			MethodName: "SetupBand",
+			Handler:    _Worker_SetupBand_Handler,
+		},
+		{
+			MethodName: "GetEdge",
+			Handler:    _Worker_GetEdge_Handler,
+		},
+		{
+			MethodName: "Step",
+			Handler:    _Worker_Step_Handler,
+		},
+		{
+			MethodName: "FetchBand",
+			Handler:    _Worker_FetchBand_Handler,
+		},
+		{
+			MethodName: "Shutdown",
+			Handler:    _Worker_Shutdown_Handler,
+		},
+		{
+			MethodName: "Drain",
+			Handler:    _Worker_Drain_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "gol.proto",
+}
@@ -0,0 +1,247 @@
+// Package hashlife evolves a Game of Life torus with Gosper's HashLife algorithm.
+//
+// The world is a quadtree whose identical subtrees are shared (hash-consed), and the
+// next generation of every distinct subtree is computed once and memoised. Repetitive
+// patterns, and the large empty areas around sparse ones, therefore cost almost nothing
+// per generation, however big the world is.
+//
+// HashLife works on an unbounded plane. A W×W torus with W a power of two is the plane
+// tiled with copies of itself, and since identical tiles share one node that tiling is
+// just a node whose four quadrants are the torus. Universe steps that node and shifts the
+// centre it gets back into place, so the rules match the wrapping engine used elsewhere.
+package hashlife
+
+import (
+	"fmt"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// maxNodes is how many distinct nodes are kept before the cache is thrown away and
+// rebuilt from the current generation, which bounds memory on chaotic worlds.
+const maxNodes = 1 << 22
+
+// node is a square of 2^level cells. Level 0 nodes are single cells.
+type node struct {
+	nw, ne, sw, se *node
+	level          int
+	pop            int   // live cells
+	next           *node // centre half of this node one generation later, once computed
+}
+
+// Universe is a square torus of 2^level cells per side.
+type Universe struct {
+	root       *node
+	nodes      map[[4]*node]*node
+	dead, live *node
+}
+
+// Supported reports whether a width×height torus can be evolved with HashLife:
+// it has to be square with a power-of-two side of at least 2.
+func Supported(width, height int) bool {
+	return width == height && width >= 2 && width&(width-1) == 0
+}
+
+// New builds a universe from a world of 0 (dead) / 255 (alive) bytes.
+func New(world [][]uint8) (*Universe, error) {
+	size := len(world)
+	if size == 0 || !Supported(len(world[0]), size) {
+		width := 0
+		if size > 0 {
+			width = len(world[0])
+		}
+		return nil, fmt.Errorf("hashlife: %dx%d world is not a square with a power-of-two side", width, size)
+	}
+	u := &Universe{}
+	u.reset()
+	level := 0
+	for 1<<level < size {
+		level++
+	}
+	u.root = u.build(world, 0, 0, level)
+	return u, nil
+}
+
+func (u *Universe) reset() {
+	u.nodes = make(map[[4]*node]*node)
+	u.dead = &node{}
+	u.live = &node{pop: 1}
+}
+
+// join returns the canonical node with the given quadrants.
+func (u *Universe) join(nw, ne, sw, se *node) *node {
+	key := [4]*node{nw, ne, sw, se}
+	if n, ok := u.nodes[key]; ok {
+		return n
+	}
+	n := &node{nw: nw, ne: ne, sw: sw, se: se, level: nw.level + 1, pop: nw.pop + ne.pop + sw.pop + se.pop}
+	u.nodes[key] = n
+	return n
+}
+
+// build makes the node for the 2^level square of world whose top-left cell is (x, y).
+func (u *Universe) build(world [][]uint8, x, y, level int) *node {
+	if level == 0 {
+		if world[y][x] != 0 {
+			return u.live
+		}
+		return u.dead
+	}
+	half := 1 << (level - 1)
+	return u.join(
+		u.build(world, x, y, level-1),
+		u.build(world, x+half, y, level-1),
+		u.build(world, x, y+half, level-1),
+		u.build(world, x+half, y+half, level-1),
+	)
+}
+
+// Size is the side of the torus in cells.
+func (u *Universe) Size() int {
+	return 1 << u.root.level
+}
+
+// Population is the number of live cells.
+func (u *Universe) Population() int {
+	return u.root.pop
+}
+
+// World expands the universe into a world of 0 / 255 bytes.
+func (u *Universe) World() [][]uint8 {
+	size := u.Size()
+	world := util.NewWorld(size, size)
+	fill(world, u.root, 0, 0)
+	return world
+}
+
+func fill(world [][]uint8, n *node, x, y int) {
+	if n.pop == 0 {
+		return
+	}
+	if n.level == 0 {
+		world[y][x] = 255
+		return
+	}
+	half := 1 << (n.level - 1)
+	fill(world, n.nw, x, y)
+	fill(world, n.ne, x+half, y)
+	fill(world, n.sw, x, y+half)
+	fill(world, n.se, x+half, y+half)
+}
+
+// Step advances the universe by one generation and returns the cells that flipped.
+func (u *Universe) Step() []util.Cell {
+	if len(u.nodes) > maxNodes {
+		// Start the cache afresh from the current generation.
+		world := u.World()
+		u.reset()
+		u.root = u.build(world, 0, 0, u.root.level)
+	}
+
+	// The plane tiled with the torus, stepped, is the torus shifted by half its side.
+	t := u.root
+	c := u.next(u.join(t, t, t, t))
+	r := u.join(c.se, c.sw, c.ne, c.nw)
+
+	var flipped []util.Cell
+	diff(t, r, 0, 0, &flipped)
+	u.root = r
+	return flipped
+}
+
+// diff appends the cells that differ between a and b, which cover the same square at (x, y).
+// Identical subtrees are the same node, so unchanged areas are skipped in one comparison.
+func diff(a, b *node, x, y int, out *[]util.Cell) {
+	if a == b {
+		return
+	}
+	if a.level == 0 {
+		*out = append(*out, util.Cell{X: x, Y: y})
+		return
+	}
+	half := 1 << (a.level - 1)
+	diff(a.nw, b.nw, x, y, out)
+	diff(a.ne, b.ne, x+half, y, out)
+	diff(a.sw, b.sw, x, y+half, out)
+	diff(a.se, b.se, x+half, y+half, out)
+}
+
+// rule is the next state of cell c with the given number of live neighbours.
+func (u *Universe) rule(c *node, neighbours int) *node {
+	if neighbours == 3 || (neighbours == 2 && c.pop == 1) {
+		return u.live
+	}
+	return u.dead
+}
+
+// next returns the centre half of n (level >= 2) one generation later.
+func (u *Universe) next(n *node) *node {
+	if n.next != nil {
+		return n.next
+	}
+	if n.pop == 0 {
+		n.next = n.nw
+		return n.next
+	}
+
+	if n.level == 2 {
+		// 4×4 cells: work out the centre 2×2 directly.
+		var cells [4][4]*node
+		for y := 0; y < 4; y++ {
+			for x := 0; x < 4; x++ {
+				cells[y][x] = n.quadrant(x/2, y/2).quadrant(x%2, y%2)
+			}
+		}
+		centre := func(x, y int) *node {
+			neighbours := 0
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					if dx != 0 || dy != 0 {
+						neighbours += cells[y+dy][x+dx].pop
+					}
+				}
+			}
+			return u.rule(cells[y][x], neighbours)
+		}
+		n.next = u.join(centre(1, 1), centre(2, 1), centre(1, 2), centre(2, 2))
+		return n.next
+	}
+
+	// The nine overlapping half-size squares, as of this generation...
+	n00 := u.centre(n.nw)
+	n01 := u.centre(u.join(n.nw.ne, n.ne.nw, n.nw.se, n.ne.sw))
+	n02 := u.centre(n.ne)
+	n10 := u.centre(u.join(n.nw.sw, n.nw.se, n.sw.nw, n.sw.ne))
+	n11 := u.centre(u.join(n.nw.se, n.ne.sw, n.sw.ne, n.se.nw))
+	n12 := u.centre(u.join(n.ne.sw, n.ne.se, n.se.nw, n.se.ne))
+	n20 := u.centre(n.sw)
+	n21 := u.centre(u.join(n.sw.ne, n.se.nw, n.sw.se, n.se.sw))
+	n22 := u.centre(n.se)
+
+	// ...grouped into four quadrants whose centres, one generation on, tile our centre.
+	n.next = u.join(
+		u.next(u.join(n00, n01, n10, n11)),
+		u.next(u.join(n01, n02, n11, n12)),
+		u.next(u.join(n10, n11, n20, n21)),
+		u.next(u.join(n11, n12, n21, n22)),
+	)
+	return n.next
+}
+
+// centre returns the centre half of n (level >= 2), at the same generation.
+func (u *Universe) centre(n *node) *node {
+	return u.join(n.nw.se, n.ne.sw, n.sw.ne, n.se.nw)
+}
+
+// quadrant returns the child at (x, y), each 0 or 1.
+func (n *node) quadrant(x, y int) *node {
+	switch {
+	case x == 0 && y == 0:
+		return n.nw
+	case y == 0:
+		return n.ne
+	case x == 0:
+		return n.sw
+	}
+	return n.se
+}
@@ -0,0 +1,88 @@
+package hashlife
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/engine"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// evolveAlongside steps u and world, evolved by the engine package on the same torus,
+// generation by generation, and fails as soon as the world, population or flipped cells of u
+// differ from it.
+func evolveAlongside(t *testing.T, u *Universe, world [][]uint8, generations int) {
+	t.Helper()
+	for turn := 1; turn <= generations; turn++ {
+		next := engine.Evolve(world, engine.BoundaryTorus, engine.Conway, 1)
+		flipped := u.Step()
+		var want []util.Cell
+		alive := 0
+		for y := range next {
+			for x := range next[y] {
+				if next[y][x] != world[y][x] {
+					want = append(want, util.Cell{X: x, Y: y})
+				}
+				if next[y][x] == 255 {
+					alive++
+				}
+			}
+		}
+		slices.SortFunc(flipped, func(a, b util.Cell) int { return (a.Y-b.Y)*len(world[0]) + a.X - b.X })
+		if !slices.Equal(flipped, want) {
+			t.Fatalf("turn %d: %d cells flipped, want %d", turn, len(flipped), len(want))
+		}
+		if got := u.World(); !slices.EqualFunc(got, next, slices.Equal) {
+			t.Fatalf("turn %d: world differs", turn)
+		}
+		if u.Population() != alive {
+			t.Fatalf("turn %d: population %d, want %d", turn, u.Population(), alive)
+		}
+		world = next
+	}
+}
+
+// TestGlider tests a glider crossing the edges of a small torus, and coming back round, against
+// the engine package.
+func TestGlider(t *testing.T) {
+	world := util.NewWorld(16, 16)
+	for _, c := range []util.Cell{{X: 1, Y: 0}, {X: 2, Y: 1}, {X: 0, Y: 2}, {X: 1, Y: 2}, {X: 2, Y: 2}} {
+		world[c.Y][c.X] = 255
+	}
+	u, err := New(world)
+	if err != nil {
+		t.Fatal(err)
+	}
+	evolveAlongside(t, u, world, 4*16+5)
+}
+
+// TestSoup tests random soups, which leave debris, oscillators and gliders wrapping around,
+// against the engine package.
+func TestSoup(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, size := range []int{2, 4, 64, 128} {
+		world := util.NewWorld(size, size)
+		for y := range world {
+			for x := range world[y] {
+				if r.Intn(3) == 0 {
+					world[y][x] = 255
+				}
+			}
+		}
+		u, err := New(world)
+		if err != nil {
+			t.Fatal(err)
+		}
+		evolveAlongside(t, u, world, 200)
+	}
+}
+
+// TestUnsupported tests that only square worlds with a power-of-two side are accepted.
+func TestUnsupported(t *testing.T) {
+	for _, size := range [][2]int{{0, 0}, {1, 1}, {3, 3}, {64, 32}, {48, 48}} {
+		if _, err := New(util.NewWorld(size[0], size[1])); err == nil {
+			t.Errorf("%dx%d: expected an error", size[0], size[1])
+		}
+	}
+}
@@ -2,16 +2,21 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"runtime"
+	"slices"
+	"strconv"
+	"strings"
 	"sync/atomic"
 	"syscall"
 	"time"
 
 	"uk.ac.bris.cs/gameoflife/gol"
 	"uk.ac.bris.cs/gameoflife/sdl"
+	"uk.ac.bris.cs/gameoflife/transport"
 	"uk.ac.bris.cs/gameoflife/util"
 )
 
@@ -44,29 +49,511 @@ func main() {
 		10000000000,
 		"Specify the number of turns to process. Defaults to 10000000000.")
 
+	flag.StringVar(
+		&params.Input,
+		"input",
+		"",
+		"Specify an image to start from ("+strings.Join(gol.Formats(), ", ")+", by extension). The size of an image overrides -w and -h; a pattern (rle, cells, lif) is placed into the -w x -h world. Defaults to images/WxH.pgm.")
+
+	offset := flag.String(
+		"offset",
+		"",
+		"Specify where the top-left corner of an -input pattern (rle, cells, lif) goes in the world, as x,y. Defaults to centring it.")
+
+	flag.Func(
+		"random",
+		"Fill the -w x -h world pseudo-randomly instead of loading an image, e.g. \"density=0.3 seed=42\". The same seed gives the same world. Defaults to density=0.5 and a seed from the clock.",
+		func(value string) error {
+			random, err := gol.ParseRandom(value)
+			params.Random = random
+			return err
+		})
+
+	flag.Func(
+		"place",
+		"Stamp a pattern into the initial world with its top-left corner at x,y, e.g. gosper@100,100. Repeat to place several. The pattern is a file (rle, cells, lif) or one of "+strings.Join(gol.Patterns(), ", ")+". Without -input or -random the world starts empty.",
+		func(value string) error {
+			place, err := gol.ParsePlacement(value)
+			params.Place = append(params.Place, place)
+			return err
+		})
+
+	flag.Func(
+		"boundary",
+		"Specify what cells on the edge of the world see beyond it: torus (wrap around), dead or mirror. Defaults to torus.",
+		func(value string) error {
+			boundary, err := util.ParseBoundary(value)
+			params.Boundary = boundary
+			return err
+		})
+
+	var presets []string
+	for _, preset := range util.Presets() {
+		presets = append(presets, preset.Name)
+	}
+	flag.Func(
+		"rule",
+		"Specify the rule the world evolves by in B/S notation, e.g. B36/S23, or B/S/C for Generations rules with dying states, e.g. B2/S345/C4, "+
+			"or by name: "+strings.Join(presets, ", ")+". Defaults to B3/S23.",
+		func(value string) error {
+			rule, err := util.ParseRule(value)
+			params.Rule = rule.String()
+			return err
+		})
+
+	flag.StringVar(
+		&params.Format,
+		"format",
+		"pgm",
+		"Specify the format worlds are saved in: "+strings.Join(gol.Formats(), " or ")+". Defaults to pgm.")
+
+	flag.StringVar(
+		&params.OutDir,
+		"outdir",
+		"out",
+		"Specify the directory worlds and snapshots are saved in. Defaults to out.")
+
+	flag.IntVar(
+		&params.SaveEvery,
+		"save-every",
+		0,
+		"Save the world every N turns without pressing 's'. Defaults to 0 (off).")
+
+	flag.IntVar(
+		&params.MaxFPS,
+		"maxfps",
+		0,
+		"Limit the simulation to N turns per second ('+' and '-' change it while running). Defaults to 0 (no limit).")
+
+	flag.DurationVar(
+		&params.CallTimeout,
+		"call-timeout",
+		time.Minute,
+		"Give up on a call to the broker after this long, 0 = never. Defaults to 1m.")
+
+	flag.IntVar(
+		&params.CallRetries,
+		"call-retries",
+		3,
+		"Retry a timed-out turn this many times in a row, catching up with the broker first, before quitting. Defaults to 3.")
+
+	flag.DurationVar(
+		&params.DialWait,
+		"dial-wait",
+		30*time.Second,
+		"Keep retrying to connect to the broker for this long at startup, 0 = try once. Defaults to 30s.")
+
+	flag.BoolVar(
+		&params.Ages,
+		"ages",
+		false,
+		"Track how long every cell has been alive: report the oldest with the alive count and save a colour <name>-ages.png with every world.")
+
+	flag.IntVar(
+		&params.FlipRegions,
+		"flip-regions",
+		0,
+		"Send the changed regions of the world, at most once a frame, instead of the flipped cells for turns flipping more than N cells, to save memory on huge worlds. Defaults to 0 (off).")
+
+	flag.IntVar(
+		&params.DetectPeriod,
+		"detect-period",
+		0,
+		"Report when the world starts repeating itself with a period of up to N turns (1 = still life). Defaults to 0 (off).")
+
+	flag.BoolVar(
+		&params.StopWhenStable,
+		"stop-when-stable",
+		false,
+		"Stop early once -detect-period finds the world repeating itself.")
+
+	flag.BoolVar(
+		&params.Timings,
+		"timings",
+		false,
+		"Send a TurnTiming event every turn with how long the broker call, the broker, its slowest worker and the events took.")
+
+	flag.StringVar(
+		&params.StatsCSV,
+		"stats-csv",
+		"",
+		"Write the births, deaths and density of every turn to this CSV file.")
+
+	flag.StringVar(
+		&params.Benchmark,
+		"bench",
+		"",
+		"Run headless without per-turn events and write the time taken by every call to the broker to this CSV file.")
+
+	flag.Func(
+		"alive-every",
+		"Specify how often the number of alive cells is reported, e.g. 500ms, or off. Defaults to 2s.",
+		func(value string) error {
+			if value == "off" {
+				params.AliveInterval = -1
+				return nil
+			}
+			interval, err := time.ParseDuration(value)
+			if err != nil || interval <= 0 {
+				return fmt.Errorf("want a positive duration or off")
+			}
+			params.AliveInterval = interval
+			return nil
+		})
+
+	flag.StringVar(
+		&params.BrokerAddr,
+		"broker",
+		"",
+		"Specify the broker address (host:port). Defaults to $GOL_BROKER_ADDR, then the AWS broker.")
+
+	flag.StringVar(
+		&params.Token,
+		"token",
+		"",
+		"Specify the shared secret for the broker. Defaults to $GOL_TOKEN.")
+
+	flag.StringVar(
+		&params.Job,
+		"job",
+		"",
+		"Name the simulation to run or watch on the broker, so several can share it. Defaults to the broker's default one.")
+
+	flag.StringVar(
+		&params.Compress,
+		"compress",
+		"",
+		"Compress traffic to the broker with "+strings.Join(transport.Compressors(), " or ")+". Defaults to $GOL_COMPRESS, then none.")
+
+	flag.DurationVar(
+		&params.TCP.KeepAlive,
+		"tcp-keepalive",
+		0,
+		"Send TCP keep-alive probes to the broker this often, negative = off. Defaults to Go's default (15s).")
+
+	noDelay := flag.Bool(
+		"tcp-nodelay",
+		true,
+		"Set TCP_NODELAY on the broker connection; false lets Nagle's algorithm batch small writes. Defaults to true.")
+
+	flag.IntVar(
+		&params.TCP.ReadBuffer,
+		"tcp-read-buffer",
+		0,
+		"Set the TCP receive buffer of the broker connection to N bytes, raise it for large worlds over high-latency links. Defaults to the OS default.")
+
+	flag.IntVar(
+		&params.TCP.WriteBuffer,
+		"tcp-write-buffer",
+		0,
+		"Set the TCP send buffer of the broker connection to N bytes. Defaults to the OS default.")
+
+	flag.IntVar(
+		&params.TurnsPerCall,
+		"batch",
+		1,
+		"Specify how many turns the broker evolves per RPC call. Defaults to 1.")
+
+	flag.IntVar(
+		&params.Pipeline,
+		"pipeline",
+		2,
+		"Keep up to N calls to the broker in flight, so it evolves the next turns while this side reports the last ones, 1 = one at a time. Defaults to 2.")
+
+	flag.IntVar(
+		&params.MaxBacklog,
+		"max-backlog",
+		0,
+		"Have at most N turns asked of the broker and not yet shown, so a slow window or broker can't make replies pile up in memory, 0 = only the -pipeline and -batch limits. Defaults to 0.")
+
+	flag.IntVar(
+		&params.FrameEvery,
+		"frame-every",
+		0,
+		"Send the whole world as a PixelData event every N turns, for renderers that draw frames rather than flips, 0 = never. Defaults to 0.")
+
+	dumpDir := flag.String(
+		"dump-frames",
+		"",
+		"Write a PNG of the world into this directory every -frame-every turns (100 if not given), window or not, e.g. to look back over a long headless run.")
+
+	dumpSize := flag.Int(
+		"dump-size",
+		gol.DefaultDumpSize,
+		"Shrink the frames of -dump-frames to at most N pixels wide and high, each pixel the average of the cells it stands for. Defaults to 1024.")
+
+	flag.IntVar(
+		&params.ViewWidth,
+		"view-width",
+		0,
+		"Make the window at most N pixels wide, showing part of a wider world that the arrow keys pan and z / x zoom in / out ('+' and '-' change the speed), 0 = as wide as the world. Defaults to 0.")
+
+	flag.IntVar(
+		&params.ViewHeight,
+		"view-height",
+		0,
+		"Make the window at most N pixels high, showing part of a higher world that the arrow keys pan and z / x zoom in / out ('+' and '-' change the speed), 0 = as high as the world. Defaults to 0.")
+
+	flag.IntVar(
+		&params.EventQueue,
+		"event-queue",
+		0,
+		"Queue up to N events for the window, so turns and keypresses carry on while it stalls for a moment, negative = no queue. Defaults to 1024.")
+
+	flag.StringVar(
+		&params.EventOverflow,
+		"event-overflow",
+		"block",
+		"Specify what happens when the event queue is full: "+strings.Join(gol.OverflowPolicies(), " or ")+" (drop the oldest progress reports, e.g. turn completions, but never flips; coalesce also merges queued frames, so the window skips to the latest turn). Defaults to block.")
+
+	flag.Var(
+		resumeFlag{&params},
+		"resume",
+		"Resume the simulation the broker kept running after the last 'q', instead of starting a new one.\n"+
+			"With a file (-resume out/512x512x100.gob), continue from a snapshot saved by 's' or 'q' with -snapshot.")
+
+	flag.BoolVar(
+		&params.Snapshot,
+		"snapshot",
+		false,
+		"Also save a snapshot (out/<image>.gob) with every image, for -resume to continue from.")
+
+	flag.BoolVar(
+		&params.Local,
+		"local",
+		false,
+		"Run the broker in-process instead of connecting to one, so no broker or workers are needed.")
+
+	flag.StringVar(
+		&params.Engine,
+		"engine",
+		gol.EngineBytes,
+		"Specify how the -local broker evolves the world: "+strings.Join(gol.Engines(), " or ")+" (HashLife, for square power-of-two B3/S23 tori; repetitive or sparse worlds run far faster). Defaults to bytes.")
+
+	flag.BoolVar(
+		&params.Observe,
+		"observe",
+		false,
+		"Watch the simulation another controller is running on the broker, read-only.")
+
+	logLevel := flag.String(
+		"log-level",
+		"info",
+		"Specify the log level of the distributor: debug, info, warn or error. Defaults to info.")
+
+	logFormat := flag.String(
+		"log-format",
+		"text",
+		"Specify the log format of the distributor: text or json. Defaults to text.")
+
 	headless := flag.Bool(
 		"headless",
 		false,
 		"Disable the SDL window for running in a headless environment.")
 
-	flag.Parse()
+	_ = flag.CommandLine.Parse(resumeArgs(os.Args[1:]))
+
+	if err := util.ConfigureLogging(os.Stderr, *logFormat, *logLevel); err != nil {
+		log.Fatalf("[Main] %v %v", util.Red("ERROR"), err)
+	}
+
+	params.TCP.Delay = !*noDelay
+	if params.TCP.ReadBuffer < 0 || params.TCP.WriteBuffer < 0 {
+		log.Fatalf("[Main] %v -tcp-read-buffer and -tcp-write-buffer must not be negative", util.Red("ERROR"))
+	}
+	if params.FrameEvery < 0 {
+		log.Fatalf("[Main] %v -frame-every must not be negative", util.Red("ERROR"))
+	}
+	if *dumpDir != "" {
+		if *dumpSize <= 0 {
+			log.Fatalf("[Main] %v -dump-size must be positive", util.Red("ERROR"))
+		}
+		if err := os.MkdirAll(*dumpDir, 0o755); err != nil {
+			log.Fatalf("[Main] %v %v", util.Red("ERROR"), err)
+		}
+		if params.FrameEvery == 0 {
+			params.FrameEvery = 100
+		}
+	}
+	if params.ViewWidth < 0 || params.ViewHeight < 0 {
+		log.Fatalf("[Main] %v -view-width and -view-height must not be negative", util.Red("ERROR"))
+	}
+	if params.MaxBacklog < 0 {
+		log.Fatalf("[Main] %v -max-backlog must not be negative", util.Red("ERROR"))
+	}
+	if !slices.Contains(gol.OverflowPolicies(), params.EventOverflow) {
+		log.Fatalf("[Main] %v unknown -event-overflow %q, want one of %v", util.Red("ERROR"), params.EventOverflow, strings.Join(gol.OverflowPolicies(), ", "))
+	}
+	if params.DialWait == 0 {
+		params.DialWait = -1 // -dial-wait 0 tries once; Params uses 0 for the default
+	}
+	if params.CallTimeout == 0 {
+		params.CallTimeout = -1 // -call-timeout 0 waits forever; Params uses 0 for the default
+	}
+	if !slices.Contains(gol.Formats(), params.Format) {
+		log.Fatalf("[Main] %v unknown -format %q, want one of %v", util.Red("ERROR"), params.Format, strings.Join(gol.Formats(), ", "))
+	}
+	if !slices.Contains(gol.Engines(), params.Engine) {
+		log.Fatalf("[Main] %v unknown -engine %q, want one of %v", util.Red("ERROR"), params.Engine, strings.Join(gol.Engines(), ", "))
+	}
+	if params.Random != nil && params.Input != "" {
+		log.Fatalf("[Main] %v -random and -input cannot be used together", util.Red("ERROR"))
+	}
+	if params.Input != "" {
+		width, height, err := gol.ImageSize(params.Input)
+		if err != nil {
+			log.Fatalf("[Main] %v %v", util.Red("ERROR"), err)
+		}
+		if gol.IsPattern(params.Input) {
+			// Patterns go into a -w x -h world, grown if the pattern doesn't fit.
+			params.ImageWidth, params.ImageHeight = max(params.ImageWidth, width), max(params.ImageHeight, height)
+			params.OffsetX, params.OffsetY = (params.ImageWidth-width)/2, (params.ImageHeight-height)/2
+			if *offset != "" {
+				if _, err := fmt.Sscanf(*offset, "%d,%d", &params.OffsetX, &params.OffsetY); err != nil {
+					log.Fatalf("[Main] %v bad -offset %q, want x,y", util.Red("ERROR"), *offset)
+				}
+			}
+		} else {
+			// The world has to match the image, whatever -w / -h said.
+			params.ImageWidth, params.ImageHeight = width, height
+		}
+	}
 
 	log.Printf("[Main] %-10v %v", "Threads", params.Threads)
 	log.Printf("[Main] %-10v %v", "Width", params.ImageWidth)
 	log.Printf("[Main] %-10v %v", "Height", params.ImageHeight)
 	log.Printf("[Main] %-10v %v", "Turns", params.Turns)
+	if params.Input != "" {
+		log.Printf("[Main] %-10v %v", "Input", params.Input)
+		if gol.IsPattern(params.Input) {
+			log.Printf("[Main] %-10v %v,%v", "Offset", params.OffsetX, params.OffsetY)
+		}
+	}
+	if params.Random != nil {
+		log.Printf("[Main] %-10v %v", "Random", params.Random)
+	}
+	for _, place := range params.Place {
+		log.Printf("[Main] %-10v %v", "Place", place)
+	}
+	if params.Rule != "" && params.Rule != util.Conway.String() {
+		log.Printf("[Main] %-10v %v", "Rule", params.Rule)
+	}
+	if !params.Boundary.Torus() {
+		log.Printf("[Main] %-10v %v", "Boundary", params.Boundary)
+	}
+	if params.Local {
+		if params.Observe {
+			log.Fatalf("[Main] %v -observe needs a broker to watch, it cannot be used with -local", util.Red("ERROR"))
+		}
+		log.Printf("[Main] %-10v %v", "Broker", "local")
+	} else if params.BrokerAddr != "" {
+		log.Printf("[Main] %-10v %v", "Broker", params.BrokerAddr)
+	}
+	if params.Job != "" {
+		log.Printf("[Main] %-10v %v", "Job", params.Job)
+	}
+	if params.OutDir != "out" {
+		log.Printf("[Main] %-10v %v", "Out dir", params.OutDir)
+	}
+	if params.Benchmark != "" {
+		log.Printf("[Main] %-10v %v", "Benchmark", params.Benchmark)
+	}
+	if params.StatsCSV != "" {
+		log.Printf("[Main] %-10v %v", "Stats CSV", params.StatsCSV)
+	}
+	if params.DetectPeriod > 0 {
+		log.Printf("[Main] %-10v %v", "Detect", fmt.Sprintf("period <= %v, stop=%v", params.DetectPeriod, params.StopWhenStable))
+	} else if params.StopWhenStable {
+		log.Fatalf("[Main] %v -stop-when-stable needs -detect-period", util.Red("ERROR"))
+	}
+	if params.Ages {
+		log.Printf("[Main] %-10v %v", "Ages", "on")
+	}
+	if *dumpDir != "" {
+		log.Printf("[Main] %-10v %v (every %v turns)", "Frames", *dumpDir, params.FrameEvery)
+	}
+	if params.ResumeFrom != "" {
+		// The window has to match the snapshot, whatever -w / -h said.
+		snapshot, err := gol.LoadSnapshot(params.ResumeFrom)
+		if err != nil {
+			log.Fatalf("[Main] %v %v", util.Red("ERROR"), err)
+		}
+		params.ImageWidth = snapshot.Params.ImageWidth
+		params.ImageHeight = snapshot.Params.ImageHeight
+		log.Printf("[Main] %-10v %v (turn %v)", "Snapshot", params.ResumeFrom, snapshot.Turn)
+	}
 
 	keyPresses := make(chan rune, 10)
-	events := make(chan gol.Event, 1000)
+	events := make(chan gol.Event)
+
+	// Everything that consumes the events subscribes to the bus rather than reading events.
+	bus := gol.NewEventBus()
+	windowEvents := bus.Subscribe(1000)
+	dumped := make(chan struct{})
+	if *dumpDir != "" {
+		frames := bus.Subscribe(1000)
+		go func() {
+			gol.DumpFrames(frames, *dumpDir, *dumpSize)
+			close(dumped)
+		}()
+	} else {
+		close(dumped)
+	}
 
 	go sigint()
 
+	go bus.Forward(events)
 	go gol.Run(params, events, keyPresses)
-	if !*headless {
-		sdl.Run(params, events, keyPresses)
+	if !*headless && params.Benchmark == "" {
+		sdl.Run(params, windowEvents, keyPresses)
 	} else {
-		sdl.RunHeadless(events)
+		sdl.RunHeadless(windowEvents)
+	}
+	<-dumped // the last frames are written once the events run out
+}
+
+// resumeFlag is -resume. On its own it takes over the simulation the broker kept running
+// (Params.Resume); given a file it continues from that snapshot (Params.ResumeFrom).
+type resumeFlag struct {
+	params *gol.Params
+}
+
+func (f resumeFlag) IsBoolFlag() bool {
+	return true
+}
+
+func (f resumeFlag) String() string {
+	if f.params == nil {
+		return ""
+	}
+	if f.params.ResumeFrom != "" {
+		return f.params.ResumeFrom
+	}
+	return strconv.FormatBool(f.params.Resume)
+}
+
+func (f resumeFlag) Set(value string) error {
+	if resume, err := strconv.ParseBool(value); err == nil {
+		f.params.Resume = resume
+		return nil
+	}
+	f.params.ResumeFrom = value
+	return nil
+}
+
+// resumeArgs rewrites "-resume snapshot.gob" as "-resume=snapshot.gob". -resume is a
+// boolean flag, so the flag package would otherwise stop parsing at the file name.
+func resumeArgs(args []string) []string {
+	out := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		if (args[i] == "-resume" || args[i] == "--resume") && i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			out = append(out, args[i]+"="+args[i+1])
+			i++
+			continue
+		}
+		out = append(out, args[i])
 	}
+	return out
 }
 
 func sigint() {
@@ -2,21 +2,22 @@ package sdl
 
 import (
 	"log"
+	"math"
 	"time"
 
 	"github.com/veandco/go-sdl2/sdl"
 	"uk.ac.bris.cs/gameoflife/gol"
-	"uk.ac.bris.cs/gameoflife/util"
 )
 
 const FPS = 60
 
 func Run(p gol.Params, events <-chan gol.Event, keyPresses chan<- rune) {
-	w := NewWindow(int32(p.ImageWidth), int32(p.ImageHeight))
+	width, height := gol.ViewSize(p)
+	w := NewWindow(int32(width), int32(height))
 	defer w.Destroy()
 	dirty := false
 	refreshTicker := time.NewTicker(time.Second / time.Duration(FPS))
-	avgTurns := util.NewAvgTurns()
+	var turnRate gol.TurnRate // the distributor sends one just before each AliveCellsCount
 
 sdl:
 	for {
@@ -39,6 +40,24 @@ sdl:
 						keyPresses <- 'q'
 					case sdl.K_k:
 						keyPresses <- 'k'
+					case sdl.K_v:
+						keyPresses <- 'v'
+					case sdl.K_PLUS, sdl.K_EQUALS, sdl.K_KP_PLUS:
+						keyPresses <- '+'
+					case sdl.K_MINUS, sdl.K_KP_MINUS:
+						keyPresses <- '-'
+					case sdl.K_LEFT:
+						keyPresses <- gol.KeyPanLeft
+					case sdl.K_RIGHT:
+						keyPresses <- gol.KeyPanRight
+					case sdl.K_UP:
+						keyPresses <- gol.KeyPanUp
+					case sdl.K_DOWN:
+						keyPresses <- gol.KeyPanDown
+					case sdl.K_z:
+						keyPresses <- gol.KeyZoomIn
+					case sdl.K_x:
+						keyPresses <- gol.KeyZoomOut
 					}
 				}
 			}
@@ -55,22 +74,40 @@ sdl:
 			case gol.CellFlipped:
 				w.FlipPixel(e.Cell.X, e.Cell.Y)
 			case gol.CellsFlipped:
-				for _, cell := range e.Cells {
-					w.FlipPixel(cell.X, cell.Y)
+				for i, cell := range e.Cells {
+					if e.Values != nil {
+						w.SetPixelGrey(cell.X, cell.Y, e.Values[i])
+					} else {
+						w.FlipPixel(cell.X, cell.Y)
+					}
+				}
+			case gol.RegionsFlipped:
+				for _, region := range e.Regions {
+					for i, v := range region.Cells {
+						w.SetPixelGrey(region.X+i%region.Width, region.Y+i/region.Width, v)
+					}
 				}
+			case gol.ViewportChanged:
+				w.SetView(e.X, e.Y, e.Zoom)
 			case gol.TurnComplete:
 				dirty = true
+			case gol.TurnRate:
+				turnRate = e
 			case gol.AliveCellsCount:
 				log.Printf(
 					"[Event] Completed Turns %-8v %-20v Avg%+5v turns/sec\n",
 					event.GetCompletedTurns(),
 					event,
-					avgTurns.TurnsPerSec(event.GetCompletedTurns()),
+					math.Round(turnRate.TurnsPerSecond),
 				)
+			case gol.CellAges, gol.Stabilized:
+				log.Printf("[Event] Completed Turns %-8v %v\n", event.GetCompletedTurns(), event)
 			case gol.FinalTurnComplete:
 				log.Printf("[Event] Completed Turns %-8v %v\n", event.GetCompletedTurns(), event)
 			case gol.ImageOutputComplete:
 				log.Printf("[Event] Completed Turns %-8v %v\n", event.GetCompletedTurns(), event)
+			case gol.BrokerError, gol.ErrorEvent, gol.WaitingForWorkers:
+				log.Printf("[Event] Completed Turns %-8v %v\n", event.GetCompletedTurns(), event)
 			case gol.StateChange:
 				log.Printf("[Event] Completed Turns %-8v %v\n", event.GetCompletedTurns(), event)
 				if e.NewState == gol.Quitting {
@@ -82,20 +119,26 @@ sdl:
 }
 
 func RunHeadless(events <-chan gol.Event) {
-	avgTurns := util.NewAvgTurns()
+	var turnRate gol.TurnRate // the distributor sends one just before each AliveCellsCount
 	for event := range events {
 		switch e := event.(type) {
+		case gol.TurnRate:
+			turnRate = e
 		case gol.AliveCellsCount:
 			log.Printf(
 				"[Event] Completed Turns %-8v %-20v Avg%+5v turns/sec\n",
 				event.GetCompletedTurns(),
 				event,
-				avgTurns.TurnsPerSec(event.GetCompletedTurns()),
+				math.Round(turnRate.TurnsPerSecond),
 			)
+		case gol.CellAges, gol.Stabilized:
+			log.Printf("[Event] Completed Turns %-8v %v\n", event.GetCompletedTurns(), event)
 		case gol.FinalTurnComplete:
 			log.Printf("[Event] Completed Turns %-8v %v\n", event.GetCompletedTurns(), "Final Turn Complete")
 		case gol.ImageOutputComplete:
 			log.Printf("[Event] Completed Turns %-8v %v\n", event.GetCompletedTurns(), event)
+		case gol.BrokerError, gol.ErrorEvent, gol.WaitingForWorkers:
+			log.Printf("[Event] Completed Turns %-8v %v\n", event.GetCompletedTurns(), event)
 		case gol.StateChange:
 			log.Printf("[Event] Completed Turns %-8v %v\n", event.GetCompletedTurns(), event)
 			if e.NewState == gol.Quitting {
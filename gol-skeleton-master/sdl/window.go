@@ -14,6 +14,11 @@ type Window struct {
 	renderer      *sdl.Renderer
 	texture       *sdl.Texture
 	pixels        []byte
+
+	// The window shows the world from cell (viewX, viewY) on, zoom pixels to a side of a cell.
+	// Cells outside it are skipped once SetView has been called, and panic before.
+	viewX, viewY, zoom int
+	clip               bool
 }
 
 func filterEvent(e sdl.Event, userdata interface{}) bool {
@@ -53,6 +58,7 @@ func NewWindow(width, height int32) *Window {
 		renderer,
 		texture,
 		make([]byte, width*height*4),
+		0, 0, 1, false,
 	}
 }
 
@@ -88,20 +94,65 @@ func (w *Window) SetPixel(x, y int) {
 	w.pixels[4*(y*width+x)+3] = 0xFF
 }
 
-func (w *Window) FlipPixel(x, y int) {
-	if x < 0 || y < 0 || x >= int(w.Width) || y >= int(w.Height) {
+// SetView makes the window show the world from cell (x, y) on, zoom pixels to a side of a
+// cell, as a gol.ViewportChanged event says, and clears it for the cells in the new view.
+func (w *Window) SetView(x, y, zoom int) {
+	w.viewX, w.viewY, w.zoom, w.clip = x, y, zoom, true
+	w.ClearPixels()
+}
+
+// cellPixels returns the pixels the cell at (x, y) of the world covers, clipped to the window.
+// ok is false for cells out of view.
+func (w *Window) cellPixels(x, y int, event string) (x0, y0, x1, y1 int, ok bool) {
+	x0, y0 = (x-w.viewX)*w.zoom, (y-w.viewY)*w.zoom
+	x1, y1 = min(x0+w.zoom, int(w.Width)), min(y0+w.zoom, int(w.Height))
+	if x0 < 0 || y0 < 0 || x0 >= int(w.Width) || y0 >= int(w.Height) {
+		if w.clip {
+			return 0, 0, 0, 0, false
+		}
 		panic(fmt.Sprintf(
-			"CellFlipped event at (%d, %d) is outside the bounds of the window.",
+			"%s event at (%d, %d) is outside the bounds of the window.",
+			event,
 			x,
 			y,
 		))
 	}
+	return x0, y0, x1, y1, true
+}
+
+func (w *Window) FlipPixel(x, y int) {
+	x0, y0, x1, y1, ok := w.cellPixels(x, y, "CellFlipped")
+	if !ok {
+		return
+	}
 
 	width := int(w.Width)
-	w.pixels[4*(y*width+x)+0] = ^w.pixels[4*(y*width+x)+0]
-	w.pixels[4*(y*width+x)+1] = ^w.pixels[4*(y*width+x)+1]
-	w.pixels[4*(y*width+x)+2] = ^w.pixels[4*(y*width+x)+2]
-	w.pixels[4*(y*width+x)+3] = ^w.pixels[4*(y*width+x)+3]
+	for py := y0; py < y1; py++ {
+		for px := x0; px < x1; px++ {
+			w.pixels[4*(py*width+px)+0] = ^w.pixels[4*(py*width+px)+0]
+			w.pixels[4*(py*width+px)+1] = ^w.pixels[4*(py*width+px)+1]
+			w.pixels[4*(py*width+px)+2] = ^w.pixels[4*(py*width+px)+2]
+			w.pixels[4*(py*width+px)+3] = ^w.pixels[4*(py*width+px)+3]
+		}
+	}
+}
+
+// SetPixelGrey sets the pixel at (x, y) to grey level v, 0xFF for live cells.
+func (w *Window) SetPixelGrey(x, y int, v uint8) {
+	x0, y0, x1, y1, ok := w.cellPixels(x, y, "CellsFlipped")
+	if !ok {
+		return
+	}
+
+	for py := y0; py < y1; py++ {
+		for px := x0; px < x1; px++ {
+			i := 4 * (py*int(w.Width) + px)
+			w.pixels[i+0] = v
+			w.pixels[i+1] = v
+			w.pixels[i+2] = v
+			w.pixels[i+3] = v
+		}
+	}
 }
 
 func (w *Window) CountPixels() int {
@@ -0,0 +1,53 @@
+package tests
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+)
+
+// TestEventBus tests that every subscriber of an EventBus sees every event of a run, in order,
+// and that one unsubscribing part way through holds up neither the others nor the run.
+// The simulation runs on the in-process broker, so no broker or workers are needed.
+func TestEventBus(t *testing.T) {
+	p := gol.Params{ImageWidth: 64, ImageHeight: 64, Turns: 50, Threads: 4, Local: true, AliveInterval: -1, OutDir: t.TempDir()}
+	events := make(chan gol.Event)
+	bus := gol.NewEventBus()
+	subs := []<-chan gol.Event{bus.Subscribe(0), bus.Subscribe(10), bus.Subscribe(1000)}
+	quitter := bus.Subscribe(0)
+	go bus.Forward(events)
+	go gol.Run(p, events, nil)
+
+	// The quitter stops after the first TurnComplete and never reads again.
+	go func() {
+		for event := range quitter {
+			if _, ok := event.(gol.TurnComplete); ok {
+				bus.Unsubscribe(quitter)
+				return
+			}
+		}
+	}()
+
+	seen := make([][]string, len(subs))
+	var wg sync.WaitGroup
+	for i, sub := range subs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for event := range sub {
+				seen[i] = append(seen[i], fmt.Sprintf("%T %v", event, event.GetCompletedTurns()))
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert(t, len(seen[0]) > p.Turns, "expected at least %d events, got %d", p.Turns+1, len(seen[0]))
+	for i := 1; i < len(seen); i++ {
+		assert(t, fmt.Sprint(seen[i]) == fmt.Sprint(seen[0]),
+			"subscriber %d saw %d events, subscriber 0 saw %d, or in a different order", i, len(seen[i]), len(seen[0]))
+	}
+	assert(t, seen[0][len(seen[0])-1] == fmt.Sprintf("%T %v", gol.StateChange{}, p.Turns),
+		"expected the last event to be the StateChange of turn %d, got %v", p.Turns, seen[0][len(seen[0])-1])
+}
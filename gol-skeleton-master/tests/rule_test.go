@@ -0,0 +1,138 @@
+package tests
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestRules tests each preset rule, selected by name, on a pattern whose behaviour under it is well known.
+// The simulations run on the in-process broker, so no broker or workers are needed.
+func TestRules(t *testing.T) {
+	t.Run("conway", func(t *testing.T) {
+		// A glider moves one cell diagonally every 4 turns.
+		glider := []string{".O.", "..O", "OOO"}
+		p := gol.Params{ImageWidth: 16, ImageHeight: 16, Turns: 4, Rule: "conway",
+			Input: writePattern(t, glider), OffsetX: 2, OffsetY: 2}
+		assertEqualBoard(t, runRule(t, p), patternCells(glider, 3, 3), p)
+	})
+
+	t.Run("highlife", func(t *testing.T) {
+		// The replicator is replaced by two copies of itself after 12 turns.
+		replicator := []string{"..OOO", ".O..O", "O...O", "O..O.", "OOO.."}
+		p := gol.Params{ImageWidth: 32, ImageHeight: 32, Turns: 12, Rule: "HighLife",
+			Input: writePattern(t, replicator), OffsetX: 12, OffsetY: 12}
+		expected := append(patternCells(replicator, 10, 10), patternCells(replicator, 14, 14)...)
+		assertEqualBoard(t, runRule(t, p), expected, p)
+	})
+
+	t.Run("seeds", func(t *testing.T) {
+		// No cell ever survives, yet this four-cell spaceship moves one cell down every turn.
+		spaceship := []string{"O..O", ".OO."}
+		p := gol.Params{ImageWidth: 32, ImageHeight: 32, Turns: 8, Rule: "seeds",
+			Input: writePattern(t, spaceship), OffsetX: 10, OffsetY: 4}
+		assertEqualBoard(t, runRule(t, p), patternCells(spaceship, 10, 12), p)
+	})
+
+	t.Run("daynight", func(t *testing.T) {
+		// The rule is symmetric under inverting the world: evolving the inverse gives the inverse.
+		p := gol.Params{ImageWidth: 64, ImageHeight: 64, Turns: 20, Rule: "Day & Night"}
+		expected := invertCells(runRule(t, p), p.ImageWidth, p.ImageHeight)
+		p.Input = writeInverted(t, fmt.Sprintf("images/%dx%d.pgm", p.ImageWidth, p.ImageHeight), p.ImageWidth*p.ImageHeight)
+		assertEqualBoard(t, runRule(t, p), expected, p)
+	})
+
+	t.Run("lifewithoutdeath", func(t *testing.T) {
+		// Live cells never die: the R-pentomino only grows, and every cell alive on turn 10 is still alive on turn 20.
+		rPentomino := []string{".OO", "OO.", ".O."}
+		p := gol.Params{ImageWidth: 64, ImageHeight: 64, Turns: 10, Rule: "life-without-death",
+			Input: writePattern(t, rPentomino), OffsetX: 30, OffsetY: 30}
+		before := runRule(t, p)
+		p.Turns = 20
+		alive := make(map[util.Cell]bool)
+		for _, c := range runRule(t, p) {
+			alive[c] = true
+		}
+		assert(t, len(before) > len(patternCells(rPentomino, 0, 0)) && len(alive) > len(before),
+			"expected the R-pentomino to grow, got %d live cells on turn 10 and %d on turn 20", len(before), len(alive))
+		for _, c := range before {
+			assert(t, alive[c], "cell %v died between turns 10 and 20", c)
+		}
+	})
+}
+
+// runRule runs p on the in-process broker and returns the alive cells after the final turn.
+func runRule(t *testing.T, p gol.Params) []util.Cell {
+	p.Threads, p.Local, p.AliveInterval, p.OutDir = 4, true, -1, t.TempDir()
+	events := make(chan gol.Event)
+	go gol.Run(p, events, nil)
+	var cells []util.Cell
+	for event := range events {
+		if e, ok := event.(gol.FinalTurnComplete); ok {
+			cells = e.Alive
+		}
+	}
+	return cells
+}
+
+// writePattern saves rows of '.' and 'O' as a plaintext pattern and returns its path.
+func writePattern(t *testing.T, rows []string) string {
+	path := filepath.Join(t.TempDir(), "pattern.cells")
+	if err := os.WriteFile(path, []byte(strings.Join(rows, "\n")+"\n"), 0o644); err != nil {
+		t.Fatalf("%v %v", util.Red("ERROR"), err)
+	}
+	return path
+}
+
+// patternCells returns the live cells of rows with their top-left corner at (x, y).
+func patternCells(rows []string, x, y int) []util.Cell {
+	var cells []util.Cell
+	for dy, row := range rows {
+		for dx, c := range row {
+			if c == 'O' {
+				cells = append(cells, util.Cell{X: x + dx, Y: y + dy})
+			}
+		}
+	}
+	return cells
+}
+
+// writeInverted saves the pgm image at path, of the given number of cells, with every cell
+// inverted and returns the new path.
+func writeInverted(t *testing.T, path string, cells int) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v %v", util.Red("ERROR"), err)
+	}
+	inverted := append([]byte(nil), data...)
+	for i := len(inverted) - cells; i < len(inverted); i++ {
+		inverted[i] = 255 - inverted[i]
+	}
+	out := filepath.Join(t.TempDir(), filepath.Base(path))
+	if err := os.WriteFile(out, inverted, 0o644); err != nil {
+		t.Fatalf("%v %v", util.Red("ERROR"), err)
+	}
+	return out
+}
+
+// invertCells returns the cells of a width×height world that are not in cells.
+func invertCells(cells []util.Cell, width, height int) []util.Cell {
+	alive := make(map[util.Cell]bool)
+	for _, c := range cells {
+		alive[c] = true
+	}
+	var inverted []util.Cell
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if c := (util.Cell{X: x, Y: y}); !alive[c] {
+				inverted = append(inverted, c)
+			}
+		}
+	}
+	return inverted
+}
@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+)
+
+// TestRunSync tests that RunSync returns the same final world as the check images for 16x16
+// and 64x64 on 0, 1 and 100 turns, with Stats that agree with it, and passes every event of
+// the run to OnEvent. The simulation runs on the in-process broker.
+func TestRunSync(t *testing.T) {
+	for _, size := range []int{16, 64} {
+		for _, turns := range []int{0, 1, 100} {
+			t.Run(fmt.Sprintf("%dx%dx%d", size, size, turns), func(t *testing.T) {
+				p := gol.Params{ImageWidth: size, ImageHeight: size, Turns: turns, Threads: 4, Local: true, AliveInterval: -1, OutDir: t.TempDir()}
+				var events []gol.Event
+				world, stats, err := gol.RunSync(p, gol.RunOptions{OnEvent: func(e gol.Event) { events = append(events, e) }})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				expected, err := gol.ReadWorld(fmt.Sprintf("check/images/%dx%dx%d.pgm", size, size, turns))
+				if err != nil {
+					t.Fatal(err)
+				}
+				alive, wrong := 0, 0
+				for y := range expected {
+					for x := range expected[y] {
+						if expected[y][x] == 255 {
+							alive++
+						}
+						if world[y][x] != expected[y][x] {
+							wrong++
+						}
+					}
+				}
+				assert(t, wrong == 0, "%d cells of the final world differ from the check image", wrong)
+				assert(t, stats.Turns == turns, "expected Stats.Turns %d, got %d", turns, stats.Turns)
+				assert(t, stats.Alive == alive, "expected Stats.Alive %d, got %d", alive, stats.Alive)
+				assert(t, stats.Output == fmt.Sprintf("%dx%dx%d", size, size, turns), "expected Stats.Output %dx%dx%d, got %q", size, size, turns, stats.Output)
+				assert(t, stats.Elapsed > 0, "expected a positive Stats.Elapsed, got %v", stats.Elapsed)
+				assert(t, len(events) > 0, "expected OnEvent to be called")
+				if len(events) > 0 {
+					last, ok := events[len(events)-1].(gol.StateChange)
+					assert(t, ok && last.NewState == gol.Quitting, "expected the last event to be the StateChange to Quitting, got %v", events[len(events)-1])
+				}
+			})
+		}
+	}
+}
+
+// TestRunSyncErrors tests that RunSync returns an error, rather than a world, for an input
+// image that is missing or of the wrong size and for a broker that can't be reached.
+func TestRunSyncErrors(t *testing.T) {
+	tests := map[string]gol.Params{
+		"missing input": {ImageWidth: 16, ImageHeight: 16, Input: "images/missing.pgm", Local: true},
+		"wrong size":    {ImageWidth: 64, ImageHeight: 64, Input: "images/16x16.pgm", Local: true},
+		"no broker":     {ImageWidth: 16, ImageHeight: 16, BrokerAddr: "127.0.0.1:1", DialWait: -1},
+	}
+	for name, p := range tests {
+		p.Turns, p.Threads, p.AliveInterval, p.OutDir = 10, 1, -1, t.TempDir()
+		world, _, err := gol.RunSync(p, gol.RunOptions{})
+		assert(t, err != nil, "%s: expected an error", name)
+		if name != "no broker" {
+			assert(t, world == nil, "%s: expected no world", name)
+		}
+	}
+}
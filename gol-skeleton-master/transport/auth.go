@@ -0,0 +1,154 @@
+package transport
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Shared-secret authentication. A server started with a token only serves clients
+// that present the same token:
+//   - net/rpc: the client sends "GOL-AUTH <token>\n" right after connecting and the
+//     server answers "OK\n" before any RPC traffic (see CheckToken);
+//   - gRPC: the token travels as "authorization: Bearer <token>" metadata;
+//   - HTTP: likewise as an "Authorization: Bearer <token>" header, or an access_token query
+//     parameter for browsers opening WebSockets, which cannot set headers (see CheckHTTPToken).
+
+const (
+	authPrefix     = "GOL-AUTH "
+	authOK         = "OK"
+	authTimeout    = 5 * time.Second
+	maxAuthLineLen = 512
+)
+
+// ErrUnauthorized is returned when the peer's token does not match.
+var ErrUnauthorized = errors.New("transport: invalid or missing token")
+
+// CheckToken runs the server side of the net/rpc handshake on a freshly accepted
+// connection. With an empty token it does nothing, so unauthenticated setups are unchanged.
+func CheckToken(conn net.Conn, token string) error {
+	if token == "" {
+		return nil
+	}
+	_ = conn.SetDeadline(time.Now().Add(authTimeout))
+	defer conn.SetDeadline(time.Time{})
+
+	line, err := readLine(conn)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, authPrefix) || !tokenEqual(strings.TrimPrefix(line, authPrefix), token) {
+		_, _ = io.WriteString(conn, "DENIED\n")
+		return ErrUnauthorized
+	}
+	_, err = io.WriteString(conn, authOK+"\n")
+	return err
+}
+
+// sendToken runs the client side of the net/rpc handshake.
+func sendToken(conn net.Conn, token string) error {
+	_ = conn.SetDeadline(time.Now().Add(authTimeout))
+	defer conn.SetDeadline(time.Time{})
+
+	if _, err := io.WriteString(conn, authPrefix+token+"\n"); err != nil {
+		return err
+	}
+	line, err := readLine(conn)
+	if err != nil {
+		return err
+	}
+	if line != authOK {
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+// readLine reads one '\n'-terminated line byte by byte so nothing after it is consumed;
+// the rest of the stream belongs to net/rpc.
+func readLine(conn net.Conn) (string, error) {
+	var sb strings.Builder
+	buf := make([]byte, 1)
+	for sb.Len() < maxAuthLineLen {
+		if _, err := conn.Read(buf); err != nil {
+			return "", err
+		}
+		if buf[0] == '\n' {
+			return sb.String(), nil
+		}
+		sb.WriteByte(buf[0])
+	}
+	return "", fmt.Errorf("transport: handshake line too long")
+}
+
+func tokenEqual(got, want string) bool {
+	return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
+
+// bearerToken attaches the token to every gRPC call.
+type bearerToken string
+
+func (t bearerToken) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+func (t bearerToken) RequireTransportSecurity() bool {
+	return false
+}
+
+// checkGRPCToken validates the bearer token carried in ctx.
+func checkGRPCToken(ctx context.Context, token string) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get("authorization") {
+		if tokenEqual(strings.TrimPrefix(v, "Bearer "), token) {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, ErrUnauthorized.Error())
+}
+
+// CheckHTTPToken validates the bearer token of an HTTP request. With an empty token every
+// request is allowed.
+func CheckHTTPToken(r *http.Request, token string) error {
+	if token == "" {
+		return nil
+	}
+	if v := r.Header.Get("Authorization"); strings.HasPrefix(v, "Bearer ") && tokenEqual(strings.TrimPrefix(v, "Bearer "), token) {
+		return nil
+	}
+	if v := r.URL.Query().Get("access_token"); v != "" && tokenEqual(v, token) {
+		return nil
+	}
+	return ErrUnauthorized
+}
+
+// authInterceptors returns server options enforcing token on every gRPC method.
+func authInterceptors(token string) []grpc.ServerOption {
+	if token == "" {
+		return nil
+	}
+	return []grpc.ServerOption{
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := checkGRPCToken(ctx, token); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := checkGRPCToken(ss.Context(), token); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	}
+}
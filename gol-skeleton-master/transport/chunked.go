@@ -0,0 +1,142 @@
+package transport
+
+import (
+	"fmt"
+	"net/rpc"
+	"reflect"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// Chunked world transfers for net/rpc. A gob message holding a whole 16k x 16k world
+// has to be built in memory in one piece on both ends, so chunkedClient sends big
+// worlds as numbered row ranges through the broker's UploadChunk / FetchChunk RPCs
+// instead. The gRPC client gets the same effect from the streaming RPCs.
+
+// chunkCells is roughly how many cells go into one chunk; smaller worlds still travel
+// in a single call.
+const chunkCells = 1 << 22
+
+// chunkedClient is a net/rpc client that chunks StartSimulation, ProcessTurn and
+// FetchWorld when the world is large. Go is passed through unchanged.
+type chunkedClient struct {
+	*rpc.Client
+}
+
+func (c chunkedClient) Call(serviceMethod string, args interface{}, reply interface{}) error {
+	switch serviceMethod {
+	case "Broker.StartSimulation", "Broker.ProcessTurn":
+		p, ok := worldParams(args)
+		if !ok || p.ImageWidth*p.ImageHeight <= chunkCells {
+			break
+		}
+		id, err := c.upload(p)
+		if err != nil {
+			return err
+		}
+		if serviceMethod == "Broker.StartSimulation" {
+			return c.Client.Call("Broker.StartUploaded", TransferArgs{ID: id, JobID: p.JobID}, reply)
+		}
+		var t TransferReply
+		if err := c.Client.Call("Broker.ProcessUploaded", TransferArgs{ID: id, JobID: p.JobID}, &t); err != nil {
+			return err
+		}
+		return c.download(t, reply)
+
+	case "Broker.FetchWorld":
+		var a JobArgs
+		if err := bridge(args, &a); err != nil {
+			return err
+		}
+		var t TransferReply
+		if err := c.Client.Call("Broker.OpenWorld", TransferArgs{MaxCells: chunkCells, JobID: a.JobID}, &t); err != nil {
+			return err
+		}
+		return c.download(t, reply)
+	}
+	return c.Client.Call(serviceMethod, args, reply)
+}
+
+// upload sends p.World in chunks and returns the broker's transfer ID.
+func (c chunkedClient) upload(p WorldParams) (int, error) {
+	step := chunkHeight(p.ImageWidth)
+	id := 0
+	for seq, start := 0, 0; start < len(p.World); seq, start = seq+1, start+step {
+		end := min(start+step, len(p.World))
+		chunk := WorldChunk{ID: id, Seq: seq, StartY: start, Rows: p.World[start:end]}
+		if seq == 0 {
+			chunk.ImageWidth = p.ImageWidth
+			chunk.ImageHeight = p.ImageHeight
+			chunk.Turn = p.Turn
+			chunk.Boundary = p.Boundary
+			chunk.Rule = p.Rule
+		}
+		if err := c.Client.Call("Broker.UploadChunk", chunk, &id); err != nil {
+			return 0, err
+		}
+	}
+	return id, nil
+}
+
+// download fetches the world described by t into reply, chunk by chunk unless the
+// broker already put it in t.
+func (c chunkedClient) download(t TransferReply, reply interface{}) error {
+	if t.ID == 0 {
+		return bridge(t.World, reply)
+	}
+	step := chunkHeight(t.ImageWidth)
+	// The chunks are copied into one world made up front, so that it is laid out flat like
+	// any other (see util.NewWorld); world is cut down to the rows received so far.
+	world := util.NewWorld(t.ImageWidth, t.ImageHeight)[:0]
+	for seq := 0; len(world) < t.ImageHeight; seq++ {
+		var chunk WorldChunk
+		if err := c.Client.Call("Broker.FetchChunk", ChunkArgs{ID: t.ID, Seq: seq, StartY: len(world), Rows: step}, &chunk); err != nil {
+			return err
+		}
+		if chunk.Seq != seq || chunk.StartY != len(world) || len(chunk.Rows) == 0 || len(world)+len(chunk.Rows) > t.ImageHeight {
+			return fmt.Errorf("transport: out of order chunk %d at row %d", chunk.Seq, chunk.StartY)
+		}
+		world = world[:len(world)+len(chunk.Rows)]
+		for i, row := range chunk.Rows {
+			copy(world[chunk.StartY+i], row)
+		}
+	}
+	return bridge(world, reply)
+}
+
+// chunkHeight is how many rows of the given width fit in one chunk.
+func chunkHeight(width int) int {
+	return max(1, chunkCells/max(1, width))
+}
+
+// worldParams reads the WorldParams fields of args (any binary's own copy of the type)
+// without re-encoding the world.
+func worldParams(args interface{}) (WorldParams, bool) {
+	v := reflect.Indirect(reflect.ValueOf(args))
+	if v.Kind() != reflect.Struct {
+		return WorldParams{}, false
+	}
+	width, height, world := v.FieldByName("ImageWidth"), v.FieldByName("ImageHeight"), v.FieldByName("World")
+	rows := reflect.TypeOf([][]uint8(nil))
+	if width.Kind() != reflect.Int || height.Kind() != reflect.Int || !world.IsValid() || !world.Type().ConvertibleTo(rows) {
+		return WorldParams{}, false
+	}
+	p := WorldParams{
+		ImageWidth:  int(width.Int()),
+		ImageHeight: int(height.Int()),
+		World:       world.Convert(rows).Interface().([][]uint8),
+	}
+	if turn := v.FieldByName("Turn"); turn.Kind() == reflect.Int {
+		p.Turn = int(turn.Int())
+	}
+	if boundary := v.FieldByName("Boundary"); boundary.Kind() == reflect.String {
+		p.Boundary = util.Boundary(boundary.String())
+	}
+	if rule := v.FieldByName("Rule"); rule.Kind() == reflect.String {
+		p.Rule = rule.String()
+	}
+	if job := v.FieldByName("JobID"); job.Kind() == reflect.String {
+		p.JobID = job.String()
+	}
+	return p, true
+}
@@ -0,0 +1,158 @@
+package transport
+
+import (
+	"bufio"
+	"compress/flate"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Optional compression of net/rpc connections, chosen by the client per connection.
+// After the auth handshake (if any) the client sends "GOL-COMPRESS <name>\n"; the server
+// answers "OK\n" and from then on both ends wrap the connection with that compressor.
+// Clients that skip the line get an uncompressed connection, so nothing changes for them.
+//
+// The server can tell the line apart from plain net/rpc traffic by its first byte: a net/rpc
+// client always opens with gob's type definition of rpc.Request, whose length byte is 46,
+// never 'G'.
+//
+// gRPC connections use gRPC's own gzip compressor instead (see dialGRPC).
+
+const compressPrefix = "GOL-COMPRESS "
+
+// compressor wraps one direction of a connection. Writers are flushed after every
+// Write so each RPC message reaches the peer without waiting for more data.
+type compressor struct {
+	reader func(io.Reader) io.Reader
+	writer func(io.Writer) (flushWriter, error)
+}
+
+type flushWriter interface {
+	io.Writer
+	Flush() error
+}
+
+// compressors are the names accepted by Options.Compress. Only the standard library
+// is available to this module, so flate is the one built in.
+var compressors = map[string]compressor{
+	"flate": {
+		reader: func(r io.Reader) io.Reader { return flate.NewReader(r) },
+		writer: func(w io.Writer) (flushWriter, error) { return flate.NewWriter(w, flate.BestSpeed) },
+	},
+}
+
+// Compressors lists the names accepted by Options.Compress, for flag help and errors.
+func Compressors() []string {
+	names := make([]string, 0, len(compressors))
+	for name := range compressors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// compressedConn is a connection with compressor c applied in both directions.
+type compressedConn struct {
+	net.Conn
+	r io.Reader
+	w flushWriter
+}
+
+func newCompressedConn(conn net.Conn, r io.Reader, c compressor) (*compressedConn, error) {
+	w, err := c.writer(conn)
+	if err != nil {
+		return nil, err
+	}
+	return &compressedConn{Conn: conn, r: c.reader(r), w: w}, nil
+}
+
+func (c *compressedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
+
+func (c *compressedConn) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	if err != nil {
+		return n, err
+	}
+	return n, c.w.Flush()
+}
+
+// requestCompression runs the client side of the compression handshake.
+func requestCompression(conn net.Conn, name string) (net.Conn, error) {
+	c, ok := compressors[name]
+	if !ok {
+		return nil, fmt.Errorf("transport: unknown compression %q (want one of %s)", name, strings.Join(Compressors(), ", "))
+	}
+	_ = conn.SetDeadline(time.Now().Add(authTimeout))
+	defer conn.SetDeadline(time.Time{})
+
+	if _, err := io.WriteString(conn, compressPrefix+name+"\n"); err != nil {
+		return nil, err
+	}
+	line, err := readLine(conn)
+	if err != nil {
+		return nil, err
+	}
+	if line != authOK {
+		return nil, fmt.Errorf("transport: server refused compression %q: %s", name, line)
+	}
+	return newCompressedConn(conn, conn, c)
+}
+
+// Negotiate runs the server side of the compression handshake on a connection that has
+// already passed CheckToken, and returns what net/rpc should serve: the connection
+// itself, or a compressing wrapper if the client asked for one. A worker offering a
+// reverse connection instead has it parked (see reverse.go) and ErrParked returned.
+func Negotiate(conn net.Conn) (io.ReadWriteCloser, error) {
+	br := bufio.NewReader(conn)
+	first, err := br.Peek(1)
+	if err != nil || first[0] != compressPrefix[0] {
+		// Not a handshake: replay whatever was buffered to net/rpc.
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+
+	_ = conn.SetDeadline(time.Now().Add(authTimeout))
+	raw, err := br.ReadSlice('\n')
+	_ = conn.SetDeadline(time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	line := string(raw)
+	if strings.HasPrefix(line, reversePrefix) {
+		id := strings.TrimSuffix(strings.TrimPrefix(line, reversePrefix), "\n")
+		if id == "" {
+			_, _ = io.WriteString(conn, "INVALID\n")
+			return nil, fmt.Errorf("transport: reverse connection without an id")
+		}
+		if _, err := io.WriteString(conn, authOK+"\n"); err != nil {
+			return nil, err
+		}
+		park(id, &bufferedConn{Conn: conn, r: br})
+		return nil, ErrParked
+	}
+	name := strings.TrimPrefix(strings.TrimSuffix(line, "\n"), compressPrefix)
+	c, ok := compressors[name]
+	if !strings.HasPrefix(line, compressPrefix) || !ok {
+		_, _ = io.WriteString(conn, "UNSUPPORTED\n")
+		return nil, fmt.Errorf("transport: unsupported compression request %q", strings.TrimSpace(line))
+	}
+	if _, err := io.WriteString(conn, authOK+"\n"); err != nil {
+		return nil, err
+	}
+	return newCompressedConn(conn, br, c)
+}
+
+// bufferedConn reads through the bufio.Reader Negotiate peeked with.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
@@ -0,0 +1,114 @@
+package transport
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"net"
+	"sync"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/engine"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestCompressedConn tests that both ends of a connection that negotiated compression read
+// what the other wrote, message by message, without either closing.
+func TestCompressedConn(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	defer serverConn.Close()
+
+	served := make(chan io.ReadWriteCloser, 1)
+	go func() {
+		conn, err := Negotiate(serverConn)
+		if err != nil {
+			t.Error(err)
+		}
+		served <- conn
+	}()
+	client, err := requestCompression(clientConn, "flate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := <-served
+	if server == nil {
+		t.FailNow()
+	}
+
+	for _, payload := range payloads() {
+		for _, ends := range [][2]io.ReadWriter{{client, server}, {server, client}} {
+			go func() {
+				if _, err := ends[0].Write(payload.data); err != nil {
+					t.Error(err)
+				}
+			}()
+			got := make([]byte, len(payload.data))
+			if _, err := io.ReadFull(ends[1], got); err != nil {
+				t.Fatalf("%s: %v", payload.name, err)
+			}
+			if !bytes.Equal(got, payload.data) {
+				t.Fatalf("%s: read back different bytes", payload.name)
+			}
+		}
+	}
+}
+
+// TestUnknownCompression tests that a compressor the server doesn't have is refused.
+func TestUnknownCompression(t *testing.T) {
+	clientConn, _ := net.Pipe()
+	defer clientConn.Close()
+	if _, err := requestCompression(clientConn, "snappy"); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+// BenchmarkFlate measures a world going through the flate compressor and back, as one turn's
+// request or reply does with -compress flate. The ratio metric is compressed/original size.
+func BenchmarkFlate(b *testing.B) {
+	for _, payload := range payloads() {
+		b.Run(payload.name, func(b *testing.B) {
+			var compressed bytes.Buffer
+			size := 0
+			b.SetBytes(int64(len(payload.data)))
+			for b.Loop() {
+				compressed.Reset()
+				w, _ := compressors["flate"].writer(&compressed)
+				_, _ = w.Write(payload.data)
+				_ = w.Flush()
+				size = compressed.Len()
+				if _, err := io.ReadFull(compressors["flate"].reader(&compressed), make([]byte, len(payload.data))); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(size)/float64(len(payload.data)), "ratio")
+		})
+	}
+}
+
+type payload struct {
+	name string
+	data []byte
+}
+
+// payloads are 512x512 worlds as net/rpc sends them: a random soup, packed; the same soup
+// after 100 turns, packed (the usual case mid-run); and that world a byte per cell, as
+// worlds with dying cells are sent.
+var payloads = sync.OnceValue(func() []payload {
+	r := rand.New(rand.NewSource(1))
+	world := util.NewWorld(512, 512)
+	for y := range world {
+		for x := range world[y] {
+			if r.Intn(2) == 0 {
+				world[y][x] = 255
+			}
+		}
+	}
+	soup, _ := util.CodedWorld{World: world, Codecs: []util.Codec{util.CodecRaw, util.CodecPacked}}.GobEncode()
+	for range 100 {
+		world = engine.Evolve(world, engine.BoundaryTorus, engine.Conway, 1)
+	}
+	evolved, _ := util.CodedWorld{World: world, Codecs: []util.Codec{util.CodecRaw, util.CodecPacked}}.GobEncode()
+	raw, _ := util.CodedWorld{World: world, Codecs: []util.Codec{util.CodecRaw}}.GobEncode()
+	return []payload{{"soup-packed", soup}, {"evolved-packed", evolved}, {"evolved-raw", raw}}
+})
@@ -0,0 +1,274 @@
+package transport
+
+import (
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/golpb"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// Conversions between the canonical types and the generated protobuf messages.
+
+func toPBCells(cells []util.Cell) []*golpb.Cell {
+	out := make([]*golpb.Cell, len(cells))
+	for i, c := range cells {
+		out[i] = &golpb.Cell{X: int32(c.X), Y: int32(c.Y)}
+	}
+	return out
+}
+
+func fromPBCells(cells []*golpb.Cell) []util.Cell {
+	if len(cells) == 0 {
+		return nil
+	}
+	out := make([]util.Cell, len(cells))
+	for i, c := range cells {
+		out[i] = util.Cell{X: int(c.GetX()), Y: int(c.GetY())}
+	}
+	return out
+}
+
+// toPBWorld packs rows one bit per cell, or lists the live cells when the world is
+// mostly empty and that is smaller. Worlds with dying cells are sent a byte per cell.
+func toPBWorld(rows [][]uint8) *golpb.World {
+	if !util.TwoState(rows) {
+		w := &golpb.World{Width: int32(len(rows[0])), Height: int32(len(rows)), States: make([]byte, 0, len(rows)*len(rows[0]))}
+		for _, row := range rows {
+			w.States = append(w.States, row...)
+		}
+		return w
+	}
+	if indices, ok := util.SparseIndices(rows); ok && len(indices) > 0 {
+		w := &golpb.World{Width: int32(len(rows[0])), Height: int32(len(rows)), Cells: make([]uint64, len(indices))}
+		prev := 0
+		for k, i := range indices {
+			w.Cells[k] = uint64(i - prev)
+			prev = i
+		}
+		return w
+	}
+	p := util.Pack(rows)
+	return &golpb.World{Width: int32(p.Width), Height: int32(p.Height), Bits: p.Bits}
+}
+
+// fromPBWorld unpacks w. A missing or empty world comes back as nil, as it did when
+// rows were sent as repeated bytes (PollReply relies on this to mean "no resync").
+func fromPBWorld(w *golpb.World) [][]uint8 {
+	if w.GetHeight() == 0 {
+		return nil
+	}
+	width, height := int(w.GetWidth()), int(w.GetHeight())
+	if states := w.GetStates(); len(states) == width*height {
+		return util.Rows(states, width, height)
+	}
+	if cells := w.GetCells(); len(cells) > 0 {
+		indices := make([]int, 0, len(cells))
+		i := 0
+		for _, gap := range cells {
+			if i += int(gap); i >= width*height {
+				break // malformed: ignore cells outside the world
+			}
+			indices = append(indices, i)
+		}
+		return util.FromIndices(width, height, indices)
+	}
+	return util.PackedWorld{Width: width, Height: height, Bits: w.GetBits()}.Unpack()
+}
+
+func toPBWorldParams(p WorldParams) *golpb.WorldParams {
+	return &golpb.WorldParams{
+		ImageWidth:  int32(p.ImageWidth),
+		ImageHeight: int32(p.ImageHeight),
+		World:       toPBWorld(p.World),
+		Turn:        int32(p.Turn),
+		Boundary:    string(p.Boundary),
+		Rule:        p.Rule,
+		JobId:       p.JobID,
+	}
+}
+
+func fromPBWorldParams(p *golpb.WorldParams) WorldParams {
+	return WorldParams{
+		ImageWidth:  int(p.GetImageWidth()),
+		ImageHeight: int(p.GetImageHeight()),
+		World:       fromPBWorld(p.GetWorld()),
+		Turn:        int(p.GetTurn()),
+		Boundary:    util.Boundary(p.GetBoundary()),
+		Rule:        p.GetRule(),
+		JobID:       p.GetJobId(),
+	}
+}
+
+func toPBProcessTurnsReply(r ProcessTurnsReply) *golpb.ProcessTurnsReply {
+	out := &golpb.ProcessTurnsReply{Turn: int32(r.Turn)}
+	for _, f := range r.Flipped {
+		out.Flipped = append(out.Flipped, &golpb.TurnFlips{Cells: toPBCells(f)})
+	}
+	for _, t := range r.Timings {
+		out.Timings = append(out.Timings, toPBStepTiming(t))
+	}
+	return out
+}
+
+func fromPBProcessTurnsReply(r *golpb.ProcessTurnsReply) ProcessTurnsReply {
+	out := ProcessTurnsReply{Turn: int(r.GetTurn())}
+	for _, f := range r.GetFlipped() {
+		out.Flipped = append(out.Flipped, fromPBCells(f.GetCells()))
+	}
+	for _, t := range r.GetTimings() {
+		out.Timings = append(out.Timings, fromPBStepTiming(t))
+	}
+	return out
+}
+
+func toPBStepTiming(t StepTiming) *golpb.StepTiming {
+	return &golpb.StepTiming{ElapsedNs: int64(t.Elapsed), Slowest: t.Slowest, SlowestElapsedNs: int64(t.SlowestElapsed)}
+}
+
+func fromPBStepTiming(t *golpb.StepTiming) StepTiming {
+	return StepTiming{Elapsed: time.Duration(t.GetElapsedNs()), Slowest: t.GetSlowest(), SlowestElapsed: time.Duration(t.GetSlowestElapsedNs())}
+}
+
+func toPBTask(t Task) *golpb.Task {
+	return &golpb.Task{StartY: int32(t.StartY), EndY: int32(t.EndY), WorldPart: toPBWorld(t.WorldPart), Boundary: string(t.Boundary), Rule: t.Rule}
+}
+
+func fromPBTask(t *golpb.Task) Task {
+	return Task{StartY: int(t.GetStartY()), EndY: int(t.GetEndY()), WorldPart: fromPBWorld(t.GetWorldPart()), Boundary: util.Boundary(t.GetBoundary()), Rule: t.GetRule()}
+}
+
+func toPBPartReply(r PartReply) *golpb.PartReply {
+	return &golpb.PartReply{Rows: toPBWorld(r.Rows), Flipped: toPBCells(r.Flipped)}
+}
+
+func fromPBPartReply(r *golpb.PartReply) PartReply {
+	return PartReply{Rows: fromPBWorld(r.GetRows()), Flipped: fromPBCells(r.GetFlipped())}
+}
+
+func toPBBandSetup(s BandSetup) *golpb.BandSetup {
+	return &golpb.BandSetup{
+		StartY:   int32(s.StartY),
+		EndY:     int32(s.EndY),
+		Rows:     toPBWorld(s.Rows),
+		Above:    s.Above,
+		Below:    s.Below,
+		Height:   int32(s.Height),
+		Boundary: string(s.Boundary),
+		Rule:     s.Rule,
+	}
+}
+
+func fromPBBandSetup(s *golpb.BandSetup) BandSetup {
+	return BandSetup{
+		StartY:   int(s.GetStartY()),
+		EndY:     int(s.GetEndY()),
+		Rows:     fromPBWorld(s.GetRows()),
+		Above:    s.GetAbove(),
+		Below:    s.GetBelow(),
+		Height:   int(s.GetHeight()),
+		Boundary: util.Boundary(s.GetBoundary()),
+		Rule:     s.GetRule(),
+	}
+}
+
+func toPBPollReply(r PollReply) *golpb.PollReply {
+	out := &golpb.PollReply{Turn: int32(r.Turn), World: toPBWorld(r.World), Rule: r.Rule, Deltas: make([]*golpb.TurnDelta, len(r.Deltas))}
+	for i, d := range r.Deltas {
+		out.Deltas[i] = &golpb.TurnDelta{Turn: int32(d.Turn), Flipped: toPBCells(d.Flipped)}
+	}
+	return out
+}
+
+func fromPBPollReply(r *golpb.PollReply) PollReply {
+	out := PollReply{Turn: int(r.GetTurn()), World: fromPBWorld(r.GetWorld()), Rule: r.GetRule()}
+	for _, d := range r.GetDeltas() {
+		out.Deltas = append(out.Deltas, TurnDelta{Turn: int(d.GetTurn()), Flipped: fromPBCells(d.GetFlipped())})
+	}
+	return out
+}
+
+func toPBSubscriptionArgs(a SubscriptionArgs) *golpb.SubscriptionArgs {
+	return &golpb.SubscriptionArgs{Id: int32(a.ID), JobId: a.JobID}
+}
+
+func fromPBSubscriptionArgs(a *golpb.SubscriptionArgs) SubscriptionArgs {
+	return SubscriptionArgs{ID: int(a.GetId()), JobID: a.GetJobId()}
+}
+
+func toPBRegisterArgs(a RegisterArgs) *golpb.RegisterArgs {
+	out := &golpb.RegisterArgs{
+		Address:   a.Address,
+		Port:      int32(a.Port),
+		Transport: a.Transport,
+		Score:     a.Score,
+		Version:   int32(a.Version),
+		Cores:     int32(a.Cores),
+	}
+	for _, c := range a.Codecs {
+		out.Codecs = append(out.Codecs, string(c))
+	}
+	for _, r := range a.Rules {
+		out.Rules = append(out.Rules, string(r))
+	}
+	return out
+}
+
+func fromPBRegisterArgs(a *golpb.RegisterArgs) RegisterArgs {
+	out := RegisterArgs{
+		Address:   a.GetAddress(),
+		Port:      int(a.GetPort()),
+		Transport: a.GetTransport(),
+		Score:     a.GetScore(),
+		Version:   int(a.GetVersion()),
+		Cores:     int(a.GetCores()),
+	}
+	for _, c := range a.GetCodecs() {
+		out.Codecs = append(out.Codecs, util.Codec(c))
+	}
+	for _, r := range a.GetRules() {
+		out.Rules = append(out.Rules, util.RuleKind(r))
+	}
+	return out
+}
+
+func toPBListWorkersReply(r ListWorkersReply) *golpb.ListWorkersReply {
+	out := &golpb.ListWorkersReply{Workers: make([]*golpb.WorkerInfo, len(r.Workers))}
+	for i, w := range r.Workers {
+		out.Workers[i] = &golpb.WorkerInfo{
+			Address:    w.Address,
+			Healthy:    w.Healthy,
+			Missed:     int32(w.Missed),
+			Suspect:    w.Suspect,
+			LatencyNs:  int64(w.Latency),
+			Score:      w.Score,
+			Throughput: w.Throughput,
+			Rows:       int32(w.Rows),
+			Version:    int32(w.Version),
+			Cores:      int32(w.Cores),
+			Codecs:     w.Codecs,
+			Rules:      w.Rules,
+		}
+	}
+	return out
+}
+
+func fromPBListWorkersReply(r *golpb.ListWorkersReply) ListWorkersReply {
+	out := ListWorkersReply{Workers: make([]WorkerInfo, len(r.GetWorkers()))}
+	for i, w := range r.GetWorkers() {
+		out.Workers[i] = WorkerInfo{
+			Address:    w.GetAddress(),
+			Healthy:    w.GetHealthy(),
+			Missed:     int(w.GetMissed()),
+			Suspect:    w.GetSuspect(),
+			Latency:    time.Duration(w.GetLatencyNs()),
+			Score:      w.GetScore(),
+			Throughput: w.GetThroughput(),
+			Rows:       int(w.GetRows()),
+			Version:    int(w.GetVersion()),
+			Cores:      int(w.GetCores()),
+			Codecs:     w.GetCodecs(),
+			Rules:      w.GetRules(),
+		}
+	}
+	return out
+}
@@ -0,0 +1,42 @@
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"net/rpc"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// ErrTimeout is returned (wrapped) by Call when Options.Timeout runs out. The call itself may
+// still complete on the server, so whether it took effect is unknown. It carries
+// util.CodeTimeout, which survives being passed on over another RPC.
+var ErrTimeout error = &util.CodedError{Code: util.CodeTimeout, Err: errors.New("transport: call timed out")}
+
+// deadlineClient bounds how long Call waits. It wraps the whole call, rather than the Go of
+// the underlying client, so calls split into chunks (see chunked.go) are bounded as a whole.
+type deadlineClient struct {
+	Client
+	timeout time.Duration
+}
+
+func (c deadlineClient) Call(serviceMethod string, args interface{}, reply interface{}) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Client.Call(serviceMethod, args, reply)
+	}()
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return fmt.Errorf("%s after %v: %w", serviceMethod, c.timeout, ErrTimeout)
+	}
+}
+
+// Go is not bounded: the caller already chooses how long to wait on done.
+func (c deadlineClient) Go(serviceMethod string, args interface{}, reply interface{}, done chan *rpc.Call) *rpc.Call {
+	return c.Client.Go(serviceMethod, args, reply, done)
+}
@@ -0,0 +1,631 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/rpc"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/status"
+	"uk.ac.bris.cs/gameoflife/golpb"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// maxMessageSize lifts gRPC's 4MB default so unary calls can carry 512x512+ worlds;
+// the streaming RPCs are used for world upload/download regardless.
+const maxMessageSize = 1 << 30
+
+// chunkRows is how many rows go into each RowChunk of a streamed world.
+const chunkRows = 64
+
+// grpcClient maps net/rpc style "Service.Method" calls onto the generated gRPC stubs.
+type grpcClient struct {
+	conn   *grpc.ClientConn
+	broker golpb.BrokerClient
+	worker golpb.WorkerClient
+
+	mu      sync.Mutex
+	watches map[SubscriptionArgs]*watch // Broker.Watch streams by observer, opened by the first Poll
+	noWatch bool                        // the broker has no Broker.Watch, Poll with unary calls
+}
+
+// watch is the stream an observer's Polls are read from.
+type watch struct {
+	stream grpc.ServerStreamingClient[golpb.PollReply]
+	cancel context.CancelFunc
+}
+
+func dialGRPC(addr string, o Options) (Client, error) {
+	callOpts := []grpc.CallOption{grpc.MaxCallRecvMsgSize(maxMessageSize), grpc.MaxCallSendMsgSize(maxMessageSize)}
+	if o.Compress != "" {
+		// gRPC compresses with its own gzip codec whichever compressor was named.
+		if _, ok := compressors[o.Compress]; !ok {
+			return nil, fmt.Errorf("transport: unknown compression %q (want one of %s)", o.Compress, strings.Join(Compressors(), ", "))
+		}
+		callOpts = append(callOpts, grpc.UseCompressor(gzip.Name))
+	}
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(callOpts...),
+	}
+	if o.Token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerToken(o.Token)))
+	}
+	if o.TCP != (TCPOptions{}) {
+		opts = append(opts, grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return o.TCP.dialContext(ctx, "tcp", addr, 0)
+		}))
+	}
+	conn, err := grpc.NewClient(addr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	// grpc.NewClient connects lazily; fail now like rpc.Dial would if nothing is listening.
+	conn.Connect()
+	if _, err := golpb.NewWorkerClient(conn).Ping(context.Background(), &golpb.Empty{}, grpc.WaitForReady(false)); err != nil {
+		if s, ok := status.FromError(err); ok && s.Code() == codes.Unavailable {
+			_ = conn.Close()
+			return nil, fmt.Errorf("dial grpc %s: %v", addr, s.Message())
+		}
+	}
+	return &grpcClient{
+		conn:   conn,
+		broker: golpb.NewBrokerClient(conn),
+		worker: golpb.NewWorkerClient(conn),
+	}, nil
+}
+
+func (c *grpcClient) Close() error {
+	c.mu.Lock()
+	for sub, w := range c.watches {
+		w.cancel()
+		delete(c.watches, sub)
+	}
+	c.mu.Unlock()
+	return c.conn.Close()
+}
+
+// Go runs Call in the background, matching (*rpc.Client).Go.
+func (c *grpcClient) Go(serviceMethod string, args interface{}, reply interface{}, done chan *rpc.Call) *rpc.Call {
+	if done == nil {
+		done = make(chan *rpc.Call, 1)
+	}
+	call := &rpc.Call{ServiceMethod: serviceMethod, Args: args, Reply: reply, Done: done}
+	go func() {
+		call.Error = c.Call(serviceMethod, args, reply)
+		call.Done <- call
+	}()
+	return call
+}
+
+func (c *grpcClient) Call(serviceMethod string, args interface{}, reply interface{}) error {
+	err := c.call(context.Background(), serviceMethod, args, reply)
+	// Errors returned by the remote method arrive as codes.Unknown; report them as
+	// rpc.ServerError so callers can tell them apart from connection failures, which
+	// wrap rpc.ErrShutdown like a net/rpc client whose connection went away.
+	if s, ok := status.FromError(err); ok && err != nil {
+		switch s.Code() {
+		case codes.Unknown:
+			return rpc.ServerError(s.Message())
+		case codes.Unauthenticated:
+			return ErrUnauthorized
+		case codes.Unavailable:
+			return fmt.Errorf("%s: %s: %w", serviceMethod, s.Message(), rpc.ErrShutdown)
+		}
+	}
+	return err
+}
+
+func (c *grpcClient) call(ctx context.Context, serviceMethod string, args interface{}, reply interface{}) error {
+	switch serviceMethod {
+	case "Broker.ProcessTurn":
+		var p WorldParams
+		if err := bridge(args, &p); err != nil {
+			return err
+		}
+		return c.processTurnStream(ctx, p, reply)
+
+	case "Broker.GetAliveCellsCount":
+		var a JobArgs
+		if err := bridge(args, &a); err != nil {
+			return err
+		}
+		res, err := c.broker.GetAliveCellsCount(ctx, &golpb.JobArgs{JobId: a.JobID})
+		if err != nil {
+			return err
+		}
+		return bridge(int(res.GetValue()), reply)
+
+	case "Broker.RegisterWorker":
+		var a RegisterArgs
+		if err := bridge(args, &a); err != nil {
+			return err
+		}
+		res, err := c.broker.RegisterWorker(ctx, toPBRegisterArgs(a))
+		if err != nil {
+			return err
+		}
+		return bridge(res.GetOk(), reply)
+
+	case "Broker.DeregisterWorker":
+		var a RegisterArgs
+		if err := bridge(args, &a); err != nil {
+			return err
+		}
+		res, err := c.broker.DeregisterWorker(ctx, toPBRegisterArgs(a))
+		if err != nil {
+			return err
+		}
+		return bridge(res.GetOk(), reply)
+
+	case "Broker.RetireWorker":
+		var a RegisterArgs
+		if err := bridge(args, &a); err != nil {
+			return err
+		}
+		res, err := c.broker.RetireWorker(ctx, toPBRegisterArgs(a))
+		if err != nil {
+			return err
+		}
+		return bridge(res.GetOk(), reply)
+
+	case "Broker.StartSimulation":
+		var p WorldParams
+		if err := bridge(args, &p); err != nil {
+			return err
+		}
+		return c.uploadWorld(ctx, p, reply)
+
+	case "Broker.NextTurn":
+		var a JobArgs
+		if err := bridge(args, &a); err != nil {
+			return err
+		}
+		res, err := c.broker.NextTurn(ctx, &golpb.JobArgs{JobId: a.JobID})
+		if err != nil {
+			return err
+		}
+		return bridge(NextTurnReply{Turn: int(res.GetTurn()), Flipped: fromPBCells(res.GetFlipped()), Timing: fromPBStepTiming(res.GetTiming())}, reply)
+
+	case "Broker.ProcessTurns":
+		var a ProcessTurnsArgs
+		if err := bridge(args, &a); err != nil {
+			return err
+		}
+		res, err := c.broker.ProcessTurns(ctx, &golpb.ProcessTurnsArgs{Turns: int32(a.Turns), JobId: a.JobID})
+		if err != nil {
+			return err
+		}
+		return bridge(fromPBProcessTurnsReply(res), reply)
+
+	case "Broker.FetchWorld":
+		var a JobArgs
+		if err := bridge(args, &a); err != nil {
+			return err
+		}
+		return c.streamWorld(ctx, a.JobID, reply)
+
+	case "Broker.Detach":
+		var a DetachArgs
+		if err := bridge(args, &a); err != nil {
+			return err
+		}
+		res, err := c.broker.Detach(ctx, &golpb.DetachArgs{Turns: int32(a.Turns), JobId: a.JobID})
+		if err != nil {
+			return err
+		}
+		return bridge(res.GetOk(), reply)
+
+	case "Broker.Attach":
+		var a JobArgs
+		if err := bridge(args, &a); err != nil {
+			return err
+		}
+		res, err := c.broker.Attach(ctx, &golpb.JobArgs{JobId: a.JobID})
+		if err != nil {
+			return err
+		}
+		return bridge(AttachReply{Turn: int(res.GetTurn()), World: fromPBWorld(res.GetWorld()), Rule: res.GetRule()}, reply)
+
+	case "Broker.Subscribe":
+		var a JobArgs
+		if err := bridge(args, &a); err != nil {
+			return err
+		}
+		res, err := c.broker.Subscribe(ctx, &golpb.JobArgs{JobId: a.JobID})
+		if err != nil {
+			return err
+		}
+		return bridge(SubscribeReply{ID: int(res.GetId()), Turn: int(res.GetTurn()), World: fromPBWorld(res.GetWorld()), Rule: res.GetRule()}, reply)
+
+	case "Broker.Poll":
+		var a SubscriptionArgs
+		if err := bridge(args, &a); err != nil {
+			return err
+		}
+		res, err := c.poll(ctx, a)
+		if err != nil {
+			return err
+		}
+		return bridge(fromPBPollReply(res), reply)
+
+	case "Broker.Unsubscribe":
+		var a SubscriptionArgs
+		if err := bridge(args, &a); err != nil {
+			return err
+		}
+		c.unwatch(a)
+		res, err := c.broker.Unsubscribe(ctx, toPBSubscriptionArgs(a))
+		if err != nil {
+			return err
+		}
+		return bridge(res.GetOk(), reply)
+
+	case "Broker.Pause":
+		var a JobArgs
+		if err := bridge(args, &a); err != nil {
+			return err
+		}
+		res, err := c.broker.Pause(ctx, &golpb.JobArgs{JobId: a.JobID})
+		if err != nil {
+			return err
+		}
+		return bridge(res.GetOk(), reply)
+
+	case "Broker.Resume":
+		var a JobArgs
+		if err := bridge(args, &a); err != nil {
+			return err
+		}
+		res, err := c.broker.Resume(ctx, &golpb.JobArgs{JobId: a.JobID})
+		if err != nil {
+			return err
+		}
+		return bridge(res.GetOk(), reply)
+
+	case "Broker.ListWorkers":
+		res, err := c.broker.ListWorkers(ctx, &golpb.Empty{})
+		if err != nil {
+			return err
+		}
+		return bridge(fromPBListWorkersReply(res), reply)
+
+	case "Broker.GetStatus":
+		var a JobArgs
+		if err := bridge(args, &a); err != nil {
+			return err
+		}
+		res, err := c.broker.GetStatus(ctx, &golpb.JobArgs{JobId: a.JobID})
+		if err != nil {
+			return err
+		}
+		return bridge(StatusReply{
+			Turn:       int(res.GetTurn()),
+			Paused:     res.GetPaused(),
+			Detached:   res.GetDetached(),
+			Workers:    int(res.GetWorkers()),
+			Observers:  int(res.GetObservers()),
+			Rule:       res.GetRule(),
+			Waiting:    time.Duration(res.GetWaitingNs()),
+			MinWorkers: int(res.GetMinWorkers()),
+		}, reply)
+
+	case "Broker.Shutdown":
+		res, err := c.broker.Shutdown(ctx, &golpb.Empty{})
+		if err != nil {
+			return err
+		}
+		return bridge(res.GetOk(), reply)
+
+	case "Broker.GetWorld":
+		var a JobArgs
+		if err := bridge(args, &a); err != nil {
+			return err
+		}
+		res, err := c.broker.GetWorld(ctx, &golpb.JobArgs{JobId: a.JobID})
+		if err != nil {
+			return err
+		}
+		return bridge(WorldReply{Turn: int(res.GetTurn()), World: fromPBWorld(res.GetWorld())}, reply)
+
+	case "Broker.WatchAlive":
+		var a AliveArgs
+		if err := bridge(args, &a); err != nil {
+			return err
+		}
+		res, err := c.broker.WatchAlive(ctx, &golpb.AliveArgs{Interval: int64(a.Interval), JobId: a.JobID})
+		if err != nil {
+			return err
+		}
+		return bridge(AliveReport{Turn: int(res.GetTurn()), Count: int(res.GetCount())}, reply)
+
+	case "Broker.SubmitJob":
+		var a SubmitArgs
+		if err := bridge(args, &a); err != nil {
+			return err
+		}
+		res, err := c.broker.SubmitJob(ctx, &golpb.SubmitArgs{
+			ImageWidth:  int32(a.ImageWidth),
+			ImageHeight: int32(a.ImageHeight),
+			World:       toPBWorld(a.World),
+			Turns:       int32(a.Turns),
+			Boundary:    string(a.Boundary),
+			Rule:        a.Rule,
+			JobId:       a.JobID,
+		})
+		if err != nil {
+			return err
+		}
+		return bridge(SubmitReply{JobID: res.GetJobId()}, reply)
+
+	case "Broker.JobProgress":
+		var a JobArgs
+		if err := bridge(args, &a); err != nil {
+			return err
+		}
+		res, err := c.broker.JobProgress(ctx, &golpb.JobArgs{JobId: a.JobID})
+		if err != nil {
+			return err
+		}
+		return bridge(JobProgressReply{
+			State: res.GetState(),
+			Turn:  int(res.GetTurn()),
+			Turns: int(res.GetTurns()),
+			Alive: int(res.GetAlive()),
+			Err:   res.GetErr(),
+		}, reply)
+
+	case "Broker.JobResult":
+		var a JobArgs
+		if err := bridge(args, &a); err != nil {
+			return err
+		}
+		res, err := c.broker.JobResult(ctx, &golpb.JobArgs{JobId: a.JobID})
+		if err != nil {
+			return err
+		}
+		return bridge(JobResultReply{Turn: int(res.GetTurn()), World: fromPBWorld(res.GetWorld()), Alive: fromPBCells(res.GetAlive())}, reply)
+
+	case "Worker.Ping":
+		res, err := c.worker.Ping(ctx, &golpb.Empty{})
+		if err != nil {
+			return err
+		}
+		return bridge(res.GetOk(), reply)
+
+	case "Worker.ProcessPart":
+		var t Task
+		if err := bridge(args, &t); err != nil {
+			return err
+		}
+		res, err := c.worker.ProcessPart(ctx, toPBTask(t))
+		if err != nil {
+			return err
+		}
+		return bridge(fromPBPartReply(res), reply)
+
+	case "Worker.ProcessTile":
+		var t TileTask
+		if err := bridge(args, &t); err != nil {
+			return err
+		}
+		res, err := c.worker.ProcessTile(ctx, &golpb.TileTask{
+			StartX: int32(t.StartX), EndX: int32(t.EndX),
+			StartY: int32(t.StartY), EndY: int32(t.EndY),
+			Cells: toPBWorld(t.Cells),
+			Rule:  t.Rule,
+		})
+		if err != nil {
+			return err
+		}
+		return bridge(fromPBPartReply(res), reply)
+
+	case "Worker.SetupBand":
+		var s BandSetup
+		if err := bridge(args, &s); err != nil {
+			return err
+		}
+		res, err := c.worker.SetupBand(ctx, toPBBandSetup(s))
+		if err != nil {
+			return err
+		}
+		return bridge(int(res.GetValue()), reply)
+
+	case "Worker.GetEdge":
+		var a EdgeArgs
+		if err := bridge(args, &a); err != nil {
+			return err
+		}
+		res, err := c.worker.GetEdge(ctx, &golpb.EdgeArgs{Turn: int32(a.Turn), Top: a.Top})
+		if err != nil {
+			return err
+		}
+		return bridge(res.GetCells(), reply)
+
+	case "Worker.Step":
+		var a StepArgs
+		if err := bridge(args, &a); err != nil {
+			return err
+		}
+		res, err := c.worker.Step(ctx, &golpb.StepArgs{Turn: int32(a.Turn)})
+		if err != nil {
+			return err
+		}
+		return bridge(StepReply{Flipped: fromPBCells(res.GetFlipped()), AliveCount: int(res.GetAliveCount())}, reply)
+
+	case "Worker.FetchBand":
+		res, err := c.worker.FetchBand(ctx, &golpb.Empty{})
+		if err != nil {
+			return err
+		}
+		return bridge(fromPBWorld(res), reply)
+
+	case "Worker.Shutdown":
+		res, err := c.worker.Shutdown(ctx, &golpb.Empty{})
+		if err != nil {
+			return err
+		}
+		return bridge(res.GetOk(), reply)
+
+	case "Worker.Drain":
+		res, err := c.worker.Drain(ctx, &golpb.Empty{})
+		if err != nil {
+			return err
+		}
+		return bridge(res.GetOk(), reply)
+	}
+	return fmt.Errorf("transport: %s is not available over gRPC", serviceMethod)
+}
+
+// poll reads the next batch of turns for observer sub from its Broker.Watch stream, opening the
+// stream first if need be. Brokers without Broker.Watch are polled with unary calls instead.
+func (c *grpcClient) poll(ctx context.Context, sub SubscriptionArgs) (*golpb.PollReply, error) {
+	c.mu.Lock()
+	w, noWatch := c.watches[sub], c.noWatch
+	c.mu.Unlock()
+	if noWatch {
+		return c.broker.Poll(ctx, toPBSubscriptionArgs(sub))
+	}
+	if w == nil {
+		// The stream outlives this call: it is closed by Unsubscribe, Close or its first error.
+		streamCtx, cancel := context.WithCancel(context.Background())
+		stream, err := c.broker.Watch(streamCtx, toPBSubscriptionArgs(sub))
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		w = &watch{stream: stream, cancel: cancel}
+		c.mu.Lock()
+		if c.watches == nil {
+			c.watches = make(map[SubscriptionArgs]*watch)
+		}
+		c.watches[sub] = w
+		c.mu.Unlock()
+	}
+
+	res, err := w.stream.Recv()
+	if err != nil {
+		c.unwatch(sub)
+		if status.Code(err) == codes.Unimplemented {
+			c.mu.Lock()
+			c.noWatch = true
+			c.mu.Unlock()
+			return c.broker.Poll(ctx, toPBSubscriptionArgs(sub))
+		}
+		return nil, err
+	}
+	return res, nil
+}
+
+// unwatch closes observer sub's Broker.Watch stream, if it has one.
+func (c *grpcClient) unwatch(sub SubscriptionArgs) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if w := c.watches[sub]; w != nil {
+		w.cancel()
+		delete(c.watches, sub)
+	}
+}
+
+// uploadWorld sends the initial world in row chunks so it never has to fit in one message.
+func (c *grpcClient) uploadWorld(ctx context.Context, p WorldParams, reply interface{}) error {
+	stream, err := c.broker.UploadWorld(ctx)
+	if err != nil {
+		return err
+	}
+	if err := sendRows(stream.Send, p); err != nil {
+		return err
+	}
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+	return bridge(res.GetOk(), reply)
+}
+
+// streamWorld downloads job's world chunk by chunk.
+func (c *grpcClient) streamWorld(ctx context.Context, job string, reply interface{}) error {
+	stream, err := c.broker.StreamWorld(ctx, &golpb.JobArgs{JobId: job})
+	if err != nil {
+		return err
+	}
+	p, err := recvRows(stream.Recv)
+	if err != nil {
+		return err
+	}
+	return bridge(p.World, reply)
+}
+
+// processTurnStream streams the world up and the next one back, so neither has to fit in one message.
+func (c *grpcClient) processTurnStream(ctx context.Context, p WorldParams, reply interface{}) error {
+	stream, err := c.broker.ProcessTurnStream(ctx)
+	if err != nil {
+		return err
+	}
+	if err := sendRows(stream.Send, p); err != nil {
+		return err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+	next, err := recvRows(stream.Recv)
+	if err != nil {
+		return err
+	}
+	return bridge(next.World, reply)
+}
+
+// sendRows sends p.World as numbered chunks of chunkRows rows. The first chunk carries
+// the dimensions and is sent even for an empty world.
+func sendRows(send func(*golpb.RowChunk) error, p WorldParams) error {
+	for seq, start := 0, 0; start < len(p.World) || seq == 0; seq, start = seq+1, start+chunkRows {
+		end := min(start+chunkRows, len(p.World))
+		chunk := &golpb.RowChunk{Seq: int32(seq), StartY: int32(start), Rows: toPBWorld(p.World[start:end])}
+		if seq == 0 {
+			chunk.ImageWidth = int32(p.ImageWidth)
+			chunk.ImageHeight = int32(p.ImageHeight)
+			chunk.Turn = int32(p.Turn)
+			chunk.Boundary = string(p.Boundary)
+			chunk.Rule = p.Rule
+			chunk.JobId = p.JobID
+		}
+		if err := send(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recvRows reassembles chunks written by sendRows until the stream ends.
+func recvRows(recv func() (*golpb.RowChunk, error)) (WorldParams, error) {
+	var p WorldParams
+	for seq := int32(0); ; seq++ {
+		chunk, err := recv()
+		if err == io.EOF {
+			return p, nil
+		}
+		if err != nil {
+			return p, err
+		}
+		if chunk.GetSeq() != seq || int(chunk.GetStartY()) != len(p.World) {
+			return p, fmt.Errorf("transport: out of order chunk %d at row %d", chunk.GetSeq(), chunk.GetStartY())
+		}
+		if seq == 0 {
+			p.ImageWidth = int(chunk.GetImageWidth())
+			p.ImageHeight = int(chunk.GetImageHeight())
+			p.Turn = int(chunk.GetTurn())
+			p.Boundary = util.Boundary(chunk.GetBoundary())
+			p.Rule = chunk.GetRule()
+			p.JobID = chunk.GetJobId()
+		}
+		p.World = append(p.World, fromPBWorld(chunk.GetRows())...)
+	}
+}
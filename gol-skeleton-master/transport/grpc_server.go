@@ -0,0 +1,409 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"google.golang.org/grpc"
+	"uk.ac.bris.cs/gameoflife/golpb"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// NewGRPCServer exposes net/rpc style receivers (the same *Broker / *Worker values
+// passed to rpc.Register) as the gRPC services from proto/gol.proto.
+// Either receiver may be nil. A non-empty token is required from every caller.
+func NewGRPCServer(broker, worker interface{}, token string) *grpc.Server {
+	opts := append([]grpc.ServerOption{grpc.MaxRecvMsgSize(maxMessageSize), grpc.MaxSendMsgSize(maxMessageSize)}, authInterceptors(token)...)
+	srv := grpc.NewServer(opts...)
+	if broker != nil {
+		golpb.RegisterBrokerServer(srv, &brokerServer{rcv: reflect.ValueOf(broker)})
+	}
+	if worker != nil {
+		golpb.RegisterWorkerServer(srv, &workerServer{rcv: reflect.ValueOf(worker)})
+	}
+	return srv
+}
+
+// invoke calls rcv.name(args, reply) the way net/rpc would, converting args into the
+// method's own parameter type and its reply back into reply.
+func invoke(rcv reflect.Value, name string, args interface{}, reply interface{}) error {
+	m := rcv.MethodByName(name)
+	if !m.IsValid() {
+		return fmt.Errorf("method %s not implemented", name)
+	}
+	argv := reflect.New(m.Type().In(0))
+	if err := bridge(args, argv.Interface()); err != nil {
+		return err
+	}
+	replyv := reflect.New(m.Type().In(1).Elem())
+	if errv := m.Call([]reflect.Value{argv.Elem(), replyv})[0]; !errv.IsNil() {
+		return errv.Interface().(error)
+	}
+	return bridge(replyv.Interface(), reply)
+}
+
+type brokerServer struct {
+	golpb.UnimplementedBrokerServer
+	rcv reflect.Value
+}
+
+func (s *brokerServer) ProcessTurn(_ context.Context, in *golpb.WorldParams) (*golpb.World, error) {
+	var world util.World
+	if err := invoke(s.rcv, "ProcessTurn", fromPBWorldParams(in), &world); err != nil {
+		return nil, err
+	}
+	return toPBWorld(world), nil
+}
+
+func (s *brokerServer) GetAliveCellsCount(_ context.Context, in *golpb.JobArgs) (*golpb.Count, error) {
+	var count int
+	if err := invoke(s.rcv, "GetAliveCellsCount", JobArgs{JobID: in.GetJobId()}, &count); err != nil {
+		return nil, err
+	}
+	return &golpb.Count{Value: int64(count)}, nil
+}
+
+func (s *brokerServer) RegisterWorker(_ context.Context, in *golpb.RegisterArgs) (*golpb.Ok, error) {
+	var ok bool
+	if err := invoke(s.rcv, "RegisterWorker", fromPBRegisterArgs(in), &ok); err != nil {
+		return nil, err
+	}
+	return &golpb.Ok{Ok: ok}, nil
+}
+
+func (s *brokerServer) DeregisterWorker(_ context.Context, in *golpb.RegisterArgs) (*golpb.Ok, error) {
+	var ok bool
+	if err := invoke(s.rcv, "DeregisterWorker", fromPBRegisterArgs(in), &ok); err != nil {
+		return nil, err
+	}
+	return &golpb.Ok{Ok: ok}, nil
+}
+
+func (s *brokerServer) RetireWorker(_ context.Context, in *golpb.RegisterArgs) (*golpb.Ok, error) {
+	var ok bool
+	if err := invoke(s.rcv, "RetireWorker", fromPBRegisterArgs(in), &ok); err != nil {
+		return nil, err
+	}
+	return &golpb.Ok{Ok: ok}, nil
+}
+
+func (s *brokerServer) StartSimulation(_ context.Context, in *golpb.WorldParams) (*golpb.Ok, error) {
+	var ok bool
+	if err := invoke(s.rcv, "StartSimulation", fromPBWorldParams(in), &ok); err != nil {
+		return nil, err
+	}
+	return &golpb.Ok{Ok: ok}, nil
+}
+
+func (s *brokerServer) NextTurn(_ context.Context, in *golpb.JobArgs) (*golpb.NextTurnReply, error) {
+	var reply NextTurnReply
+	if err := invoke(s.rcv, "NextTurn", JobArgs{JobID: in.GetJobId()}, &reply); err != nil {
+		return nil, err
+	}
+	return &golpb.NextTurnReply{Turn: int32(reply.Turn), Flipped: toPBCells(reply.Flipped), Timing: toPBStepTiming(reply.Timing)}, nil
+}
+
+func (s *brokerServer) ProcessTurns(_ context.Context, in *golpb.ProcessTurnsArgs) (*golpb.ProcessTurnsReply, error) {
+	var reply ProcessTurnsReply
+	if err := invoke(s.rcv, "ProcessTurns", ProcessTurnsArgs{Turns: int(in.GetTurns()), JobID: in.GetJobId()}, &reply); err != nil {
+		return nil, err
+	}
+	return toPBProcessTurnsReply(reply), nil
+}
+
+func (s *brokerServer) FetchWorld(_ context.Context, in *golpb.JobArgs) (*golpb.World, error) {
+	var world util.World
+	if err := invoke(s.rcv, "FetchWorld", JobArgs{JobID: in.GetJobId()}, &world); err != nil {
+		return nil, err
+	}
+	return toPBWorld(world), nil
+}
+
+func (s *brokerServer) Detach(_ context.Context, in *golpb.DetachArgs) (*golpb.Ok, error) {
+	var ok bool
+	if err := invoke(s.rcv, "Detach", DetachArgs{Turns: int(in.GetTurns()), JobID: in.GetJobId()}, &ok); err != nil {
+		return nil, err
+	}
+	return &golpb.Ok{Ok: ok}, nil
+}
+
+func (s *brokerServer) Attach(_ context.Context, in *golpb.JobArgs) (*golpb.AttachReply, error) {
+	var reply AttachReply
+	if err := invoke(s.rcv, "Attach", JobArgs{JobID: in.GetJobId()}, &reply); err != nil {
+		return nil, err
+	}
+	return &golpb.AttachReply{Turn: int32(reply.Turn), World: toPBWorld(reply.World), Rule: reply.Rule}, nil
+}
+
+func (s *brokerServer) Subscribe(_ context.Context, in *golpb.JobArgs) (*golpb.SubscribeReply, error) {
+	var reply SubscribeReply
+	if err := invoke(s.rcv, "Subscribe", JobArgs{JobID: in.GetJobId()}, &reply); err != nil {
+		return nil, err
+	}
+	return &golpb.SubscribeReply{Id: int32(reply.ID), Turn: int32(reply.Turn), World: toPBWorld(reply.World), Rule: reply.Rule}, nil
+}
+
+func (s *brokerServer) Poll(_ context.Context, in *golpb.SubscriptionArgs) (*golpb.PollReply, error) {
+	var reply PollReply
+	if err := invoke(s.rcv, "Poll", fromPBSubscriptionArgs(in), &reply); err != nil {
+		return nil, err
+	}
+	return toPBPollReply(reply), nil
+}
+
+// Watch pushes the replies of successive Polls down one stream, so observers on gRPC are sent
+// the turns rather than asking for each batch. It ends when the observer unsubscribes.
+func (s *brokerServer) Watch(in *golpb.SubscriptionArgs, stream grpc.ServerStreamingServer[golpb.PollReply]) error {
+	args := fromPBSubscriptionArgs(in)
+	for stream.Context().Err() == nil {
+		var reply PollReply
+		if err := invoke(s.rcv, "Poll", args, &reply); err != nil {
+			return err
+		}
+		if err := stream.Send(toPBPollReply(reply)); err != nil {
+			return err
+		}
+	}
+	return stream.Context().Err()
+}
+
+func (s *brokerServer) Unsubscribe(_ context.Context, in *golpb.SubscriptionArgs) (*golpb.Ok, error) {
+	var ok bool
+	if err := invoke(s.rcv, "Unsubscribe", fromPBSubscriptionArgs(in), &ok); err != nil {
+		return nil, err
+	}
+	return &golpb.Ok{Ok: ok}, nil
+}
+
+func (s *brokerServer) Pause(_ context.Context, in *golpb.JobArgs) (*golpb.Ok, error) {
+	var ok bool
+	if err := invoke(s.rcv, "Pause", JobArgs{JobID: in.GetJobId()}, &ok); err != nil {
+		return nil, err
+	}
+	return &golpb.Ok{Ok: ok}, nil
+}
+
+func (s *brokerServer) Resume(_ context.Context, in *golpb.JobArgs) (*golpb.Ok, error) {
+	var ok bool
+	if err := invoke(s.rcv, "Resume", JobArgs{JobID: in.GetJobId()}, &ok); err != nil {
+		return nil, err
+	}
+	return &golpb.Ok{Ok: ok}, nil
+}
+
+func (s *brokerServer) ListWorkers(context.Context, *golpb.Empty) (*golpb.ListWorkersReply, error) {
+	var reply ListWorkersReply
+	if err := invoke(s.rcv, "ListWorkers", struct{}{}, &reply); err != nil {
+		return nil, err
+	}
+	return toPBListWorkersReply(reply), nil
+}
+
+func (s *brokerServer) GetStatus(_ context.Context, in *golpb.JobArgs) (*golpb.StatusReply, error) {
+	var reply StatusReply
+	if err := invoke(s.rcv, "GetStatus", JobArgs{JobID: in.GetJobId()}, &reply); err != nil {
+		return nil, err
+	}
+	return &golpb.StatusReply{
+		Turn:       int32(reply.Turn),
+		Paused:     reply.Paused,
+		Detached:   reply.Detached,
+		Workers:    int32(reply.Workers),
+		Observers:  int32(reply.Observers),
+		Rule:       reply.Rule,
+		WaitingNs:  int64(reply.Waiting),
+		MinWorkers: int32(reply.MinWorkers),
+	}, nil
+}
+
+func (s *brokerServer) Shutdown(context.Context, *golpb.Empty) (*golpb.Ok, error) {
+	var ok bool
+	if err := invoke(s.rcv, "Shutdown", struct{}{}, &ok); err != nil {
+		return nil, err
+	}
+	return &golpb.Ok{Ok: ok}, nil
+}
+
+func (s *brokerServer) GetWorld(_ context.Context, in *golpb.JobArgs) (*golpb.WorldReply, error) {
+	var reply WorldReply
+	if err := invoke(s.rcv, "GetWorld", JobArgs{JobID: in.GetJobId()}, &reply); err != nil {
+		return nil, err
+	}
+	return &golpb.WorldReply{Turn: int32(reply.Turn), World: toPBWorld(reply.World)}, nil
+}
+
+func (s *brokerServer) WatchAlive(_ context.Context, in *golpb.AliveArgs) (*golpb.AliveReport, error) {
+	var reply AliveReport
+	if err := invoke(s.rcv, "WatchAlive", AliveArgs{Interval: time.Duration(in.GetInterval()), JobID: in.GetJobId()}, &reply); err != nil {
+		return nil, err
+	}
+	return &golpb.AliveReport{Turn: int32(reply.Turn), Count: int64(reply.Count)}, nil
+}
+
+func (s *brokerServer) SubmitJob(_ context.Context, in *golpb.SubmitArgs) (*golpb.SubmitReply, error) {
+	var reply SubmitReply
+	args := SubmitArgs{
+		ImageWidth:  int(in.GetImageWidth()),
+		ImageHeight: int(in.GetImageHeight()),
+		World:       fromPBWorld(in.GetWorld()),
+		Turns:       int(in.GetTurns()),
+		Boundary:    util.Boundary(in.GetBoundary()),
+		Rule:        in.GetRule(),
+		JobID:       in.GetJobId(),
+	}
+	if err := invoke(s.rcv, "SubmitJob", args, &reply); err != nil {
+		return nil, err
+	}
+	return &golpb.SubmitReply{JobId: reply.JobID}, nil
+}
+
+func (s *brokerServer) JobProgress(_ context.Context, in *golpb.JobArgs) (*golpb.JobProgressReply, error) {
+	var reply JobProgressReply
+	if err := invoke(s.rcv, "JobProgress", JobArgs{JobID: in.GetJobId()}, &reply); err != nil {
+		return nil, err
+	}
+	return &golpb.JobProgressReply{
+		State: reply.State,
+		Turn:  int32(reply.Turn),
+		Turns: int32(reply.Turns),
+		Alive: int64(reply.Alive),
+		Err:   reply.Err,
+	}, nil
+}
+
+func (s *brokerServer) JobResult(_ context.Context, in *golpb.JobArgs) (*golpb.JobResultReply, error) {
+	var reply JobResultReply
+	if err := invoke(s.rcv, "JobResult", JobArgs{JobID: in.GetJobId()}, &reply); err != nil {
+		return nil, err
+	}
+	return &golpb.JobResultReply{Turn: int32(reply.Turn), World: toPBWorld(reply.World), Alive: toPBCells(reply.Alive)}, nil
+}
+
+// UploadWorld assembles a chunked world and starts a simulation with it.
+func (s *brokerServer) UploadWorld(stream grpc.ClientStreamingServer[golpb.RowChunk, golpb.Ok]) error {
+	p, err := recvRows(stream.Recv)
+	if err != nil {
+		return err
+	}
+	var ok bool
+	if err := invoke(s.rcv, "StartSimulation", p, &ok); err != nil {
+		return err
+	}
+	return stream.SendAndClose(&golpb.Ok{Ok: ok})
+}
+
+// StreamWorld sends the current world in row chunks.
+func (s *brokerServer) StreamWorld(in *golpb.JobArgs, stream grpc.ServerStreamingServer[golpb.RowChunk]) error {
+	var world util.World
+	if err := invoke(s.rcv, "FetchWorld", JobArgs{JobID: in.GetJobId()}, &world); err != nil {
+		return err
+	}
+	return sendRows(stream.Send, worldOf(world))
+}
+
+// ProcessTurnStream is ProcessTurn with both worlds sent in row chunks.
+func (s *brokerServer) ProcessTurnStream(stream grpc.BidiStreamingServer[golpb.RowChunk, golpb.RowChunk]) error {
+	p, err := recvRows(stream.Recv)
+	if err != nil {
+		return err
+	}
+	var next util.World
+	if err := invoke(s.rcv, "ProcessTurn", p, &next); err != nil {
+		return err
+	}
+	return sendRows(stream.Send, worldOf(next))
+}
+
+// worldOf wraps a world with its dimensions for sendRows.
+func worldOf(world [][]uint8) WorldParams {
+	p := WorldParams{ImageHeight: len(world), World: world}
+	if len(world) > 0 {
+		p.ImageWidth = len(world[0])
+	}
+	return p
+}
+
+type workerServer struct {
+	golpb.UnimplementedWorkerServer
+	rcv reflect.Value
+}
+
+func (s *workerServer) Ping(context.Context, *golpb.Empty) (*golpb.Ok, error) {
+	var ok bool
+	if err := invoke(s.rcv, "Ping", struct{}{}, &ok); err != nil {
+		return nil, err
+	}
+	return &golpb.Ok{Ok: ok}, nil
+}
+
+func (s *workerServer) ProcessPart(_ context.Context, in *golpb.Task) (*golpb.PartReply, error) {
+	var reply PartReply
+	if err := invoke(s.rcv, "ProcessPart", fromPBTask(in), &reply); err != nil {
+		return nil, err
+	}
+	return toPBPartReply(reply), nil
+}
+
+func (s *workerServer) ProcessTile(_ context.Context, in *golpb.TileTask) (*golpb.PartReply, error) {
+	var reply PartReply
+	t := TileTask{
+		StartX: int(in.GetStartX()), EndX: int(in.GetEndX()),
+		StartY: int(in.GetStartY()), EndY: int(in.GetEndY()),
+		Cells: fromPBWorld(in.GetCells()),
+		Rule:  in.GetRule(),
+	}
+	if err := invoke(s.rcv, "ProcessTile", t, &reply); err != nil {
+		return nil, err
+	}
+	return toPBPartReply(reply), nil
+}
+
+func (s *workerServer) SetupBand(_ context.Context, in *golpb.BandSetup) (*golpb.Count, error) {
+	var alive int
+	if err := invoke(s.rcv, "SetupBand", fromPBBandSetup(in), &alive); err != nil {
+		return nil, err
+	}
+	return &golpb.Count{Value: int64(alive)}, nil
+}
+
+func (s *workerServer) GetEdge(_ context.Context, in *golpb.EdgeArgs) (*golpb.Row, error) {
+	var row []uint8
+	if err := invoke(s.rcv, "GetEdge", EdgeArgs{Turn: int(in.GetTurn()), Top: in.GetTop()}, &row); err != nil {
+		return nil, err
+	}
+	return &golpb.Row{Cells: row}, nil
+}
+
+func (s *workerServer) Step(_ context.Context, in *golpb.StepArgs) (*golpb.StepReply, error) {
+	var reply StepReply
+	if err := invoke(s.rcv, "Step", StepArgs{Turn: int(in.GetTurn())}, &reply); err != nil {
+		return nil, err
+	}
+	return &golpb.StepReply{Flipped: toPBCells(reply.Flipped), AliveCount: int32(reply.AliveCount)}, nil
+}
+
+func (s *workerServer) FetchBand(context.Context, *golpb.Empty) (*golpb.World, error) {
+	var rows util.World
+	if err := invoke(s.rcv, "FetchBand", struct{}{}, &rows); err != nil {
+		return nil, err
+	}
+	return toPBWorld(rows), nil
+}
+
+func (s *workerServer) Shutdown(context.Context, *golpb.Empty) (*golpb.Ok, error) {
+	var ok bool
+	if err := invoke(s.rcv, "Shutdown", struct{}{}, &ok); err != nil {
+		return nil, err
+	}
+	return &golpb.Ok{Ok: ok}, nil
+}
+
+func (s *workerServer) Drain(context.Context, *golpb.Empty) (*golpb.Ok, error) {
+	var ok bool
+	if err := invoke(s.rcv, "Drain", struct{}{}, &ok); err != nil {
+		return nil, err
+	}
+	return &golpb.Ok{Ok: ok}, nil
+}
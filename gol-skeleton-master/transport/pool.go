@@ -0,0 +1,101 @@
+package transport
+
+import (
+	"net/rpc"
+	"sync"
+)
+
+// A net/rpc client sends its requests down one connection in order, so a large world
+// being written holds up every call behind it, even a ping. DialPool opens several
+// connections to the same server and spreads calls across them, so parts of a turn, jobs
+// running at the same time and heartbeats to one worker do not queue up on one stream.
+
+// pool is a Client made of several connections; each call goes to the one with the fewest
+// calls in flight.
+type pool struct {
+	mu       sync.Mutex
+	clients  []Client
+	inFlight []int
+	next     int // where the search for the least busy connection starts, so ties rotate
+}
+
+// DialPool is DialOptions with n connections to addr used as one Client. A gRPC connection
+// already multiplexes its calls and a worker offers one reverse connection at a time, so for
+// those addresses, and for n <= 1, it dials just one.
+func DialPool(addr string, opts Options, n int) (Client, error) {
+	if n <= 1 || IsGRPC(addr) || IsReverse(addr) {
+		return DialOptions(addr, opts)
+	}
+	p := &pool{clients: make([]Client, 0, n), inFlight: make([]int, n)}
+	for i := 0; i < n; i++ {
+		client, err := DialOptions(addr, opts)
+		if err != nil {
+			_ = p.Close()
+			return nil, err
+		}
+		p.clients = append(p.clients, client)
+	}
+	return p, nil
+}
+
+// acquire picks the least busy connection and counts a call on it.
+func (p *pool) acquire() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	best := p.next
+	for i := range p.clients {
+		if j := (p.next + i) % len(p.clients); p.inFlight[j] < p.inFlight[best] {
+			best = j
+		}
+	}
+	p.inFlight[best]++
+	p.next = (best + 1) % len(p.clients)
+	return best
+}
+
+func (p *pool) release(i int) {
+	p.mu.Lock()
+	p.inFlight[i]--
+	p.mu.Unlock()
+}
+
+func (p *pool) Call(serviceMethod string, args interface{}, reply interface{}) error {
+	i := p.acquire()
+	defer p.release(i)
+	return p.clients[i].Call(serviceMethod, args, reply)
+}
+
+// Go behaves like rpc.Client.Go: done must be buffered, nil allocates one, and the
+// returned call is sent on it once the reply (or an error) is in.
+func (p *pool) Go(serviceMethod string, args interface{}, reply interface{}, done chan *rpc.Call) *rpc.Call {
+	if done == nil {
+		done = make(chan *rpc.Call, 10)
+	} else if cap(done) == 0 {
+		panic("transport: done channel is unbuffered")
+	}
+	call := &rpc.Call{ServiceMethod: serviceMethod, Args: args, Reply: reply, Done: done}
+	i := p.acquire()
+	inner := p.clients[i].Go(serviceMethod, args, reply, make(chan *rpc.Call, 1))
+	go func() {
+		<-inner.Done
+		p.release(i)
+		call.Error = inner.Error
+		select {
+		case call.Done <- call:
+		default:
+			// like net/rpc, never block on a caller that stopped listening
+		}
+	}()
+	return call
+}
+
+// Close closes every connection and returns the first error.
+func (p *pool) Close() error {
+	var first error
+	for _, client := range p.clients {
+		if err := client.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
@@ -0,0 +1,104 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/rpc"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Reverse connections, for workers the broker cannot dial: behind NAT, or in a security group
+// without open inbound ports. The worker dials the broker itself (DialReverse), sends
+// "GOL-REVERSE <id>\n" after the token handshake and then serves its RPCs on that connection.
+// The broker parks the connection under id (see Negotiate) until it dials "reverse://<id>",
+// which the worker asks for by registering with that id as its address.
+
+// ReverseScheme marks the address of a worker reached over a connection it opened itself.
+const ReverseScheme = "reverse://"
+
+const reversePrefix = "GOL-REVERSE "
+
+// ErrParked is returned by Negotiate for a worker's reverse connection. The connection now
+// belongs to the next Dial of its reverse:// address and must not be served or closed.
+var ErrParked = errors.New("transport: reverse connection parked")
+
+var (
+	parked   = make(map[string]net.Conn) // reverse connections waiting to be dialled, by id
+	parkedMu sync.Mutex
+)
+
+// IsReverse reports whether addr is a reverse connection.
+func IsReverse(addr string) bool {
+	return strings.HasPrefix(addr, ReverseScheme)
+}
+
+// DialReverse dials the broker at addr and offers it a reverse connection named id, which the
+// caller then serves its RPCs on, e.g. with rpc.ServeConn. opts.Compress is not supported.
+func DialReverse(addr, id string, opts Options) (net.Conn, error) {
+	if IsGRPC(addr) {
+		return nil, fmt.Errorf("transport: reverse connections need a net/rpc broker address, not %s", addr)
+	}
+	if id == "" || strings.ContainsAny(id, "\r\n") {
+		return nil, fmt.Errorf("transport: invalid reverse connection id %q", id)
+	}
+	network := "tcp"
+	if IsUnix(addr) {
+		network, addr = "unix", strings.TrimPrefix(addr, UnixScheme)
+	}
+	conn, err := opts.TCP.dialContext(context.Background(), network, addr, opts.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Token != "" {
+		if err := sendToken(conn, opts.Token); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+	_ = conn.SetDeadline(time.Now().Add(authTimeout))
+	defer conn.SetDeadline(time.Time{})
+	if _, err := io.WriteString(conn, reversePrefix+id+"\n"); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	line, err := readLine(conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	if line != authOK {
+		_ = conn.Close()
+		return nil, fmt.Errorf("transport: broker refused reverse connection: %s", line)
+	}
+	return conn, nil
+}
+
+// park keeps conn until its reverse:// address is dialled, replacing (and closing) a connection
+// parked earlier under the same id.
+func park(id string, conn net.Conn) {
+	parkedMu.Lock()
+	old := parked[id]
+	parked[id] = conn
+	parkedMu.Unlock()
+	if old != nil {
+		_ = old.Close()
+	}
+}
+
+// dialReverse hands out the connection parked for addr.
+func dialReverse(addr string) (Client, error) {
+	id := strings.TrimPrefix(addr, ReverseScheme)
+	parkedMu.Lock()
+	conn, ok := parked[id]
+	delete(parked, id)
+	parkedMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("transport: no reverse connection from %s", id)
+	}
+	return chunkedClient{rpc.NewClient(conn)}, nil
+}
@@ -0,0 +1,89 @@
+package transport
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// TCPOptions tune the TCP connections under a client or accepted by a server. The zero value
+// keeps Go's and the OS's defaults. Over a high-latency link the default buffers cap how much
+// of a large world is in flight at once, so raising ReadBuffer on the receiving end and
+// WriteBuffer on the sending end (both, for worlds going both ways) can speed transfers up.
+// Unix domain sockets ignore them.
+type TCPOptions struct {
+	KeepAlive   time.Duration // period of keep-alive probes; 0 keeps Go's default (15s), negative turns them off
+	Delay       bool          // turn TCP_NODELAY off (Go turns it on), so Nagle's algorithm batches small writes
+	ReadBuffer  int           // SO_RCVBUF in bytes; 0 keeps the OS default
+	WriteBuffer int           // SO_SNDBUF in bytes; 0 keeps the OS default
+}
+
+// dialContext dials network/addr and tunes the connection with t.
+func (t TCPOptions) dialContext(ctx context.Context, network, addr string, timeout time.Duration) (net.Conn, error) {
+	d := net.Dialer{Timeout: timeout, KeepAlive: t.KeepAlive}
+	conn, err := d.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+	if err := Tune(conn, t); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// Tune applies t to conn if it is a TCP connection. Keep-alives are left to the dialer or
+// listener, which already set Go's default.
+func Tune(conn net.Conn, t TCPOptions) error {
+	tcp, ok := conn.(*net.TCPConn)
+	if !ok {
+		return nil
+	}
+	if t.Delay {
+		if err := tcp.SetNoDelay(false); err != nil {
+			return err
+		}
+	}
+	if t.ReadBuffer > 0 {
+		if err := tcp.SetReadBuffer(t.ReadBuffer); err != nil {
+			return err
+		}
+	}
+	if t.WriteBuffer > 0 {
+		if err := tcp.SetWriteBuffer(t.WriteBuffer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TuneListener returns l with t applied to every connection it accepts. A zero t returns l
+// as is.
+func TuneListener(l net.Listener, t TCPOptions) net.Listener {
+	if t == (TCPOptions{}) {
+		return l
+	}
+	return tunedListener{l, t}
+}
+
+type tunedListener struct {
+	net.Listener
+	opts TCPOptions
+}
+
+func (l tunedListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	if tcp, ok := conn.(*net.TCPConn); ok && l.opts.KeepAlive != 0 {
+		if l.opts.KeepAlive < 0 {
+			_ = tcp.SetKeepAlive(false)
+		} else {
+			_ = tcp.SetKeepAlivePeriod(l.opts.KeepAlive)
+		}
+	}
+	// A connection that cannot be tuned still works, just with the defaults.
+	_ = Tune(conn, l.opts)
+	return conn, nil
+}
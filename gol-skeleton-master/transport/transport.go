@@ -0,0 +1,135 @@
+// Package transport hides how distributor, broker and worker talk to each other.
+// Addresses of the form "grpc://host:port" use gRPC (see proto/gol.proto),
+// "unix:///path" is a Unix domain socket served by net/rpc, "reverse://id" is a
+// connection a worker opened to the broker (see reverse.go), and anything else is
+// a plain "host:port" served by net/rpc.
+package transport
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net"
+	"net/rpc"
+	"os"
+	"strings"
+	"time"
+)
+
+// GRPCScheme marks an address that should be dialled with gRPC.
+const GRPCScheme = "grpc://"
+
+// UnixScheme marks the address of a Unix domain socket, e.g. "unix:///tmp/gol-worker-1.sock".
+// It speaks the same net/rpc protocol as TCP without going through the TCP stack, for a
+// broker and workers running on the same machine.
+const UnixScheme = "unix://"
+
+// Client is the part of *rpc.Client the rest of the code relies on, so callers
+// can keep using client.Call("Broker.NextTurn", ...) whatever the transport.
+type Client interface {
+	Call(serviceMethod string, args interface{}, reply interface{}) error
+	Go(serviceMethod string, args interface{}, reply interface{}, done chan *rpc.Call) *rpc.Call
+	Close() error
+}
+
+// Options configure a connection made by DialOptions.
+type Options struct {
+	Token    string // shared secret for servers started with a token (see auth.go); empty skips authentication
+	Compress string // compress every message with this compressor (see compress.go); empty sends them as is
+
+	// Timeout bounds every Call, which then fails with ErrTimeout (see deadline.go), and dialling.
+	// 0 waits as long as it takes.
+	Timeout time.Duration
+
+	TCP TCPOptions // keep-alives, TCP_NODELAY and buffer sizes of the connection (see tcp.go)
+}
+
+// Dial connects to addr using the transport selected by its scheme.
+func Dial(addr string) (Client, error) {
+	return DialOptions(addr, Options{})
+}
+
+// DialToken is Dial for servers started with a shared token (see auth.go).
+// An empty token skips authentication.
+func DialToken(addr, token string) (Client, error) {
+	return DialOptions(addr, Options{Token: token})
+}
+
+// DialOptions is Dial with authentication, compression, timeouts and TCP tuning chosen by opts.
+func DialOptions(addr string, opts Options) (Client, error) {
+	client, err := dial(addr, opts)
+	if err != nil || opts.Timeout <= 0 {
+		return client, err
+	}
+	return deadlineClient{client, opts.Timeout}, nil
+}
+
+func dial(addr string, opts Options) (Client, error) {
+	if IsGRPC(addr) {
+		return dialGRPC(strings.TrimPrefix(addr, GRPCScheme), opts)
+	}
+	if IsReverse(addr) {
+		return dialReverse(addr)
+	}
+	network := "tcp"
+	if IsUnix(addr) {
+		network, addr = "unix", strings.TrimPrefix(addr, UnixScheme)
+	}
+	conn, err := opts.TCP.dialContext(context.Background(), network, addr, opts.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Timeout > 0 {
+		// Bound the handshakes below too; calls are bounded by deadlineClient.
+		_ = conn.SetDeadline(time.Now().Add(opts.Timeout))
+		defer conn.SetDeadline(time.Time{})
+	}
+	if opts.Token != "" {
+		if err := sendToken(conn, opts.Token); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+	if opts.Compress != "" {
+		compressed, err := requestCompression(conn, opts.Compress)
+		if err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		conn = compressed
+	}
+	return chunkedClient{rpc.NewClient(conn)}, nil
+}
+
+// IsGRPC reports whether addr selects the gRPC transport.
+func IsGRPC(addr string) bool {
+	return strings.HasPrefix(addr, GRPCScheme)
+}
+
+// IsUnix reports whether addr is a Unix domain socket.
+func IsUnix(addr string) bool {
+	return strings.HasPrefix(addr, UnixScheme)
+}
+
+// Listen listens on addr for net/rpc connections: a Unix domain socket for "unix:///path",
+// TCP for anything else. A socket file left behind by a process that did not exit cleanly
+// is removed first; one still in use is not.
+func Listen(addr string) (net.Listener, error) {
+	if !IsUnix(addr) {
+		return net.Listen("tcp", addr)
+	}
+	path := strings.TrimPrefix(addr, UnixScheme)
+	if info, err := os.Stat(path); err == nil && info.Mode()&fs.ModeSocket != 0 {
+		if conn, err := net.Dial("unix", path); err == nil {
+			_ = conn.Close()
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			_ = os.Remove(path)
+		}
+	}
+	return net.Listen("unix", path)
+}
+
+// HostPort strips any transport scheme from addr.
+func HostPort(addr string) string {
+	return strings.TrimPrefix(addr, GRPCScheme)
+}
@@ -0,0 +1,269 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/gob"
+	"reflect"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// Canonical copies of the RPC types. Each binary declares its own gob-compatible
+// versions (same names and fields); bridge converts between them.
+
+type WorldParams struct {
+	ImageWidth  int
+	ImageHeight int
+	World       util.World
+	Turn        int
+	Boundary    util.Boundary
+	Rule        string
+	JobID       string
+}
+
+type JobArgs struct {
+	JobID string
+}
+
+type RegisterArgs struct {
+	Address   string
+	Port      int
+	Transport string
+	Score     float64
+	Version   int
+	Codecs    []util.Codec
+	Cores     int
+	Rules     []util.RuleKind
+}
+
+type NextTurnReply struct {
+	Turn    int
+	Flipped []util.Cell
+	Timing  StepTiming
+}
+
+type StepTiming struct {
+	Elapsed        time.Duration
+	Slowest        string
+	SlowestElapsed time.Duration
+}
+
+type ProcessTurnsArgs struct {
+	Turns int
+	JobID string
+}
+
+type ProcessTurnsReply struct {
+	Turn    int
+	Flipped [][]util.Cell
+	Timings []StepTiming
+}
+
+type DetachArgs struct {
+	Turns int
+	JobID string
+}
+
+type AttachReply struct {
+	Turn  int
+	World util.World
+	Rule  string
+}
+
+type SubscriptionArgs struct {
+	ID    int
+	JobID string
+}
+
+type SubscribeReply struct {
+	ID    int
+	Turn  int
+	World util.World
+	Rule  string
+}
+
+type TurnDelta struct {
+	Turn    int
+	Flipped []util.Cell
+}
+
+type PollReply struct {
+	Turn   int
+	World  util.World
+	Rule   string
+	Deltas []TurnDelta
+}
+
+type StatusReply struct {
+	Turn      int
+	Paused    bool
+	Detached  bool
+	Workers   int
+	Observers int
+	Rule      string
+
+	Waiting    time.Duration // how long the job's turn has waited for workers to register, 0 = not waiting
+	MinWorkers int
+}
+
+type WorldReply struct {
+	Turn  int
+	World util.World
+}
+
+type AliveArgs struct {
+	Interval time.Duration
+	JobID    string
+}
+
+type AliveReport struct {
+	Turn  int
+	Count int
+}
+
+type WorkerInfo struct {
+	Address    string
+	Healthy    bool
+	Missed     int
+	Suspect    bool
+	Latency    time.Duration
+	Score      float64
+	Throughput float64
+	Rows       int
+	Version    int
+	Cores      int
+	Codecs     []string
+	Rules      []string
+}
+
+type ListWorkersReply struct {
+	Workers []WorkerInfo
+}
+
+type SubmitArgs struct {
+	ImageWidth  int
+	ImageHeight int
+	World       util.World
+	Turns       int
+	Boundary    util.Boundary
+	Rule        string
+	JobID       string
+}
+
+type SubmitReply struct {
+	JobID string
+}
+
+type JobProgressReply struct {
+	State string
+	Turn  int
+	Turns int
+	Alive int
+	Err   string
+}
+
+type JobResultReply struct {
+	Turn  int
+	World util.World
+	Alive []util.Cell
+}
+
+type WorldChunk struct {
+	ID          int
+	Seq         int
+	StartY      int
+	Rows        util.World
+	ImageWidth  int
+	ImageHeight int
+	Turn        int
+	Boundary    util.Boundary
+	Rule        string
+}
+
+type TransferArgs struct {
+	ID       int
+	MaxCells int
+	JobID    string
+}
+
+type TransferReply struct {
+	ID          int
+	ImageWidth  int
+	ImageHeight int
+	World       util.World
+}
+
+type ChunkArgs struct {
+	ID     int
+	Seq    int
+	StartY int
+	Rows   int
+}
+
+type Task struct {
+	StartY, EndY int
+	WorldPart    util.World
+	Boundary     util.Boundary
+	Rule         string
+}
+
+type TileTask struct {
+	StartX, EndX int
+	StartY, EndY int
+	Cells        util.World
+	Rule         string
+}
+
+type PartReply struct {
+	Rows    util.World
+	Flipped []util.Cell
+}
+
+type BandSetup struct {
+	StartY, EndY int
+	Rows         util.World
+	Above, Below string
+	Height       int
+	Boundary     util.Boundary
+	Rule         string
+}
+
+type EdgeArgs struct {
+	Turn int
+	Top  bool
+}
+
+type StepArgs struct {
+	Turn int
+}
+
+type StepReply struct {
+	Flipped    []util.Cell
+	AliveCount int
+}
+
+// bridge copies src into dst (a pointer) through gob, which matches fields by name.
+// Empty structs (the struct{} args used by several RPCs) carry nothing and are skipped.
+func bridge(src, dst interface{}) error {
+	if isEmpty(reflect.TypeOf(src)) || isEmpty(reflect.TypeOf(dst).Elem()) {
+		return nil
+	}
+	// Same underlying type (worlds, counts, flags): assign directly instead of re-encoding.
+	if sv, dv := reflect.ValueOf(src), reflect.ValueOf(dst).Elem(); sv.Type().AssignableTo(dv.Type()) {
+		dv.Set(sv)
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(src); err != nil {
+		return err
+	}
+	return gob.NewDecoder(&buf).Decode(dst)
+}
+
+func isEmpty(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t.NumField() == 0
+}
@@ -0,0 +1,109 @@
+package util
+
+import (
+	"encoding/binary"
+	"slices"
+)
+
+// Workers and the broker can be upgraded one at a time, so a worker tells the broker at
+// registration which protocol version it speaks, which world codecs it can decode and
+// which kinds of rule it can run. The broker then only sends it what it understands.
+
+// ProtocolVersion is the version of the broker/worker protocol this build speaks.
+// A worker that registers without one is version 0: it predates negotiation and can
+// decode LegacyCodecs and run every RuleKind.
+const ProtocolVersion = 1
+
+// Codec names one way of sending a world over net/rpc (see World.GobEncode).
+type Codec string
+
+const (
+	CodecRaw    Codec = "raw"    // a byte per cell; every peer can decode it and dying cells need it
+	CodecPacked Codec = "packed" // a bit per cell
+	CodecSparse Codec = "sparse" // the gaps between live cells
+	CodecRLE    Codec = "rle"    // alternating runs of dead and live cells
+)
+
+// Codecs lists every codec this build can decode.
+var Codecs = []Codec{CodecRaw, CodecPacked, CodecSparse, CodecRLE}
+
+// LegacyCodecs are the codecs a version 0 peer can decode, and what World.GobEncode uses
+// when nothing was negotiated.
+var LegacyCodecs = []Codec{CodecRaw, CodecPacked, CodecSparse}
+
+// RuleKinds lists every kind of rule this build can run.
+var RuleKinds = []RuleKind{RuleConway, RuleLifeLike, RuleGenerations}
+
+// Supports reports whether a peer that can run kinds can run r. Running any life-like
+// rule includes Conway's.
+func Supports(kinds []RuleKind, r Rule) bool {
+	kind := r.Kind()
+	return slices.Contains(kinds, kind) || (kind == RuleConway && slices.Contains(kinds, RuleLifeLike))
+}
+
+// CodedWorld is a World that gob encodes with only the given codecs, for a peer that
+// listed them at registration. It is received into a plain World.
+type CodedWorld struct {
+	World  World
+	Codecs []Codec
+}
+
+// GobEncode writes the same format as World.GobEncode, restricted to c.Codecs.
+func (c CodedWorld) GobEncode() ([]byte, error) {
+	return c.World.encode(c.Codecs), nil
+}
+
+// appendRLE appends the lengths of the alternating runs of dead and live cells of a
+// two-state world, starting with a (possibly empty) dead run, as uvarints.
+func appendRLE(buf []byte, w World) []byte {
+	live, run := false, 0
+	for _, row := range w {
+		for _, cell := range row {
+			if (cell != 0) != live {
+				buf = binary.AppendUvarint(buf, uint64(run))
+				live, run = !live, 0
+			}
+			run++
+		}
+	}
+	return binary.AppendUvarint(buf, uint64(run))
+}
+
+// rleRuns is a rough count of the runs in w, at most limit, so the encoder can tell
+// whether RLE is worth writing out before it does.
+func rleRuns(w World, limit int) int {
+	runs, live := 1, false
+	for _, row := range w {
+		for _, cell := range row {
+			if (cell != 0) != live {
+				if runs++; runs > limit {
+					return runs
+				}
+				live = !live
+			}
+		}
+	}
+	return runs
+}
+
+// decodeRLE fills a width×height world from the runs written by appendRLE.
+func decodeRLE(width, height int, data []byte) (World, bool) {
+	world := NewWorld(width, height)
+	cells, _ := Flat(world)
+	live, i := false, 0
+	for len(data) > 0 {
+		run, n := binary.Uvarint(data)
+		if n <= 0 || run > uint64(len(cells)-i) {
+			return nil, false
+		}
+		data = data[n:]
+		if live {
+			for k := i; k < i+int(run); k++ {
+				cells[k] = 255
+			}
+		}
+		i += int(run)
+		live = !live
+	}
+	return world, i == len(cells)
+}
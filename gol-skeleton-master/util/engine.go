@@ -0,0 +1,47 @@
+package util
+
+import "uk.ac.bris.cs/gameoflife/engine"
+
+// Rules and boundaries live in the engine package, which other projects can import to evolve
+// worlds without the rest of this one. These aliases keep the names used throughout the repo.
+type (
+	Rule     = engine.Rule
+	Preset   = engine.Preset
+	Boundary = engine.Boundary
+	RuleKind = engine.RuleKind
+)
+
+const (
+	BoundaryTorus  = engine.BoundaryTorus
+	BoundaryDead   = engine.BoundaryDead
+	BoundaryMirror = engine.BoundaryMirror
+
+	RuleConway      = engine.RuleConway
+	RuleLifeLike    = engine.RuleLifeLike
+	RuleGenerations = engine.RuleGenerations
+
+	MaxStates = engine.MaxStates
+)
+
+// Conway is engine.Conway.
+var Conway = engine.Conway
+
+// Presets is engine.Presets.
+func Presets() []Preset {
+	return engine.Presets()
+}
+
+// ParseRule is engine.ParseRule.
+func ParseRule(s string) (Rule, error) {
+	return engine.ParseRule(s)
+}
+
+// Boundaries is engine.Boundaries.
+func Boundaries() []Boundary {
+	return engine.Boundaries()
+}
+
+// ParseBoundary is engine.ParseBoundary.
+func ParseBoundary(s string) (Boundary, error) {
+	return engine.ParseBoundary(s)
+}
@@ -0,0 +1,61 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrorCode says why a run could not go on, so the distributor can tell a missing worker from
+// a dead broker without matching on messages. net/rpc only carries the text of an error, so a
+// CodedError writes its code into the text and CodeOf reads it back on the other side.
+type ErrorCode string
+
+const (
+	CodeBrokerUnreachable ErrorCode = "broker-unreachable" // the broker cannot be dialled or dropped the connection
+	CodeNoWorkers         ErrorCode = "no-workers"         // not enough workers are registered to run a turn
+	CodeWorkerFailed      ErrorCode = "worker-failed"      // a worker failed its part and nobody could take it over
+	CodeTimeout           ErrorCode = "timeout"            // a call did not finish in time
+)
+
+// ErrorCodes lists every code CodeOf recognises in an error's text.
+var ErrorCodes = []ErrorCode{CodeBrokerUnreachable, CodeNoWorkers, CodeWorkerFailed, CodeTimeout}
+
+// CodedError is an error with an ErrorCode. Its text starts with the code in brackets.
+type CodedError struct {
+	Code ErrorCode
+	Err  error
+}
+
+func (e *CodedError) Error() string {
+	return "[" + string(e.Code) + "] " + e.Err.Error()
+}
+
+func (e *CodedError) Unwrap() error {
+	return e.Err
+}
+
+// Errorf formats an error like fmt.Errorf and gives it code.
+func Errorf(code ErrorCode, format string, args ...interface{}) error {
+	return &CodedError{Code: code, Err: fmt.Errorf(format, args...)}
+}
+
+// CodeOf returns the code of the first CodedError in err's chain or, for an error that lost
+// its type crossing an RPC, the first code written in its text. It returns "" for nil and
+// for errors without a code.
+func CodeOf(err error) ErrorCode {
+	if err == nil {
+		return ""
+	}
+	var coded *CodedError
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+	text, code, at := err.Error(), ErrorCode(""), -1
+	for _, c := range ErrorCodes {
+		if i := strings.Index(text, "["+string(c)+"]"); i >= 0 && (at < 0 || i < at) {
+			code, at = c, i
+		}
+	}
+	return code
+}
@@ -0,0 +1,51 @@
+package util
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// HandleHealth adds the probes load balancers, systemd watchdogs and Kubernetes use to
+// supervise a process to mux:
+//
+//	GET /healthz  200 "ok" while the process is serving HTTP at all
+//	GET /readyz   200 "ok" when ready returns nil, 503 with its error otherwise
+//
+// Neither needs a token: probes cannot be given one and reveal nothing.
+func HandleHealth(mux *http.ServeMux, ready func() error) {
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if err := ready(); err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintf(w, "not ready: %v\n", err)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+}
+
+// IsHealthPath reports whether path is one of the probes added by HandleHealth.
+func IsHealthPath(path string) bool {
+	return path == "/healthz" || path == "/readyz"
+}
+
+// StartHealth serves only the HandleHealth probes on addr (e.g. ":8090"), for processes
+// without an HTTP server of their own. Like StartPprof, the listener is opened before it
+// returns and requests are served in the background.
+func StartHealth(addr string, ready func() error) (net.Addr, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	HandleHealth(mux, ready)
+	go func() {
+		_ = http.Serve(listener, mux)
+	}()
+	return listener.Addr(), nil
+}
@@ -0,0 +1,89 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+)
+
+// Shared structured logging for distributor, broker and worker.
+// Every component gets its own logger tagged with component=<name>; ConfigureLogging
+// picks the level and output format for the whole process, and also applies to
+// loggers created before it was called (e.g. package-level ones).
+
+var handler atomic.Pointer[slog.Handler]
+
+// ConfigureLogging sets the process-wide handler. format is "text" or "json",
+// level is one of debug, info, warn, error.
+func ConfigureLogging(w io.Writer, format, level string) error {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("unknown log level %q", level)
+	}
+	opts := &slog.HandlerOptions{Level: lvl}
+
+	var h slog.Handler
+	switch strings.ToLower(format) {
+	case "text":
+		h = slog.NewTextHandler(w, opts)
+	case "json":
+		h = slog.NewJSONHandler(w, opts)
+	default:
+		return fmt.Errorf("unknown log format %q", format)
+	}
+	handler.Store(&h)
+	return nil
+}
+
+// Logger returns a logger tagged with the given component name.
+func Logger(component string) *slog.Logger {
+	return slog.New(componentHandler{}).With("component", component)
+}
+
+// current is the configured handler, or slog's default (which writes through the
+// standard log package, so log.SetOutput still silences it in tests).
+func current() slog.Handler {
+	if h := handler.Load(); h != nil {
+		return *h
+	}
+	return slog.Default().Handler()
+}
+
+// componentHandler records With/WithGroup calls and replays them on whatever
+// handler is current when a record is logged.
+type componentHandler struct {
+	ops []func(slog.Handler) slog.Handler
+}
+
+func (h componentHandler) resolve() slog.Handler {
+	out := current()
+	for _, op := range h.ops {
+		out = op(out)
+	}
+	return out
+}
+
+func (h componentHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return current().Enabled(ctx, level)
+}
+
+func (h componentHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.resolve().Handle(ctx, r)
+}
+
+func (h componentHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(next slog.Handler) slog.Handler { return next.WithAttrs(attrs) })
+}
+
+func (h componentHandler) WithGroup(name string) slog.Handler {
+	return h.with(func(next slog.Handler) slog.Handler { return next.WithGroup(name) })
+}
+
+func (h componentHandler) with(op func(slog.Handler) slog.Handler) componentHandler {
+	ops := make([]func(slog.Handler) slog.Handler, len(h.ops), len(h.ops)+1)
+	copy(ops, h.ops)
+	return componentHandler{ops: append(ops, op)}
+}
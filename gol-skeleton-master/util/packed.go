@@ -0,0 +1,260 @@
+package util
+
+import (
+	"encoding/binary"
+	"fmt"
+	"slices"
+)
+
+// PackedWorld stores one bit per cell instead of one byte, which is how worlds travel
+// over the network: a 5120x5120 image is 3.2 MB packed instead of 26 MB.
+// Cell (x, y) is bit i%64 of Bits[i/64], where i = y*Width + x.
+type PackedWorld struct {
+	Width, Height int
+	Bits          []uint64
+}
+
+// Pack converts a world of 0 (dead) / 255 (alive) bytes. Any non-zero byte counts as alive.
+func Pack(world [][]uint8) PackedWorld {
+	p := PackedWorld{Height: len(world)}
+	if p.Height > 0 {
+		p.Width = len(world[0])
+	}
+	p.Bits = make([]uint64, (p.Width*p.Height+63)/64)
+	i := 0
+	for _, row := range world {
+		for _, cell := range row {
+			if cell != 0 {
+				p.Bits[i/64] |= 1 << (i % 64)
+			}
+			i++
+		}
+	}
+	return p
+}
+
+// Unpack converts back to a world of 0 / 255 bytes.
+func (p PackedWorld) Unpack() [][]uint8 {
+	world := NewWorld(p.Width, p.Height)
+	i := 0
+	for y := range world {
+		for x := range world[y] {
+			if p.Bits[i/64]&(1<<(i%64)) != 0 {
+				world[y][x] = 255
+			}
+			i++
+		}
+	}
+	return world
+}
+
+// World is a world of 0 / 255 bytes that gob encodes as a PackedWorld, or as a list of
+// live cells when that is smaller. The RPC types use it for every world field, so net/rpc
+// sends bits (or, for a mostly empty world, a few bytes per live cell) rather than bytes.
+// Worlds with dying cells (see Rule.Generations) are sent a byte per cell instead.
+type World [][]uint8
+
+// Encodings written by GobEncode, in the first byte.
+const (
+	encodingPacked = 0 // the packed bits as little-endian words
+	encodingSparse = 1 // the number of live cells, then the gap from one live cell's index to the next
+	encodingBytes  = 2 // the cells themselves, row by row
+	encodingRLE    = 3 // the lengths of alternating dead and live runs, starting with dead (see appendRLE)
+)
+
+// sparseCellBytes is roughly what one live cell costs in the sparse encoding; a world is sent
+// sparse when that beats eight bytes per packed word.
+const sparseCellBytes = 3
+
+// rleRunBytes is roughly what one run costs in the RLE encoding.
+const rleRunBytes = 2
+
+// GobEncode writes the encoding, the width and the height as uvarints, then the cells,
+// using only LegacyCodecs so that every peer can read it. CodedWorld allows more.
+func (w World) GobEncode() ([]byte, error) {
+	return w.encode(LegacyCodecs), nil
+}
+
+// encode writes w with whichever of codecs is likely to be smallest. Worlds with dying
+// cells, and peers with nothing better, get a byte per cell.
+func (w World) encode(codecs []Codec) []byte {
+	width, height := 0, len(w)
+	if height > 0 {
+		width = len(w[0])
+	}
+	words := (width*height + 63) / 64
+	header := func(encoding, size int) []byte {
+		buf := make([]byte, 0, 1+2*binary.MaxVarintLen64+size)
+		buf = append(buf, byte(encoding))
+		buf = binary.AppendUvarint(buf, uint64(width))
+		return binary.AppendUvarint(buf, uint64(height))
+	}
+
+	best, codec := width*height, CodecRaw
+	var indices []int
+	if TwoState(w) {
+		if slices.Contains(codecs, CodecPacked) {
+			best, codec = 8*words, CodecPacked
+		}
+		if slices.Contains(codecs, CodecSparse) {
+			if live, ok := liveIndices(w, best/sparseCellBytes); ok {
+				indices, best, codec = live, sparseCellBytes*len(live), CodecSparse
+			}
+		}
+		if slices.Contains(codecs, CodecRLE) && rleRuns(w, best/rleRunBytes) <= best/rleRunBytes {
+			codec = CodecRLE
+		}
+	}
+
+	switch codec {
+	case CodecRLE:
+		return appendRLE(header(encodingRLE, best), w)
+	case CodecSparse:
+		buf := header(encodingSparse, sparseCellBytes*len(indices))
+		buf = binary.AppendUvarint(buf, uint64(len(indices)))
+		prev := 0
+		for _, i := range indices {
+			buf = binary.AppendUvarint(buf, uint64(i-prev))
+			prev = i
+		}
+		return buf
+	case CodecPacked:
+		p := Pack(w)
+		buf := header(encodingPacked, 8*words)
+		for _, word := range p.Bits {
+			buf = binary.LittleEndian.AppendUint64(buf, word)
+		}
+		return buf
+	}
+
+	buf := header(encodingBytes, width*height)
+	if cells, ok := Flat(w); ok {
+		return append(buf, cells...)
+	}
+	for _, row := range w {
+		buf = append(buf, row...)
+	}
+	return buf
+}
+
+// TwoState reports whether every cell of world is 0 or 255, so that it can be packed
+// one bit per cell without losing dying cells.
+func TwoState(world [][]uint8) bool {
+	for _, row := range world {
+		for _, cell := range row {
+			if cell != 0 && cell != 255 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// SparseIndices returns the index y*width+x of every live cell, in order, if listing them
+// is smaller than packing the world one bit per cell; otherwise it returns false without
+// scanning further than it has to.
+func SparseIndices(world [][]uint8) ([]int, bool) {
+	cells := 0
+	if len(world) > 0 {
+		cells = len(world) * len(world[0])
+	}
+	return liveIndices(world, (cells+63)/64*8/sparseCellBytes)
+}
+
+// liveIndices returns the index y*width+x of every live cell, or false as soon as there
+// are more than limit of them.
+func liveIndices(world [][]uint8, limit int) ([]int, bool) {
+	var indices []int
+	i := 0
+	for _, row := range world {
+		for _, cell := range row {
+			if cell != 0 {
+				if len(indices) == limit {
+					return nil, false
+				}
+				indices = append(indices, i)
+			}
+			i++
+		}
+	}
+	return indices, true
+}
+
+// GobDecode reads the format written by GobEncode.
+func (w *World) GobDecode(data []byte) error {
+	if len(data) == 0 {
+		return fmt.Errorf("packed world: empty")
+	}
+	encoding := data[0]
+	data = data[1:]
+	width, n := binary.Uvarint(data)
+	if n <= 0 {
+		return fmt.Errorf("packed world: bad width")
+	}
+	data = data[n:]
+	height, n := binary.Uvarint(data)
+	if n <= 0 {
+		return fmt.Errorf("packed world: bad height")
+	}
+	data = data[n:]
+
+	switch encoding {
+	case encodingPacked:
+		words := (width*height + 63) / 64
+		if uint64(len(data)) != 8*words {
+			return fmt.Errorf("packed world: %dx%d needs %d bytes of cells, got %d", width, height, 8*words, len(data))
+		}
+		p := PackedWorld{Width: int(width), Height: int(height), Bits: make([]uint64, words)}
+		for i := range p.Bits {
+			p.Bits[i] = binary.LittleEndian.Uint64(data[8*i:])
+		}
+		*w = p.Unpack()
+	case encodingSparse:
+		count, n := binary.Uvarint(data)
+		if n <= 0 || count > width*height {
+			return fmt.Errorf("packed world: bad live cell count")
+		}
+		data = data[n:]
+		indices := make([]int, count)
+		i := uint64(0)
+		for k := range indices {
+			gap, n := binary.Uvarint(data)
+			if n <= 0 {
+				return fmt.Errorf("packed world: truncated live cells")
+			}
+			data = data[n:]
+			if i += gap; i >= width*height {
+				return fmt.Errorf("packed world: live cell %d outside %dx%d", i, width, height)
+			}
+			indices[k] = int(i)
+		}
+		*w = FromIndices(int(width), int(height), indices)
+	case encodingBytes:
+		if uint64(len(data)) != width*height {
+			return fmt.Errorf("packed world: %dx%d needs %d bytes of cells, got %d", width, height, width*height, len(data))
+		}
+		world := NewWorld(int(width), int(height))
+		cells, _ := Flat(world)
+		copy(cells, data)
+		*w = world
+	case encodingRLE:
+		world, ok := decodeRLE(int(width), int(height), data)
+		if !ok {
+			return fmt.Errorf("packed world: runs do not cover %dx%d", width, height)
+		}
+		*w = world
+	default:
+		return fmt.Errorf("packed world: unknown encoding %d", encoding)
+	}
+	return nil
+}
+
+// FromIndices builds a width×height world whose live cells are at the given
+// indices y*width+x, and nothing else.
+func FromIndices(width, height int, indices []int) [][]uint8 {
+	world := NewWorld(width, height)
+	for _, i := range indices {
+		world[i/width][i%width] = 255
+	}
+	return world
+}
@@ -0,0 +1,131 @@
+package util
+
+import (
+	"bytes"
+	"encoding/gob"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// testSizes are widths and heights around the 8 and 64 cell boundaries of bytes and packed
+// words, so rows start part way through a word.
+var testSizes = [][2]int{{1, 1}, {7, 3}, {8, 8}, {9, 5}, {63, 2}, {64, 64}, {65, 3}, {100, 7}, {130, 17}}
+
+// randomWorld returns a width×height world of 0 / 255 bytes with about density of them alive.
+func randomWorld(r *rand.Rand, width, height int, density float64) [][]uint8 {
+	world := NewWorld(width, height)
+	for y := range world {
+		for x := range world[y] {
+			if r.Float64() < density {
+				world[y][x] = 255
+			}
+		}
+	}
+	return world
+}
+
+// testWorlds returns worlds of every test size: empty, full, sparse and dense.
+func testWorlds() [][][]uint8 {
+	r := rand.New(rand.NewSource(1))
+	var worlds [][][]uint8
+	for _, size := range testSizes {
+		for _, density := range []float64{0, 1, 0.02, 0.5} {
+			worlds = append(worlds, randomWorld(r, size[0], size[1], density))
+		}
+	}
+	return worlds
+}
+
+func equalWorlds(a, b [][]uint8) bool {
+	return slices.EqualFunc(a, b, func(x, y []uint8) bool { return slices.Equal(x, y) })
+}
+
+// TestPack tests that cell (x, y) is bit i%64 of word i/64, i = y*width+x, and that
+// unpacking gives back the world.
+func TestPack(t *testing.T) {
+	for _, world := range testWorlds() {
+		p := Pack(world)
+		if p.Height != len(world) || p.Width != len(world[0]) || len(p.Bits) != (p.Width*p.Height+63)/64 {
+			t.Fatalf("%dx%d packed as %dx%d in %d words", len(world[0]), len(world), p.Width, p.Height, len(p.Bits))
+		}
+		for y := range world {
+			for x := range world[y] {
+				i := y*p.Width + x
+				if alive := p.Bits[i/64]>>(i%64)&1 == 1; alive != (world[y][x] != 0) {
+					t.Fatalf("%dx%d: cell (%d, %d) packed as %v", p.Width, p.Height, x, y, alive)
+				}
+			}
+		}
+		if !equalWorlds(p.Unpack(), world) {
+			t.Errorf("%dx%d: unpacked world differs", p.Width, p.Height)
+		}
+	}
+}
+
+// TestCodecs tests that a world sent with any one codec, or with whichever of them all is
+// smallest, decodes to the world it was, and that a world with dying cells is sent whole
+// whatever the codecs.
+func TestCodecs(t *testing.T) {
+	codecSets := [][]Codec{{CodecRaw}, {CodecRaw, CodecPacked}, {CodecRaw, CodecSparse}, {CodecRaw, CodecRLE}, LegacyCodecs, Codecs}
+	dying := NewWorld(9, 3)
+	dying[0][0], dying[1][4], dying[2][8] = 255, 128, 64
+	worlds := append(testWorlds(), dying)
+	for _, codecs := range codecSets {
+		for _, world := range worlds {
+			var got World
+			if err := got.GobDecode(World(world).encode(codecs)); err != nil {
+				t.Fatalf("%v %dx%d: %v", codecs, len(world[0]), len(world), err)
+			}
+			if !equalWorlds(got, world) {
+				t.Errorf("%v %dx%d: decoded world differs", codecs, len(world[0]), len(world))
+			}
+		}
+	}
+}
+
+// TestWorldGob tests that World and CodedWorld fields go through encoding/gob, as they do
+// over net/rpc.
+func TestWorldGob(t *testing.T) {
+	for _, world := range testWorlds() {
+		var buf bytes.Buffer
+		sent := struct {
+			Plain World
+			Coded CodedWorld
+		}{world, CodedWorld{World: world, Codecs: Codecs}}
+		if err := gob.NewEncoder(&buf).Encode(sent); err != nil {
+			t.Fatal(err)
+		}
+		var got struct{ Plain, Coded World }
+		if err := gob.NewDecoder(&buf).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		if !equalWorlds(got.Plain, world) || !equalWorlds(got.Coded, world) {
+			t.Errorf("%dx%d: world differs after gob", len(world[0]), len(world))
+		}
+	}
+}
+
+// TestWorldDecodeMalformed tests that truncated or inconsistent encodings are errors rather
+// than panics or wrong worlds.
+func TestWorldDecodeMalformed(t *testing.T) {
+	world := randomWorld(rand.New(rand.NewSource(2)), 65, 3, 0.5)
+	for _, codecs := range [][]Codec{{CodecRaw}, {CodecRaw, CodecPacked}, {CodecRaw, CodecRLE}} {
+		data := World(world).encode(codecs)
+		var got World
+		if err := got.GobDecode(data[:len(data)-1]); err == nil {
+			t.Errorf("%v: decoded a truncated world", codecs)
+		}
+	}
+	for name, data := range map[string][]byte{
+		"empty":            nil,
+		"unknown encoding": {9, 1, 1},
+		"no height":        {encodingPacked, 1},
+		"sparse overflow":  {encodingSparse, 2, 2, 1, 4},
+	} {
+		var got World
+		if err := got.GobDecode(data); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
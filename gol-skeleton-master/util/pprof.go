@@ -0,0 +1,32 @@
+package util
+
+import (
+	"net"
+	"net/http"
+	"net/http/pprof"
+)
+
+// StartPprof serves the net/http/pprof handlers under /debug/pprof/ on addr (e.g. ":6060"),
+// so CPU and heap profiles can be taken from a running broker or worker with
+//
+//	go tool pprof http://host:6060/debug/pprof/profile
+//
+// The listener is opened before StartPprof returns, so a bad or busy address is reported
+// to the caller; requests are then served in the background. The handlers get their own
+// mux rather than http.DefaultServeMux.
+func StartPprof(addr string) (net.Addr, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	go func() {
+		_ = http.Serve(listener, mux)
+	}()
+	return listener.Addr(), nil
+}
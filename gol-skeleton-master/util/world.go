@@ -0,0 +1,90 @@
+package util
+
+import "sync"
+
+// Worlds are laid out flat: the cells of a width×height world are one []uint8 of
+// width*height bytes, row after row, so that cell (x, y) is at y*width+x, and the rows of
+// the [][]uint8 everything passes around are views into it. Make worlds with NewWorld (or
+// Rows, for cells already in one slice) to keep them that way; Flat gives the flat slice
+// back to loops over every cell.
+
+// worldBuffers holds the cell buffers of worlds given back with FreeWorld, as *[]uint8.
+var worldBuffers sync.Pool
+
+// NewWorld returns a height×width world of dead cells whose rows share one buffer, reusing
+// one given back with FreeWorld when it is big enough. Code that makes a new world every
+// turn and knows when it is done with the old one can then keep the garbage collector out
+// of long runs. A row's capacity runs on into the rows after it, so rows must not be
+// appended to.
+func NewWorld(width, height int) [][]uint8 {
+	n := width * height
+	var buf []uint8
+	if p, ok := worldBuffers.Get().(*[]uint8); ok && cap(*p) >= n {
+		buf = (*p)[:n]
+		clear(buf)
+	} else {
+		buf = make([]uint8, n)
+	}
+	return Rows(buf, width, height)
+}
+
+// Rows returns a height×width world whose rows are views into cells, which must hold at
+// least width*height of them.
+func Rows(cells []uint8, width, height int) [][]uint8 {
+	world := make([][]uint8, height)
+	for y := range world {
+		world[y] = cells[y*width : (y+1)*width]
+	}
+	return world
+}
+
+// Flat returns the cells of world row after row, cell (x, y) at y*width+x, if its rows lie
+// back to back in one buffer, as they do in worlds made by NewWorld and Rows. Loops over
+// every cell can then run over the one slice, falling back to the rows otherwise.
+func Flat(world [][]uint8) ([]uint8, bool) {
+	if len(world) == 0 {
+		return nil, true
+	}
+	width := len(world[0])
+	n := width * len(world)
+	if cap(world[0]) < n {
+		return nil, false
+	}
+	cells := world[0][:n]
+	if width == 0 {
+		return cells, true
+	}
+	for y, row := range world {
+		if len(row) != width || &row[0] != &cells[y*width] {
+			return nil, false
+		}
+	}
+	return cells, true
+}
+
+// CopyWorld returns a copy of world made by NewWorld.
+func CopyWorld(world [][]uint8) [][]uint8 {
+	if len(world) == 0 {
+		return world
+	}
+	dst := NewWorld(len(world[0]), len(world))
+	if cells, ok := Flat(world); ok {
+		copy(dst[0][:len(cells)], cells)
+		return dst
+	}
+	for y, row := range world {
+		copy(dst[y], row)
+	}
+	return dst
+}
+
+// FreeWorld gives the buffer of a world made by NewWorld back for later ones to reuse.
+// Neither world nor any of its rows may be used afterwards. Worlds made some other way
+// are fine too; only their first row is reused.
+func FreeWorld(world [][]uint8) {
+	if len(world) == 0 || cap(world[0]) == 0 {
+		return
+	}
+	buf := world[0][:cap(world[0])]
+	worldBuffers.Put(&buf)
+}
@@ -0,0 +1,183 @@
+package main
+
+import "uk.ac.bris.cs/gameoflife/util"
+
+// 位运算内核：一行细胞压成 []uint64，第 x 个细胞是第 x/64 个字的第 x%64 位（和 util.PackedWorld 同样的位序）。
+// 8 个邻居各是一个移位后的字，用半加器逐位累加成 3 位计数，一次算 64 个细胞，
+// 不再对每个细胞做 dy/dx 两重循环和取模环绕
+
+// packRowInto：把一行压成活细胞（255）的位图写进调用方给的全零的 bits（(len(row)+63)/64 个字），
+// 将死的灰色细胞算死的，超出 len(row) 的位保持 0（east 依赖这一点）
+func packRowInto(bits []uint64, row []uint8) {
+	for x, cell := range row {
+		if cell == 255 {
+			bits[x>>6] |= 1 << (x & 63)
+		}
+	}
+}
+
+// unpackRow：把位图里从第 from 位开始的 len(dst) 位展开成 0/255 写进 dst
+func unpackRow(dst []uint8, bits []uint64, from int) {
+	for x := range dst {
+		i := from + x
+		if bits[i>>6]>>(i&63)&1 != 0 {
+			dst[x] = 255
+		} else {
+			dst[x] = 0
+		}
+	}
+}
+
+// decayRow：Generations 规则下修正 unpackRow 的结果。old 是这一行原来的细胞：
+// 将死的细胞不会出生，只会继续衰减；活细胞没存活就开始衰减，不是直接死掉
+func decayRow(dst, old []uint8, rule util.Rule) {
+	for x, cell := range old {
+		if cell != 0 && (cell != 255 || dst[x] == 0) {
+			dst[x] = rule.Decay(cell)
+		}
+	}
+}
+
+// bit：位图 r 的第 x 位
+func bit(r []uint64, x int) uint64 {
+	return r[x>>6] >> (x & 63) & 1
+}
+
+// west：第 k 个字里每个细胞左边那个邻居（第 x-1 位）组成的字，第 0 位左边看到什么由 boundary 决定
+func west(r []uint64, k, width int, boundary util.Boundary) uint64 {
+	v := r[k] << 1
+	if k > 0 {
+		v |= r[k-1] >> 63
+	} else if x, ok := boundary.Neighbour(-1, width); ok {
+		v |= bit(r, x)
+	}
+	return v
+}
+
+// east：第 k 个字里每个细胞右边那个邻居（第 x+1 位）组成的字，最后一位右边看到什么由 boundary 决定
+func east(r []uint64, k, width int, boundary util.Boundary) uint64 {
+	v := r[k] >> 1
+	if k+1 < len(r) {
+		v |= r[k+1] << 63
+	}
+	if k == (width-1)>>6 {
+		if x, ok := boundary.Neighbour(width, width); ok {
+			v |= bit(r, x) << ((width - 1) & 63)
+		}
+	}
+	return v
+}
+
+// add：给每一位的 3 位计数 (s0, s1, s2) 加上 x 对应位。s2 只置不清，计数 >= 4 就一直是 1，正好够判断 2 和 3
+func add(s0, s1, s2, x uint64) (uint64, uint64, uint64) {
+	c0 := s0 & x
+	s0 ^= x
+	c1 := s1 & c0
+	s1 ^= c0
+	return s0, s1, s2 | c1
+}
+
+// add4：和 add 一样，但是 4 位计数 (s0, s1, s2, s3) 不饱和，0 到 8 都能分清，任意规则都用得上
+func add4(s0, s1, s2, s3, x uint64) (uint64, uint64, uint64, uint64) {
+	c0 := s0 & x
+	s0 ^= x
+	c1 := s1 & c0
+	s1 ^= c0
+	c2 := s2 & c1
+	s2 ^= c1
+	return s0, s1, s2, s3 | c2
+}
+
+// count：计数 (s0, s1, s2, s3) 正好等于 n 的那些位
+func count(n int, s0, s1, s2, s3 uint64) uint64 {
+	m := ^uint64(0)
+	for i, s := range [4]uint64{s0, s1, s2, s3} {
+		if n>>i&1 != 0 {
+			m &= s
+		} else {
+			m &^= s
+		}
+	}
+	return m
+}
+
+// stepRow：由上中下三行位图按 rule 算出中间一行的下一代写进 dst。
+// 行的左右两端按 boundary 处理（tile 的左右 halo 已经在行里，用 util.BoundaryDead）
+func stepRow(dst, above, mid, below []uint64, width int, boundary util.Boundary, rule util.Rule) {
+	if rule == util.Conway {
+		stepConway(dst, above, mid, below, width, boundary)
+	} else {
+		stepRule(dst, above, mid, below, width, boundary, rule)
+	}
+	// 最后一个字超出 width 的位清零，保持 packRowInto 的约定
+	if tail := width & 63; tail != 0 {
+		dst[len(dst)-1] &= 1<<tail - 1
+	}
+}
+
+// stepConway：B3/S23 的快路径，3 位饱和计数就够
+func stepConway(dst, above, mid, below []uint64, width int, boundary util.Boundary) {
+	for k := range dst {
+		var s0, s1, s2 uint64
+		s0, s1, s2 = add(s0, s1, s2, west(above, k, width, boundary))
+		s0, s1, s2 = add(s0, s1, s2, above[k])
+		s0, s1, s2 = add(s0, s1, s2, east(above, k, width, boundary))
+		s0, s1, s2 = add(s0, s1, s2, west(mid, k, width, boundary))
+		s0, s1, s2 = add(s0, s1, s2, east(mid, k, width, boundary))
+		s0, s1, s2 = add(s0, s1, s2, west(below, k, width, boundary))
+		s0, s1, s2 = add(s0, s1, s2, below[k])
+		s0, s1, s2 = add(s0, s1, s2, east(below, k, width, boundary))
+		// 下一代活着：邻居正好 3 个，或者正好 2 个且自己活着
+		dst[k] = s1 &^ s2 & (s0 | mid[k])
+	}
+}
+
+// stepRule：任意 B/S 规则，用 4 位计数分出 0 到 8 个邻居，再按 rule 挑出出生和存活的位
+func stepRule(dst, above, mid, below []uint64, width int, boundary util.Boundary, rule util.Rule) {
+	for k := range dst {
+		var s0, s1, s2, s3 uint64
+		s0, s1, s2, s3 = add4(s0, s1, s2, s3, west(above, k, width, boundary))
+		s0, s1, s2, s3 = add4(s0, s1, s2, s3, above[k])
+		s0, s1, s2, s3 = add4(s0, s1, s2, s3, east(above, k, width, boundary))
+		s0, s1, s2, s3 = add4(s0, s1, s2, s3, west(mid, k, width, boundary))
+		s0, s1, s2, s3 = add4(s0, s1, s2, s3, east(mid, k, width, boundary))
+		s0, s1, s2, s3 = add4(s0, s1, s2, s3, west(below, k, width, boundary))
+		s0, s1, s2, s3 = add4(s0, s1, s2, s3, below[k])
+		s0, s1, s2, s3 = add4(s0, s1, s2, s3, east(below, k, width, boundary))
+
+		var next uint64
+		for n := 0; n <= 8; n++ {
+			if (rule.Birth|rule.Survival)>>n&1 == 0 {
+				continue
+			}
+			c := count(n, s0, s1, s2, s3)
+			if rule.Birth>>n&1 != 0 {
+				next |= c &^ mid[k]
+			}
+			if rule.Survival>>n&1 != 0 {
+				next |= c & mid[k]
+			}
+		}
+		dst[k] = next
+	}
+}
+
+// packRows：并行把每一行压成位图。所有行的位图连在一个 []uint64 里，第 y 行是第 y*words 到 (y+1)*words 个字，
+// 返回的每一行只是其中的一段，和 util.NewWorld 的世界一样不会一行一块地分配
+func packRows(rows [][]uint8) [][]uint64 {
+	words := 0
+	if len(rows) > 0 {
+		words = (len(rows[0]) + 63) / 64
+	}
+	buf := make([]uint64, words*len(rows))
+	packed := make([][]uint64, len(rows))
+	for y := range packed {
+		packed[y] = buf[y*words : (y+1)*words : (y+1)*words]
+	}
+	splitRows(len(rows), func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			packRowInto(packed[y], rows[y])
+		}
+	})
+	return packed
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/engine"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// testWidths are around the 64 cells of a packed word, so the last word of a row is partly
+// used and the edges wrap from one word into another.
+var testWidths = []int{1, 2, 3, 7, 63, 64, 65, 100, 127, 128, 129}
+
+// testRules are Conway's rule, which has its own kernel, another life-like rule and a
+// Generations rule with dying cells.
+var testRules = []string{"B3/S23", "B36/S23", "B2/S345/C4"}
+
+// soup returns a width×height world with about half its cells alive and, under a
+// Generations rule, a few evolutions in so that there are dying cells too.
+func soup(r *rand.Rand, width, height int, rule util.Rule) [][]uint8 {
+	world := util.NewWorld(width, height)
+	for y := range world {
+		for x := range world[y] {
+			if r.Intn(2) == 0 {
+				world[y][x] = 255
+			}
+		}
+	}
+	if rule.Generations() {
+		for range 3 {
+			world = engine.Evolve(world, engine.BoundaryTorus, rule, 1)
+		}
+	}
+	return world
+}
+
+// flips lists the cells that differ between old and next, as the worker reports them.
+func flips(old, next [][]uint8, x0, y0 int) []util.Cell {
+	var cells []util.Cell
+	for y := range next {
+		for x := range next[y] {
+			if next[y][x] != old[y][x] {
+				cells = append(cells, util.Cell{X: x0 + x, Y: y0 + y})
+			}
+		}
+	}
+	return cells
+}
+
+func equalRows(a, b [][]uint8) bool {
+	return slices.EqualFunc(a, b, func(x, y []uint8) bool { return slices.Equal(x, y) })
+}
+
+// TestNextRows tests that the bit-sliced kernel evolves a band of rows, with the halo rows
+// the broker sends above and below it, to the same cells as the byte-per-cell engine does the
+// whole world, for every boundary and rule, on both one goroutine and several.
+func TestNextRows(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	defer func(n int) { threads = n }(threads)
+	for _, n := range []int{1, 4} {
+		threads = n
+		for _, name := range testRules {
+			rule, _ := util.ParseRule(name)
+			for _, boundary := range util.Boundaries() {
+				for _, width := range testWidths {
+					for _, height := range []int{1, 2, 9} {
+						world := soup(r, width, height, rule)
+						want := engine.Evolve(world, boundary, rule, 1)
+						for startY := 0; startY < height; startY += 4 {
+							endY := min(startY+4, height)
+							got, flipped := nextRows(haloBand(world, startY, endY, boundary), startY, endY-startY, boundary, rule)
+							if !equalRows(got, want[startY:endY]) {
+								t.Fatalf("%s %s %dx%d rows %d-%d: next generation differs", name, boundary, width, height, startY, endY)
+							}
+							if !slices.Equal(flipped, flips(world[startY:endY], want[startY:endY], 0, startY)) {
+								t.Fatalf("%s %s %dx%d rows %d-%d: flipped cells differ", name, boundary, width, height, startY, endY)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// haloBand returns rows [startY, endY) of world with the rows seen above and below them under
+// boundary, as the broker sends a worker its part.
+func haloBand(world [][]uint8, startY, endY int, boundary util.Boundary) [][]uint8 {
+	part := make([][]uint8, 0, endY-startY+2)
+	for y := startY - 1; y <= endY; y++ {
+		row := make([]uint8, len(world[0]))
+		if j, ok := boundary.Neighbour(y, len(world)); ok {
+			copy(row, world[j])
+		}
+		part = append(part, row)
+	}
+	return part
+}
+
+// TestNextTile tests that a tile, sent with a ring of halo cells around it, evolves to the
+// same cells as that part of the whole world does, wherever it sits, including the corners
+// whose halo wraps around both edges.
+func TestNextTile(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for _, name := range testRules {
+		rule, _ := util.ParseRule(name)
+		for _, boundary := range util.Boundaries() {
+			for _, size := range [][2]int{{7, 5}, {65, 9}, {130, 3}} {
+				width, height := size[0], size[1]
+				world := soup(r, width, height, rule)
+				want := engine.Evolve(world, boundary, rule, 1)
+				for _, tile := range [][4]int{{0, 0, width, height}, {0, 0, 1, 1}, {width - 1, height - 1, width, height}, {1, 1, width - 1, height}} {
+					left, top, right, bottom := tile[0], tile[1], tile[2], tile[3]
+					cells := make([][]uint8, 0, bottom-top+2)
+					for y := top - 1; y <= bottom; y++ {
+						row := make([]uint8, 0, right-left+2)
+						for x := left - 1; x <= right; x++ {
+							row = append(row, boundary.Cell(world, x, y))
+						}
+						cells = append(cells, row)
+					}
+					got, flipped := nextTile(cells, left, top, right-left, bottom-top, rule)
+					wantTile := make([][]uint8, 0, bottom-top)
+					oldTile := make([][]uint8, 0, bottom-top)
+					for y := top; y < bottom; y++ {
+						wantTile = append(wantTile, want[y][left:right])
+						oldTile = append(oldTile, world[y][left:right])
+					}
+					if !equalRows(got, wantTile) {
+						t.Fatalf("%s %s %dx%d tile %v: next generation differs", name, boundary, width, height, tile)
+					}
+					if !slices.Equal(flipped, flips(oldTile, wantTile, left, top)) {
+						t.Fatalf("%s %s %dx%d tile %v: flipped cells differ", name, boundary, width, height, tile)
+					}
+				}
+			}
+		}
+	}
+}
+
+// TestPackRow tests that packing a row and unpacking it from any offset gives back its live
+// cells, with dying cells packed as dead and the bits past the end of the row left clear.
+func TestPackRow(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	for _, width := range testWidths {
+		row := make([]uint8, width)
+		for x := range row {
+			row[x] = []uint8{0, 255, 128}[r.Intn(3)]
+		}
+		bits := make([]uint64, (width+63)/64)
+		packRowInto(bits, row)
+		if tail := width & 63; tail != 0 && bits[len(bits)-1]>>tail != 0 {
+			t.Fatalf("width %d: bits set past the end of the row", width)
+		}
+		for from := 0; from < width; from++ {
+			got := make([]uint8, width-from)
+			unpackRow(got, bits, from)
+			for x, cell := range got {
+				if want := row[from+x] == 255; (cell == 255) != want || (cell != 0 && cell != 255) {
+					t.Fatalf("width %d from %d: cell %d unpacked as %d, was %d", width, from, x, cell, row[from+x])
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/discovery"
+)
+
+// 服务发现：worker 不用知道 broker 在哪，只要把自己登记到 Consul，broker 盯着同一个前缀就会来连。
+// 登记挂在一个带 TTL 的 session 上，worker 定期续期；进程挂了续不上，Consul 会自动删掉这条登记
+
+// discoveryTTL：session 多久没续期就失效，每 discoveryTTL/3 续一次
+const discoveryTTL = 15 * time.Second
+
+// announce：把 e 登记到 Consul 并一直续期，失败按指数退避重新登记，直到 Shutdown 或者排空时注销
+func announce(c *discovery.Consul, e discovery.Entry) {
+	backoff := registerBackoffMin
+	for {
+		lease, err := c.Register(e, discoveryTTL)
+		if err != nil {
+			logger.Warn("register with discovery failed, retrying", "discovery", c, "err", err, "retry_in", backoff)
+			if !sleep(backoff) {
+				return
+			}
+			backoff = min(2*backoff, registerBackoffMax)
+			continue
+		}
+		backoff = registerBackoffMin
+		logger.Info("registered with discovery", "discovery", c, "address", e.Address, "score", e.Score)
+
+		for sleep(discoveryTTL / 3) {
+			if err = lease.Renew(); err != nil {
+				logger.Warn("renew discovery registration failed, registering again", "err", err)
+				break
+			}
+		}
+		if err == nil {
+			// Shutdown / 排空：马上注销，不用等 TTL 过期
+			lease.Release()
+			return
+		}
+	}
+}
+
+// localIP：连到 host 时本机用的地址，也就是别人能连回来的地址
+func localIP(host string) (string, error) {
+	conn, err := net.Dial("tcp", host)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	return conn.LocalAddr().(*net.TCPAddr).IP.String(), nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// 排空：滚动重启时 worker 不能直接退出，否则手上的任务失败，broker 只能重算或者让这一回合失败。
+// 收到 Drain 或者 SIGTERM / SIGINT 之后先从 broker（和 Consul）注销，broker 不再派新任务；
+// 等正在算的 ProcessPart / ProcessTile 都返回，再和 Shutdown 一样关掉监听退出。
+// halo 模式下 broker 在注销时把行段收回去分给别的 worker，之后才返回
+
+// drainPoll：等正在算的任务时多久看一次
+const drainPoll = 10 * time.Millisecond
+
+var (
+	draining  = make(chan struct{}) // 开始排空时 close，之后不再重新注册
+	drainOnce sync.Once
+
+	// drainTimeout：排空最多等多久，超时就不管还没算完的任务直接退出，-drain-timeout 设置
+	drainTimeout = 30 * time.Second
+
+	// deregister：向 broker 注销自己，main 根据 -broker 设置，没有 broker 时为 nil
+	deregister func() error
+)
+
+// Drain：注销之后等手上的任务算完再退出，立即返回，排空在后台进行
+func (w *Worker) Drain(_ struct{}, reply *bool) error {
+	logger.Info("drain requested")
+	go w.drain(drainTimeout)
+	*reply = true
+	return nil
+}
+
+// drain：注销，等 inflight 归零（最多 timeout），然后关闭 worker。只有第一次调用生效
+func (w *Worker) drain(timeout time.Duration) {
+	drainOnce.Do(func() {
+		close(draining)
+		if deregister != nil {
+			if err := deregister(); err != nil {
+				logger.Warn("deregister from broker failed", "err", err)
+			} else {
+				logger.Info("deregistered from broker")
+			}
+		}
+
+		// 注销之前已经发出的任务可能还在路上，稍等一下再开始看
+		time.Sleep(shutdownGrace)
+		deadline := time.Now().Add(timeout)
+		for w.inflight.Load() > 0 {
+			if time.Now().After(deadline) {
+				logger.Warn("drain timed out, exiting with tasks in flight", "tasks", w.inflight.Load(), "timeout", timeout)
+				break
+			}
+			time.Sleep(drainPoll)
+		}
+		logger.Info("drained, shutting down")
+		shutdownOnce.Do(func() { close(shutdown) })
+	})
+}
+
+// drainOnSignal：SIGTERM / SIGINT 时排空；排空过程中再收到一次就直接退出
+func (w *Worker) drainOnSignal() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+	go func() {
+		sig := <-signals
+		logger.Info("signal received, draining", "signal", sig)
+		go w.drain(drainTimeout)
+		sig = <-signals
+		logger.Warn("second signal received, exiting now", "signal", sig)
+		os.Exit(1)
+	}()
+}
+
+// task：ProcessPart / ProcessTile 开始时调用，返回的函数在结束时调用，排空据此等它们算完
+func (w *Worker) task() func() {
+	w.inflight.Add(1)
+	return func() { w.inflight.Add(-1) }
+}
+
+// ready：/readyz 的判断（见 -health），排空或者关闭之后就不再接新任务
+func ready() error {
+	select {
+	case <-shutdown:
+		return errors.New("worker shutting down")
+	case <-draining:
+		return errors.New("worker draining")
+	default:
+		return nil
+	}
+}
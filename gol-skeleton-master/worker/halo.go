@@ -0,0 +1,257 @@
+package main
+
+import (
+	"fmt"
+
+	"uk.ac.bris.cs/gameoflife/transport"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// halo 模式：worker 长期持有自己的一段行，每回合只和上下两个邻居 worker 交换边界行，
+// broker 只负责建立拓扑、发回合指令，需要完整世界时再 FetchBand 收集
+
+// 以下类型和 broker 中的同名类型保持一致
+type BandSetup struct {
+	StartY, EndY int
+	Rows         util.World    // [StartY, EndY) 的初始状态
+	Above, Below string        // 负责 StartY-1 行 / EndY 行的 worker 地址，空字符串表示就是自己
+	Height       int           // 整个世界的行数，第一段 / 最后一段据此判断自己在世界边上
+	Boundary     util.Boundary // 世界边界之外看到什么；不是环绕时边上的段不向邻居取 halo
+	Rule         string        // B/S 记法的规则，空字符串表示 B3/S23
+}
+
+type EdgeArgs struct {
+	Turn int
+	Top  bool // true 取本段第一行，false 取最后一行
+}
+
+type StepArgs struct {
+	Turn int // 推进之前的回合数，必须和 worker 当前回合一致
+}
+
+type StepReply struct {
+	Flipped    []util.Cell // 本段翻转的细胞（全局坐标）
+	AliveCount int         // 推进之后本段的存活细胞数
+}
+
+// band：worker 持有的行段
+type band struct {
+	startY, endY int
+	height       int
+	boundary     util.Boundary
+	rule         util.Rule
+	rows         [][]uint8
+	turn         int
+
+	// 上一回合的首尾行：邻居可能已经先算完下一回合，这时还要能拿到旧的边界
+	prevTurn          int
+	prevTop, prevBott []uint8
+
+	aboveAddr, belowAddr string
+	above, below         transport.Client
+}
+
+// SetupBand：broker 开始 halo 模式模拟时调用，分配行段和邻居
+func (w *Worker) SetupBand(s BandSetup, reply *int) error {
+	if s.EndY-s.StartY <= 0 || len(s.Rows) != s.EndY-s.StartY {
+		return fmt.Errorf("invalid band [%d, %d) with %d rows", s.StartY, s.EndY, len(s.Rows))
+	}
+	rule, err := util.ParseRule(s.Rule)
+	if err != nil {
+		return fmt.Errorf("invalid band: %v", err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.closeBand()
+	w.band = &band{
+		startY:    s.StartY,
+		endY:      s.EndY,
+		height:    s.Height,
+		boundary:  s.Boundary,
+		rule:      rule,
+		rows:      s.Rows,
+		prevTurn:  -1,
+		aboveAddr: s.Above,
+		belowAddr: s.Below,
+	}
+	*reply = countAliveRows(s.Rows)
+	logger.Info("band assigned", "start_y", s.StartY, "end_y", s.EndY, "above", s.Above, "below", s.Below)
+	return nil
+}
+
+// GetEdge：邻居 worker 来取 Turn 回合时本段的首行 / 末行
+func (w *Worker) GetEdge(args EdgeArgs, reply *[]uint8) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	b := w.band
+	if b == nil {
+		return fmt.Errorf("no band assigned")
+	}
+	switch args.Turn {
+	case b.turn:
+		if args.Top {
+			*reply = b.rows[0]
+		} else {
+			*reply = b.rows[len(b.rows)-1]
+		}
+	case b.prevTurn:
+		if args.Top {
+			*reply = b.prevTop
+		} else {
+			*reply = b.prevBott
+		}
+	default:
+		return fmt.Errorf("edge for turn %d not available (at turn %d)", args.Turn, b.turn)
+	}
+	return nil
+}
+
+// Step：向邻居取 halo 行，推进一回合，返回本段翻转的细胞
+func (w *Worker) Step(args StepArgs, reply *StepReply) error {
+	w.mu.Lock()
+	b := w.band
+	if b == nil {
+		w.mu.Unlock()
+		return fmt.Errorf("no band assigned")
+	}
+	if args.Turn != b.turn {
+		w.mu.Unlock()
+		return fmt.Errorf("step for turn %d but band is at turn %d", args.Turn, b.turn)
+	}
+	if err := b.dialNeighbours(); err != nil {
+		w.mu.Unlock()
+		return err
+	}
+	rows := b.rows
+	above, below := b.above, b.below
+	w.mu.Unlock()
+
+	// 取 halo 时不能持有锁：邻居也会同时来取我们的边界
+	top, ok := b.edgeRow(rows, b.startY-1)
+	if !ok {
+		var err error
+		if top, err = fetchEdge(above, rows[len(rows)-1], args.Turn, false); err != nil {
+			return fmt.Errorf("fetch halo from above: %v", err)
+		}
+	}
+	bottom, ok := b.edgeRow(rows, b.endY)
+	if !ok {
+		var err error
+		if bottom, err = fetchEdge(below, rows[0], args.Turn, true); err != nil {
+			return fmt.Errorf("fetch halo from below: %v", err)
+		}
+	}
+
+	height := len(rows)
+	worldPart := make([][]uint8, 0, height+2)
+	worldPart = append(worldPart, top)
+	worldPart = append(worldPart, rows...)
+	worldPart = append(worldPart, bottom)
+	newRows, flipped := nextRows(worldPart, b.startY, height, b.boundary, b.rule)
+
+	alive := 0
+	for y := range newRows {
+		for x := range newRows[y] {
+			if newRows[y][x] == 255 {
+				alive++
+			}
+		}
+	}
+
+	w.mu.Lock()
+	b.prevTurn = b.turn
+	b.prevTop = rows[0]
+	b.prevBott = rows[len(rows)-1]
+	b.rows = newRows
+	b.turn++
+	w.mu.Unlock()
+
+	reply.Flipped = flipped
+	reply.AliveCount = alive
+	return nil
+}
+
+// FetchBand：broker 收集完整世界时调用，返回本段当前所有行
+func (w *Worker) FetchBand(_ struct{}, reply *util.World) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.band == nil {
+		return fmt.Errorf("no band assigned")
+	}
+	*reply = w.band.rows
+	return nil
+}
+
+// edgeRow：y 在世界之外（第一段的上一行 / 最后一段的下一行）且边界不环绕时，
+// 不用问邻居就知道那一行：dead 是全死的一行，mirror 是本段边上那一行自己。ok 为 false 表示要向邻居取
+// rows 是调用方不持锁时拿到的本段当前的行
+func (b *band) edgeRow(rows [][]uint8, y int) (row []uint8, ok bool) {
+	if b.boundary.Torus() || (y >= 0 && y < b.height) {
+		return nil, false
+	}
+	if _, alive := b.boundary.Neighbour(y, b.height); !alive {
+		return make([]uint8, len(rows[0])), true
+	}
+	if y < 0 {
+		return rows[0], true
+	}
+	return rows[len(rows)-1], true
+}
+
+// fetchEdge：client 为空表示邻居就是自己（只有一个 worker），直接用自己的行
+func fetchEdge(client transport.Client, own []uint8, turn int, top bool) ([]uint8, error) {
+	if client == nil {
+		return own, nil
+	}
+	var edge []uint8
+	err := client.Call("Worker.GetEdge", EdgeArgs{Turn: turn, Top: top}, &edge)
+	return edge, err
+}
+
+// dialNeighbours：第一次 Step 时连上邻居，之后复用连接
+func (b *band) dialNeighbours() error {
+	var err error
+	if b.aboveAddr != "" && b.above == nil {
+		if b.above, err = transport.DialOptions(b.aboveAddr, transport.Options{TCP: tcpOptions}); err != nil {
+			return fmt.Errorf("connect neighbour %s: %v", b.aboveAddr, err)
+		}
+	}
+	if b.belowAddr != "" && b.below == nil {
+		if b.belowAddr == b.aboveAddr {
+			b.below = b.above // 只有两个 worker 时上下邻居是同一个
+		} else if b.below, err = transport.DialOptions(b.belowAddr, transport.Options{TCP: tcpOptions}); err != nil {
+			return fmt.Errorf("connect neighbour %s: %v", b.belowAddr, err)
+		}
+	}
+	return nil
+}
+
+// closeBand：换新的行段前关闭旧的邻居连接
+func (w *Worker) closeBand() {
+	if w.band == nil {
+		return
+	}
+	if w.band.above != nil {
+		_ = w.band.above.Close()
+	}
+	if w.band.below != nil && w.band.below != w.band.above {
+		_ = w.band.below.Close()
+	}
+	w.band = nil
+}
+
+func countAliveRows(rows [][]uint8) int {
+	count := 0
+	for _, row := range rows {
+		for _, cell := range row {
+			if cell == 255 {
+				count++
+			}
+		}
+	}
+	return count
+}
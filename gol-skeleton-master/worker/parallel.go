@@ -0,0 +1,30 @@
+package main
+
+import (
+	"runtime"
+	"sync"
+)
+
+// threads：一次计算最多同时用几个 goroutine，默认 CPU 核数，-threads 可以改
+// 一台 16 核机器上只跑一个 worker 进程时，不至于只用上一个核
+var threads = runtime.NumCPU()
+
+// splitRows：把 [0, height) 尽量均匀地分成至多 threads 段，并行调用 fn(y0, y1)，全部算完才返回
+func splitRows(height int, fn func(y0, y1 int)) {
+	n := min(threads, height)
+	if n <= 1 {
+		fn(0, height)
+		return
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		y0, y1 := i*height/n, (i+1)*height/n
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn(y0, y1)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// 自动重连：broker 重启之后 workerList 是空的，也不会再来 ping 这个 worker。
+// worker 记下最近一次被 ping 的时间，太久没被 ping 就认为 broker 已经把自己忘了，重新注册；
+// 注册失败（比如 broker 还没起来）按指数退避重试，集群不用手动重启就能自己恢复
+
+const (
+	registerBackoffMin = time.Second
+	registerBackoffMax = 30 * time.Second
+)
+
+// lastPing：broker 最近一次 Ping 的时间（UnixNano）
+var lastPing atomic.Int64
+
+// keepRegistered：向 broker 注册，之后 silence 这么久没被 ping 就重新注册，直到 Shutdown 或者排空
+// silence 为 0 时注册成功一次就不再管
+func keepRegistered(register func() error, silence time.Duration) {
+	backoff := registerBackoffMin
+	for {
+		if err := register(); err != nil {
+			logger.Warn("register with broker failed, retrying", "err", err, "retry_in", backoff)
+			if !sleep(backoff) {
+				return
+			}
+			backoff = min(2*backoff, registerBackoffMax)
+			continue
+		}
+		backoff = registerBackoffMin
+		lastPing.Store(time.Now().UnixNano())
+		if silence <= 0 {
+			return
+		}
+
+		// 等到 broker 太久没来 ping
+		for time.Since(time.Unix(0, lastPing.Load())) < silence {
+			if !sleep(silence / 4) {
+				return
+			}
+		}
+		logger.Warn("no ping from broker, registering again", "silence", silence)
+	}
+}
+
+// sleep：睡 d，期间 Shutdown 或者开始排空了就返回 false
+func sleep(d time.Duration) bool {
+	select {
+	case <-shutdown:
+		return false
+	case <-draining:
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
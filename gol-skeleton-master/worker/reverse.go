@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/rpc"
+	"os"
+	"sync/atomic"
+
+	"uk.ac.bris.cs/gameoflife/transport"
+)
+
+// 反向注册：worker 在 NAT 或者没开入站端口的安全组后面时，broker 回拨不进来。
+// -reverse 时 worker 主动连 broker，在这条连接上提供 RPC 服务，再用连接的 id 注册，
+// broker 就用这条连接给它派任务、发心跳（见 transport/reverse.go）
+
+// reverseGen：当前反向连接的编号，旧连接断开时不用管
+var reverseGen atomic.Int64
+
+// reverseID：反向连接的 id，broker 那边的地址是 reverse://<id>
+func reverseID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "worker"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// serveReverse：连上 broker 并在这条连接上服务 srv。连接断了（broker 重启、网络断开）就清掉
+// lastPing，keepRegistered 马上重新连接注册，不用等心跳超时
+func serveReverse(srv *rpc.Server, brokerAddr, id string, opts transport.Options) (net.Conn, error) {
+	conn, err := transport.DialReverse(brokerAddr, id, opts)
+	if err != nil {
+		return nil, err
+	}
+	gen := reverseGen.Add(1)
+	go func() {
+		srv.ServeConn(conn)
+		if reverseGen.Load() == gen {
+			logger.Warn("reverse connection to broker lost", "broker", brokerAddr)
+			lastPing.Store(0)
+		}
+	}()
+	return conn, nil
+}
@@ -3,22 +3,91 @@ package main
 import (
 	"flag"
 	"fmt"
+	"math/rand"
 	"net"
 	"net/rpc"
 	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"uk.ac.bris.cs/gameoflife/discovery"
+	"uk.ac.bris.cs/gameoflife/transport"
+	"uk.ac.bris.cs/gameoflife/util"
 )
 
 // 和 broker 中的 Task 保持字段、名字一致（导出）
 type Task struct {
 	StartY, EndY int
-	WorldPart    [][]uint8
+	WorldPart    util.World
+	Boundary     util.Boundary // 左右边界之外看到什么，上下的 halo 行 broker 已经按它填好
+	Rule         string        // B/S 记法的规则，空字符串表示 B3/S23
+}
+
+// 和 broker 中的 RegisterArgs 保持一致
+type RegisterArgs struct {
+	Address   string
+	Port      int
+	Transport string
+	Score     float64
+
+	// 能力协商，broker 据此决定给这个 worker 发什么
+	Version int
+	Codecs  []util.Codec
+	Cores   int
+	Rules   []util.RuleKind
+}
+
+var logger = util.Logger("worker")
+
+// shutdownGrace：收到 Shutdown 之后等一下再关监听，让 RPC 的回复先发出去
+const shutdownGrace = 100 * time.Millisecond
+
+var (
+	shutdown     = make(chan struct{}) // 关闭时 close，main 的 accept 循环据此退出
+	shutdownOnce sync.Once
+)
+
+// tcpOptions：连 broker、连 halo 邻居和接受的连接都用的 TCP 参数（见 transport/tcp.go）
+var tcpOptions transport.TCPOptions
+
+// Worker 类型，halo 模式下持有自己负责的那一段行（见 halo.go）
+type Worker struct {
+	mu   sync.Mutex
+	band *band
+
+	inflight atomic.Int64 // 正在算的 ProcessPart / ProcessTile 个数，排空时等它归零（见 drain.go）
+}
+
+// Ping：broker 心跳检测用，能返回就说明 worker 还活着；同时说明 broker 还记得这个 worker
+func (w *Worker) Ping(_ struct{}, reply *bool) error {
+	lastPing.Store(time.Now().UnixNano())
+	*reply = true
+	return nil
 }
 
-// Worker 类型
-type Worker struct{}
+// Shutdown：broker 关闭整个系统时调用，worker 停止监听并退出
+func (w *Worker) Shutdown(_ struct{}, reply *bool) error {
+	logger.Info("shutdown requested by broker")
+	shutdownOnce.Do(func() { close(shutdown) })
+	*reply = true
+	return nil
+}
 
-// ProcessPart：对 Task.WorldPart 的“中间那几行”应用 GOL 规则，返回结果行
-func (w *Worker) ProcessPart(t Task, reply *[][]uint8) error {
+// PartReply：ProcessPart / ProcessTile 的结果，必须和 broker 那边保持一致
+type PartReply struct {
+	Rows    util.World  // 这一块的下一代
+	Flipped []util.Cell // 这一块里变了的细胞，按整个世界的坐标，broker 直接拼起来不用再对比新旧世界
+}
+
+// ProcessPart：对 Task.WorldPart 的“中间那几行”应用 GOL 规则，返回结果行和变了的细胞
+func (w *Worker) ProcessPart(t Task, reply *PartReply) error {
+	defer w.task()()
 	height := t.EndY - t.StartY
 	if height <= 0 {
 		return fmt.Errorf("invalid task: height <= 0")
@@ -26,82 +95,375 @@ func (w *Worker) ProcessPart(t Task, reply *[][]uint8) error {
 	if len(t.WorldPart) < height+2 {
 		return fmt.Errorf("invalid task: worldPart too small")
 	}
+	rule, err := util.ParseRule(t.Rule)
+	if err != nil {
+		return fmt.Errorf("invalid task: %v", err)
+	}
 
-	width := len(t.WorldPart[0])
-	res := make([][]uint8, height) // new state subm  nohalo
+	reply.Rows, reply.Flipped = nextRows(t.WorldPart, t.StartY, height, t.Boundary, rule)
+	// 收到的这一段算完就没用了，缓冲区留给下一次解码；结果行要等 RPC 回复发完，不能回收
+	util.FreeWorld(t.WorldPart)
+	logger.Debug("task processed", "start_y", t.StartY, "end_y", t.EndY)
+	return nil
+}
 
-	// 对应的核心行在 WorldPart 中是 [1 .. height]
-	for y := 0; y < height; y++ {
-		row := make([]uint8, width)
-		srcY := y + 1 // 对应 worldPart 中的行号
+// TileTask：和 broker 中的 TileTask 保持一致，Cells 四周各带一圈 halo
+type TileTask struct {
+	StartX, EndX int
+	StartY, EndY int
+	Cells        util.World
+	Rule         string
+}
 
-		for x := 0; x < width; x++ {
-			neighbors := 0
+// ProcessTile：按列 / 按块切分时用，halo 已经由 broker 填好，这里不做环绕
+func (w *Worker) ProcessTile(t TileTask, reply *PartReply) error {
+	defer w.task()()
+	width, height := t.EndX-t.StartX, t.EndY-t.StartY
+	if width <= 0 || height <= 0 {
+		return fmt.Errorf("invalid tile: empty")
+	}
+	if len(t.Cells) != height+2 || len(t.Cells[0]) != width+2 {
+		return fmt.Errorf("invalid tile: cells are not %dx%d", width+2, height+2)
+	}
+	rule, err := util.ParseRule(t.Rule)
+	if err != nil {
+		return fmt.Errorf("invalid tile: %v", err)
+	}
 
-			// 8 邻居  垂直方向靠 halo 行，水平方向用环绕
-			for dy := -1; dy <= 1; dy++ {
-				for dx := -1; dx <= 1; dx++ {
-					if dx == 0 && dy == 0 {
-						continue
-					}
-					ny := srcY + dy
-					if ny < 0 || ny >= len(t.WorldPart) {
-						continue
-					}
-					nx := (x + dx + width) % width // 左右环绕
-					if t.WorldPart[ny][nx] == 255 {
-						neighbors++
-					}
-				}
+	reply.Rows, reply.Flipped = nextTile(t.Cells, t.StartX, t.StartY, width, height, rule)
+	util.FreeWorld(t.Cells)
+	logger.Debug("tile processed", "start_x", t.StartX, "end_x", t.EndX, "start_y", t.StartY, "end_y", t.EndY)
+	return nil
+}
+
+// nextTile：cells 四周是 halo，返回中间 height 行 × width 列的下一代，
+// 以及其中变了的细胞（这一块的左上角在世界的 (left, top)）
+func nextTile(cells [][]uint8, left, top, width, height int, rule util.Rule) ([][]uint8, []util.Cell) {
+	packed := packRows(cells)
+	res := util.NewWorld(width, height)
+	flips := make([][]util.Cell, height)
+	splitRows(height, func(y0, y1 int) {
+		next := make([]uint64, len(packed[0]))
+		for y := y0 + 1; y <= y1; y++ {
+			// 左右 halo 列已经在行里，不环绕
+			stepRow(next, packed[y-1], packed[y], packed[y+1], width+2, util.BoundaryDead, rule)
+			unpackRow(res[y-1], next, 1)
+			if rule.Generations() {
+				decayRow(res[y-1], cells[y][1:width+1], rule)
 			}
+			flips[y-1] = appendFlips(nil, cells[y][1:width+1], res[y-1], left, top+y-1)
+		}
+	})
+	return res, slices.Concat(flips...)
+}
 
-			cell := t.WorldPart[srcY][x]
-			if cell == 255 {
-				// 存活细胞
-				if neighbors == 2 || neighbors == 3 {
-					row[x] = 255
-				} else {
-					row[x] = 0
-				}
-			} else {
-				// 死细胞
-				if neighbors == 3 {
-					row[x] = 255
-				} else {
-					row[x] = 0
-				}
+// nextRows：worldPart 第 0 行和最后一行是上下 halo，按 rule 返回中间 height 行的下一代（左右按 boundary 处理），
+// 以及其中变了的细胞（中间第一行是世界的第 startY 行）
+func nextRows(worldPart [][]uint8, startY, height int, boundary util.Boundary, rule util.Rule) ([][]uint8, []util.Cell) {
+	width := len(worldPart[0])
+	packed := packRows(worldPart[:height+2])
+	res := util.NewWorld(width, height)
+	flips := make([][]util.Cell, height)
+
+	// 按行分给多个 goroutine 并行算，每个 goroutine 只写自己那几行 res
+	splitRows(height, func(y0, y1 int) {
+		next := make([]uint64, len(packed[0]))
+		for y := y0; y < y1; y++ {
+			// 对应的核心行在 worldPart 中是 y+1
+			stepRow(next, packed[y], packed[y+1], packed[y+2], width, boundary, rule)
+			unpackRow(res[y], next, 0)
+			if rule.Generations() {
+				decayRow(res[y], worldPart[y+1], rule)
 			}
+			flips[y] = appendFlips(nil, worldPart[y+1], res[y], 0, startY+y)
+		}
+	})
+	return res, slices.Concat(flips...)
+}
+
+// appendFlips：把 old 变成 next 时变了的细胞加到 flipped 后面，这一行是世界的第 y 行、从第 x0 列开始
+func appendFlips(flipped []util.Cell, old, next []uint8, x0, y int) []util.Cell {
+	for x := range next {
+		if next[x] != old[x] {
+			flipped = append(flipped, util.Cell{X: x0 + x, Y: y})
 		}
-		res[y] = row
 	}
+	return flipped
+}
 
-	*reply = res
-	return nil
+// benchmark：用随机世界跑一小段 nextRows，估算这台机器每秒能算多少个细胞，broker 按它分行
+func benchmark() float64 {
+	const width, height = 512, 64
+	part := make([][]uint8, height+2)
+	for y := range part {
+		part[y] = make([]uint8, width)
+		for x := range part[y] {
+			if rand.Intn(4) == 0 {
+				part[y][x] = 255
+			}
+		}
+	}
+
+	cells := 0
+	start := time.Now()
+	for time.Since(start) < 200*time.Millisecond {
+		nextRows(part, 0, height, util.BoundaryTorus, util.Conway)
+		cells += width * height
+	}
+	return float64(cells) / time.Since(start).Seconds()
+}
+
+// registerWithBroker：向 broker 报到，broker 会回拨 ip:port 建立连接（transportName 为 "grpc" 时用 gRPC 回拨）
+// ip 为空时用连 broker 的 TCP 连接的本地地址，正好是 broker 能访问到的网卡 IP
+func registerWithBroker(brokerAddr, token, ip string, port int, transportName string, score float64) error {
+	if ip == "" {
+		var err error
+		if ip, err = localIP(transport.HostPort(brokerAddr)); err != nil {
+			return err
+		}
+	}
+
+	client, err := transport.DialOptions(brokerAddr, transport.Options{Token: token, TCP: tcpOptions})
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	var ok bool
+	args := RegisterArgs{
+		Address:   ip,
+		Port:      port,
+		Transport: transportName,
+		Score:     score,
+		Version:   util.ProtocolVersion,
+		Codecs:    util.Codecs,
+		Cores:     runtime.NumCPU(),
+		Rules:     util.RuleKinds,
+	}
+	return client.Call("Broker.RegisterWorker", args, &ok)
+}
+
+// deregisterFromBroker：排空时告诉 broker 不要再派任务过来，参数和 registerWithBroker 一样
+func deregisterFromBroker(brokerAddr, token, ip string, port int, transportName string) error {
+	if ip == "" {
+		var err error
+		if ip, err = localIP(transport.HostPort(brokerAddr)); err != nil {
+			return err
+		}
+	}
+
+	client, err := transport.DialOptions(brokerAddr, transport.Options{Token: token, TCP: tcpOptions})
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	var ok bool
+	return client.Call("Broker.DeregisterWorker", RegisterArgs{Address: ip, Port: port, Transport: transportName}, &ok)
 }
 
 // main：启动 RPC 服务，监听指定端口
 func main() {
 	port := flag.Int("port", 8031, "port to listen on")
+	listen := flag.String("listen", "", "listen on this address instead of -port, e.g. unix:///tmp/gol-worker-1.sock to skip TCP when the broker runs on the same machine")
+	grpcPort := flag.Int("grpc-port", 0, "also serve the worker over gRPC on this port, 0 = off; the worker then registers as a gRPC worker")
+	brokerAddr := flag.String("broker", "", "broker address to register with, e.g. 172.31.0.10:8080 or grpc://172.31.0.10:8081 (empty = wait for broker to dial)")
+	reverse := flag.Bool("reverse", false, "dial out to -broker and serve RPCs over that connection instead of waiting for the broker to dial in, for workers behind NAT or without open inbound ports")
+	token := flag.String("token", os.Getenv("GOL_TOKEN"), "shared secret for the broker (default $GOL_TOKEN)")
+	logLevel := flag.String("log-level", "info", "log level: debug, info, warn or error")
+	logFormat := flag.String("log-format", "text", "log format: text or json")
+	discoveryAddr := flag.String("discovery", "", "register in Consul for the broker to find, e.g. consul://127.0.0.1:8500/gol/workers (empty = off)")
+	ip := flag.String("ip", "", "address the broker should use to reach this worker (default: detected from the broker or Consul connection)")
+	score := flag.Float64("score", 0, "throughput reported to the broker in cells/sec, used to size this worker's share of rows (0 = measure at startup)")
+	flag.IntVar(&threads, "threads", threads, "goroutines used to compute one part, 1 = single-threaded (default: number of CPUs)")
+	reregister := flag.Duration("reregister-after", 10*time.Second, "register with the broker again when it has not pinged for this long, e.g. after a broker restart (0 = register once)")
+	pprofAddr := flag.String("pprof", "", "serve net/http/pprof profiles on this address, e.g. :6060 (empty = off)")
+	healthAddr := flag.String("health", "", "serve /healthz and /readyz probes over HTTP on this address, e.g. :8090; ready while the RPC listener is up and the worker is not draining (empty = off)")
+	flag.DurationVar(&drainTimeout, "drain-timeout", drainTimeout, "on SIGTERM or Drain, how long to wait for tasks in flight after deregistering before exiting anyway")
+	flag.DurationVar(&tcpOptions.KeepAlive, "tcp-keepalive", 0, "TCP keep-alive probe period, 0 = Go's default (15s), negative = off")
+	noDelay := flag.Bool("tcp-nodelay", true, "set TCP_NODELAY; false lets Nagle's algorithm batch small writes")
+	flag.IntVar(&tcpOptions.ReadBuffer, "tcp-read-buffer", 0, "TCP receive buffer (SO_RCVBUF) in bytes, raise it for large worlds over high-latency links, 0 = OS default")
+	flag.IntVar(&tcpOptions.WriteBuffer, "tcp-write-buffer", 0, "TCP send buffer (SO_SNDBUF) in bytes, 0 = OS default")
 	flag.Parse()
+	tcpOptions.Delay = !*noDelay
 
+	if err := util.ConfigureLogging(os.Stderr, *logFormat, *logLevel); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	logger = logger.With("port", *port)
+	if *pprofAddr != "" {
+		addr, err := util.StartPprof(*pprofAddr)
+		if err != nil {
+			logger.Error("start pprof failed", "addr", *pprofAddr, "err", err)
+			os.Exit(1)
+		}
+		logger.Info("serving pprof", "addr", addr)
+	}
+	if threads < 1 {
+		logger.Error("-threads must be at least 1", "threads", threads)
+		os.Exit(2)
+	}
+	if *reverse && (*brokerAddr == "" || *grpcPort > 0) {
+		logger.Error("-reverse needs -broker and cannot be used with -grpc-port")
+		os.Exit(2)
+	}
+	if tcpOptions.ReadBuffer < 0 || tcpOptions.WriteBuffer < 0 {
+		logger.Error("-tcp-read-buffer and -tcp-write-buffer must not be negative", "read_buffer", tcpOptions.ReadBuffer, "write_buffer", tcpOptions.WriteBuffer)
+		os.Exit(2)
+	}
+
+	var consul *discovery.Consul
+	if *discoveryAddr != "" {
+		var err error
+		if consul, err = discovery.Parse(*discoveryAddr); err != nil {
+			logger.Error("invalid -discovery", "err", err)
+			os.Exit(2)
+		}
+	}
+
+	worker := new(Worker)
 	srv := rpc.NewServer()
-	if err := srv.RegisterName("Worker", new(Worker)); err != nil {
-		fmt.Println("RegisterName error:", err)
+	if err := srv.RegisterName("Worker", worker); err != nil {
+		logger.Error("register RPC service failed", "err", err)
 		os.Exit(1)
 	}
 
 	addr := fmt.Sprintf(":%d", *port)
-	l, err := net.Listen("tcp", addr)
+	if *listen != "" {
+		addr = *listen
+	}
+	l, err := transport.Listen(addr)
 	if err != nil {
-		fmt.Println("Listen error:", err)
+		logger.Error("listen failed", "addr", addr, "err", err)
 		os.Exit(1)
 	}
-	fmt.Printf("Worker listening on %s\n", addr)
+	l = transport.TuneListener(l, tcpOptions)
+	logger.Info("worker listening", "addr", addr, "threads", threads)
+	if *healthAddr != "" {
+		// 监听建立之后才开探活，/readyz 能回 200 时 RPC 一定连得上
+		haddr, err := util.StartHealth(*healthAddr, ready)
+		if err != nil {
+			logger.Error("start health probes failed", "addr", *healthAddr, "err", err)
+			os.Exit(1)
+		}
+		logger.Info("serving health probes", "addr", haddr)
+	}
+
+	// 向 broker 注册的地址：Unix 域套接字注册路径，TCP 注册实际监听的端口
+	registerIP, registerPort, registerTransport := *ip, *port, ""
+	if transport.IsUnix(addr) {
+		registerIP, registerPort, registerTransport = strings.TrimPrefix(addr, transport.UnixScheme), 0, "unix"
+	} else if tcp, ok := l.Addr().(*net.TCPAddr); ok {
+		registerPort = tcp.Port
+	}
+
+	// 可选的 gRPC 入口，和 net/rpc 共用同一个 worker
+	var grpcServer *grpc.Server
+	if *grpcPort > 0 {
+		gl, err := net.Listen("tcp", fmt.Sprintf(":%d", *grpcPort))
+		if err != nil {
+			logger.Error("listen failed", "grpc_port", *grpcPort, "err", err)
+			os.Exit(1)
+		}
+		gl = transport.TuneListener(gl, tcpOptions)
+		grpcServer = transport.NewGRPCServer(nil, worker, "")
+		go func() {
+			if err := grpcServer.Serve(gl); err != nil {
+				logger.Error("gRPC server stopped", "err", err)
+			}
+		}()
+		logger.Info("worker gRPC listening", "grpc_port", *grpcPort)
+		registerIP, registerPort, registerTransport = *ip, *grpcPort, "grpc"
+	}
+	if *reverse {
+		registerIP, registerPort, registerTransport = reverseID(), 0, "reverse"
+	}
+
+	if *brokerAddr != "" {
+		deregister = func() error {
+			return deregisterFromBroker(*brokerAddr, *token, registerIP, registerPort, registerTransport)
+		}
+	}
+	worker.drainOnSignal()
+
+	// 监听建立之后再注册，保证 broker 回拨时能连上
+	if *brokerAddr != "" || consul != nil {
+		go func() {
+			if *score <= 0 {
+				*score = benchmark()
+				logger.Info("benchmark done", "score", *score)
+			}
+			if consul != nil && !*reverse {
+				go func() {
+					if registerTransport == "unix" {
+						announce(consul, discovery.Entry{Address: transport.UnixScheme + registerIP, Score: *score})
+						return
+					}
+					host := *ip
+					for host == "" {
+						var err error
+						if host, err = localIP(consul.Host()); err != nil {
+							logger.Warn("reach discovery failed, retrying", "discovery", consul, "err", err)
+							if !sleep(registerBackoffMax) {
+								return
+							}
+						}
+					}
+					address := net.JoinHostPort(host, strconv.Itoa(registerPort))
+					if registerTransport == "grpc" {
+						address = transport.GRPCScheme + address
+					}
+					announce(consul, discovery.Entry{Address: address, Score: *score})
+				}()
+			}
+			if *brokerAddr == "" {
+				return
+			}
+			// 失败会退避重试，broker 重启后也会重新注册
+			keepRegistered(func() error {
+				if *reverse {
+					// 先连上 broker，注册时 broker 就用这条连接
+					conn, err := serveReverse(srv, *brokerAddr, registerIP, transport.Options{Token: *token, TCP: tcpOptions})
+					if err != nil {
+						return err
+					}
+					if err := registerWithBroker(*brokerAddr, *token, registerIP, registerPort, registerTransport, *score); err != nil {
+						_ = conn.Close()
+						return err
+					}
+					logger.Info("registered with broker over a reverse connection", "broker", *brokerAddr, "id", registerIP, "score", *score)
+					return nil
+				}
+				if err := registerWithBroker(*brokerAddr, *token, registerIP, registerPort, registerTransport, *score); err != nil {
+					return err
+				}
+				logger.Info("registered with broker", "broker", *brokerAddr, "score", *score)
+				return nil
+			}, *reregister)
+		}()
+	}
+
+	// Shutdown 之后关掉监听，accept 循环随之退出
+	go func() {
+		<-shutdown
+		time.Sleep(shutdownGrace)
+		if grpcServer != nil {
+			grpcServer.Stop()
+		}
+		_ = l.Close()
+	}()
 
 	for {
 		conn, err := l.Accept()
 		if err != nil {
-			fmt.Println("Accept error:", err)
+			select {
+			case <-shutdown:
+				logger.Info("worker shut down")
+				return
+			default:
+			}
+			logger.Warn("accept connection failed", "err", err)
 			continue
 		}
 		go srv.ServeConn(conn)